@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -11,12 +12,18 @@ import (
 	"google.golang.org/api/option"
 )
 
+// ErrFallback acompanha as respostas estáticas (fallback do modo ou aviso de bloqueio de segurança)
+// devolvidas no lugar do texto do modelo, seja por erro, timeout, resposta vazia ou bloqueio. O texto
+// retornado junto continua utilizável; o erro só indica que ele não veio da IA.
+var ErrFallback = errors.New("resposta estática no lugar da IA")
+
 type Client struct {
-	model *genai.GenerativeModel
+	model  *genai.GenerativeModel
+	limits Limits
 }
 
-// Cria um novo cliente da IA Gemini
-func NewClient() (*Client, error) {
+// Cria um novo cliente da IA Gemini com os limites de resposta informados (services.Config.AILimits)
+func NewClient(limits Limits) (*Client, error) {
 	apiKey := os.Getenv("GOOGLE_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("GOOGLE_API_KEY não configurada")
@@ -29,65 +36,106 @@ func NewClient() (*Client, error) {
 	}
 
 	model := client.GenerativeModel("gemini-1.5-flash")
+	model.SafetySettings = loadSafetySettings()
 
-	return &Client{model: model}, nil
+	return &Client{model: model, limits: limits}, nil
 }
 
-// Gera resposta da IA para problemas técnicos
-func (c *Client) GenerateResponse(problema string) (string, error) {
+// Gera resposta da IA para problemas técnicos; ctx limita o tempo total, inclusive as novas tentativas
+func (c *Client) GenerateResponse(ctx context.Context, problema string) (string, error) {
 	if c.model == nil {
-		return generateTechFallback(problema), nil
+		return generateTechFallback(problema), ErrFallback
 	}
 
-	ctx := context.Background()
 	prompt := fmt.Sprintf(`Como assistente técnico especializado, resolva este problema de forma clara e prática:
 
 Problema: %s
 
-Forneça uma solução objetiva em até 200 palavras, incluindo:
+Forneça uma solução objetiva em até %d palavras, incluindo:
 - Diagnóstico do problema
 - Passos para resolver
 - Dicas de prevenção
 
-Seja direto e útil, lembrando que você pode estar lidando com pessoas leigas no assunto.`, problema)
+Seja direto e útil, lembrando que você pode estar lidando com pessoas leigas no assunto.`, problema, c.limits.Tech.Words)
 
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	resp, err := c.generate(ctx, c.limits.Tech, prompt)
+	if isBlocked(err) {
+		log.Printf("Resposta da IA Gemini bloqueada (técnico): %v", err)
+		return safetyBlockedMessage, fmt.Errorf("%w: %v", ErrFallback, err)
+	}
 	if err != nil {
 		log.Printf("Erro na IA Gemini (técnico): %v", err)
-		return generateTechFallback(problema), nil
+		return generateTechFallback(problema), fmt.Errorf("%w: %v", ErrFallback, err)
 	}
 
-	if len(resp.Candidates) > 0 && len(resp.Candidates[0].Content.Parts) > 0 {
-		return fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0]), nil
+	text, blocked := responseText(resp)
+	if blocked {
+		log.Printf("Resposta da IA Gemini bloqueada por segurança (técnico): %s", blockDetails(resp))
+		return safetyBlockedMessage, fmt.Errorf("%w: bloqueada por segurança", ErrFallback)
+	}
+	if text != "" {
+		return truncateAtSentence(text, c.limits.Tech.Chars), nil
 	}
 
-	return generateTechFallback(problema), nil
+	log.Printf("Resposta vazia da IA Gemini (técnico), usando fallback")
+	return generateTechFallback(problema), fmt.Errorf("%w: resposta vazia", ErrFallback)
 }
 
-// Gera resposta livre da IA
-func (c *Client) GenerateFreeResponse(pergunta string) (string, error) {
+// Gera resposta livre da IA; ctx limita o tempo total, inclusive as novas tentativas
+func (c *Client) GenerateFreeResponse(ctx context.Context, pergunta string) (string, error) {
 	if c.model == nil {
-		return generateFreeFallback(), nil
+		return generateFreeFallback(), ErrFallback
 	}
 
-	ctx := context.Background()
 	prompt := fmt.Sprintf(`Responda de forma útil e amigável em português:
 
 Pergunta: %s
 
-Seja informativo, claro e conciso (máximo 250 palavras).`, pergunta)
+Seja informativo, claro e conciso (máximo %d palavras).`, pergunta, c.limits.Free.Words)
 
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	resp, err := c.generate(ctx, c.limits.Free, prompt)
+	if isBlocked(err) {
+		log.Printf("Resposta da IA Gemini bloqueada (livre): %v", err)
+		return safetyBlockedMessage, fmt.Errorf("%w: %v", ErrFallback, err)
+	}
 	if err != nil {
 		log.Printf("Erro na IA Gemini (livre): %v", err)
-		return generateFreeFallback(), nil
+		return generateFreeFallback(), fmt.Errorf("%w: %v", ErrFallback, err)
 	}
 
-	if len(resp.Candidates) > 0 && len(resp.Candidates[0].Content.Parts) > 0 {
-		return fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0]), nil
+	text, blocked := responseText(resp)
+	if blocked {
+		log.Printf("Resposta da IA Gemini bloqueada por segurança (livre): %s", blockDetails(resp))
+		return safetyBlockedMessage, fmt.Errorf("%w: bloqueada por segurança", ErrFallback)
+	}
+	if text != "" {
+		return truncateAtSentence(text, c.limits.Free.Chars), nil
 	}
 
-	return generateFreeFallback(), nil
+	log.Printf("Resposta vazia da IA Gemini (livre), usando fallback")
+	return generateFreeFallback(), fmt.Errorf("%w: resposta vazia", ErrFallback)
+}
+
+// generate chama o modelo com o timeout do modo, repetindo até mode.Retries vezes em erros que não
+// sejam bloqueio de segurança. Cada tentativa herda ctx, de modo que o prazo da requisição (ou seu
+// cancelamento) interrompe a chamada e as tentativas restantes. Esgotadas as tentativas, o chamador
+// responde com o fallback do modo.
+func (c *Client) generate(ctx context.Context, mode ModeLimit, prompt string) (*genai.GenerateContentResponse, error) {
+	var err error
+	for attempt := 0; attempt <= mode.Retries; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, mode.Timeout)
+		var resp *genai.GenerateContentResponse
+		resp, err = c.model.GenerateContent(attemptCtx, genai.Text(prompt))
+		cancel()
+		if err == nil || isBlocked(err) {
+			return resp, err
+		}
+		log.Printf("Tentativa %d da IA Gemini falhou: %v", attempt+1, err)
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+	return nil, err
 }
 
 // Fallbacks caso a IA não esteja disponível
@@ -142,3 +190,12 @@ func generateFreeFallback() string {
 		"• Para fatura: Use a opção 'Fatura' no menu\n\n" +
 		"Agradecemos sua compreensão! 🙏"
 }
+
+// Ping verifica se a API do Gemini responde, consultando os metadados do modelo (não consome geração).
+func (c *Client) Ping(ctx context.Context) error {
+	if c.model == nil {
+		return fmt.Errorf("modelo Gemini não configurado")
+	}
+	_, err := c.model.Info(ctx)
+	return err
+}
@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClientWithoutModelReturnsFallbackSentinel(t *testing.T) {
+	c := &Client{limits: DefaultLimits()}
+
+	text, err := c.GenerateResponse(context.Background(), "minha internet caiu")
+	if !errors.Is(err, ErrFallback) {
+		t.Errorf("GenerateResponse err = %v; esperado ErrFallback", err)
+	}
+	if text != generateTechFallback("minha internet caiu") {
+		t.Errorf("GenerateResponse = %q; esperado o fallback técnico", text)
+	}
+
+	text, err = c.GenerateFreeResponse(context.Background(), "qual a capital?")
+	if !errors.Is(err, ErrFallback) {
+		t.Errorf("GenerateFreeResponse err = %v; esperado ErrFallback", err)
+	}
+	if text != generateFreeFallback() {
+		t.Errorf("GenerateFreeResponse = %q; esperado o fallback livre", text)
+	}
+}
+
+func TestResponseTextTreatsBlankAsEmpty(t *testing.T) {
+	for _, blank := range []string{"", "   \n", "**", "\u200b---"} {
+		if !isBlank(blank) {
+			t.Errorf("isBlank(%q) = false", blank)
+		}
+	}
+	if isBlank("ok") {
+		t.Error(`isBlank("ok") = true`)
+	}
+}
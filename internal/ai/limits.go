@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ModeLimit define o tamanho pedido no prompt (palavras), o corte rígido da resposta (caracteres, 0 desativa),
+// o tempo máximo de cada chamada e quantas novas tentativas são feitas antes de usar o fallback do modo.
+type ModeLimit struct {
+	Words   int
+	Chars   int
+	Timeout time.Duration
+	Retries int
+}
+
+// Limits agrupa os limites de resposta por modo de uso da IA.
+type Limits struct {
+	Tech ModeLimit
+	Free ModeLimit
+}
+
+// DefaultLimits retorna os limites padrão. O corte de 4000 caracteres mantém a resposta abaixo do limite de
+// texto do WhatsApp; o suporte técnico tolera mais espera que o assistente livre, mas as duas tentativas
+// somadas (2 × 12s) ainda cabem no HTTP_WRITE_TIMEOUT padrão de 30s.
+func DefaultLimits() Limits {
+	return Limits{
+		Tech: ModeLimit{Words: 200, Chars: 4000, Timeout: 12 * time.Second, Retries: 1},
+		Free: ModeLimit{Words: 250, Chars: 4000, Timeout: 8 * time.Second, Retries: 0},
+	}
+}
+
+// truncateAtSentence corta o texto em até max caracteres, preferindo terminar no fim de uma frase
+// (ou, na falta dela, de uma palavra). max <= 0 desativa o corte.
+func truncateAtSentence(text string, max int) string {
+	runes := []rune(text)
+	if max <= 0 || len(runes) <= max {
+		return text
+	}
+
+	cut := string(runes[:max])
+	if i := lastSentenceEnd(cut); i > len(cut)/2 {
+		return strings.TrimSpace(cut[:i])
+	}
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
+
+// lastSentenceEnd retorna a posição logo após o último fim de frase (".", "!", "?" ou quebra de linha), ou -1.
+func lastSentenceEnd(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		switch s[i] {
+		case '.', '!', '?', '\n':
+			if i == len(s)-1 || s[i+1] == ' ' || s[i+1] == '\n' || s[i] == '\n' {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
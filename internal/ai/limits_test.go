@@ -0,0 +1,33 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateAtSentence(t *testing.T) {
+	cases := []struct {
+		name, text string
+		max        int
+		want       string
+	}{
+		{"dentro do limite", "Reinicie o roteador.", 100, "Reinicie o roteador."},
+		{"corte desativado", strings.Repeat("a", 50), 0, strings.Repeat("a", 50)},
+		{"fim de frase", "Reinicie o roteador. Depois verifique os cabos de rede.", 30, "Reinicie o roteador."},
+		{"fim de palavra", "Reinicie o roteador e aguarde alguns minutos", 25, "Reinicie o roteador e…"},
+		{"caracteres, não bytes", "Conexão estável. Ótimo sinal agora", 17, "Conexão estável."},
+	}
+	for _, c := range cases {
+		if got := truncateAtSentence(c.text, c.max); got != c.want {
+			t.Errorf("%s: truncateAtSentence = %q; esperado %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestTruncateAtSentenceIgnoresEarlySentence(t *testing.T) {
+	// Um fim de frase antes da metade do corte descartaria texto demais: corta na palavra.
+	got := truncateAtSentence("Ok. Verifique se a luz do modem está acesa e piscando", 40)
+	if got != "Ok. Verifique se a luz do modem está…" {
+		t.Errorf("truncateAtSentence = %q; esperado corte na última palavra", got)
+	}
+}
@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// safetyBlockedMessage é exibido quando os filtros de segurança do Gemini bloqueiam a resposta.
+const safetyBlockedMessage = "⚠️ Não posso responder a essa mensagem. Tente reformular sua pergunta ou digite *ATENDENTE* para falar com nossa equipe."
+
+// isBlocked indica se o erro do Gemini corresponde a prompt ou resposta bloqueados.
+func isBlocked(err error) bool {
+	var blocked *genai.BlockedError
+	return errors.As(err, &blocked)
+}
+
+// responseText concatena as partes de texto do primeiro candidato, ignorando partes que não são texto.
+// blocked é verdadeiro quando o prompt ou o candidato foram barrados pelos filtros de segurança.
+// Texto sem conteúdo legível (só espaços, caracteres invisíveis ou pontuação) retorna "".
+func responseText(resp *genai.GenerateContentResponse) (text string, blocked bool) {
+	if resp == nil {
+		return "", false
+	}
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+		return "", true
+	}
+	if len(resp.Candidates) == 0 {
+		return "", false
+	}
+
+	candidate := resp.Candidates[0]
+	if candidate.FinishReason == genai.FinishReasonSafety {
+		return "", true
+	}
+	if candidate.Content == nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if t, ok := part.(genai.Text); ok {
+			b.WriteString(string(t))
+		}
+	}
+	text = strings.TrimSpace(b.String())
+	if isBlank(text) {
+		return "", false
+	}
+	return text, false
+}
+
+// isBlank indica se o texto não tem nenhuma letra ou dígito, como respostas com apenas espaços,
+// quebras de linha, caracteres de largura zero ou marcação solta ("**", "---").
+func isBlank(text string) bool {
+	return strings.IndexFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) == -1
+}
+
+// blockDetails descreve o motivo do bloqueio e as categorias sinalizadas, para diagnóstico em log.
+func blockDetails(resp *genai.GenerateContentResponse) string {
+	var ratings []*genai.SafetyRating
+	reason := ""
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+		reason = "prompt: " + resp.PromptFeedback.BlockReason.String()
+		ratings = resp.PromptFeedback.SafetyRatings
+	} else if len(resp.Candidates) > 0 {
+		reason = "candidato: " + resp.Candidates[0].FinishReason.String()
+		ratings = resp.Candidates[0].SafetyRatings
+	}
+
+	var flagged []string
+	for _, r := range ratings {
+		if r.Blocked || r.Probability >= genai.HarmProbabilityMedium {
+			flagged = append(flagged, r.Category.String()+"="+r.Probability.String())
+		}
+	}
+	if len(flagged) > 0 {
+		reason += " (" + strings.Join(flagged, ", ") + ")"
+	}
+	return reason
+}
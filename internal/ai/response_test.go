@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func candidateResponse(reason genai.FinishReason, parts ...genai.Part) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		FinishReason: reason,
+		Content:      &genai.Content{Parts: parts},
+	}}}
+}
+
+func TestResponseTextConcatenatesTextParts(t *testing.T) {
+	resp := candidateResponse(genai.FinishReasonStop,
+		genai.Text("Reinicie o roteador. "),
+		genai.Blob{MIMEType: "image/png", Data: []byte{1}},
+		genai.Text("Depois teste a conexão.\n"),
+	)
+	text, blocked := responseText(resp)
+	if blocked || text != "Reinicie o roteador. Depois teste a conexão." {
+		t.Errorf("responseText = %q, %v; esperado as partes de texto concatenadas", text, blocked)
+	}
+}
+
+func TestResponseTextDetectsBlocks(t *testing.T) {
+	promptBlocked := &genai.GenerateContentResponse{PromptFeedback: &genai.PromptFeedback{BlockReason: genai.BlockReasonSafety}}
+	if _, blocked := responseText(promptBlocked); !blocked {
+		t.Error("prompt bloqueado não detectado")
+	}
+	if _, blocked := responseText(candidateResponse(genai.FinishReasonSafety, genai.Text("parcial"))); !blocked {
+		t.Error("candidato bloqueado por segurança não detectado")
+	}
+}
+
+func TestResponseTextWithoutContent(t *testing.T) {
+	for name, resp := range map[string]*genai.GenerateContentResponse{
+		"nil":            nil,
+		"sem candidatos": {},
+		"sem conteúdo":   {Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonStop}}},
+		"só marcação":    candidateResponse(genai.FinishReasonStop, genai.Text("**"), genai.Text(" ---")),
+	} {
+		if text, blocked := responseText(resp); text != "" || blocked {
+			t.Errorf("%s: responseText = %q, %v; esperado vazio sem bloqueio", name, text, blocked)
+		}
+	}
+}
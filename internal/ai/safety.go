@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// harmThresholds mapeia os valores aceitos nas variáveis de ambiente para os limiares do Gemini.
+var harmThresholds = map[string]genai.HarmBlockThreshold{
+	"low_and_above":    genai.HarmBlockLowAndAbove,
+	"medium_and_above": genai.HarmBlockMediumAndAbove,
+	"only_high":        genai.HarmBlockOnlyHigh,
+	"none":             genai.HarmBlockNone,
+}
+
+// safetyCategories lista as categorias configuráveis e a variável de ambiente de cada uma.
+var safetyCategories = []struct {
+	env      string
+	category genai.HarmCategory
+}{
+	{"GEMINI_SAFETY_HARASSMENT", genai.HarmCategoryHarassment},
+	{"GEMINI_SAFETY_HATE_SPEECH", genai.HarmCategoryHateSpeech},
+	{"GEMINI_SAFETY_SEXUALLY_EXPLICIT", genai.HarmCategorySexuallyExplicit},
+	{"GEMINI_SAFETY_DANGEROUS_CONTENT", genai.HarmCategoryDangerousContent},
+}
+
+// loadSafetySettings monta os filtros de segurança do modelo. GEMINI_SAFETY_THRESHOLD define o
+// limiar de todas as categorias (padrão medium_and_above) e GEMINI_SAFETY_<CATEGORIA> o sobrescreve.
+// Valores: low_and_above, medium_and_above, only_high, none.
+func loadSafetySettings() []*genai.SafetySetting {
+	def := parseThreshold("GEMINI_SAFETY_THRESHOLD", genai.HarmBlockMediumAndAbove)
+
+	settings := make([]*genai.SafetySetting, 0, len(safetyCategories))
+	for _, c := range safetyCategories {
+		settings = append(settings, &genai.SafetySetting{
+			Category:  c.category,
+			Threshold: parseThreshold(c.env, def),
+		})
+	}
+	return settings
+}
+
+// parseThreshold lê um limiar do ambiente, mantendo def se ausente ou inválido.
+func parseThreshold(key string, def genai.HarmBlockThreshold) genai.HarmBlockThreshold {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
+	if v == "" {
+		return def
+	}
+	if t, ok := harmThresholds[v]; ok {
+		return t
+	}
+	log.Printf("Valor inválido para %s: %q (usando padrão)", key, v)
+	return def
+}
@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func thresholds(settings []*genai.SafetySetting) map[genai.HarmCategory]genai.HarmBlockThreshold {
+	got := make(map[genai.HarmCategory]genai.HarmBlockThreshold, len(settings))
+	for _, s := range settings {
+		got[s.Category] = s.Threshold
+	}
+	return got
+}
+
+func TestLoadSafetySettingsDefaults(t *testing.T) {
+	got := thresholds(loadSafetySettings())
+	if len(got) != len(safetyCategories) {
+		t.Fatalf("%d categorias; esperado %d", len(got), len(safetyCategories))
+	}
+	for category, threshold := range got {
+		if threshold != genai.HarmBlockMediumAndAbove {
+			t.Errorf("%s = %s; esperado medium_and_above por padrão", category, threshold)
+		}
+	}
+}
+
+func TestLoadSafetySettingsFromEnv(t *testing.T) {
+	t.Setenv("GEMINI_SAFETY_THRESHOLD", "only_high")
+	t.Setenv("GEMINI_SAFETY_HARASSMENT", "LOW_AND_ABOVE")
+	t.Setenv("GEMINI_SAFETY_HATE_SPEECH", "bloquear tudo")
+
+	got := thresholds(loadSafetySettings())
+	if got[genai.HarmCategoryHarassment] != genai.HarmBlockLowAndAbove {
+		t.Errorf("assédio = %s; esperado o valor da categoria", got[genai.HarmCategoryHarassment])
+	}
+	if got[genai.HarmCategoryHateSpeech] != genai.HarmBlockOnlyHigh {
+		t.Errorf("discurso de ódio = %s; esperado o limiar geral com valor inválido", got[genai.HarmCategoryHateSpeech])
+	}
+	if got[genai.HarmCategoryDangerousContent] != genai.HarmBlockOnlyHigh {
+		t.Errorf("conteúdo perigoso = %s; esperado o limiar geral", got[genai.HarmCategoryDangerousContent])
+	}
+}
+
+func TestBlockDetailsListsFlaggedCategories(t *testing.T) {
+	resp := candidateResponse(genai.FinishReasonSafety)
+	resp.Candidates[0].SafetyRatings = []*genai.SafetyRating{
+		{Category: genai.HarmCategoryDangerousContent, Probability: genai.HarmProbabilityHigh},
+		{Category: genai.HarmCategoryHarassment, Probability: genai.HarmProbabilityLow},
+	}
+
+	got := blockDetails(resp)
+	if !strings.HasPrefix(got, "candidato: ") || !strings.Contains(got, genai.HarmCategoryDangerousContent.String()) {
+		t.Errorf("blockDetails = %q; esperado o motivo e a categoria sinalizada", got)
+	}
+	if strings.Contains(got, genai.HarmCategoryHarassment.String()) {
+		t.Errorf("blockDetails = %q; categoria com probabilidade baixa não deveria aparecer", got)
+	}
+}
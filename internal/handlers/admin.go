@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"leadprojectarrumado/internal/security"
+	"leadprojectarrumado/internal/services"
+)
+
+// AdminService define as operações administrativas expostas pelos endpoints /admin.
+type AdminService interface {
+	HandoffQueueDepth() (int64, error)
+	ResolveHandoff(userID string) (int64, error)
+	MenuSelectionCounts(from, to time.Time) (map[string]int, error)
+	SetMaintenance(enabled bool)
+	InMaintenance() bool
+	FeatureFlags() map[string]bool
+	SetFeatureFlag(name string, enabled bool) error
+	ResetSession(userID string) error
+	ReloadDenylist() (int, error)
+	StartNotificationReplay() error
+	StartStoreReplay() error
+	InspectSession(userID string) (services.SessionInspection, error)
+	Snapshot(ctx context.Context) (services.ServiceStats, error)
+}
+
+// userIDPattern aceita os identificadores usados pelos canais (telefone do WhatsApp, UUID da web).
+var userIDPattern = regexp.MustCompile(`^[A-Za-z0-9+_.:@-]{1,128}$`)
+
+// AdminHandler lida com requisições administrativas (uso interno da equipe de suporte).
+type AdminHandler struct {
+	service AdminService
+	// rawToken libera ?raw=true em GET /admin/session/{userID} (ADMIN_RAW_TOKEN, header X-Admin-Raw-Token).
+	rawToken string
+}
+
+// NewAdminHandler cria um novo handler para os endpoints administrativos.
+func NewAdminHandler(service AdminService) *AdminHandler {
+	return &AdminHandler{service: service, rawToken: os.Getenv("ADMIN_RAW_TOKEN")}
+}
+
+// handoffResolveRequest representa o corpo de POST /admin/handoff/resolve.
+type handoffResolveRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// HandleHandoffResolve marca o atendimento humano do usuário informado como resolvido, retirando-o da fila.
+// Sem user_id responde 400; com um usuário fora da fila, 404.
+func (h *AdminHandler) HandleHandoffResolve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req handoffResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "JSON inválido"})
+		return
+	}
+
+	depth, err := h.service.ResolveHandoff(strings.TrimSpace(req.UserID))
+	if errors.Is(err, services.ErrHandoffUserRequired) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrHandoffNotQueued) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "handoff_queue": depth})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Erro ao resolver atendimento humano")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Erro interno do servidor"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"handoff_queue": depth})
+}
+
+// HandleMenuAnalytics retorna a contagem de escolhas por opção do menu no período informado.
+// Parâmetros opcionais: from e to no formato AAAA-MM-DD (padrão: últimos 30 dias, to inclusivo).
+func (h *AdminHandler) HandleMenuAnalytics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	to := time.Now().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	from := to.AddDate(0, 0, -30)
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Parâmetro from inválido (use AAAA-MM-DD)"})
+			return
+		}
+		from = t
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Parâmetro to inválido (use AAAA-MM-DD)"})
+			return
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+
+	counts, err := h.service.MenuSelectionCounts(from, to)
+	if err != nil {
+		log.Error().Err(err).Msg("Erro ao consultar analytics do menu")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Erro interno do servidor"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"counts": counts,
+	})
+}
+
+// maintenanceRequest representa o corpo de POST /admin/maintenance.
+type maintenanceRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// HandleMaintenance consulta (GET) ou altera (POST) o modo de manutenção em tempo de execução.
+func (h *AdminHandler) HandleMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == http.MethodPost {
+		var req maintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Informe {\"enabled\": true|false}"})
+			return
+		}
+		h.service.SetMaintenance(*req.Enabled)
+		log.Info().Bool("enabled", *req.Enabled).Msg("Modo de manutenção alterado")
+	}
+
+	json.NewEncoder(w).Encode(map[string]bool{"maintenance": h.service.InMaintenance()})
+}
+
+// featureFlagRequest representa o corpo de POST /admin/flags.
+type featureFlagRequest struct {
+	Name    string `json:"name"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// HandleFeatureFlags lista (GET) ou altera (POST) as feature flags dos subsistemas (ai, sheets, whatsapp).
+func (h *AdminHandler) HandleFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == http.MethodPost {
+		var req featureFlagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" || req.Enabled == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Informe {\"name\": \"ai|sheets|whatsapp\", \"enabled\": true|false}"})
+			return
+		}
+		if err := h.service.SetFeatureFlag(req.Name, *req.Enabled); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		log.Info().Str("flag", req.Name).Bool("enabled", *req.Enabled).Msg("Feature flag alterada")
+	}
+
+	json.NewEncoder(w).Encode(h.service.FeatureFlags())
+}
+
+// sessionResetRequest representa o corpo de POST /admin/session/reset.
+type sessionResetRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// HandleSessionReset descarta a sessão de um usuário travado, sem depender de ele digitar MENU.
+func (h *AdminHandler) HandleSessionReset(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req sessionResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Informe {\"user_id\": \"...\"}"})
+		return
+	}
+	userID := strings.TrimSpace(req.UserID)
+	if !userIDPattern.MatchString(userID) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "user_id inválido"})
+		return
+	}
+
+	if err := h.service.ResetSession(userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Erro ao reiniciar sessão")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Erro interno do servidor"})
+		return
+	}
+
+	log.Info().Str("user_id", userID).Msg("Sessão reiniciada via admin")
+	json.NewEncoder(w).Encode(map[string]interface{}{"user_id": userID, "reset": true})
+}
+
+// HandleDenylistReload relê a lista de usuários bloqueados (DENYLIST_FILE) sem reiniciar o serviço.
+func (h *AdminHandler) HandleDenylistReload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	n, err := h.service.ReloadDenylist()
+	if err != nil {
+		log.Error().Err(err).Msg("Erro ao recarregar denylist")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Erro ao recarregar denylist"})
+		return
+	}
+
+	log.Info().Int("entries", n).Msg("Denylist recarregada via admin")
+	json.NewEncoder(w).Encode(map[string]int{"entries": n})
+}
+
+// HandleSessionInspect mostra a sessão de um usuário (GET /admin/session/{userID}): estado, dados coletados,
+// TTLs e histórico recente. Dados pessoais são mascarados; ?raw=true os mostra sem máscara e exige também
+// o header X-Admin-Raw-Token.
+func (h *AdminHandler) HandleSessionInspect(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := strings.TrimPrefix(r.URL.Path, "/admin/session/")
+	if !userIDPattern.MatchString(userID) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "user_id inválido"})
+		return
+	}
+	raw := r.URL.Query().Get("raw") == "true"
+	if raw && !security.RawAccess(r, h.rawToken) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Acesso sem máscara requer X-Admin-Raw-Token"})
+		return
+	}
+
+	inspection, err := h.service.InspectSession(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Erro ao consultar sessão")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Erro interno do servidor"})
+		return
+	}
+
+	if raw {
+		log.Warn().Str("user_id", userID).Msg("Sessão consultada sem máscara via admin")
+	} else {
+		inspection = inspection.Redacted()
+	}
+	json.NewEncoder(w).Encode(inspection)
+}
+
+// HandleSessionStats mostra as sessões em andamento (GET /admin/sessions/stats): total, contagem por estado
+// e a idade da sessão ativa mais antiga. Com o Redis fora responde 503.
+func (h *AdminHandler) HandleSessionStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	stats, err := h.service.Snapshot(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Erro ao contar sessões ativas")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Redis indisponível"})
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
+// HandleNotificationsReplay dispara em segundo plano o reenvio dos avisos que falharam em todas as tentativas
+// (dead-letter) e responde 202 na hora; com um reenvio em andamento responde 409.
+func (h *AdminHandler) HandleNotificationsReplay(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := h.service.StartNotificationReplay()
+	if errors.Is(err, services.ErrReplayInProgress) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Erro ao reenviar avisos da dead-letter")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Erro ao reenviar avisos"})
+		return
+	}
+
+	log.Info().Msg("Reenvio dos avisos da dead-letter iniciado via admin")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "started"})
+}
+
+// HandleStoresReplay dispara em segundo plano o reenvio aos backends adicionais dos registros que falharam em
+// todas as tentativas (dead-letter) e responde 202 na hora; com um reenvio em andamento responde 409.
+func (h *AdminHandler) HandleStoresReplay(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := h.service.StartStoreReplay()
+	if errors.Is(err, services.ErrStoreReplayInProgress) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Erro ao reenviar registros aos backends")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Erro ao reenviar registros"})
+		return
+	}
+
+	log.Info().Msg("Reenvio da dead-letter dos backends iniciado via admin")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "started"})
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"leadprojectarrumado/internal/services"
+)
+
+// queueAdminService simula a fila de atendimento humano; os demais métodos de AdminService não são usados.
+type queueAdminService struct {
+	AdminService
+	queued map[string]bool
+}
+
+func (q *queueAdminService) ResolveHandoff(userID string) (int64, error) {
+	if userID == "" {
+		return 0, services.ErrHandoffUserRequired
+	}
+	if !q.queued[userID] {
+		return int64(len(q.queued)), services.ErrHandoffNotQueued
+	}
+	delete(q.queued, userID)
+	return int64(len(q.queued)), nil
+}
+
+func resolveHandoff(t *testing.T, h *AdminHandler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/admin/handoff/resolve", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleHandoffResolve(rec, req)
+	return rec
+}
+
+func TestHandoffResolveStatusCodes(t *testing.T) {
+	svc := &queueAdminService{queued: map[string]bool{"5544999998888": true, "5544911112222": true}}
+	h := NewAdminHandler(svc)
+
+	cases := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"sem corpo", "", http.StatusBadRequest},
+		{"user_id em branco", `{"user_id":"  "}`, http.StatusBadRequest},
+		{"usuário na fila", `{"user_id":"5544999998888"}`, http.StatusOK},
+		{"usuário já resolvido", `{"user_id":"5544999998888"}`, http.StatusNotFound},
+		{"usuário desconhecido", `{"user_id":"5500000000000"}`, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if rec := resolveHandoff(t, h, tc.body); rec.Code != tc.want {
+			t.Errorf("%s: status = %d, quer %d (%s)", tc.name, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+	if len(svc.queued) != 1 {
+		t.Errorf("fila = %d, quer 1: apenas o usuário resolvido deveria sair", len(svc.queued))
+	}
+}
+
+// replayAdminService simula o disparo do reenvio da dead-letter com o resultado informado.
+type replayAdminService struct {
+	AdminService
+	err error
+}
+
+func (r replayAdminService) StartNotificationReplay() error { return r.err }
+
+func TestNotificationsReplayStatusCodes(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"iniciado", nil, http.StatusAccepted},
+		{"em andamento", services.ErrReplayInProgress, http.StatusConflict},
+		{"sem banco", errors.New("banco local não configurado"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		h := NewAdminHandler(replayAdminService{err: tc.err})
+		rec := httptest.NewRecorder()
+		h.HandleNotificationsReplay(rec, httptest.NewRequest(http.MethodPost, "/admin/notifications/replay", nil))
+		if rec.Code != tc.want {
+			t.Errorf("%s: status = %d, quer %d", tc.name, rec.Code, tc.want)
+		}
+	}
+}
+
+// statsAdminService devolve a fotografia de sessões informada, ou err.
+type statsAdminService struct {
+	AdminService
+	stats services.ServiceStats
+	err   error
+}
+
+func (s statsAdminService) Snapshot(ctx context.Context) (services.ServiceStats, error) {
+	return s.stats, s.err
+}
+
+func TestSessionStats(t *testing.T) {
+	svc := statsAdminService{stats: services.ServiceStats{
+		ActiveSessions:          3,
+		SessionsByState:         map[string]int{"menu": 2, "plans_name": 1},
+		OldestSessionAgeSeconds: 120,
+	}}
+	rec := httptest.NewRecorder()
+	NewAdminHandler(svc).HandleSessionStats(rec, httptest.NewRequest(http.MethodGet, "/admin/sessions/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, quer 200 (%s)", rec.Code, rec.Body.String())
+	}
+	var got services.ServiceStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("corpo inválido: %v", err)
+	}
+	if got.ActiveSessions != 3 || got.SessionsByState["menu"] != 2 || got.OldestSessionAgeSeconds != 120 {
+		t.Errorf("corpo = %+v; quer a fotografia do serviço", got)
+	}
+
+	rec = httptest.NewRecorder()
+	NewAdminHandler(statsAdminService{err: errors.New("circuito aberto")}).HandleSessionStats(rec, httptest.NewRequest(http.MethodGet, "/admin/sessions/stats", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status com Redis fora = %d, quer 503", rec.Code)
+	}
+}
+
+// analyticsAdminService registra o período consultado e devolve contagens fixas.
+type analyticsAdminService struct {
+	AdminService
+	from, to time.Time
+}
+
+func (a *analyticsAdminService) MenuSelectionCounts(from, to time.Time) (map[string]int, error) {
+	a.from, a.to = from, to
+	return map[string]int{"1": 3}, nil
+}
+
+func TestMenuAnalyticsPeriod(t *testing.T) {
+	svc := &analyticsAdminService{}
+	h := NewAdminHandler(svc)
+
+	rec := httptest.NewRecorder()
+	h.HandleMenuAnalytics(rec, httptest.NewRequest(http.MethodGet, "/admin/analytics/menu?from=2026-03-01&to=2026-03-02", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, quer 200", rec.Code)
+	}
+	if !svc.from.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !svc.to.Equal(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("período consultado = [%s, %s); quer o dia to incluído", svc.from, svc.to)
+	}
+	if !strings.Contains(rec.Body.String(), `"counts":{"1":3}`) || !strings.Contains(rec.Body.String(), `"to":"2026-03-02"`) {
+		t.Errorf("corpo = %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleMenuAnalytics(rec, httptest.NewRequest(http.MethodGet, "/admin/analytics/menu?from=01/03/2026", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("from inválido = %d, quer 400", rec.Code)
+	}
+}
+
+// inspectAdminService devolve a inspeção informada, ou err, para qualquer usuário.
+type inspectAdminService struct {
+	AdminService
+	inspection services.SessionInspection
+	err        error
+}
+
+func (i *inspectAdminService) InspectSession(userID string) (services.SessionInspection, error) {
+	i.inspection.UserID = userID
+	return i.inspection, i.err
+}
+
+func inspectSession(h *AdminHandler, path string, header http.Header) (*httptest.ResponseRecorder, services.SessionInspection) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.HandleSessionInspect(rec, req)
+	var in services.SessionInspection
+	json.Unmarshal(rec.Body.Bytes(), &in)
+	return rec, in
+}
+
+func TestSessionInspectMasksByDefault(t *testing.T) {
+	t.Setenv("ADMIN_RAW_TOKEN", "segredo")
+	svc := &inspectAdminService{inspection: services.SessionInspection{
+		State: services.StatePlansPhone,
+		Data:  services.UserData{Nome: "Maria da Silva", Telefone: "5544999998888"},
+	}}
+	h := NewAdminHandler(svc)
+
+	rec, in := inspectSession(h, "/admin/session/5544999998888", nil)
+	if rec.Code != http.StatusOK || in.UserID != "5544999998888" || in.State != services.StatePlansPhone {
+		t.Fatalf("status = %d, inspeção = %+v", rec.Code, in)
+	}
+	if in.Data.Nome != "M*** d*** S***" || in.Data.Telefone != "*********8888" {
+		t.Errorf("dados = %q / %q; esperado mascarados por padrão", in.Data.Nome, in.Data.Telefone)
+	}
+
+	if rec, _ := inspectSession(h, "/admin/session/5544999998888?raw=true", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("raw sem token = %d; esperado 403", rec.Code)
+	}
+	if rec, _ := inspectSession(h, "/admin/session/5544999998888?raw=true", http.Header{"X-Admin-Raw-Token": {"errado"}}); rec.Code != http.StatusForbidden {
+		t.Errorf("raw com token errado = %d; esperado 403", rec.Code)
+	}
+	rec, in = inspectSession(h, "/admin/session/5544999998888?raw=true", http.Header{"X-Admin-Raw-Token": {"segredo"}})
+	if rec.Code != http.StatusOK || in.Data.Nome != "Maria da Silva" {
+		t.Errorf("raw com token = %d, nome %q; esperado os dados sem máscara", rec.Code, in.Data.Nome)
+	}
+}
+
+func TestSessionInspectErrors(t *testing.T) {
+	t.Setenv("ADMIN_RAW_TOKEN", "")
+	h := NewAdminHandler(&inspectAdminService{})
+	for _, path := range []string{"/admin/session/", "/admin/session/a%20b", "/admin/session/" + strings.Repeat("9", 129)} {
+		if rec, _ := inspectSession(h, path, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s = %d; esperado 400", path, rec.Code)
+		}
+	}
+	if rec, _ := inspectSession(h, "/admin/session/5544999998888?raw=true", http.Header{"X-Admin-Raw-Token": {""}}); rec.Code != http.StatusForbidden {
+		t.Errorf("raw sem ADMIN_RAW_TOKEN = %d; esperado 403 sempre", rec.Code)
+	}
+
+	failing := NewAdminHandler(&inspectAdminService{err: errors.New("redis fora")})
+	if rec, _ := inspectSession(failing, "/admin/session/5544999998888", nil); rec.Code != http.StatusInternalServerError {
+		t.Errorf("falha do serviço = %d; esperado 500", rec.Code)
+	}
+}
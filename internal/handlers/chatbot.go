@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"path/filepath"
@@ -10,11 +11,17 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+
+	"leadprojectarrumado/internal/services"
 )
 
 // ChatbotHandler lida com requisições HTTP relacionadas ao chatbot.
 type ChatbotHandler struct {
-	service ChatbotService
+	service        ChatbotService
+	limits         chatRequestLimits
+	sessionSources []string
+	cors           corsConfig
+	dedupe         *messageDedupe
 }
 
 // Service retorna a instância subjacente de ChatbotService.
@@ -24,7 +31,32 @@ func (h *ChatbotHandler) Service() ChatbotService {
 
 // ChatbotService define a interface para processar mensagens do usuário.
 type ChatbotService interface {
-	ProcessMessage(userID, message string) (string, error)
+	ProcessMessage(ctx context.Context, channel, userID, message string) (string, error)
+}
+
+// HandoffQueue é implementado por serviços que expõem a fila de atendimento humano.
+type HandoffQueue interface {
+	HandoffQueueDepth() (int64, error)
+}
+
+// ReadinessChecker é implementado por serviços que informam se estão aptos a receber tráfego.
+type ReadinessChecker interface {
+	Readiness() (bool, map[string]interface{})
+}
+
+// StatusReporter é implementado por serviços que informam a disponibilidade de cada subsistema.
+type StatusReporter interface {
+	Status(ctx context.Context) services.ServiceStatus
+}
+
+// OutboundLogger é implementado por serviços que auditam as mensagens enviadas pelo bot.
+type OutboundLogger interface {
+	LogOutbound(channel, recipient, messageID, text, status string)
+}
+
+// QuickReplier é implementado por serviços que sugerem as próximas ações ao usuário.
+type QuickReplier interface {
+	QuickReplies(userID string) []services.QuickReply
 }
 
 // ChatRequest representa a requisição JSON recebida pelo endpoint do chatbot.
@@ -33,55 +65,74 @@ type ChatRequest struct {
 	Message string `json:"message"`
 }
 
+// Códigos de erro retornados em ChatResponse.Code, para tratamento programático pelo widget.
+const (
+	// ErrCodeInvalidJSON indica corpo da requisição malformado (HTTP 400).
+	ErrCodeInvalidJSON = "invalid_json"
+	// ErrCodeEmptyBody indica requisição sem corpo (HTTP 400).
+	ErrCodeEmptyBody = "empty_body"
+	// ErrCodeBodyTooLarge indica corpo acima do limite configurado em BODY_LIMIT_BYTES (HTTP 413).
+	ErrCodeBodyTooLarge = "body_too_large"
+	// ErrCodeUnknownField indica campo não previsto em ChatRequest (HTTP 400).
+	ErrCodeUnknownField = "unknown_field"
+	// ErrCodeFieldTooLong indica campo acima do tamanho máximo configurado (HTTP 400).
+	ErrCodeFieldTooLong = "field_too_long"
+	// ErrCodeEmptyMessage indica mensagem vazia ou só com espaços (HTTP 400).
+	ErrCodeEmptyMessage = "empty_message"
+	// ErrCodeMethodNotAllowed indica método HTTP não suportado pelo endpoint (HTTP 405).
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+	// ErrCodeRateLimited indica limite de requisições excedido (HTTP 429).
+	ErrCodeRateLimited = "rate_limited"
+	// ErrCodeInternal indica falha inesperada no processamento (HTTP 500).
+	ErrCodeInternal = "internal"
+)
+
 // ChatResponse representa a resposta JSON retornada pelo endpoint do chatbot.
+// Em caso de erro, Error traz a mensagem legível e Code o identificador estável do erro.
 type ChatResponse struct {
 	Response  string `json:"response"`
 	Error     string `json:"error,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Field     string `json:"field,omitempty"`
 	SessionID string `json:"session_id,omitempty"`
+	// QuickReplies são as sugestões de próxima ação para o estado em que a resposta deixou o usuário.
+	QuickReplies []services.QuickReply `json:"quick_replies,omitempty"`
+}
+
+// writeError escreve uma resposta de erro padronizada com status HTTP, código e mensagem.
+func writeError(w http.ResponseWriter, status int, code, message, sessionID string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ChatResponse{Error: message, Code: code, SessionID: sessionID})
 }
 
 // NewChatbotHandler cria um novo handler para o chatbot.
 func NewChatbotHandler(service ChatbotService) *ChatbotHandler {
-	return &ChatbotHandler{service: service}
+	return &ChatbotHandler{service: service, limits: loadChatRequestLimits(), sessionSources: loadSessionSources(), cors: loadCORSConfig(), dedupe: loadMessageDedupe("WEB")}
 }
 
 // HandleChatbot processa requisições POST para o endpoint /chatbot.
 func (h *ChatbotHandler) HandleChatbot(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	h.cors.apply(w, r)
 
-	if r.Method == "OPTIONS" {
+	// Demais métodos são barrados por security.MethodGuard antes de chegar aqui.
+	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	if r.Method != "POST" {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(ChatResponse{
-			Error: "Método não permitido",
-		})
+	req, ferr := decodeChatRequest(r, h.limits)
+	if ferr != nil {
+		writeFieldError(w, ferr)
 		return
 	}
 
-	var req ChatRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Error().Err(err).Msg("Erro ao decodificar JSON")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ChatResponse{Error: "JSON inválido"})
+	sessionID := resolveSessionID(r, req, h.sessionSources)
+	// O ID vindo do header ou do cookie não passa por decodeChatRequest e precisa do mesmo limite do corpo.
+	if ferr := checkUserIDLength(sessionID, h.limits); ferr != nil {
+		writeFieldError(w, ferr)
 		return
 	}
-
-	sessionID := strings.TrimSpace(req.UserID)
-	if sessionID == "" {
-		sessionID = strings.TrimSpace(r.Header.Get("X-Session-ID"))
-	}
-	if sessionID == "" {
-		if c, err := r.Cookie("qid"); err == nil {
-			sessionID = c.Value
-		}
-	}
 	if sessionID == "" {
 		newID, err := uuid.NewRandom()
 		if err != nil {
@@ -100,20 +151,30 @@ func (h *ChatbotHandler) HandleChatbot(w http.ResponseWriter, r *http.Request) {
 	}
 	req.UserID = sessionID
 	if strings.TrimSpace(req.Message) == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ChatResponse{Error: "Mensagem não pode estar vazia", SessionID: sessionID})
+		writeError(w, http.StatusBadRequest, ErrCodeEmptyMessage, "Mensagem não pode estar vazia", sessionID)
 		return
 	}
 
-	response, err := h.service.ProcessMessage(req.UserID, req.Message)
+	response, duplicate, err := h.dedupe.do(r.Context(), req.UserID, req.Message, func() (string, error) {
+		return h.service.ProcessMessage(r.Context(), "web", req.UserID, req.Message)
+	})
 	if err != nil {
 		log.Error().Err(err).Msg("Erro ao processar mensagem")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ChatResponse{Error: "Erro interno do servidor", SessionID: sessionID})
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Erro interno do servidor", sessionID)
 		return
 	}
 
-	json.NewEncoder(w).Encode(ChatResponse{Response: response, SessionID: sessionID})
+	if duplicate {
+		log.Debug().Str("session_id", sessionID).Msg("Mensagem duplicada ignorada; devolvendo a resposta anterior")
+	} else if ol, ok := h.service.(OutboundLogger); ok {
+		// A resposta vai no corpo HTTP; sem confirmação de leitura do navegador, o status é "sent" como nos demais canais.
+		ol.LogOutbound("web", sessionID, "", response, "sent")
+	}
+	resp := ChatResponse{Response: response, SessionID: sessionID}
+	if qr, ok := h.service.(QuickReplier); ok {
+		resp.QuickReplies = qr.QuickReplies(sessionID)
+	}
+	json.NewEncoder(w).Encode(resp)
 }
 
 // HandleStatic serve arquivos estáticos (HTML, CSS, JS, imagens) a partir do diretório raiz.
@@ -148,9 +209,52 @@ func (h *ChatbotHandler) HandleStatic(w http.ResponseWriter, r *http.Request) {
 // HandleHealth retorna o status de saúde do serviço para monitoramento.
 func (h *ChatbotHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	health := map[string]interface{}{
 		"status":  "healthy",
 		"service": "qibot-chatbot",
+	}
+	if q, ok := h.service.(HandoffQueue); ok {
+		if depth, err := q.HandoffQueueDepth(); err == nil {
+			health["handoff_queue"] = depth
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(health)
+}
+
+// HandleStatus informa a disponibilidade de cada subsistema (ai, sheets, redis), para que o widget avise o
+// usuário quando a IA estiver fora. Responde sempre 200; a prontidão para tráfego fica em /readyz.
+func (h *ChatbotHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	h.cors.apply(w, r)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	status := services.ServiceStatus{Status: "ok"}
+	if sr, ok := h.service.(StatusReporter); ok {
+		status = sr.Status(r.Context())
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// HandleReady informa se o serviço está pronto para receber tráfego (readiness), retornando 503 caso contrário.
+// Diferente de /health (liveness), reflete manutenção e dependências.
+func (h *ChatbotHandler) HandleReady(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ready, checks := true, map[string]interface{}{}
+	if rc, ok := h.service.(ReadinessChecker); ok {
+		ready, checks = rc.Readiness()
+	}
+
+	status := "ready"
+	if !ready {
+		status = "not_ready"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": status,
+		"checks": checks,
 	})
 }
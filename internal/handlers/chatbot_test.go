@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// outboundEntry é uma mensagem registrada por outboundService.
+type outboundEntry struct {
+	channel, recipient, text, status string
+}
+
+// outboundService é um echoService que registra as mensagens auditadas com LogOutbound.
+type outboundService struct {
+	echoService
+	mu     sync.Mutex
+	logged []outboundEntry
+}
+
+func (o *outboundService) LogOutbound(channel, recipient, _, text, status string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.logged = append(o.logged, outboundEntry{channel, recipient, text, status})
+}
+
+func TestWebChatLogsResponseAsSent(t *testing.T) {
+	svc := &outboundService{}
+	h := NewChatbotHandler(svc)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/chatbot", strings.NewReader(`{"user_id":"sessao-1","message":"oi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	h.HandleChatbot(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, corpo = %s", rec.Code, rec.Body)
+	}
+	if len(svc.logged) != 1 {
+		t.Fatalf("auditoria = %+v; esperado 1 registro", svc.logged)
+	}
+	if got := svc.logged[0]; got.channel != "web" || got.text != "eco: oi" || got.status != "sent" {
+		t.Errorf("auditoria = %+v; esperado status sent, sem confirmação de entrega no canal web", got)
+	}
+}
+
+// handoffDepthService é um echoService que informa a profundidade da fila de atendimento humano.
+type handoffDepthService struct {
+	echoService
+	depth int64
+	err   error
+}
+
+func (h handoffDepthService) HandoffQueueDepth() (int64, error) { return h.depth, h.err }
+
+func TestHealthReportsHandoffQueueDepth(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewChatbotHandler(handoffDepthService{depth: 4}).HandleHealth(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"handoff_queue":4`) {
+		t.Errorf("/health = %d %s; esperado 200 com handoff_queue 4", rec.Code, rec.Body.String())
+	}
+
+	// Com o Redis fora, /health continua 200 e apenas omite a fila.
+	rec = httptest.NewRecorder()
+	NewChatbotHandler(handoffDepthService{err: errors.New("circuito aberto")}).HandleHealth(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "handoff_queue") {
+		t.Errorf("/health sem Redis = %d %s; esperado 200 sem handoff_queue", rec.Code, rec.Body.String())
+	}
+}
+
+// failingService é um ChatbotService cujo processamento sempre falha.
+type failingService struct{}
+
+func (failingService) ProcessMessage(context.Context, string, string, string) (string, error) {
+	return "", errors.New("falha inesperada")
+}
+
+// postChat envia o corpo ao endpoint do chatbot e decodifica a resposta.
+func postChat(t *testing.T, h *ChatbotHandler, body string) (int, ChatResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/chatbot", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.HandleChatbot(rec, req)
+	var resp ChatResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("corpo não é ChatResponse: %q", rec.Body.String())
+	}
+	return rec.Code, resp
+}
+
+func TestChatbotErrorsCarryCodes(t *testing.T) {
+	cases := []struct {
+		name   string
+		svc    ChatbotService
+		body   string
+		status int
+		code   string
+	}{
+		{"JSON malformado", echoService{}, `{"message":`, http.StatusBadRequest, ErrCodeInvalidJSON},
+		{"mensagem em branco", echoService{}, `{"user_id":"s1","message":"   "}`, http.StatusBadRequest, ErrCodeEmptyMessage},
+		{"falha do serviço", failingService{}, `{"user_id":"s1","message":"oi"}`, http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tc := range cases {
+		status, resp := postChat(t, NewChatbotHandler(tc.svc), tc.body)
+		if status != tc.status || resp.Code != tc.code {
+			t.Errorf("%s: %d %q; esperado %d %q", tc.name, status, resp.Code, tc.status, tc.code)
+		}
+		if resp.Error == "" || resp.Response != "" {
+			t.Errorf("%s: resposta = %+v; esperado só a mensagem de erro", tc.name, resp)
+		}
+	}
+
+	status, resp := postChat(t, NewChatbotHandler(echoService{}), `{"user_id":"s1","message":"oi"}`)
+	if status != http.StatusOK || resp.Code != "" || resp.Error != "" || resp.Response != "eco: oi" {
+		t.Errorf("sucesso = %d %+v; esperado sem código de erro", status, resp)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// corsConfig define os headers CORS do endpoint do chatbot usado pelo widget web.
+type corsConfig struct {
+	// AllowedOrigins vazio ou com "*" libera qualquer origem.
+	AllowedOrigins []string
+	AllowedMethods string
+	AllowedHeaders string
+	// MaxAge (segundos) permite ao navegador reaproveitar o preflight; 0 omite o header.
+	MaxAge int
+}
+
+// loadCORSConfig lê CORS_ALLOWED_ORIGINS (padrão "*"), CORS_ALLOWED_METHODS (padrão "POST, OPTIONS"),
+// CORS_ALLOWED_HEADERS (padrão "Content-Type, X-Session-ID") e CORS_MAX_AGE (padrão 600 segundos).
+func loadCORSConfig() corsConfig {
+	cfg := corsConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: "POST, OPTIONS",
+		AllowedHeaders: "Content-Type, X-Session-ID",
+		MaxAge:         600,
+	}
+	if v := os.Getenv("CORS_ALLOWED_ORIGINS"); v != "" {
+		cfg.AllowedOrigins = nil
+		for _, o := range strings.Split(v, ",") {
+			if o = strings.TrimSpace(o); o != "" {
+				cfg.AllowedOrigins = append(cfg.AllowedOrigins, o)
+			}
+		}
+	}
+	if v := os.Getenv("CORS_ALLOWED_METHODS"); v != "" {
+		cfg.AllowedMethods = v
+	}
+	if v := os.Getenv("CORS_ALLOWED_HEADERS"); v != "" {
+		cfg.AllowedHeaders = v
+	}
+	if n, err := strconv.Atoi(os.Getenv("CORS_MAX_AGE")); err == nil && n >= 0 {
+		cfg.MaxAge = n
+	}
+	return cfg
+}
+
+// apply escreve os headers CORS da resposta. Com lista de origens, só a origem da requisição
+// (se permitida) é devolvida, com Vary: Origin. Access-Control-Max-Age só vai no preflight.
+func (c corsConfig) apply(w http.ResponseWriter, r *http.Request) {
+	if origin := c.allowedOrigin(r.Header.Get("Origin")); origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if !c.allowsAny() {
+		w.Header().Add("Vary", "Origin")
+	}
+	w.Header().Set("Access-Control-Allow-Methods", c.AllowedMethods)
+	w.Header().Set("Access-Control-Allow-Headers", c.AllowedHeaders)
+	if r.Method == http.MethodOptions && c.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.MaxAge))
+	}
+}
+
+// allowsAny indica se qualquer origem é aceita.
+func (c corsConfig) allowsAny() bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return len(c.AllowedOrigins) == 0
+}
+
+// allowedOrigin retorna o valor de Access-Control-Allow-Origin para a origem informada, ou "" se negada.
+func (c corsConfig) allowedOrigin(origin string) string {
+	if c.allowsAny() {
+		return "*"
+	}
+	for _, o := range c.AllowedOrigins {
+		if strings.EqualFold(o, origin) {
+			return origin
+		}
+	}
+	return ""
+}
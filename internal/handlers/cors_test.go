@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingService é um echoService que conta as mensagens processadas.
+type countingService struct {
+	echoService
+	calls atomic.Int32
+}
+
+func (c *countingService) ProcessMessage(ctx context.Context, channel, userID, message string) (string, error) {
+	c.calls.Add(1)
+	return c.echoService.ProcessMessage(ctx, channel, userID, message)
+}
+
+func preflight(h *ChatbotHandler, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/chatbot", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := httptest.NewRecorder()
+	h.HandleChatbot(rec, req)
+	return rec
+}
+
+func TestCORSPreflightEchoesAllowedOrigin(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://qitelecom.com.br, https://app.qitelecom.com.br")
+	svc := &countingService{}
+	h := NewChatbotHandler(svc)
+
+	rec := preflight(h, "https://app.qitelecom.com.br")
+	if rec.Code != http.StatusOK {
+		t.Errorf("preflight = %d; esperado 200", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.qitelecom.com.br" {
+		t.Errorf("Access-Control-Allow-Origin = %q; esperado a origem da requisição", got)
+	}
+	if rec.Header().Get("Vary") != "Origin" {
+		t.Errorf("Vary = %q; esperado Origin com lista de origens", rec.Header().Get("Vary"))
+	}
+	if rec.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("Access-Control-Max-Age = %q; esperado 600 no preflight", rec.Header().Get("Access-Control-Max-Age"))
+	}
+	if !strings.Contains(rec.Header().Get("Access-Control-Allow-Headers"), "X-Session-ID") {
+		t.Errorf("Access-Control-Allow-Headers = %q", rec.Header().Get("Access-Control-Allow-Headers"))
+	}
+	if n := svc.calls.Load(); n != 0 {
+		t.Errorf("preflight chegou ao serviço %d vezes; esperado responder antes", n)
+	}
+}
+
+func TestCORSRejectsOriginOutsideList(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://qitelecom.com.br")
+	h := NewChatbotHandler(&countingService{})
+
+	rec := preflight(h, "https://evil.example")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q para origem fora da lista; esperado omitido", got)
+	}
+}
+
+func TestCORSAllowsAnyOriginByDefault(t *testing.T) {
+	h := NewChatbotHandler(&countingService{})
+
+	rec := preflight(h, "https://qualquer.example")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q; esperado *", got)
+	}
+	if rec.Header().Get("Vary") != "" {
+		t.Errorf("Vary = %q; esperado omitido com qualquer origem liberada", rec.Header().Get("Vary"))
+	}
+}
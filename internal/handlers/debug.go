@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// SimulateRequest descreve uma conversa roteirizada para /debug/simulate.
+type SimulateRequest struct {
+	UserID   string   `json:"user_id"`
+	Channel  string   `json:"channel,omitempty"`
+	Messages []string `json:"messages"`
+}
+
+// SimulateTurn é uma mensagem da conversa simulada com a resposta do bot.
+type SimulateTurn struct {
+	Message  string `json:"message"`
+	Response string `json:"response"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HandleSimulate executa as mensagens em sequência no ProcessMessage, sem passar por nenhum canal real,
+// e retorna as respostas na ordem. Só é registrado com DEBUG_ROUTES=true.
+func (h *ChatbotHandler) HandleSimulate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req SimulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Informe {\"user_id\": \"...\", \"messages\": [...]}"})
+		return
+	}
+	req.UserID = strings.TrimSpace(req.UserID)
+	if !userIDPattern.MatchString(req.UserID) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "user_id inválido"})
+		return
+	}
+	if req.Channel == "" {
+		req.Channel = "web"
+	}
+
+	turns := make([]SimulateTurn, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		turn := SimulateTurn{Message: msg}
+		response, err := h.service.ProcessMessage(r.Context(), req.Channel, req.UserID, msg)
+		if err != nil {
+			turn.Error = err.Error()
+		}
+		turn.Response = response
+		turns = append(turns, turn)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"user_id": req.UserID, "turns": turns})
+}
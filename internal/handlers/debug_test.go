@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleSimulateRunsScriptedConversation(t *testing.T) {
+	h := NewChatbotHandler(echoService{})
+	rec := httptest.NewRecorder()
+	h.HandleSimulate(rec, httptest.NewRequest(http.MethodPost, "/debug/simulate", strings.NewReader(`{"user_id":"qa-1","messages":["oi","1"]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; esperado 200", rec.Code)
+	}
+
+	var resp struct {
+		UserID string         `json:"user_id"`
+		Turns  []SimulateTurn `json:"turns"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("corpo inválido: %q", rec.Body.String())
+	}
+	if resp.UserID != "qa-1" || len(resp.Turns) != 2 || resp.Turns[0].Response != "eco: oi" || resp.Turns[1].Response != "eco: 1" {
+		t.Errorf("resposta = %+v; esperado as respostas na ordem das mensagens", resp)
+	}
+}
+
+func TestHandleSimulateRejectsInvalidRequests(t *testing.T) {
+	h := NewChatbotHandler(echoService{})
+	for _, body := range []string{`{"messages":`, `{"user_id":"a b","messages":["oi"]}`, `{"messages":["oi"]}`} {
+		rec := httptest.NewRecorder()
+		h.HandleSimulate(rec, httptest.NewRequest(http.MethodPost, "/debug/simulate", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("corpo %q = %d; esperado 400", body, rec.Code)
+		}
+	}
+}
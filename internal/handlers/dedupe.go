@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// messageDedupe ignora mensagens idênticas do mesmo usuário recebidas dentro de Window (ex.: duplo clique
+// no widget), devolvendo a resposta da primeira em vez de processá-las de novo.
+type messageDedupe struct {
+	Window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*dedupeEntry
+}
+
+// dedupeEntry guarda a resposta da primeira mensagem; done é fechado quando ela fica pronta.
+type dedupeEntry struct {
+	at       time.Time
+	done     chan struct{}
+	response string
+	err      error
+}
+
+// loadMessageDedupe lê <PREFIX>_DEDUPE_WINDOW (ex.: "3s"). Sem configuração a deduplicação fica desligada.
+func loadMessageDedupe(prefix string) *messageDedupe {
+	return &messageDedupe{
+		Window:  envDelay(prefix + "_DEDUPE_WINDOW"),
+		now:     time.Now,
+		entries: make(map[string]*dedupeEntry),
+	}
+}
+
+// do executa process uma única vez por par usuário+mensagem dentro da janela. Duplicatas aguardam a
+// resposta da primeira (mesmo que ainda em processamento) e retornam duplicate=true.
+// Falhas não são memorizadas, para que o reenvio seja processado normalmente.
+func (d *messageDedupe) do(ctx context.Context, userID, message string, process func() (string, error)) (response string, duplicate bool, err error) {
+	if d == nil || d.Window <= 0 {
+		response, err = process()
+		return response, false, err
+	}
+
+	key := userID + "\x00" + message
+	now := d.now()
+	d.mu.Lock()
+	d.sweep(now)
+	if e, ok := d.entries[key]; ok {
+		d.mu.Unlock()
+		select {
+		case <-e.done:
+			return e.response, true, e.err
+		case <-ctx.Done():
+			return "", true, ctx.Err()
+		}
+	}
+	e := &dedupeEntry{at: now, done: make(chan struct{})}
+	d.entries[key] = e
+	d.mu.Unlock()
+
+	e.response, e.err = process()
+	close(e.done)
+	if e.err != nil {
+		d.mu.Lock()
+		if d.entries[key] == e {
+			delete(d.entries, key)
+		}
+		d.mu.Unlock()
+	}
+	return e.response, false, e.err
+}
+
+// sweep remove as entradas fora da janela. Deve ser chamado com mu travado.
+func (d *messageDedupe) sweep(now time.Time) {
+	for key, e := range d.entries {
+		if now.Sub(e.at) >= d.Window {
+			delete(d.entries, key)
+		}
+	}
+}
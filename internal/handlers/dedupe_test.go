@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWebDedupeSuppressesRepeatedMessage(t *testing.T) {
+	t.Setenv("WEB_DEDUPE_WINDOW", "3s")
+	svc := &countingService{}
+	h := NewChatbotHandler(svc)
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	h.dedupe.now = func() time.Time { return now }
+
+	body := `{"user_id":"sessao-1","message":"oi"}`
+	_, first := postChat(t, h, body)
+	_, second := postChat(t, h, body)
+	if n := svc.calls.Load(); n != 1 {
+		t.Errorf("processadas = %d; esperado ignorar a duplicata", n)
+	}
+	if second.Response != first.Response {
+		t.Errorf("resposta da duplicata = %q; esperado a resposta anterior %q", second.Response, first.Response)
+	}
+
+	postChat(t, h, `{"user_id":"sessao-1","message":"menu"}`)
+	postChat(t, h, `{"user_id":"sessao-2","message":"oi"}`)
+	if n := svc.calls.Load(); n != 3 {
+		t.Errorf("processadas = %d; mensagens distintas ou de outro usuário deveriam ser processadas", n)
+	}
+
+	now = now.Add(3 * time.Second)
+	postChat(t, h, body)
+	if n := svc.calls.Load(); n != 4 {
+		t.Errorf("processadas = %d; esperado processar de novo após a janela", n)
+	}
+}
+
+func TestWebDedupeOffByDefault(t *testing.T) {
+	t.Setenv("WEB_DEDUPE_WINDOW", "")
+	svc := &countingService{}
+	h := NewChatbotHandler(svc)
+
+	postChat(t, h, `{"user_id":"sessao-1","message":"oi"}`)
+	postChat(t, h, `{"user_id":"sessao-1","message":"oi"}`)
+	if n := svc.calls.Load(); n != 2 {
+		t.Errorf("processadas = %d; esperado sem deduplicação por padrão", n)
+	}
+}
+
+func TestDedupeDoesNotRememberFailures(t *testing.T) {
+	d := &messageDedupe{Window: time.Minute, now: time.Now, entries: make(map[string]*dedupeEntry)}
+	calls := 0
+	fail := func() (string, error) { calls++; return "", errors.New("falha") }
+
+	d.do(context.Background(), "sessao-1", "oi", fail)
+	_, duplicate, err := d.do(context.Background(), "sessao-1", "oi", fail)
+	if duplicate || err == nil || calls != 2 {
+		t.Errorf("duplicate = %v, err = %v, chamadas = %d; esperado reprocessar após a falha", duplicate, err, calls)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// replyDelay é a pausa aleatória entre Min e Max aplicada antes de enviar uma resposta,
+// para que o bot não pareça instantâneo (e não acione a detecção de spam do canal).
+type replyDelay struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// loadReplyDelay lê <PREFIX>_REPLY_DELAY_MIN e <PREFIX>_REPLY_DELAY_MAX (ex.: "800ms", "2s").
+// Sem configuração o atraso fica desligado.
+func loadReplyDelay(prefix string) replyDelay {
+	d := replyDelay{
+		Min: envDelay(prefix + "_REPLY_DELAY_MIN"),
+		Max: envDelay(prefix + "_REPLY_DELAY_MAX"),
+	}
+	if d.Max < d.Min {
+		d.Max = d.Min
+	}
+	return d
+}
+
+// envDelay lê uma duração não negativa do ambiente, ou zero se ausente ou inválida.
+func envDelay(key string) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// duration sorteia o atraso dentro do intervalo configurado.
+func (d replyDelay) duration() time.Duration {
+	if d.Max <= d.Min {
+		return d.Min
+	}
+	return d.Min + time.Duration(rand.Int63n(int64(d.Max-d.Min)+1))
+}
+
+// wait aguarda o atraso sorteado, retornando antes se o contexto for cancelado (ex.: desligamento do servidor).
+func (d replyDelay) wait(ctx context.Context) {
+	delay := d.duration()
+	if delay <= 0 {
+		return
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLoadReplyDelay(t *testing.T) {
+	if d := loadReplyDelay("WHATSAPP"); d.Min != 0 || d.Max != 0 {
+		t.Errorf("sem configuração = %+v; esperado atraso desligado", d)
+	}
+
+	t.Setenv("WHATSAPP_REPLY_DELAY_MIN", "2s")
+	t.Setenv("WHATSAPP_REPLY_DELAY_MAX", "500ms")
+	if d := loadReplyDelay("WHATSAPP"); d.Min != 2*time.Second || d.Max != 2*time.Second {
+		t.Errorf("máximo abaixo do mínimo = %+v; esperado Max igual a Min", d)
+	}
+
+	t.Setenv("WHATSAPP_REPLY_DELAY_MIN", "-1s")
+	t.Setenv("WHATSAPP_REPLY_DELAY_MAX", "abc")
+	if d := loadReplyDelay("WHATSAPP"); d.Min != 0 || d.Max != 0 {
+		t.Errorf("valores inválidos = %+v; esperado atraso desligado", d)
+	}
+}
+
+func TestReplyDelayDurationStaysInRange(t *testing.T) {
+	d := replyDelay{Min: 800 * time.Millisecond, Max: 2 * time.Second}
+	for i := 0; i < 200; i++ {
+		if got := d.duration(); got < d.Min || got > d.Max {
+			t.Fatalf("duration = %s; esperado entre %s e %s", got, d.Min, d.Max)
+		}
+	}
+}
+
+func TestReplyDelayWaitStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	replyDelay{Min: time.Minute, Max: time.Minute}.wait(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("wait levou %s com o contexto cancelado; esperado retornar na hora", elapsed)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"leadprojectarrumado/internal/services"
+)
+
+// newServiceOnMiniredis cria o serviço real sobre um Redis em memória, com o heartbeat já executado
+// para que /readyz reflita só o estado do serviço.
+func newServiceOnMiniredis(t *testing.T) *services.ChatbotService {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	cfg := services.LoadConfig()
+	cfg.SheetsWorkers = 0
+	cfg.SheetsReplayInterval = 0
+	svc := services.NewChatbotService(rdb, nil, nil, nil, cfg)
+	t.Cleanup(func() { svc.Shutdown(context.Background()) })
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc.StartRedisHeartbeat(ctx)
+	return svc
+}
+
+// probe retorna os status de /health e /readyz.
+func probe(h *ChatbotHandler) (health, ready int) {
+	rec := httptest.NewRecorder()
+	h.HandleHealth(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	health = rec.Code
+	rec = httptest.NewRecorder()
+	h.HandleReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return health, rec.Code
+}
+
+func TestMaintenanceFromEnvFailsReadinessOnly(t *testing.T) {
+	t.Setenv("MAINTENANCE_MODE", "true")
+	h := NewChatbotHandler(newServiceOnMiniredis(t))
+
+	if health, ready := probe(h); health != http.StatusOK || ready != http.StatusServiceUnavailable {
+		t.Errorf("/health = %d, /readyz = %d; esperado 200 e 503 em manutenção", health, ready)
+	}
+}
+
+func TestMaintenanceToggledByAdminFailsReadinessOnly(t *testing.T) {
+	svc := newServiceOnMiniredis(t)
+	h := NewChatbotHandler(svc)
+	admin := NewAdminHandler(svc)
+
+	if health, ready := probe(h); health != http.StatusOK || ready != http.StatusOK {
+		t.Fatalf("/health = %d, /readyz = %d; esperado 200 e 200 fora de manutenção", health, ready)
+	}
+
+	toggle := func(body string) {
+		rec := httptest.NewRecorder()
+		admin.HandleMaintenance(rec, httptest.NewRequest(http.MethodPost, "/admin/maintenance", bytes.NewBufferString(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST /admin/maintenance %s = %d", body, rec.Code)
+		}
+	}
+	toggle(`{"enabled":true}`)
+	if health, ready := probe(h); health != http.StatusOK || ready != http.StatusServiceUnavailable {
+		t.Errorf("/health = %d, /readyz = %d; esperado 200 e 503 em manutenção", health, ready)
+	}
+	toggle(`{"enabled":false}`)
+	if _, ready := probe(h); ready != http.StatusOK {
+		t.Errorf("/readyz = %d após desligar a manutenção; esperado 200", ready)
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MessengerWebhookHandler lida com requisições do webhook do Facebook Messenger.
+type MessengerWebhookHandler struct {
+	service ChatbotService
+	retry   sendRetry
+	// graphURL substitui o endereço da Graph API (testes, proxy); vazio usa whatsAppGraphURL.
+	graphURL string
+}
+
+// NewMessengerWebhookHandler cria um novo handler para o webhook do Messenger.
+func NewMessengerWebhookHandler(service ChatbotService) *MessengerWebhookHandler {
+	return &MessengerWebhookHandler{service: service, retry: loadSendRetry("MESSENGER")}
+}
+
+// MessengerWebhookPayload representa o payload recebido do webhook do Messenger.
+type MessengerWebhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		Messaging []MessengerEvent `json:"messaging"`
+	} `json:"entry"`
+}
+
+// MessengerEvent representa um evento de mensagem; Sender.ID é o PSID do usuário na página.
+type MessengerEvent struct {
+	Sender struct {
+		ID string `json:"id"`
+	} `json:"sender"`
+	Timestamp int64 `json:"timestamp"`
+	Message   *struct {
+		MID    string `json:"mid"`
+		Text   string `json:"text"`
+		IsEcho bool   `json:"is_echo"`
+	} `json:"message"`
+}
+
+// HandleMessengerWebhook valida o webhook (GET) e processa as mensagens recebidas (POST),
+// respondendo pelo Send API com o PSID como identificador da sessão.
+func (h *MessengerWebhookHandler) HandleMessengerWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		handleVerifyHandshake(w, r, os.Getenv("MESSENGER_VERIFY_TOKEN"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var payload MessengerWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Object != "page" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, entry := range payload.Entry {
+		for _, event := range entry.Messaging {
+			if event.Message == nil || event.Message.IsEcho || event.Sender.ID == "" {
+				continue
+			}
+			text := event.Message.Text
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			if mc, ok := h.service.(MessageClaimer); ok && !mc.ClaimMessage("messenger", event.Message.MID) {
+				log.Info().Str("message_id", event.Message.MID).Msg("Mensagem Messenger já recebida, reenvio ignorado")
+				continue
+			}
+			response, err := h.service.ProcessMessage(r.Context(), "messenger", event.Sender.ID, text)
+			if err == nil {
+				h.reply(r.Context(), event.Sender.ID, response)
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// reply envia a resposta ao usuário e registra o envio na auditoria de mensagens.
+func (h *MessengerWebhookHandler) reply(ctx context.Context, psid, message string) {
+	messageID, err := h.retry.do(ctx, func() (string, error) { return sendMessengerText(h.graphURL, psid, message) })
+	status := "sent"
+	if err != nil {
+		status = "failed"
+		log.Error().Err(err).Str("recipient", psid).Msg("Erro ao enviar mensagem Messenger")
+		if q, ok := h.service.(ReplyQueue); ok {
+			if qerr := q.QueueReply("messenger", psid, message); qerr == nil {
+				status = "queued"
+			}
+		}
+	}
+	if ol, ok := h.service.(OutboundLogger); ok {
+		ol.LogOutbound("messenger", psid, messageID, message, status)
+	}
+}
+
+// SendMessengerMessage envia uma mensagem de texto para um usuário via Send API do Messenger.
+func SendMessengerMessage(psid, message string) error {
+	_, err := sendMessengerText("", psid, message)
+	return err
+}
+
+// newMessengerSendRequest monta a chamada ao Send API para o PSID informado; baseURL vazio usa whatsAppGraphURL.
+func newMessengerSendRequest(baseURL, psid, message, token string) (*http.Request, error) {
+	if baseURL == "" {
+		baseURL = whatsAppGraphURL
+	}
+	payload := map[string]interface{}{
+		"recipient":      map[string]string{"id": psid},
+		"messaging_type": "RESPONSE",
+		"message":        map[string]string{"text": message},
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/me/messages", strings.NewReader(string(b)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// sendMessengerText envia a mensagem e retorna o ID atribuído pelo Send API.
+func sendMessengerText(baseURL, psid, message string) (string, error) {
+	req, err := newMessengerSendRequest(baseURL, psid, message, os.Getenv("MESSENGER_PAGE_TOKEN"))
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{Timeout: sendTimeout("MESSENGER")}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var sent struct {
+		MessageID string `json:"message_id"`
+	}
+	json.NewDecoder(resp.Body).Decode(&sent)
+	if resp.StatusCode >= 300 {
+		return "", sendStatusError{API: "Messenger Send API", StatusCode: resp.StatusCode}
+	}
+	return sent.MessageID, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMessengerVerifyHandshake(t *testing.T) {
+	h := NewMessengerWebhookHandler(echoService{})
+	verify := func(query string) (int, string) {
+		rec := httptest.NewRecorder()
+		h.HandleMessengerWebhook(rec, httptest.NewRequest(http.MethodGet, "/webhook/messenger?"+query, nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	const query = "hub.mode=subscribe&hub.verify_token=segredo&hub.challenge=12345"
+	if code, _ := verify(query); code != http.StatusForbidden {
+		t.Errorf("sem MESSENGER_VERIFY_TOKEN = %d; esperado 403", code)
+	}
+
+	t.Setenv("MESSENGER_VERIFY_TOKEN", "segredo")
+	if code, body := verify(query); code != http.StatusOK || body != "12345" {
+		t.Errorf("token correto = %d %q; esperado 200 com o challenge", code, body)
+	}
+	if code, _ := verify("hub.mode=subscribe&hub.verify_token=outro&hub.challenge=12345"); code != http.StatusForbidden {
+		t.Errorf("token errado = %d; esperado 403", code)
+	}
+}
+
+func TestMessengerWebhookRepliesThroughSendAPI(t *testing.T) {
+	t.Setenv("MESSENGER_PAGE_TOKEN", "page-token")
+	var mu sync.Mutex
+	var sent []map[string]interface{}
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/me/messages" || r.Header.Get("Authorization") != "Bearer page-token" {
+			t.Errorf("chamada ao Send API = %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		sent = append(sent, payload)
+		mu.Unlock()
+		w.Write([]byte(`{"message_id":"m_1"}`))
+	}))
+	defer graph.Close()
+
+	h := NewMessengerWebhookHandler(echoService{})
+	h.graphURL = graph.URL
+	body := `{"object":"page","entry":[{"messaging":[
+		{"sender":{"id":"psid-1"},"message":{"mid":"m.1","text":"oi"}},
+		{"sender":{"id":"psid-1"},"message":{"mid":"m.2","text":"eco da página","is_echo":true}},
+		{"sender":{"id":"psid-2"},"message":{"mid":"m.3","text":"   "}}
+	]}]}`
+	rec := httptest.NewRecorder()
+	h.HandleMessengerWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/messenger", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; esperado 200", rec.Code)
+	}
+
+	if len(sent) != 1 {
+		t.Fatalf("%d envios; esperado só a resposta à mensagem de texto do usuário", len(sent))
+	}
+	recipient, _ := sent[0]["recipient"].(map[string]interface{})
+	message, _ := sent[0]["message"].(map[string]interface{})
+	if recipient["id"] != "psid-1" || message["text"] != "eco: oi" {
+		t.Errorf("envio = %v; esperado a resposta do serviço ao PSID", sent[0])
+	}
+}
+
+func TestMessengerWebhookRejectsOtherObjects(t *testing.T) {
+	h := NewMessengerWebhookHandler(echoService{})
+	for _, body := range []string{`{"object":"instagram","entry":[]}`, `{"object":`} {
+		rec := httptest.NewRecorder()
+		h.HandleMessengerWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/messenger", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("corpo %q = %d; esperado 400", body, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"leadprojectarrumado/internal/services"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PlanCatalog é implementado por serviços que expõem o catálogo de planos estruturado.
+type PlanCatalog interface {
+	Plans() []services.Plan
+}
+
+// plansCacheTTL é por quanto tempo o JSON do catálogo é reaproveitado (servidor e Cache-Control).
+const plansCacheTTL = time.Minute
+
+// plansCache guarda o catálogo já serializado.
+type plansCache struct {
+	mu      sync.Mutex
+	body    []byte
+	expires time.Time
+}
+
+// HandlePlans retorna o catálogo de planos em JSON para o widget montar os cards sem depender do texto do bot.
+func HandlePlans(catalog PlanCatalog) http.HandlerFunc {
+	cache := &plansCache{}
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := cache.get(catalog)
+		if err != nil {
+			log.Error().Err(err).Msg("Erro ao serializar catálogo de planos")
+			http.Error(w, "Erro interno do servidor", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(plansCacheTTL.Seconds())))
+		w.Write(body)
+	}
+}
+
+// get retorna o JSON em cache ou serializa o catálogo novamente quando expirado.
+func (c *plansCache) get(catalog PlanCatalog) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.body != nil && time.Now().Before(c.expires) {
+		return c.body, nil
+	}
+	body, err := json.Marshal(map[string]interface{}{"plans": catalog.Plans()})
+	if err != nil {
+		return nil, err
+	}
+	c.body, c.expires = body, time.Now().Add(plansCacheTTL)
+	return body, nil
+}
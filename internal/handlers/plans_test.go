@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"leadprojectarrumado/internal/services"
+)
+
+// countingCatalog é um PlanCatalog que conta as leituras do catálogo.
+type countingCatalog struct {
+	plans []services.Plan
+	calls int
+}
+
+func (c *countingCatalog) Plans() []services.Plan {
+	c.calls++
+	return c.plans
+}
+
+func TestHandlePlansReturnsCatalogJSON(t *testing.T) {
+	catalog := &countingCatalog{plans: []services.Plan{
+		{ID: "basic", Name: "QI FIBRA BASIC", SpeedMbps: 300, Features: []string{"IPV6"}, Aliases: []string{"basic"}},
+	}}
+	h := HandlePlans(catalog)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/plans", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("/plans = %d %q; esperado 200 em JSON", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q; esperado \"public, max-age=60\"", got)
+	}
+
+	var body map[string][]map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("corpo inválido: %q", rec.Body.String())
+	}
+	plans := body["plans"]
+	if len(plans) != 1 || plans[0]["id"] != "basic" || plans[0]["name"] != "QI FIBRA BASIC" || plans[0]["speed_mbps"] != float64(300) {
+		t.Errorf("plans = %v; esperado o catálogo com id, name e speed_mbps", plans)
+	}
+	if _, ok := plans[0]["Aliases"]; ok {
+		t.Error("apelidos internos expostos no JSON")
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/plans", nil))
+	if catalog.calls != 1 {
+		t.Errorf("catálogo lido %d vezes; esperado reaproveitar o JSON em cache", catalog.calls)
+	}
+}
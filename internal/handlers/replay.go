@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// replayWindow descarta mensagens de webhook antigas demais (reentregas tardias ou payloads reenviados
+// de propósito), tolerando uma diferença de relógio entre a Meta e o servidor.
+type replayWindow struct {
+	MaxAge time.Duration
+	Skew   time.Duration
+	now    func() time.Time
+}
+
+// loadReplayWindow lê <PREFIX>_MAX_MESSAGE_AGE (padrão 5m, 0 desativa) e <PREFIX>_CLOCK_SKEW (padrão 30s).
+func loadReplayWindow(prefix string) replayWindow {
+	w := replayWindow{MaxAge: 5 * time.Minute, Skew: 30 * time.Second, now: time.Now}
+	if d, err := time.ParseDuration(os.Getenv(prefix + "_MAX_MESSAGE_AGE")); err == nil && d >= 0 {
+		w.MaxAge = d
+	}
+	if d, err := time.ParseDuration(os.Getenv(prefix + "_CLOCK_SKEW")); err == nil && d >= 0 {
+		w.Skew = d
+	}
+	return w
+}
+
+// fresh indica se a mensagem com o timestamp informado (segundos Unix) está dentro da janela.
+// Timestamps ausentes ou inválidos são aceitos, já que não há como avaliá-los.
+func (w replayWindow) fresh(timestamp string) bool {
+	if w.MaxAge <= 0 {
+		return true
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return true
+	}
+	age := w.now().Sub(time.Unix(ts, 0))
+	return age <= w.MaxAge+w.Skew && age >= -w.Skew
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReplayWindowFresh(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	w := replayWindow{MaxAge: 5 * time.Minute, Skew: 30 * time.Second, now: func() time.Time { return now }}
+	at := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+
+	cases := []struct {
+		name      string
+		timestamp string
+		want      bool
+	}{
+		{"agora", at(0), true},
+		{"dentro da janela", at(-4 * time.Minute), true},
+		{"no limite com a tolerância", at(-5*time.Minute - 30*time.Second), true},
+		{"além da janela", at(-5*time.Minute - 31*time.Second), false},
+		{"reentrega de horas atrás", at(-3 * time.Hour), false},
+		{"adiantado dentro da tolerância", at(20 * time.Second), true},
+		{"adiantado além da tolerância", at(time.Minute), false},
+		{"sem timestamp", "", true},
+		{"timestamp inválido", "ontem", true},
+	}
+	for _, tc := range cases {
+		if got := w.fresh(tc.timestamp); got != tc.want {
+			t.Errorf("%s: fresh(%q) = %v; esperado %v", tc.name, tc.timestamp, got, tc.want)
+		}
+	}
+
+	w.MaxAge = 0
+	if !w.fresh(at(-3 * time.Hour)) {
+		t.Error("MaxAge 0 deveria desativar a janela")
+	}
+}
+
+func TestLoadReplayWindowFromEnv(t *testing.T) {
+	t.Setenv("WHATSAPP_MAX_MESSAGE_AGE", "2m")
+	t.Setenv("WHATSAPP_CLOCK_SKEW", "invalido")
+	w := loadReplayWindow("WHATSAPP")
+	if w.MaxAge != 2*time.Minute || w.Skew != 30*time.Second {
+		t.Errorf("janela = %s/%s; esperado 2m e a tolerância padrão", w.MaxAge, w.Skew)
+	}
+}
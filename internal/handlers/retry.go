@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReplyQueue é implementado por serviços que guardam respostas não entregues para reenvio posterior.
+type ReplyQueue interface {
+	QueueReply(channel, recipient, text string) error
+}
+
+// sendRetry define quantas vezes um envio é tentado e o intervalo inicial entre tentativas (dobrado a cada falha).
+type sendRetry struct {
+	Attempts int
+	Backoff  time.Duration
+}
+
+// loadSendRetry lê <PREFIX>_SEND_ATTEMPTS (padrão 3) e <PREFIX>_SEND_BACKOFF (padrão 500ms).
+func loadSendRetry(prefix string) sendRetry {
+	r := sendRetry{
+		Attempts: envLimit(prefix+"_SEND_ATTEMPTS", 3),
+		Backoff:  500 * time.Millisecond,
+	}
+	if d, err := time.ParseDuration(os.Getenv(prefix + "_SEND_BACKOFF")); err == nil && d >= 0 {
+		r.Backoff = d
+	}
+	return r
+}
+
+// sendTimeout lê <PREFIX>_SEND_TIMEOUT (padrão 10s), o tempo máximo de cada chamada à API do canal.
+func sendTimeout(prefix string) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(prefix + "_SEND_TIMEOUT")); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Second
+}
+
+// sendStatusError é a recusa de um envio pela API do canal, com o status HTTP da resposta.
+type sendStatusError struct {
+	API        string
+	StatusCode int
+}
+
+func (e sendStatusError) Error() string {
+	return fmt.Sprintf("%s retornou status %d", e.API, e.StatusCode)
+}
+
+// retryableSend indica se vale tentar o envio de novo: falhas de rede e respostas 5xx ou 429. Demais
+// recusas da API (token inválido, destinatário fora da janela de 24h, payload inválido) se repetiriam.
+func retryableSend(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var se sendStatusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500 || se.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// do executa send até ter sucesso, esgotar as tentativas ou receber um erro que não vale repetir, aguardando
+// o backoff entre elas. A espera termina antes se ctx for cancelado, retornando o último erro do envio.
+func (r sendRetry) do(ctx context.Context, send func() (string, error)) (string, error) {
+	attempts := r.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := r.Backoff
+	var err error
+	for i := 1; i <= attempts; i++ {
+		var id string
+		if id, err = send(); err == nil {
+			return id, nil
+		}
+		if i == attempts || !retryableSend(err) {
+			break
+		}
+		log.Warn().Err(err).Int("attempt", i).Msg("Falha no envio, tentando novamente")
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", err
+		}
+		backoff *= 2
+	}
+	return "", err
+}
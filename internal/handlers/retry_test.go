@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// failingSend falha com errs em sequência e depois retorna o ID "wamid.ok".
+type failingSend struct {
+	errs  []error
+	calls int
+}
+
+func (f *failingSend) send() (string, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return "", f.errs[f.calls-1]
+	}
+	return "wamid.ok", nil
+}
+
+func TestSendRetryOnlyRetriesTransientFailures(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		calls int
+	}{
+		{"5xx", sendStatusError{API: "API", StatusCode: http.StatusBadGateway}, 3},
+		{"429", sendStatusError{API: "API", StatusCode: http.StatusTooManyRequests}, 3},
+		{"falha de rede", errors.New("connection reset by peer"), 3},
+		{"400", sendStatusError{API: "API", StatusCode: http.StatusBadRequest}, 1},
+		{"401", sendStatusError{API: "API", StatusCode: http.StatusUnauthorized}, 1},
+		{"prazo do envio", context.DeadlineExceeded, 1},
+	}
+	r := sendRetry{Attempts: 3, Backoff: time.Millisecond}
+	for _, c := range cases {
+		f := &failingSend{errs: []error{c.err, c.err, c.err}}
+		if _, err := r.do(context.Background(), f.send); !errors.Is(err, c.err) {
+			t.Errorf("%s: err = %v", c.name, err)
+		}
+		if f.calls != c.calls {
+			t.Errorf("%s: tentativas = %d; esperado %d", c.name, f.calls, c.calls)
+		}
+	}
+}
+
+func TestSendRetrySucceedsAfterTransientFailure(t *testing.T) {
+	f := &failingSend{errs: []error{sendStatusError{API: "API", StatusCode: http.StatusServiceUnavailable}}}
+	id, err := sendRetry{Attempts: 3, Backoff: time.Millisecond}.do(context.Background(), f.send)
+	if err != nil || id != "wamid.ok" || f.calls != 2 {
+		t.Errorf("do = %q, %v com %d tentativas", id, err, f.calls)
+	}
+}
+
+func TestSendRetryStopsWaitingWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &failingSend{errs: []error{sendStatusError{API: "API", StatusCode: http.StatusInternalServerError}}}
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := sendRetry{Attempts: 3, Backoff: time.Hour}.do(ctx, f.send)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("do levou %v; esperado interromper o backoff no cancelamento", elapsed)
+	}
+	if err == nil || f.calls != 1 {
+		t.Errorf("err = %v com %d tentativas; esperado o erro do envio sem nova tentativa", err, f.calls)
+	}
+}
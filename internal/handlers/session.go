@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Fontes possíveis do identificador de sessão em /chatbot.
+const (
+	sessionSourceBody   = "body"
+	sessionSourceHeader = "header"
+	sessionSourceCookie = "cookie"
+)
+
+// defaultSessionSources é a precedência usada quando SESSION_SOURCES não está definido.
+var defaultSessionSources = []string{sessionSourceBody, sessionSourceHeader, sessionSourceCookie}
+
+// loadSessionSources lê SESSION_SOURCES (ex.: "header,cookie,body"). Fontes omitidas são ignoradas;
+// valores desconhecidos são descartados e, se nada válido sobrar, vale a precedência padrão.
+func loadSessionSources() []string {
+	v := os.Getenv("SESSION_SOURCES")
+	if v == "" {
+		return defaultSessionSources
+	}
+	var sources []string
+	seen := map[string]bool{}
+	for _, s := range strings.Split(v, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		switch s {
+		case sessionSourceBody, sessionSourceHeader, sessionSourceCookie:
+			if !seen[s] {
+				seen[s] = true
+				sources = append(sources, s)
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return defaultSessionSources
+	}
+	return sources
+}
+
+// resolveSessionID retorna o primeiro identificador não vazio seguindo a precedência configurada,
+// ou "" se nenhuma fonte trouxer valor (o chamador então gera um UUID).
+func resolveSessionID(r *http.Request, req ChatRequest, sources []string) string {
+	for _, source := range sources {
+		var id string
+		switch source {
+		case sessionSourceBody:
+			id = req.UserID
+		case sessionSourceHeader:
+			id = r.Header.Get("X-Session-ID")
+		case sessionSourceCookie:
+			if c, err := r.Cookie("qid"); err == nil {
+				id = c.Value
+			}
+		}
+		if id = strings.TrimSpace(id); id != "" {
+			return id
+		}
+	}
+	return ""
+}
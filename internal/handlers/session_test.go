@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadSessionSources(t *testing.T) {
+	cases := map[string][]string{
+		"":                       defaultSessionSources,
+		"Header, cookie":         {sessionSourceHeader, sessionSourceCookie},
+		"cookie,body,cookie,url": {sessionSourceCookie, sessionSourceBody},
+		"query,url":              defaultSessionSources,
+	}
+	for v, want := range cases {
+		t.Setenv("SESSION_SOURCES", v)
+		if got := loadSessionSources(); !reflect.DeepEqual(got, want) {
+			t.Errorf("SESSION_SOURCES=%q: %v; esperado %v", v, got, want)
+		}
+	}
+}
+
+func TestResolveSessionIDFollowsPrecedence(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/chatbot", nil)
+	req.Header.Set("X-Session-ID", "do-header")
+	req.AddCookie(&http.Cookie{Name: "qid", Value: "do-cookie"})
+	body := ChatRequest{UserID: "do-corpo"}
+
+	cases := []struct {
+		sources []string
+		want    string
+	}{
+		{defaultSessionSources, "do-corpo"},
+		{[]string{sessionSourceHeader, sessionSourceBody}, "do-header"},
+		{[]string{sessionSourceCookie, sessionSourceHeader}, "do-cookie"},
+	}
+	for _, c := range cases {
+		if got := resolveSessionID(req, body, c.sources); got != c.want {
+			t.Errorf("fontes %v: %q; esperado %q", c.sources, got, c.want)
+		}
+	}
+
+	// Fonte vazia passa para a próxima; fora da lista configurada, não é consultada.
+	if got := resolveSessionID(req, ChatRequest{UserID: "  "}, defaultSessionSources); got != "do-header" {
+		t.Errorf("corpo em branco: %q; esperado o header", got)
+	}
+	if got := resolveSessionID(req, ChatRequest{}, []string{sessionSourceBody}); got != "" {
+		t.Errorf("só corpo, vazio: %q; esperado \"\" para o handler gerar o ID", got)
+	}
+}
+
+func TestOversizedSessionIDFromHeaderOrCookieIsRejected(t *testing.T) {
+	t.Setenv("MAX_USER_ID_LENGTH", "16")
+	svc := &countingService{}
+	h := NewChatbotHandler(svc)
+	long := strings.Repeat("a", 17)
+
+	for name, set := range map[string]func(*http.Request){
+		"header": func(r *http.Request) { r.Header.Set("X-Session-ID", long) },
+		"cookie": func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "qid", Value: long}) },
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/chatbot", strings.NewReader(`{"message":"oi"}`))
+		set(req)
+		rec := httptest.NewRecorder()
+		h.HandleChatbot(rec, req)
+
+		var resp ChatResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusBadRequest || resp.Code != ErrCodeFieldTooLong || resp.Field != "user_id" {
+			t.Errorf("%s: %d %+v; esperado 400 field_too_long em user_id", name, rec.Code, resp)
+		}
+	}
+	if n := svc.calls.Load(); n != 0 {
+		t.Errorf("processadas = %d; IDs longos não deveriam chegar ao serviço", n)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/chatbot", strings.NewReader(`{"message":"oi"}`))
+	req.Header.Set("X-Session-ID", strings.Repeat("é", 16))
+	rec := httptest.NewRecorder()
+	h.HandleChatbot(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("ID no limite (16 caracteres acentuados) = %d; esperado 200", rec.Code)
+	}
+}
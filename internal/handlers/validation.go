@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+)
+
+// chatRequestLimits define o tamanho máximo (em caracteres) dos campos de ChatRequest.
+type chatRequestLimits struct {
+	UserID  int
+	Message int
+}
+
+// loadChatRequestLimits lê MAX_USER_ID_LENGTH (padrão 128) e MAX_MESSAGE_LENGTH (padrão 2000).
+func loadChatRequestLimits() chatRequestLimits {
+	return chatRequestLimits{
+		UserID:  envLimit("MAX_USER_ID_LENGTH", 128),
+		Message: envLimit("MAX_MESSAGE_LENGTH", 2000),
+	}
+}
+
+// envLimit lê um limite positivo do ambiente, mantendo def se ausente ou inválido.
+func envLimit(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// fieldError descreve uma falha de validação da requisição, opcionalmente ligada a um campo.
+// Status é o código HTTP a responder (400 quando zero).
+type fieldError struct {
+	Status  int
+	Code    string
+	Field   string
+	Message string
+}
+
+// decodeChatRequest decodifica o corpo de forma estrita (campos desconhecidos são recusados)
+// e valida o tamanho de cada campo.
+func decodeChatRequest(r *http.Request, limits chatRequestLimits) (ChatRequest, *fieldError) {
+	var req ChatRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return req, &fieldError{Status: http.StatusRequestEntityTooLarge, Code: ErrCodeBodyTooLarge, Message: fmt.Sprintf("Corpo da requisição excede %d bytes", tooLarge.Limit)}
+		}
+		if errors.Is(err, io.EOF) {
+			return req, &fieldError{Code: ErrCodeEmptyBody, Message: "Corpo da requisição vazio"}
+		}
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			field = strings.Trim(field, `"`)
+			return req, &fieldError{Code: ErrCodeUnknownField, Field: field, Message: fmt.Sprintf("Campo desconhecido: %s", field)}
+		}
+		return req, &fieldError{Code: ErrCodeInvalidJSON, Message: "JSON inválido"}
+	}
+
+	if ferr := checkUserIDLength(req.UserID, limits); ferr != nil {
+		return req, ferr
+	}
+	if utf8.RuneCountInString(req.Message) > limits.Message {
+		return req, &fieldError{Code: ErrCodeFieldTooLong, Field: "message", Message: fmt.Sprintf("message excede %d caracteres", limits.Message)}
+	}
+	return req, nil
+}
+
+// checkUserIDLength recusa identificadores de usuário acima de limits.UserID caracteres.
+func checkUserIDLength(userID string, limits chatRequestLimits) *fieldError {
+	if utf8.RuneCountInString(userID) > limits.UserID {
+		return &fieldError{Code: ErrCodeFieldTooLong, Field: "user_id", Message: fmt.Sprintf("user_id excede %d caracteres", limits.UserID)}
+	}
+	return nil
+}
+
+// writeFieldError responde a falha de validação com o status e o código correspondentes.
+func writeFieldError(w http.ResponseWriter, ferr *fieldError) {
+	log.Warn().Str("code", ferr.Code).Str("field", ferr.Field).Msg("Requisição do chatbot inválida")
+	status := ferr.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ChatResponse{Error: ferr.Message, Code: ferr.Code, Field: ferr.Field})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChatRequestRejectsUnknownFields(t *testing.T) {
+	status, resp := postChat(t, NewChatbotHandler(echoService{}), `{"user_id":"s1","message":"oi","nome":"Maria"}`)
+	if status != http.StatusBadRequest || resp.Code != ErrCodeUnknownField || resp.Field != "nome" {
+		t.Errorf("campo desconhecido = %d %+v; esperado 400 unknown_field com field nome", status, resp)
+	}
+}
+
+func TestChatRequestFieldLengths(t *testing.T) {
+	t.Setenv("MAX_USER_ID_LENGTH", "8")
+	t.Setenv("MAX_MESSAGE_LENGTH", "10")
+	h := NewChatbotHandler(echoService{})
+
+	cases := []struct {
+		name, body, field string
+	}{
+		{"user_id", `{"user_id":"123456789","message":"oi"}`, "user_id"},
+		{"message", `{"user_id":"s1","message":"` + strings.Repeat("a", 11) + `"}`, "message"},
+	}
+	for _, tc := range cases {
+		status, resp := postChat(t, h, tc.body)
+		if status != http.StatusBadRequest || resp.Code != ErrCodeFieldTooLong || resp.Field != tc.field {
+			t.Errorf("%s longo = %d %+v; esperado 400 field_too_long com field %s", tc.name, status, resp, tc.field)
+		}
+	}
+
+	// O limite conta caracteres: acentos não fazem a mensagem passar do limite.
+	if status, resp := postChat(t, h, `{"user_id":"s1","message":"conexão lá"}`); status != http.StatusOK {
+		t.Errorf("mensagem no limite = %d %+v; esperado 200", status, resp)
+	}
+}
+
+func TestChatRequestEmptyAndOversizedBodies(t *testing.T) {
+	h := NewChatbotHandler(echoService{})
+
+	if status, resp := postChat(t, h, ""); status != http.StatusBadRequest || resp.Code != ErrCodeEmptyBody {
+		t.Errorf("corpo vazio = %d %q; esperado 400 empty_body", status, resp.Code)
+	}
+
+	// O limite vem do MaxBytesReader aplicado por security.WrapHandler.
+	body := `{"user_id":"s1","message":"` + strings.Repeat("a", 200) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/chatbot", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	req.Body = http.MaxBytesReader(rec, req.Body, 64)
+	h.HandleChatbot(rec, req)
+	var resp ChatResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusRequestEntityTooLarge || resp.Code != ErrCodeBodyTooLarge || !strings.Contains(resp.Error, "64 bytes") {
+		t.Errorf("corpo grande = %d %+v; esperado 413 body_too_large com o limite", rec.Code, resp)
+	}
+}
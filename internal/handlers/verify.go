@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// handleVerifyHandshake responde à verificação de webhook da Meta (GET com hub.mode, hub.verify_token
+// e hub.challenge), comum ao WhatsApp Cloud API e ao Messenger.
+func handleVerifyHandshake(w http.ResponseWriter, r *http.Request, expectedToken string) {
+	mode := r.URL.Query().Get("hub.mode")
+	verifyToken := r.URL.Query().Get("hub.verify_token")
+	challenge := r.URL.Query().Get("hub.challenge")
+	if mode == "subscribe" && expectedToken != "" && verifyToken == expectedToken {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(challenge))
+		return
+	}
+	log.Warn().Str("path", r.URL.Path).Str("mode", mode).Msg("Verificação de webhook recusada")
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte("Forbidden: token mismatch or mode error"))
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func getVersion(t *testing.T, info BuildInfo) BuildInfo {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	HandleVersion(info).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("/version = %d %q; esperado 200 em JSON", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var got BuildInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("corpo inválido: %q", rec.Body.String())
+	}
+	return got
+}
+
+func TestHandleVersionReportsInjectedBuild(t *testing.T) {
+	info := BuildInfo{Version: "1.4.0", Commit: "abc1234", BuildTime: "2026-10-01T12:00:00Z"}
+
+	got := getVersion(t, info)
+	if got.Version != info.Version || got.Commit != info.Commit || got.BuildTime != info.BuildTime {
+		t.Errorf("/version = %+v; esperado os valores injetados", got)
+	}
+	if got.GoVersion != runtime.Version() {
+		t.Errorf("go_version = %q; esperado %q", got.GoVersion, runtime.Version())
+	}
+}
+
+func TestHandleVersionFillsDefaults(t *testing.T) {
+	got := getVersion(t, BuildInfo{})
+	if got.Version != "dev" || got.Commit == "" || got.BuildTime == "" {
+		t.Errorf("/version sem ldflags = %+v; esperado versão dev e commit/build_time preenchidos", got)
+	}
+}
@@ -2,22 +2,37 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"leadprojectarrumado/internal/services"
 )
 
 // WhatsAppWebhookHandler lida com requisições do webhook do WhatsApp Cloud API.
 type WhatsAppWebhookHandler struct {
 	service ChatbotService
+	sender  WhatsAppSender
+	delay   replyDelay
+	retry   sendRetry
+	replay  replayWindow
+	pool    *batchPool
 }
 
-// NewWhatsAppWebhookHandler cria um novo handler para o webhook do WhatsApp.
-func NewWhatsAppWebhookHandler(service ChatbotService) *WhatsAppWebhookHandler {
-	return &WhatsAppWebhookHandler{service: service}
+// NewWhatsAppWebhookHandler cria um novo handler para o webhook do WhatsApp, que responde pelo sender
+// informado (nil usa a Cloud API).
+func NewWhatsAppWebhookHandler(service ChatbotService, sender WhatsAppSender) *WhatsAppWebhookHandler {
+	if sender == nil {
+		sender = CloudAPISender{}
+	}
+	return &WhatsAppWebhookHandler{service: service, sender: sender, delay: loadReplyDelay("WHATSAPP"), retry: loadSendRetry("WHATSAPP"), replay: loadReplayWindow("WHATSAPP"), pool: loadBatchPool("WHATSAPP")}
 }
 
 // WhatsAppWebhookPayload representa o payload recebido do webhook do WhatsApp Cloud API.
@@ -25,44 +40,87 @@ type WhatsAppWebhookPayload struct {
 	Entry []struct {
 		Changes []struct {
 			Value struct {
-				Messages []struct {
-					From string `json:"from"`
-					ID   string `json:"id"`
-					Text struct {
-						Body string `json:"body"`
-					} `json:"text"`
-				} `json:"messages"`
+				Contacts []WhatsAppContact `json:"contacts"`
+				Messages []WhatsAppMessage `json:"messages"`
+				Statuses []WhatsAppStatus  `json:"statuses"`
 			} `json:"value"`
 		} `json:"changes"`
 	} `json:"entry"`
 }
 
+// WhatsAppContact identifica o remetente das mensagens do lote, com o nome do perfil no WhatsApp.
+type WhatsAppContact struct {
+	WaID    string `json:"wa_id"`
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+}
+
+// WhatsAppMessage representa uma mensagem recebida no webhook do WhatsApp Cloud API.
+type WhatsAppMessage struct {
+	From      string `json:"from"`
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Text      struct {
+		Body string `json:"body"`
+	} `json:"text"`
+	Image       *WhatsAppMedia       `json:"image,omitempty"`
+	Document    *WhatsAppMedia       `json:"document,omitempty"`
+	Interactive *WhatsAppInteractive `json:"interactive,omitempty"`
+}
+
+// WhatsAppStatus representa um evento de status (sent/delivered/read/failed) de uma mensagem enviada.
+type WhatsAppStatus struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Timestamp   string `json:"timestamp"`
+	RecipientID string `json:"recipient_id"`
+	Errors      []struct {
+		Code      int    `json:"code"`
+		Title     string `json:"title"`
+		Message   string `json:"message"`
+		ErrorData struct {
+			Details string `json:"details"`
+		} `json:"error_data"`
+	} `json:"errors"`
+}
+
+// FeatureChecker é implementado por serviços com subsistemas que podem ser desligados em tempo de execução.
+type FeatureChecker interface {
+	FeatureEnabled(name string) bool
+}
+
+// ProfileNameReceiver é implementado por serviços que aproveitam o nome de perfil do remetente
+// para dispensar a coleta do nome.
+type ProfileNameReceiver interface {
+	SetProfileName(userID, name string)
+}
+
+// DenyChecker é implementado por serviços com lista de bloqueio. Remetentes bloqueados recebem apenas
+// a resposta informada: a mídia não é baixada e o nome de perfil não é guardado.
+type DenyChecker interface {
+	DeniedReply(userID string) (reply string, denied bool)
+}
+
+// DeliveryStatusRecorder é implementado por serviços que registram o estado de entrega das mensagens.
+type DeliveryStatusRecorder interface {
+	RecordDeliveryStatus(messageID, status, recipientID string) error
+}
+
+// MessageClaimer é implementado por serviços que descartam mensagens de webhook já recebidas
+// (reenvios do mesmo lote), pelo ID atribuído pelo canal.
+type MessageClaimer interface {
+	ClaimMessage(channel, messageID string) bool
+}
+
 // HandleWhatsAppWebhook processa requisições GET (validação) e POST (mensagens) do webhook do WhatsApp.
 func (h *WhatsAppWebhookHandler) HandleWhatsAppWebhook(w http.ResponseWriter, r *http.Request) {
 	// Validação do webhook pelo Meta (GET)
 	// Processamento de mensagens recebidas (POST)
 	// Para cada mensagem recebida, processa com o fluxo do chatbot e responde via API do WhatsApp
 	if r.Method == "GET" {
-		mode := r.URL.Query().Get("hub.mode")
-		verifyToken := r.URL.Query().Get("hub.verify_token")
-		challenge := r.URL.Query().Get("hub.challenge")
-		envToken := os.Getenv("WHATSAPP_VERIFY_TOKEN")
-		fmt.Println("[WEBHOOK] mode:", mode)
-		fmt.Println("[WEBHOOK] verifyToken (req):", verifyToken)
-		fmt.Println("[WEBHOOK] verifyToken (env):", envToken)
-		fmt.Println("[WEBHOOK] challenge:", challenge)
-		if mode == "subscribe" && verifyToken == envToken {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(challenge))
-			return
-		}
-		w.WriteHeader(http.StatusForbidden)
-		w.Write([]byte("Forbidden: token mismatch or mode error"))
-		return
-	}
-
-	if r.Method != "POST" {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		handleVerifyHandshake(w, r, os.Getenv("WHATSAPP_VERIFY_TOKEN"))
 		return
 	}
 
@@ -78,61 +136,176 @@ func (h *WhatsAppWebhookHandler) HandleWhatsAppWebhook(w http.ResponseWriter, r
 		return
 	}
 
+	if fc, ok := h.service.(FeatureChecker); ok && !fc.FeatureEnabled("whatsapp") {
+		log.Warn().Msg("WhatsApp desligado por feature flag, webhook ignorado")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var messages []WhatsAppMessage
+	profileNames := make(map[string]string)
 	for _, entry := range payload.Entry {
 		for _, change := range entry.Changes {
-			for _, msg := range change.Value.Messages {
-				from := msg.From
-				text := msg.Text.Body
-				if strings.TrimSpace(text) == "" {
-					continue
-				}
-				response, err := h.service.ProcessMessage(from, text)
-				if err == nil {
-					SendWhatsAppMessage(from, response)
+			for _, st := range change.Value.Statuses {
+				h.handleStatus(st)
+			}
+			for _, c := range change.Value.Contacts {
+				if c.WaID != "" && c.Profile.Name != "" {
+					profileNames[c.WaID] = c.Profile.Name
 				}
 			}
+			messages = append(messages, change.Value.Messages...)
+		}
+	}
+	if pr, ok := h.service.(ProfileNameReceiver); ok {
+		for waID, name := range profileNames {
+			if _, denied := h.deniedReply(waID); !denied {
+				pr.SetProfileName(waID, name)
+			}
+		}
+	}
+
+	for _, msg := range sortMessagesByTimestamp(messages) {
+		if !h.replay.fresh(msg.Timestamp) {
+			log.Warn().Str("message_id", msg.ID).Str("timestamp", msg.Timestamp).Msg("Mensagem WhatsApp fora da janela de tempo descartada")
+			continue
+		}
+		msg := msg
+		if h.pool == nil {
+			h.handleMessage(r.Context(), msg)
+			continue
+		}
+		if !h.pool.submit(r.Context(), msg.From, func(ctx context.Context) { h.handleMessage(ctx, msg) }) {
+			// Sem 2xx o Meta reenvia o lote; as mensagens já aceitas antes desta são descartadas no reenvio pelo MessageClaimer.
+			log.Warn().Str("message_id", msg.ID).Msg("Mensagem WhatsApp não enfileirada (desligamento ou requisição cancelada), pedindo reenvio")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
 		}
 	}
+	// O Meta exige confirmação rápida; com o pool, o processamento continua após a resposta.
 	w.WriteHeader(http.StatusOK)
 }
 
-// SendWhatsAppMessage envia uma mensagem de texto para um usuário via WhatsApp Cloud API.
-func SendWhatsAppMessage(to, message string) error {
-	phoneID := os.Getenv("WHATSAPP_PHONE_ID")
-	token := os.Getenv("WHATSAPP_TOKEN")
-	url := fmt.Sprintf("https://graph.facebook.com/v19.0/%s/messages", phoneID)
+// handleMessage processa uma mensagem do lote com o fluxo do chatbot e envia a resposta. Remetentes
+// bloqueados recebem uma única resposta por mensagem, mesmo em mídia com legenda, sem download.
+// Mensagens já processadas (mesmo ID) são ignoradas.
+func (h *WhatsAppWebhookHandler) handleMessage(ctx context.Context, msg WhatsAppMessage) {
+	from := msg.From
+	if mc, ok := h.service.(MessageClaimer); ok && !mc.ClaimMessage("whatsapp", msg.ID) {
+		log.Info().Str("message_id", msg.ID).Msg("Mensagem WhatsApp já recebida, reenvio ignorado")
+		return
+	}
+	if reply, denied := h.deniedReply(from); denied {
+		log.Info().Str("recipient", from).Msg("Mensagem WhatsApp de remetente bloqueado ignorada")
+		h.reply(ctx, from, reply, nil)
+		return
+	}
+	text := msg.Text.Body
+	if media := msg.media(); media != nil {
+		h.handleMedia(ctx, from, media)
+		text = media.Caption
+	}
+	if msg.Interactive != nil && msg.Interactive.ButtonReply != nil {
+		text = msg.Interactive.ButtonReply.ID
+	}
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	response, err := h.service.ProcessMessage(ctx, "whatsapp", from, text)
+	if err != nil {
+		return
+	}
+	var replies []services.QuickReply
+	if qr, ok := h.service.(QuickReplier); ok {
+		replies = qr.QuickReplies(from)
+	}
+	h.delay.wait(ctx)
+	h.reply(ctx, from, response, replies)
+}
+
+// deniedReply consulta a lista de bloqueio do serviço, quando ele tiver uma.
+func (h *WhatsAppWebhookHandler) deniedReply(userID string) (string, bool) {
+	dc, ok := h.service.(DenyChecker)
+	if !ok {
+		return "", false
+	}
+	return dc.DeniedReply(userID)
+}
+
+// Drain para de aceitar lotes no pool de workers e aguarda o processamento das mensagens pendentes,
+// até o prazo de ctx. Deve ser chamado no desligamento, após o servidor parar de receber requisições.
+func (h *WhatsAppWebhookHandler) Drain(ctx context.Context) error {
+	if h.pool == nil {
+		return nil
+	}
+	return h.pool.drain(ctx)
+}
 
-	fmt.Println("[WHATSAPP] phoneID:", phoneID)
-	fmt.Println("[WHATSAPP] token (first 8 chars):", func() string {
-		if len(token) > 8 {
-			return token[:8] + "..."
-		} else {
-			return token
+// sortMessagesByTimestamp ordena o lote cronologicamente pelo campo timestamp (segundos Unix),
+// já que a ordem do array no payload não é garantida. Mensagens sem timestamp válido herdam o
+// horário da anterior, preservando sua posição relativa.
+func sortMessagesByTimestamp(messages []WhatsAppMessage) []WhatsAppMessage {
+	keys := make([]int64, len(messages))
+	var last int64
+	for i, msg := range messages {
+		if ts, err := strconv.ParseInt(msg.Timestamp, 10, 64); err == nil {
+			last = ts
 		}
-	}())
-	fmt.Println("[WHATSAPP] url:", url)
+		keys[i] = last
+	}
 
-	payload := map[string]interface{}{
-		"messaging_product": "whatsapp",
-		"to":                to,
-		"type":              "text",
-		"text":              map[string]string{"body": message},
+	idx := make([]int, len(messages))
+	for i := range idx {
+		idx[i] = i
 	}
-	b, _ := json.Marshal(payload)
+	sort.SliceStable(idx, func(a, b int) bool { return keys[idx[a]] < keys[idx[b]] })
 
-	req, _ := http.NewRequest("POST", url, strings.NewReader(string(b)))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
+	sorted := make([]WhatsAppMessage, len(messages))
+	for i, j := range idx {
+		sorted[i] = messages[j]
+	}
+	return sorted
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
+// handleStatus registra o estado de entrega informado pelo webhook e loga falhas com o detalhe do erro.
+func (h *WhatsAppWebhookHandler) handleStatus(st WhatsAppStatus) {
+	if st.Status == "failed" {
+		for _, e := range st.Errors {
+			log.Error().
+				Str("message_id", st.ID).
+				Str("recipient", st.RecipientID).
+				Int("code", e.Code).
+				Str("title", e.Title).
+				Str("details", e.ErrorData.Details).
+				Msg("Falha na entrega de mensagem WhatsApp")
+		}
+	}
+
+	recorder, ok := h.service.(DeliveryStatusRecorder)
+	if !ok || st.ID == "" {
+		return
+	}
+	if err := recorder.RecordDeliveryStatus(st.ID, st.Status, st.RecipientID); err != nil {
+		log.Warn().Err(err).Str("message_id", st.ID).Msg("Erro ao registrar status de entrega")
 	}
-	defer resp.Body.Close()
+}
 
-	bodyResp, _ := ioutil.ReadAll(resp.Body)
-	fmt.Println("[WHATSAPP] response status:", resp.StatusCode)
-	fmt.Println("[WHATSAPP] response body:", string(bodyResp))
-	return nil
+// reply envia a resposta ao usuário, com botões para as sugestões quando couberem, novas tentativas em falhas,
+// e registra o envio na auditoria.
+// Esgotadas as tentativas, a resposta é guardada para reenvio posterior quando o serviço suporta.
+func (h *WhatsAppWebhookHandler) reply(ctx context.Context, to, message string, replies []services.QuickReply) {
+	messageID, err := h.retry.do(ctx, func() (string, error) { return h.sender.SendReply(to, message, replies) })
+	status := "sent"
+	if err != nil {
+		status = "failed"
+		log.Error().Err(err).Str("recipient", to).Msg("Erro ao enviar mensagem WhatsApp")
+		if q, ok := h.service.(ReplyQueue); ok {
+			if qerr := q.QueueReply("whatsapp", to, message); qerr == nil {
+				status = "queued"
+			}
+		}
+	}
+	if ol, ok := h.service.(OutboundLogger); ok {
+		ol.LogOutbound("whatsapp", to, messageID, message, status)
+	}
 }
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"unicode/utf8"
+
+	"leadprojectarrumado/internal/services"
+)
+
+// Limites da Cloud API para mensagens interativas do tipo "button".
+const (
+	maxWhatsAppButtons     = 3
+	maxWhatsAppButtonTitle = 20
+	maxWhatsAppButtonBody  = 1024
+)
+
+// WhatsAppInteractive representa a resposta do usuário a uma mensagem interativa.
+type WhatsAppInteractive struct {
+	Type        string `json:"type"`
+	ButtonReply *struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"button_reply,omitempty"`
+}
+
+// fitsWhatsAppButtons indica se a resposta pode ser enviada com botões de resposta;
+// caso contrário (mais de 3 sugestões ou texto longo) ela segue como texto simples.
+func fitsWhatsAppButtons(message string, replies []services.QuickReply) bool {
+	return len(replies) > 0 && len(replies) <= maxWhatsAppButtons && utf8.RuneCountInString(message) <= maxWhatsAppButtonBody
+}
+
+// buildButtonsPayload monta o payload `type: "interactive"` com um botão por sugestão.
+// O id do botão é o valor da sugestão, devolvido ao bot quando o usuário o toca.
+func buildButtonsPayload(to, message string, replies []services.QuickReply) map[string]interface{} {
+	buttons := make([]map[string]interface{}, 0, len(replies))
+	for _, r := range replies {
+		title := r.Title
+		if utf8.RuneCountInString(title) > maxWhatsAppButtonTitle {
+			title = string([]rune(title)[:maxWhatsAppButtonTitle])
+		}
+		buttons = append(buttons, map[string]interface{}{
+			"type":  "reply",
+			"reply": map[string]string{"id": r.Value, "title": title},
+		})
+	}
+	return map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                to,
+		"type":              "interactive",
+		"interactive": map[string]interface{}{
+			"type":   "button",
+			"body":   map[string]string{"text": message},
+			"action": map[string]interface{}{"buttons": buttons},
+		},
+	}
+}
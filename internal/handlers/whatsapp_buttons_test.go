@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"leadprojectarrumado/internal/services"
+)
+
+func TestFitsWhatsAppButtons(t *testing.T) {
+	three := []services.QuickReply{{Title: "1", Value: "1"}, {Title: "2", Value: "2"}, {Title: "3", Value: "3"}}
+	cases := []struct {
+		name    string
+		message string
+		replies []services.QuickReply
+		want    bool
+	}{
+		{"sem sugestões", "oi", nil, false},
+		{"três sugestões", "oi", three, true},
+		{"quatro sugestões", "oi", append(three, services.QuickReply{Title: "4", Value: "4"}), false},
+		{"texto longo", strings.Repeat("a", maxWhatsAppButtonBody+1), three, false},
+	}
+	for _, c := range cases {
+		if got := fitsWhatsAppButtons(c.message, c.replies); got != c.want {
+			t.Errorf("%s: fitsWhatsAppButtons = %v; esperado %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestBuildButtonsPayload(t *testing.T) {
+	payload := buildButtonsPayload("5544999998888", "Resolveu?", []services.QuickReply{
+		{Title: "Sim", Value: "sim"},
+		{Title: "Não resolveu meu problema", Value: "nao"},
+	})
+
+	if payload["type"] != "interactive" || payload["to"] != "5544999998888" {
+		t.Fatalf("payload = %+v; esperado mensagem interativa para o destinatário", payload)
+	}
+	interactive := payload["interactive"].(map[string]interface{})
+	if body := interactive["body"].(map[string]string); body["text"] != "Resolveu?" {
+		t.Errorf("corpo = %q; esperado o texto da resposta", body["text"])
+	}
+	buttons := interactive["action"].(map[string]interface{})["buttons"].([]map[string]interface{})
+	if len(buttons) != 2 {
+		t.Fatalf("%d botões; esperado 2", len(buttons))
+	}
+	reply := buttons[1]["reply"].(map[string]string)
+	if reply["id"] != "nao" || reply["title"] != "Não resolveu meu pro" {
+		t.Errorf("botão = %+v; esperado id com o valor e título truncado em %d caracteres", reply, maxWhatsAppButtonTitle)
+	}
+}
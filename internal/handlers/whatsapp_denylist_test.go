@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// denyService bloqueia o remetente informado e registra o que o webhook repassou ao serviço.
+type denyService struct {
+	blocked string
+
+	mu          sync.Mutex
+	processed   int
+	attachments int
+	profiles    []string
+}
+
+func (d *denyService) ProcessMessage(context.Context, string, string, string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.processed++
+	return "ok", nil
+}
+
+func (d *denyService) DeniedReply(userID string) (string, bool) {
+	return "bloqueado", userID == d.blocked
+}
+
+func (d *denyService) ReceiveAttachment(string, string, string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.attachments++
+	return "anexo", nil
+}
+
+func (d *denyService) SetProfileName(userID, _ string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.profiles = append(d.profiles, userID)
+}
+
+// whatsAppCaptionedImagePayload monta um webhook com o contato e uma imagem com legenda.
+func whatsAppCaptionedImagePayload(from string) string {
+	return fmt.Sprintf(`{"entry":[{"changes":[{"value":{"contacts":[{"wa_id":%q,"profile":{"name":"Ana"}}],`+
+		`"messages":[{"from":%q,"id":"wamid.2","timestamp":"%d","type":"image","image":{"id":"media-1","caption":"meu modem"}}]}}]}]}`,
+		from, from, time.Now().Unix())
+}
+
+func TestWhatsAppDeniedSenderGetsSingleReplyWithoutMediaOrProfile(t *testing.T) {
+	const blocked = "5544900000000"
+	svc := &denyService{blocked: blocked}
+	sender := &recordingSender{}
+	h := NewWhatsAppWebhookHandler(svc, sender)
+	h.pool = nil
+
+	rec := httptest.NewRecorder()
+	h.HandleWhatsAppWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(whatsAppCaptionedImagePayload(blocked))))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, quer 200", rec.Code)
+	}
+	sent := sender.Sent()
+	if len(sent) != 1 || sent[0].To != blocked || sent[0].Message != "bloqueado" {
+		t.Errorf("envios = %+v, quer uma única resposta de bloqueio", sent)
+	}
+	if svc.attachments != 0 || svc.processed != 0 {
+		t.Errorf("anexos = %d, processadas = %d; nada deveria chegar ao fluxo", svc.attachments, svc.processed)
+	}
+	if len(svc.profiles) != 0 {
+		t.Errorf("nomes de perfil guardados: %v", svc.profiles)
+	}
+}
+
+func TestWhatsAppAllowedSenderIsProcessed(t *testing.T) {
+	svc := &denyService{blocked: "5544900000000"}
+	h := NewWhatsAppWebhookHandler(svc, &recordingSender{})
+	h.pool = nil
+
+	rec := httptest.NewRecorder()
+	h.HandleWhatsAppWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(whatsAppTextPayload("5544999998888", "oi"))))
+
+	if svc.processed != 1 {
+		t.Errorf("processadas = %d, quer 1", svc.processed)
+	}
+}
+
+func TestWhatsAppContactProfileNameReachesService(t *testing.T) {
+	svc := &denyService{blocked: "5544900000000"}
+	h := NewWhatsAppWebhookHandler(svc, &recordingSender{})
+	h.pool = nil
+
+	rec := httptest.NewRecorder()
+	h.HandleWhatsAppWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(whatsAppCaptionedImagePayload("5544999998888"))))
+
+	if len(svc.profiles) != 1 || svc.profiles[0] != "5544999998888" {
+		t.Errorf("nomes de perfil guardados: %v; esperado o do remetente", svc.profiles)
+	}
+}
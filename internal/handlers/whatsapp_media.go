@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WhatsAppMedia representa uma imagem ou documento recebido no webhook.
+type WhatsAppMedia struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Caption  string `json:"caption"`
+	Filename string `json:"filename"`
+}
+
+// AttachmentReceiver é implementado por serviços que anexam mídias recebidas ao atendimento do usuário.
+type AttachmentReceiver interface {
+	ReceiveAttachment(channel, userID, ref string) (string, error)
+}
+
+// maxMediaBytes limita o tamanho das mídias baixadas da Cloud API.
+const maxMediaBytes = 16 << 20
+
+// errMediaTooLarge indica mídia acima de maxMediaBytes; ela é recusada em vez de gravada pela metade.
+var errMediaTooLarge = errors.New("mídia acima do tamanho máximo")
+
+// mediaTooLargeReply é a resposta ao usuário quando a mídia enviada é recusada pelo tamanho.
+var mediaTooLargeReply = fmt.Sprintf("📎 *Arquivo muito grande.* Envie mídias de até %d MB.", maxMediaBytes>>20)
+
+// media retorna a mídia anexada à mensagem (imagem ou documento), ou nil se for apenas texto.
+func (m WhatsAppMessage) media() *WhatsAppMedia {
+	switch {
+	case m.Image != nil && m.Image.ID != "":
+		return m.Image
+	case m.Document != nil && m.Document.ID != "":
+		return m.Document
+	}
+	return nil
+}
+
+// handleMedia baixa a mídia, repassa a referência ao serviço e confirma o recebimento ao usuário.
+// Mídias acima de maxMediaBytes são recusadas com aviso ao usuário; em outras falhas do download o ID
+// da mídia é guardado como referência para consulta posterior.
+func (h *WhatsAppWebhookHandler) handleMedia(ctx context.Context, from string, media *WhatsAppMedia) {
+	ref, err := downloadWhatsAppMedia(ctx, media)
+	if errors.Is(err, errMediaTooLarge) {
+		log.Warn().Str("media_id", media.ID).Msg("Mídia do WhatsApp acima do tamanho máximo recusada")
+		h.reply(ctx, from, mediaTooLargeReply, nil)
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("media_id", media.ID).Msg("Erro ao baixar mídia do WhatsApp")
+		ref = "whatsapp-media:" + media.ID
+	}
+
+	receiver, ok := h.service.(AttachmentReceiver)
+	if !ok {
+		return
+	}
+	response, err := receiver.ReceiveAttachment("whatsapp", from, ref)
+	if err != nil {
+		log.Error().Err(err).Str("recipient", from).Msg("Erro ao registrar anexo")
+		return
+	}
+	h.reply(ctx, from, response, nil)
+}
+
+// newMediaInfoRequest monta a consulta à Cloud API que retorna a URL temporária de download da mídia.
+func newMediaInfoRequest(ctx context.Context, mediaID, token string) (*http.Request, error) {
+	url := fmt.Sprintf("https://graph.facebook.com/v19.0/%s", mediaID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// downloadWhatsAppMedia obtém a URL da mídia, baixa o arquivo e o grava em WHATSAPP_MEDIA_DIR
+// (padrão "media"), retornando o caminho local.
+func downloadWhatsAppMedia(ctx context.Context, media *WhatsAppMedia) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	token := os.Getenv("WHATSAPP_TOKEN")
+	client := &http.Client{}
+
+	req, err := newMediaInfoRequest(ctx, media.ID, token)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	var info struct {
+		URL      string `json:"url"`
+		MimeType string `json:"mime_type"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	resp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("resposta inválida da consulta de mídia: %w", err)
+	}
+	if resp.StatusCode >= 300 || info.URL == "" {
+		return "", fmt.Errorf("consulta de mídia retornou status %d", resp.StatusCode)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, info.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("download de mídia retornou status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxMediaBytes {
+		return "", errMediaTooLarge
+	}
+
+	dir := os.Getenv("WHATSAPP_MEDIA_DIR")
+	if dir == "" {
+		dir = "media"
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, filepath.Base(media.ID)+mediaExtension(info.MimeType, media.Filename))
+	if err := writeMedia(path, resp.Body, maxMediaBytes); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeMedia grava r em path, recusando com errMediaTooLarge (e removendo o arquivo) se passar de limit
+// bytes, o que cobre respostas sem Content-Length ou com tamanho informado errado.
+func writeMedia(path string, r io.Reader, limit int64) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, limit+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > limit {
+		err = errMediaTooLarge
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// mediaExtension escolhe a extensão do arquivo pelo nome original ou pelo tipo MIME.
+func mediaExtension(mimeType, filename string) string {
+	if ext := filepath.Ext(filename); ext != "" {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteMediaWithinLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foto.jpg")
+	if err := writeMedia(path, strings.NewReader("12345678"), 8); err != nil {
+		t.Fatalf("writeMedia: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "12345678" {
+		t.Errorf("arquivo = %q, %v; esperado o conteúdo completo", data, err)
+	}
+}
+
+func TestWriteMediaRejectsOversize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	err := writeMedia(path, strings.NewReader("123456789"), 8)
+	if !errors.Is(err, errMediaTooLarge) {
+		t.Fatalf("err = %v; esperado errMediaTooLarge", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("arquivo truncado não foi removido: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortMessagesByTimestamp(t *testing.T) {
+	msg := func(id, ts string) WhatsAppMessage { return WhatsAppMessage{ID: id, Timestamp: ts} }
+	cases := []struct {
+		name  string
+		batch []WhatsAppMessage
+		want  []string
+	}{
+		{"fora de ordem", []WhatsAppMessage{msg("c", "1700000030"), msg("a", "1700000010"), msg("b", "1700000020")}, []string{"a", "b", "c"}},
+		{"já ordenado", []WhatsAppMessage{msg("a", "1700000010"), msg("b", "1700000020")}, []string{"a", "b"}},
+		{"empate mantém a ordem do payload", []WhatsAppMessage{msg("b", "1700000010"), msg("a", "1700000010"), msg("c", "1700000005")}, []string{"c", "b", "a"}},
+		{"sem timestamp herda o anterior", []WhatsAppMessage{msg("b", "1700000020"), msg("b2", ""), msg("a", "1700000010"), msg("a2", "x")}, []string{"a", "a2", "b", "b2"}},
+		{"vazio", nil, []string{}},
+	}
+	for _, tc := range cases {
+		got := []string{}
+		for _, m := range sortMessagesByTimestamp(tc.batch) {
+			got = append(got, m.ID)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: ordem = %v; esperado %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSortMessagesByTimestampDoesNotModifyBatch(t *testing.T) {
+	batch := []WhatsAppMessage{{ID: "b", Timestamp: "2"}, {ID: "a", Timestamp: "1"}}
+	sortMessagesByTimestamp(batch)
+	if batch[0].ID != "b" || batch[1].ID != "a" {
+		t.Errorf("lote original alterado: %+v", batch)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"leadprojectarrumado/internal/services"
+)
+
+// WhatsAppSender entrega as respostas do bot ao usuário. O handler do webhook recebe a implementação
+// no construtor, o que permite exercitar o fluxo completo sem acessar a rede.
+type WhatsAppSender interface {
+	// SendReply envia a mensagem (com botões para as sugestões, quando couberem) e retorna o ID atribuído.
+	SendReply(to, message string, replies []services.QuickReply) (messageID string, err error)
+	// SendTemplate envia um template aprovado (HSM) com os parâmetros do corpo e retorna o ID atribuído.
+	SendTemplate(to, templateName, lang string, params []string) (messageID string, err error)
+}
+
+// whatsAppGraphURL é o endereço padrão da Graph API usado por CloudAPISender.
+const whatsAppGraphURL = "https://graph.facebook.com/v19.0"
+
+// CloudAPISender é o WhatsAppSender real, que envia pela WhatsApp Cloud API (WHATSAPP_PHONE_ID, WHATSAPP_TOKEN).
+type CloudAPISender struct {
+	// BaseURL substitui o endereço da Graph API (testes, proxy); vazio usa whatsAppGraphURL.
+	BaseURL string
+}
+
+// SendReply envia a resposta com botões quando possível, ou como texto simples.
+func (c CloudAPISender) SendReply(to, message string, replies []services.QuickReply) (string, error) {
+	if fitsWhatsAppButtons(message, replies) {
+		return c.post(buildButtonsPayload(to, message, replies))
+	}
+	return c.post(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                to,
+		"type":              "text",
+		"text":              map[string]string{"body": message},
+	})
+}
+
+// SendTemplate envia o template pela Cloud API.
+func (c CloudAPISender) SendTemplate(to, templateName, lang string, params []string) (string, error) {
+	return c.post(buildTemplatePayload(to, templateName, lang, params))
+}
+
+// whatsAppSendResponse representa a resposta da Cloud API ao envio de uma mensagem.
+type whatsAppSendResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+// post envia o payload JSON para o endpoint de mensagens da WhatsApp Cloud API
+// e retorna o ID da mensagem criada, quando informado.
+func (c CloudAPISender) post(payload map[string]interface{}) (string, error) {
+	phoneID := os.Getenv("WHATSAPP_PHONE_ID")
+	token := os.Getenv("WHATSAPP_TOKEN")
+	base := c.BaseURL
+	if base == "" {
+		base = whatsAppGraphURL
+	}
+	url := fmt.Sprintf("%s/%s/messages", base, phoneID)
+
+	b, _ := json.Marshal(payload)
+
+	req, _ := http.NewRequest("POST", url, strings.NewReader(string(b)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: sendTimeout("WHATSAPP")}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	bodyResp, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		// O corpo do erro pode trazer o telefone do destinatário; o log passa pela máscara de dados pessoais.
+		log.Warn().Int("status", resp.StatusCode).Str("body", string(bodyResp)).Msg("WhatsApp Cloud API recusou o envio")
+		return "", sendStatusError{API: "WhatsApp Cloud API", StatusCode: resp.StatusCode}
+	}
+
+	var sent whatsAppSendResponse
+	if err := json.Unmarshal(bodyResp, &sent); err == nil && len(sent.Messages) > 0 {
+		return sent.Messages[0].ID, nil
+	}
+	return "", nil
+}
+
+// Push envia uma mensagem proativa (lembretes, avisos de atendimento) pelo sender do handler.
+// É o services.Pusher do canal WhatsApp.
+func (h *WhatsAppWebhookHandler) Push(to, message string) error {
+	_, err := h.sender.SendReply(to, message, nil)
+	return err
+}
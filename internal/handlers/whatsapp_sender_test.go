@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"leadprojectarrumado/internal/services"
+)
+
+// sentWhatsAppMessage é um envio registrado por recordingSender. Em templates, Message é o nome do template
+// e Params os parâmetros do corpo.
+type sentWhatsAppMessage struct {
+	To      string
+	Message string
+	Replies []services.QuickReply
+	Params  []string
+}
+
+// recordingSender é um WhatsAppSender que apenas registra os envios. Err, se definido, é retornado em todos os envios.
+type recordingSender struct {
+	Err error
+
+	mu   sync.Mutex
+	sent []sentWhatsAppMessage
+}
+
+func (r *recordingSender) SendReply(to, message string, replies []services.QuickReply) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, sentWhatsAppMessage{To: to, Message: message, Replies: replies})
+	if r.Err != nil {
+		return "", r.Err
+	}
+	return "recorded-" + to, nil
+}
+
+func (r *recordingSender) SendTemplate(to, templateName, lang string, params []string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, sentWhatsAppMessage{To: to, Message: templateName, Params: params})
+	if r.Err != nil {
+		return "", r.Err
+	}
+	return "recorded-" + to, nil
+}
+
+// Sent retorna uma cópia dos envios registrados, na ordem em que ocorreram.
+func (r *recordingSender) Sent() []sentWhatsAppMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]sentWhatsAppMessage(nil), r.sent...)
+}
+
+// sentTo filtra os envios para um destinatário.
+func (r *recordingSender) sentTo(to string) []sentWhatsAppMessage {
+	var out []sentWhatsAppMessage
+	for _, m := range r.Sent() {
+		if m.To == to {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// quickReplyService é um echoService que também sugere respostas rápidas.
+type quickReplyService struct{ echoService }
+
+func (quickReplyService) QuickReplies(string) []services.QuickReply {
+	return []services.QuickReply{{Title: "Menu", Value: "menu"}}
+}
+
+// postWhatsApp envia o payload ao webhook e aguarda o processamento das mensagens.
+func postWhatsApp(t *testing.T, h *WhatsAppWebhookHandler, payload string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.HandleWhatsAppWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(payload)))
+	if err := h.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; esperado 200", rec.Code)
+	}
+}
+
+func TestWhatsAppWebhookRepliesToEachSenderInOrder(t *testing.T) {
+	sender := &recordingSender{}
+	h := NewWhatsAppWebhookHandler(echoService{}, sender)
+	h.pool = newBatchPool(2, 8)
+
+	now := time.Now().Unix()
+	payload := fmt.Sprintf(`{"entry":[{"changes":[{"value":{"messages":[`+
+		`{"from":"5544111111111","id":"wamid.2","timestamp":"%d","type":"text","text":{"body":"segunda"}},`+
+		`{"from":"5544222222222","id":"wamid.3","timestamp":"%d","type":"text","text":{"body":"outra pessoa"}},`+
+		`{"from":"5544111111111","id":"wamid.1","timestamp":"%d","type":"text","text":{"body":"primeira"}}]}}]}]}`,
+		now, now, now-1)
+	postWhatsApp(t, h, payload)
+
+	first := sender.sentTo("5544111111111")
+	if len(first) != 2 || first[0].Message != "eco: primeira" || first[1].Message != "eco: segunda" {
+		t.Errorf("envios para 5544111111111 = %+v; esperado as respostas na ordem do timestamp", first)
+	}
+	if other := sender.sentTo("5544222222222"); len(other) != 1 || other[0].Message != "eco: outra pessoa" {
+		t.Errorf("envios para 5544222222222 = %+v", other)
+	}
+	if n := len(sender.Sent()); n != 3 {
+		t.Errorf("envios = %d; esperado 3", n)
+	}
+}
+
+func TestWhatsAppWebhookButtonReplySendsPayloadAndQuickReplies(t *testing.T) {
+	sender := &recordingSender{}
+	h := NewWhatsAppWebhookHandler(quickReplyService{}, sender)
+	h.pool = newBatchPool(1, 1)
+
+	payload := fmt.Sprintf(`{"entry":[{"changes":[{"value":{"messages":[{"from":"5544999998888","id":"wamid.1","timestamp":"%d","type":"interactive",`+
+		`"interactive":{"type":"button_reply","button_reply":{"id":"menu","title":"Menu"}}}]}}]}]}`, time.Now().Unix())
+	postWhatsApp(t, h, payload)
+
+	sent := sender.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("envios = %+v; esperado 1", sent)
+	}
+	if sent[0].To != "5544999998888" || sent[0].Message != "eco: menu" {
+		t.Errorf("envio = %+v; esperado o ID do botão como mensagem", sent[0])
+	}
+	if len(sent[0].Replies) != 1 || sent[0].Replies[0].Value != "menu" {
+		t.Errorf("sugestões = %+v", sent[0].Replies)
+	}
+}
+
+func TestWhatsAppWebhookIgnoresEmptyAndStaleMessages(t *testing.T) {
+	sender := &recordingSender{}
+	h := NewWhatsAppWebhookHandler(echoService{}, sender)
+	h.pool = newBatchPool(1, 1)
+	h.replay = replayWindow{MaxAge: time.Hour, Skew: 30 * time.Second, now: time.Now}
+
+	postWhatsApp(t, h, whatsAppTextPayload("5544999998888", "   "))
+	stale := fmt.Sprintf(`{"entry":[{"changes":[{"value":{"messages":[{"from":"5544999998888","id":"wamid.9","timestamp":"%d","type":"text","text":{"body":"antiga"}}]}}]}]}`,
+		time.Now().Add(-24*time.Hour).Unix())
+	postWhatsApp(t, h, stale)
+
+	if sent := sender.Sent(); len(sent) != 0 {
+		t.Errorf("envios = %+v; mensagens vazias ou fora da janela não devem ter resposta", sent)
+	}
+}
+
+func TestPushUsesHandlerSender(t *testing.T) {
+	sender := &recordingSender{}
+	h := NewWhatsAppWebhookHandler(echoService{}, sender)
+
+	if err := h.Push("5511999999999", "lembrete"); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	sent := sender.sentTo("5511999999999")
+	if len(sent) != 1 || sent[0].Message != "lembrete" || sent[0].Replies != nil {
+		t.Fatalf("envios = %+v; esperado um texto simples pelo sender do handler", sent)
+	}
+
+	sender.Err = fmt.Errorf("fora do ar")
+	if err := h.Push("5511999999999", "outro"); err == nil {
+		t.Error("Push com sender falhando retornou nil; esperado o erro do envio")
+	}
+}
+
+func TestSendTemplateValidatesAndUsesHandlerSender(t *testing.T) {
+	t.Setenv("WHATSAPP_TEMPLATES", "lembrete_lead:2")
+	sender := &recordingSender{}
+	h := NewWhatsAppWebhookHandler(echoService{}, sender)
+
+	if err := h.SendTemplate("5511999999999", "desconhecido", "pt_BR", nil); err == nil {
+		t.Error("template não configurado aceito")
+	}
+	if err := h.SendTemplate("5511999999999", "lembrete_lead", "pt_BR", []string{"Ana"}); err == nil {
+		t.Error("template com parâmetros a menos aceito")
+	}
+	if len(sender.Sent()) != 0 {
+		t.Fatalf("templates inválidos chegaram ao sender: %+v", sender.Sent())
+	}
+
+	if err := h.SendTemplate("5511999999999", "lembrete_lead", "pt_BR", []string{"Ana", "Fibra 500"}); err != nil {
+		t.Fatalf("SendTemplate: %v", err)
+	}
+	sent := sender.Sent()
+	if len(sent) != 1 || sent[0].Message != "lembrete_lead" || len(sent[0].Params) != 2 {
+		t.Errorf("envios = %+v; esperado o template pelo sender do handler", sent)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// statusFixture é um lote de eventos de status como enviado pela Cloud API, um por estado de entrega.
+const statusFixture = `{"entry":[{"changes":[{"value":{"statuses":[
+	{"id":"wamid.SENT","status":"sent","timestamp":"1700000000","recipient_id":"5544111111111"},
+	{"id":"wamid.DELIVERED","status":"delivered","timestamp":"1700000001","recipient_id":"5544111111111"},
+	{"id":"wamid.READ","status":"read","timestamp":"1700000002","recipient_id":"5544222222222"},
+	{"id":"wamid.FAILED","status":"failed","timestamp":"1700000003","recipient_id":"5544333333333",
+	 "errors":[{"code":131047,"title":"Re-engagement message","message":"Re-engagement message","error_data":{"details":"janela de 24h expirada"}}]}
+]}}]}]}`
+
+func TestWhatsAppStatusWebhookStoresDeliveryStatus(t *testing.T) {
+	svc := newServiceOnMiniredis(t)
+	h := NewWhatsAppWebhookHandler(svc, &recordingSender{})
+
+	postWhatsApp(t, h, statusFixture)
+
+	for id, want := range map[string]string{
+		"wamid.SENT":      "sent",
+		"wamid.DELIVERED": "delivered",
+		"wamid.READ":      "read",
+		"wamid.FAILED":    "failed",
+	} {
+		got, err := svc.DeliveryStatus(id)
+		if err != nil {
+			t.Errorf("DeliveryStatus(%s): %v", id, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("status de %s = %q; esperado %q", id, got, want)
+		}
+	}
+}
+
+func TestWhatsAppStatusWebhookKeepsLatestStatus(t *testing.T) {
+	svc := newServiceOnMiniredis(t)
+	sender := &recordingSender{}
+	h := NewWhatsAppWebhookHandler(svc, sender)
+
+	for _, status := range []string{"sent", "delivered", "read"} {
+		postWhatsApp(t, h, `{"entry":[{"changes":[{"value":{"statuses":[{"id":"wamid.1","status":"`+status+`","recipient_id":"5544111111111"}]}}]}]}`)
+	}
+	if got, _ := svc.DeliveryStatus("wamid.1"); got != "read" {
+		t.Errorf("status = %q; esperado o último recebido (read)", got)
+	}
+	if sent := sender.Sent(); len(sent) != 0 {
+		t.Errorf("eventos de status geraram respostas: %+v", sent)
+	}
+}
+
+func TestWhatsAppStatusWithoutIDIsIgnored(t *testing.T) {
+	svc := newServiceOnMiniredis(t)
+	h := NewWhatsAppWebhookHandler(svc, &recordingSender{})
+
+	rec := httptest.NewRecorder()
+	h.HandleWhatsAppWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/whatsapp",
+		strings.NewReader(`{"entry":[{"changes":[{"value":{"statuses":[{"status":"read"}]}}]}]}`)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d; esperado 200", rec.Code)
+	}
+	if _, err := svc.DeliveryStatus(""); err == nil {
+		t.Error("status sem ID foi gravado")
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SendTemplate envia uma mensagem de template aprovado (HSM) pelo sender do handler.
+// Templates são necessários para contatar o usuário fora da janela de 24h da sessão.
+// O número de parâmetros é validado contra a definição configurada em WHATSAPP_TEMPLATES.
+func (h *WhatsAppWebhookHandler) SendTemplate(to, templateName, lang string, params []string) error {
+	templates := loadWhatsAppTemplates()
+	expected, ok := templates[templateName]
+	if !ok {
+		return fmt.Errorf("template WhatsApp não configurado: %s", templateName)
+	}
+	if len(params) != expected {
+		return fmt.Errorf("template %s espera %d parâmetros, recebeu %d", templateName, expected, len(params))
+	}
+	_, err := h.sender.SendTemplate(to, templateName, lang, params)
+	return err
+}
+
+// buildTemplatePayload monta o payload `type: "template"` com os parâmetros do corpo da mensagem.
+func buildTemplatePayload(to, templateName, lang string, params []string) map[string]interface{} {
+	template := map[string]interface{}{
+		"name":     templateName,
+		"language": map[string]string{"code": lang},
+	}
+	if len(params) > 0 {
+		parameters := make([]map[string]string, 0, len(params))
+		for _, p := range params {
+			parameters = append(parameters, map[string]string{"type": "text", "text": p})
+		}
+		template["components"] = []map[string]interface{}{
+			{"type": "body", "parameters": parameters},
+		}
+	}
+
+	return map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                to,
+		"type":              "template",
+		"template":          template,
+	}
+}
+
+// loadWhatsAppTemplates lê as definições de templates no formato "nome:qtd_parametros,nome2:qtd".
+func loadWhatsAppTemplates() map[string]int {
+	templates := make(map[string]int)
+	for _, item := range strings.Split(os.Getenv("WHATSAPP_TEMPLATES"), ",") {
+		name, count, found := strings.Cut(strings.TrimSpace(item), ":")
+		if !found || name == "" {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n < 0 {
+			continue
+		}
+		templates[strings.TrimSpace(name)] = n
+	}
+	return templates
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// templateRequest é o payload de template recebido pela Cloud API simulada.
+type templateRequest struct {
+	MessagingProduct string `json:"messaging_product"`
+	To               string `json:"to"`
+	Type             string `json:"type"`
+	Template         struct {
+		Name     string `json:"name"`
+		Language struct {
+			Code string `json:"code"`
+		} `json:"language"`
+		Components []struct {
+			Type       string `json:"type"`
+			Parameters []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"parameters"`
+		} `json:"components"`
+	} `json:"template"`
+}
+
+func TestCloudAPISenderPostsTemplatePayload(t *testing.T) {
+	t.Setenv("WHATSAPP_PHONE_ID", "1234")
+	t.Setenv("WHATSAPP_TOKEN", "segredo")
+
+	var got templateRequest
+	var path, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("payload inválido: %v", err)
+		}
+		w.Write([]byte(`{"messages":[{"id":"wamid.TEMPLATE"}]}`))
+	}))
+	defer srv.Close()
+
+	id, err := CloudAPISender{BaseURL: srv.URL}.SendTemplate("5544999998888", "lembrete_lead", "pt_BR", []string{"Ana", "Fibra 500"})
+	if err != nil {
+		t.Fatalf("SendTemplate: %v", err)
+	}
+	if id != "wamid.TEMPLATE" {
+		t.Errorf("id = %q; esperado o ID devolvido pela API", id)
+	}
+	if path != "/1234/messages" || auth != "Bearer segredo" {
+		t.Errorf("requisição em %q com Authorization %q; esperado /1234/messages e o token", path, auth)
+	}
+	if got.MessagingProduct != "whatsapp" || got.To != "5544999998888" || got.Type != "template" {
+		t.Errorf("cabeçalho do payload = %+v", got)
+	}
+	if got.Template.Name != "lembrete_lead" || got.Template.Language.Code != "pt_BR" {
+		t.Errorf("template = %q/%q; esperado lembrete_lead/pt_BR", got.Template.Name, got.Template.Language.Code)
+	}
+	if len(got.Template.Components) != 1 || got.Template.Components[0].Type != "body" {
+		t.Fatalf("components = %+v; esperado um componente body", got.Template.Components)
+	}
+	params := got.Template.Components[0].Parameters
+	if len(params) != 2 || params[0].Type != "text" || params[0].Text != "Ana" || params[1].Text != "Fibra 500" {
+		t.Errorf("parâmetros = %+v; esperado Ana e Fibra 500 como texto, na ordem", params)
+	}
+}
+
+func TestCloudAPISenderOmitsComponentsWithoutParams(t *testing.T) {
+	var raw map[string]map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&raw)
+		w.Write([]byte(`{"messages":[{"id":"wamid.1"}]}`))
+	}))
+	defer srv.Close()
+
+	if _, err := (CloudAPISender{BaseURL: srv.URL}).SendTemplate("5544999998888", "boas_vindas", "pt_BR", nil); err != nil {
+		t.Fatalf("SendTemplate: %v", err)
+	}
+	if _, ok := raw["template"]["components"]; ok {
+		t.Errorf("template sem parâmetros enviou components: %v", raw["template"])
+	}
+}
+
+func TestCloudAPISenderSurfacesRejectedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"template inexistente"}}`))
+	}))
+	defer srv.Close()
+
+	if _, err := (CloudAPISender{BaseURL: srv.URL}).SendTemplate("5544999998888", "x", "pt_BR", nil); err == nil {
+		t.Error("SendTemplate com 400 retornou nil; esperado erro")
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// batchPool processa as mensagens dos lotes do webhook fora da requisição, com um número fixo de workers.
+// Cada remetente é sempre atendido pelo mesmo worker, preservando a ordem das suas mensagens.
+type batchPool struct {
+	queues []chan func(context.Context)
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// loadBatchPool lê <PREFIX>_WORKERS (padrão 4) e <PREFIX>_QUEUE_SIZE (padrão 100, por worker).
+// Com <PREFIX>_WORKERS=0 o lote volta a ser processado dentro da requisição.
+func loadBatchPool(prefix string) *batchPool {
+	workers := 4
+	if n, err := strconv.Atoi(os.Getenv(prefix + "_WORKERS")); err == nil && n >= 0 {
+		workers = n
+	}
+	return newBatchPool(workers, envLimit(prefix+"_QUEUE_SIZE", 100))
+}
+
+// newBatchPool inicia os workers; retorna nil se workers for zero.
+func newBatchPool(workers, size int) *batchPool {
+	if workers <= 0 {
+		return nil
+	}
+	p := &batchPool{queues: make([]chan func(context.Context), workers)}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	for i := range p.queues {
+		q := make(chan func(context.Context), size)
+		p.queues[i] = q
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range q {
+				p.run(job)
+			}
+		}()
+	}
+	return p
+}
+
+// run executa job recuperando pânicos: fora da requisição o net/http não os trata, e um pânico no
+// processamento de uma mensagem derrubaria o processo inteiro.
+func (p *batchPool) run(job func(context.Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("stack", string(debug.Stack())).Msg("Pânico ao processar mensagem do WhatsApp")
+		}
+	}()
+	job(p.ctx)
+}
+
+// submit enfileira job no worker do remetente key. Com a fila cheia aguarda uma vaga (mantendo a ordem)
+// até ctx ser cancelado; retorna false se a mensagem não foi aceita.
+func (p *batchPool) submit(ctx context.Context, key string, job func(context.Context)) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	select {
+	case p.queues[h.Sum32()%uint32(len(p.queues))] <- job:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// drain para de aceitar mensagens e aguarda as enfileiradas terminarem. Se ctx expirar antes,
+// cancela o contexto dos workers (interrompendo atrasos e chamadas pendentes) e retorna ctx.Err().
+func (p *batchPool) drain(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		for _, q := range p.queues {
+			close(q)
+		}
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
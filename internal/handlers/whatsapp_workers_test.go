@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoService é um ChatbotService que responde "eco: <mensagem>".
+type echoService struct{}
+
+func (echoService) ProcessMessage(_ context.Context, _, _, message string) (string, error) {
+	return "eco: " + message, nil
+}
+
+// whatsAppTextPayload monta um webhook com uma mensagem de texto recebida agora.
+func whatsAppTextPayload(from, body string) string {
+	return fmt.Sprintf(`{"entry":[{"changes":[{"value":{"messages":[{"from":%q,"id":"wamid.1","timestamp":"%d","type":"text","text":{"body":%q}}]}}]}]}`,
+		from, time.Now().Unix(), body)
+}
+
+func TestBatchPoolRecoversFromPanic(t *testing.T) {
+	p := newBatchPool(1, 2)
+	done := make(chan struct{})
+	p.submit(context.Background(), "5544", func(context.Context) { panic("falha no processamento") })
+	p.submit(context.Background(), "5544", func(context.Context) { close(done) })
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker parou após o pânico")
+	}
+	if err := p.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWhatsAppWebhookRejectedMessageReturns503(t *testing.T) {
+	h := NewWhatsAppWebhookHandler(echoService{}, &recordingSender{})
+	h.pool = newBatchPool(1, 1)
+	h.pool.drain(context.Background())
+
+	rec := httptest.NewRecorder()
+	h.HandleWhatsAppWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(whatsAppTextPayload("5544999998888", "oi"))))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d; esperado 503 para o Meta reenviar", rec.Code)
+	}
+}
+
+func TestWhatsAppWebhookAcceptedMessageReturns200(t *testing.T) {
+	sender := &recordingSender{}
+	h := NewWhatsAppWebhookHandler(echoService{}, sender)
+	h.pool = newBatchPool(1, 1)
+
+	rec := httptest.NewRecorder()
+	h.HandleWhatsAppWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(whatsAppTextPayload("5544999998888", "oi"))))
+	if err := h.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d; esperado 200", rec.Code)
+	}
+	if sent := sender.Sent(); len(sent) != 1 {
+		t.Errorf("envios = %d; esperado 1", len(sent))
+	}
+}
@@ -0,0 +1,28 @@
+package security
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// RequireAdmin exige o token administrativo no header Authorization (Bearer) antes de executar o handler.
+// Sem token configurado, todas as requisições são recusadas.
+func RequireAdmin(h http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RawAccess indica se a requisição traz, no header X-Admin-Raw-Token, o token que libera dados pessoais
+// sem máscara. É exigido além do token administrativo; sem token configurado, o acesso é sempre negado.
+func RawAccess(r *http.Request, token string) bool {
+	provided := r.Header.Get("X-Admin-Raw-Token")
+	return token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
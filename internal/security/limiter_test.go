@@ -0,0 +1,90 @@
+package security_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"leadprojectarrumado/internal/security"
+)
+
+// fixedLimiter é um Limiter de fora do pacote que responde sempre allowed.
+type fixedLimiter struct {
+	allowed bool
+	keys    []string
+}
+
+func (f *fixedLimiter) Allow(key string) bool {
+	f.keys = append(f.keys, key)
+	return f.allowed
+}
+
+func (f *fixedLimiter) RetryAfter() time.Duration { return 1500 * time.Millisecond }
+
+func serveWith(rl security.Limiter) *httptest.ResponseRecorder {
+	h := security.WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }),
+		security.SecurityConfig{BodyLimitBytes: 1024}, rl, security.NewConcurrencyLimiter(10))
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.RemoteAddr = "203.0.113.7:5000"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWrapHandlerAcceptsExternalLimiter(t *testing.T) {
+	rl := &fixedLimiter{allowed: true}
+	if rec := serveWith(rl); rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d; esperado a requisição liberada", rec.Code)
+	}
+	if len(rl.keys) != 1 || rl.keys[0] != "203.0.113.7" {
+		t.Errorf("chaves = %v; esperado o IP sem a porta", rl.keys)
+	}
+}
+
+func TestWrapHandlerRejectsWithRetryAfter(t *testing.T) {
+	rec := serveWith(&fixedLimiter{allowed: false})
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d; esperado 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q; esperado RetryAfter arredondado para cima", got)
+	}
+}
+
+func TestTooManyRequestsBodyFollowsClient(t *testing.T) {
+	h := security.WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}),
+		security.SecurityConfig{BodyLimitBytes: 1024}, &fixedLimiter{allowed: false}, security.NewConcurrencyLimiter(10))
+
+	req := httptest.NewRequest(http.MethodPost, "/chatbot", strings.NewReader(`{"message":"oi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("cliente JSON = %d %q; esperado 429 em JSON", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var body struct {
+		Response string `json:"response"`
+		Error    string `json:"error"`
+		Code     string `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("corpo inválido: %q", rec.Body.String())
+	}
+	if body.Code != "rate_limited" || body.Error == "" {
+		t.Errorf("corpo = %+v; esperado code rate_limited com mensagem", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Accept", "text/html")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests || strings.Contains(rec.Header().Get("Content-Type"), "json") {
+		t.Errorf("cliente sem JSON = %d %q; esperado 429 em texto simples", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After ausente na resposta em texto")
+	}
+}
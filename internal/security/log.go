@@ -0,0 +1,65 @@
+package security
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxLogFieldRunes é o tamanho máximo, em caracteres, de um texto do usuário gravado em log.
+const maxLogFieldRunes = 100
+
+// SanitizeForLog prepara um texto vindo do usuário para o log: troca quebras de linha e demais
+// caracteres de controle por espaço (evitando linhas forjadas) e limita o resultado a 100 runas,
+// cortando sem separar acentos combinantes nem emojis compostos (ver clusterCut) e terminando com "...".
+func SanitizeForLog(input string) string {
+	clean := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return ' '
+		}
+		return r
+	}, input)
+	if utf8.RuneCountInString(clean) <= maxLogFieldRunes {
+		return clean
+	}
+	runes := []rune(clean)
+	return string(runes[:clusterCut(runes, maxLogFieldRunes-3)]) + "..."
+}
+
+// clusterCut recua o corte em n para não separar um caractere visível composto de várias runas: letra e
+// acento combinante ("e" + U+0301), emoji com modificador de tom de pele ou seletor de variação, sequências
+// unidas por ZWJ (👨‍👩‍👧) e bandeiras (par de indicadores regionais). Não cobre todas as regras do
+// UAX #29; se o recuo consumir tudo, corta na fronteira de runa.
+func clusterCut(runes []rune, n int) int {
+	cut := n
+	for cut > 0 && cut < len(runes) && (extendsCluster(runes[cut]) || runes[cut-1] == zeroWidthJoiner) {
+		cut--
+	}
+	if cut > 0 && cut < len(runes) && isRegionalIndicator(runes[cut]) {
+		pairs := 0
+		for i := cut - 1; i >= 0 && isRegionalIndicator(runes[i]); i-- {
+			pairs++
+		}
+		if pairs%2 == 1 {
+			cut--
+		}
+	}
+	if cut == 0 {
+		return n
+	}
+	return cut
+}
+
+// zeroWidthJoiner une emojis em uma única figura (ex.: família, profissões).
+const zeroWidthJoiner = '\u200d'
+
+// extendsCluster indica se a runa se junta à anterior no mesmo caractere visível.
+func extendsCluster(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) || r == zeroWidthJoiner ||
+		(r >= '\ufe00' && r <= '\ufe0f') || (r >= 0x1f3fb && r <= 0x1f3ff)
+}
+
+// isRegionalIndicator indica as runas que, em pares, formam bandeiras.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
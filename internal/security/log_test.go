@@ -0,0 +1,59 @@
+package security
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeForLogReplacesControlCharacters(t *testing.T) {
+	got := SanitizeForLog("linha 1\nINFO forjado\r\tfim\x00" + string([]byte{0xff}))
+	if want := "linha 1 INFO forjado  fim  "; got != want {
+		t.Errorf("SanitizeForLog = %q; esperado %q", got, want)
+	}
+}
+
+func TestSanitizeForLogKeepsShortText(t *testing.T) {
+	in := strings.Repeat("ç", maxLogFieldRunes)
+	if got := SanitizeForLog(in); got != in {
+		t.Errorf("texto com exatamente %d runas foi alterado: %q", maxLogFieldRunes, got)
+	}
+}
+
+func TestSanitizeForLogCutsOnCharacterBoundaries(t *testing.T) {
+	// O corte cai depois de maxLogFieldRunes-3 runas; com pad de 96 runas, a 97ª é a primeira do sufixo.
+	cases := []struct {
+		name      string
+		pad       int
+		tail      string
+		wantAfter string
+	}{
+		{"acento pré-composto", 96, "\u00e9\u00e9\u00e9\u00e9\u00e9\u00e9", "\u00e9"},
+		{"acento combinante", 96, "e\u0301e\u0301e\u0301", ""},
+		{"emoji simples", 96, "\U0001F600\U0001F600\U0001F600\U0001F600\U0001F600", "\U0001F600"},
+		{"tom de pele", 96, "\U0001F44D\U0001F3FD\U0001F44D\U0001F3FD\U0001F44D\U0001F3FD", ""},
+		{"seletor de variação", 96, "\u2764\ufe0f\u2764\ufe0f\u2764\ufe0f", ""},
+		{"ZWJ logo após o corte", 96, "\U0001F469\u200d\U0001F4BB\U0001F469\u200d\U0001F4BB", ""},
+		{"ZWJ logo antes do corte", 95, "\U0001F469\u200d\U0001F4BB\U0001F469\u200d\U0001F4BB", ""},
+		{"bandeira partida", 96, "\U0001F1E7\U0001F1F7\U0001F1E7\U0001F1F7\U0001F1E7\U0001F1F7", ""},
+		{"bandeira inteira", 95, "\U0001F1E7\U0001F1F7\U0001F1E7\U0001F1F7\U0001F1E7\U0001F1F7", "\U0001F1E7\U0001F1F7"},
+	}
+	for _, c := range cases {
+		pad := strings.Repeat("a", c.pad)
+		got := SanitizeForLog(pad + c.tail)
+		if want := pad + c.wantAfter + "..."; got != want {
+			t.Errorf("%s: SanitizeForLog termina em %q; esperado %q", c.name, strings.TrimPrefix(got, pad), c.wantAfter+"...")
+		}
+		if !utf8.ValidString(got) || utf8.RuneCountInString(got) > maxLogFieldRunes {
+			t.Errorf("%s: resultado inválido ou longo demais (%d runas)", c.name, utf8.RuneCountInString(got))
+		}
+	}
+}
+
+func TestSanitizeForLogFallsBackOnLongClusters(t *testing.T) {
+	// Só acentos combinantes: não há fronteira segura, o corte volta a ser por runa.
+	got := SanitizeForLog("e" + strings.Repeat("\u0301", 2*maxLogFieldRunes))
+	if utf8.RuneCountInString(got) != maxLogFieldRunes {
+		t.Errorf("runas = %d; esperado o corte por runa em %d", utf8.RuneCountInString(got), maxLogFieldRunes)
+	}
+}
@@ -0,0 +1,51 @@
+package security
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MethodGuard restringe o handler aos métodos HTTP informados.
+// Métodos não listados recebem 405 com o header Allow preenchido; como em writeTooManyRequests, só clientes
+// JSON recebem o corpo no formato de ChatResponse, e os demais (webhooks, navegador) texto simples. Requisições
+// OPTIONS são respondidas automaticamente com 204, a menos que OPTIONS esteja na
+// lista, caso em que são repassadas ao handler (ex.: preflight CORS).
+func MethodGuard(h http.Handler, methods ...string) http.Handler {
+	allowed := make(map[string]bool, len(methods)+1)
+	list := make([]string, 0, len(methods)+1)
+	for _, m := range methods {
+		m = strings.ToUpper(m)
+		if !allowed[m] {
+			allowed[m] = true
+			list = append(list, m)
+		}
+	}
+	delegateOptions := allowed[http.MethodOptions]
+	if !delegateOptions {
+		list = append(list, http.MethodOptions)
+	}
+	allowHeader := strings.Join(list, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions && !delegateOptions {
+			w.Header().Set("Allow", allowHeader)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if !allowed[r.Method] {
+			w.Header().Set("Allow", allowHeader)
+			if !wantsJSON(r) {
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte(`{"response":"","error":"Método não permitido","code":"method_not_allowed"}`))
+			return
+		}
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Allow", allowHeader)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,82 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMethodGuardRejectsUnlistedMethodsOnGuardedRoutes(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	// Mesmas combinações de métodos registradas em setupRoutes.
+	routes := []struct {
+		path    string
+		methods []string
+		allow   string
+		reject  string
+	}{
+		{"/chatbot", []string{http.MethodPost, http.MethodOptions}, "POST, OPTIONS", http.MethodGet},
+		{"/health", []string{http.MethodGet, http.MethodHead}, "GET, HEAD, OPTIONS", http.MethodPost},
+		{"/readyz", []string{http.MethodGet, http.MethodHead}, "GET, HEAD, OPTIONS", http.MethodDelete},
+		{"/status", []string{http.MethodGet, http.MethodOptions}, "GET, OPTIONS", http.MethodPut},
+		{"/webhook/whatsapp", []string{http.MethodGet, http.MethodPost}, "GET, POST, OPTIONS", http.MethodPut},
+		{"/webhook/messenger", []string{http.MethodGet, http.MethodPost}, "GET, POST, OPTIONS", http.MethodDelete},
+		{"/admin/handoff/resolve", []string{http.MethodPost}, "POST, OPTIONS", http.MethodGet},
+		{"/admin/maintenance", []string{http.MethodGet, http.MethodPost}, "GET, POST, OPTIONS", http.MethodPatch},
+	}
+	for _, rt := range routes {
+		h := MethodGuard(ok, rt.methods...)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(rt.reject, rt.path, nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s = %d; esperado 405", rt.reject, rt.path, rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != rt.allow {
+			t.Errorf("%s Allow = %q; esperado %q", rt.path, got, rt.allow)
+		}
+
+		for _, m := range rt.methods {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(m, rt.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s %s = %d; esperado repassar ao handler", m, rt.path, rec.Code)
+			}
+		}
+	}
+}
+
+func TestMethodGuardAnswersOptionsUnlessDelegated(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	MethodGuard(ok, http.MethodGet).ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/health", nil))
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Allow") != "GET, OPTIONS" {
+		t.Errorf("OPTIONS = %d, Allow %q; esperado 204 com GET, OPTIONS", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	rec = httptest.NewRecorder()
+	MethodGuard(ok, http.MethodPost, http.MethodOptions).ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/chatbot", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("OPTIONS delegado = %d; esperado repassar ao handler", rec.Code)
+	}
+}
+
+func TestMethodGuardBodyFollowsClient(t *testing.T) {
+	h := MethodGuard(http.NotFoundHandler(), http.MethodGet, http.MethodPost)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/webhook/whatsapp", nil))
+	if ct := rec.Header().Get("Content-Type"); strings.Contains(ct, "json") || strings.Contains(rec.Body.String(), "method_not_allowed") {
+		t.Errorf("405 sem cliente JSON = %q (%s); esperado texto simples", rec.Body.String(), ct)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/chatbot", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Type") != "application/json" || !strings.Contains(rec.Body.String(), `"code":"method_not_allowed"`) {
+		t.Errorf("405 para cliente JSON = %q; esperado o corpo de ChatResponse", rec.Body.String())
+	}
+}
@@ -2,10 +2,13 @@
 package security
 
 import (
+	"log"
+	"math"
 	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -37,8 +40,8 @@ func NewGlobalRateLimiter(perMinute int) *rateLimiter {
 	return newRateLimiter(perMinute, time.Minute)
 }
 
-// allow verifica se o IP pode continuar fazendo requisições dentro do limite.
-func (r *rateLimiter) allow(key string) bool {
+// Allow verifica se o IP pode continuar fazendo requisições dentro do limite.
+func (r *rateLimiter) Allow(key string) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if time.Now().After(r.resetAt) {
@@ -49,17 +52,110 @@ func (r *rateLimiter) allow(key string) bool {
 	return r.counts[key] <= r.limit
 }
 
+// RetryAfter retorna quanto falta para a janela atual do limitador ser reiniciada.
+func (r *rateLimiter) RetryAfter() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d := time.Until(r.resetAt); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// concurrencyLimiter limita o número de requisições simultâneas em andamento por IP.
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+	limit    int
+}
+
+// NewConcurrencyLimiter cria um limitador de requisições simultâneas por IP.
+func NewConcurrencyLimiter(perIP int) *concurrencyLimiter {
+	if perIP <= 0 {
+		perIP = 10
+	}
+	return &concurrencyLimiter{
+		inFlight: make(map[string]int),
+		limit:    perIP,
+	}
+}
+
+// acquire reserva uma vaga para o IP, retornando false se o limite já foi atingido.
+func (c *concurrencyLimiter) acquire(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inFlight[key] >= c.limit {
+		return false
+	}
+	c.inFlight[key]++
+	return true
+}
+
+// release libera a vaga reservada pelo IP ao término da requisição.
+func (c *concurrencyLimiter) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight[key]--
+	if c.inFlight[key] <= 0 {
+		delete(c.inFlight, key)
+	}
+}
+
 // SecurityConfig define limites de requisição e tamanho do corpo aceito.
 type SecurityConfig struct {
-	BodyLimitBytes int
-	RatePerMinute  int
+	BodyLimitBytes     int
+	RatePerMinute      int
+	MaxConcurrentPerIP int
+	AdminToken         string
+	// RateLimitBackend seleciona o limitador: "memory" (padrão, por processo) ou "redis" (compartilhado).
+	RateLimitBackend string
+	// RateLimitAllowlist são as redes (monitoramento, callbacks da Meta) isentas do rate limiting.
+	RateLimitAllowlist []*net.IPNet
+	// TarpitEnabled atrasa em TarpitDelay as requisições de IPs que estouraram o limite nos últimos TarpitWindow.
+	TarpitEnabled bool
+	TarpitDelay   time.Duration
+	TarpitWindow  time.Duration
+
+	// ForceHTTPS habilita o header Strict-Transport-Security com HSTSMaxAge (segundos).
+	ForceHTTPS            bool
+	HSTSMaxAge            int
+	HSTSIncludeSubdomains bool
+
+	// Headers de segurança; o widget web pode relaxar a CSP sem alteração de código.
+	ContentSecurityPolicy string
+	FrameOptions          string
+	ReferrerPolicy        string
+
+	// TLSCertFile/TLSKeyFile habilitam HTTPS; HTTPRedirectAddr (ex.: ":80") sobe um listener que redireciona para HTTPS.
+	TLSCertFile      string
+	TLSKeyFile       string
+	HTTPRedirectAddr string
+
+	// Timeouts do servidor HTTP.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
 }
 
 // LoadConfig carrega limites de segurança a partir das variáveis de ambiente.
 func LoadConfig() SecurityConfig {
 	cfg := SecurityConfig{
-		BodyLimitBytes: 4096,
-		RatePerMinute:  60,
+		BodyLimitBytes:     4096,
+		RatePerMinute:      60,
+		MaxConcurrentPerIP: 10,
+		RateLimitBackend:   "memory",
+		TarpitDelay:        2 * time.Second,
+		TarpitWindow:       5 * time.Minute,
+
+		HSTSMaxAge:            31536000,
+		HSTSIncludeSubdomains: true,
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'; base-uri 'none'",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  60 * time.Second,
 	}
 	if v := os.Getenv("BODY_LIMIT_BYTES"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
@@ -71,11 +167,102 @@ func LoadConfig() SecurityConfig {
 			cfg.RatePerMinute = n
 		}
 	}
+	cfg.AdminToken = os.Getenv("ADMIN_TOKEN")
+	if v := strings.ToLower(os.Getenv("RATE_LIMIT_BACKEND")); v == "redis" || v == "memory" {
+		cfg.RateLimitBackend = v
+	}
+	if v := os.Getenv("MAX_CONCURRENT_PER_IP"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MaxConcurrentPerIP = n
+		}
+	}
+	cfg.RateLimitAllowlist = parseCIDRs(os.Getenv("RATE_LIMIT_ALLOWLIST"))
+	cfg.TarpitEnabled = os.Getenv("TARPIT_ENABLED") == "true"
+	cfg.TarpitDelay = envDuration("TARPIT_DELAY", cfg.TarpitDelay)
+	cfg.TarpitWindow = envDuration("TARPIT_WINDOW", cfg.TarpitWindow)
+	cfg.ForceHTTPS = os.Getenv("FORCE_HTTPS") == "true"
+	if v := os.Getenv("HSTS_MAX_AGE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.HSTSMaxAge = n
+		}
+	}
+	if v := os.Getenv("HSTS_INCLUDE_SUBDOMAINS"); v != "" {
+		cfg.HSTSIncludeSubdomains = v == "true"
+	}
+	if v := os.Getenv("CONTENT_SECURITY_POLICY"); v != "" {
+		cfg.ContentSecurityPolicy = v
+	}
+	if v := os.Getenv("X_FRAME_OPTIONS"); v != "" {
+		cfg.FrameOptions = v
+	}
+	if v := os.Getenv("REFERRER_POLICY"); v != "" {
+		cfg.ReferrerPolicy = v
+	}
+	cfg.TLSCertFile = os.Getenv("TLS_CERT_FILE")
+	cfg.TLSKeyFile = os.Getenv("TLS_KEY_FILE")
+	cfg.HTTPRedirectAddr = os.Getenv("HTTP_REDIRECT_ADDR")
+	cfg.ReadTimeout = envDuration("HTTP_READ_TIMEOUT", cfg.ReadTimeout)
+	cfg.WriteTimeout = envDuration("HTTP_WRITE_TIMEOUT", cfg.WriteTimeout)
+	cfg.IdleTimeout = envDuration("HTTP_IDLE_TIMEOUT", cfg.IdleTimeout)
 	return cfg
 }
 
-// WrapHandler aplica body limit, rate limiting e headers de segurança ao handler HTTP.
-func WrapHandler(h http.Handler, cfg SecurityConfig, rl *rateLimiter) http.Handler {
+// TLSEnabled indica se certificado e chave foram configurados.
+func (cfg SecurityConfig) TLSEnabled() bool {
+	return cfg.TLSCertFile != "" && cfg.TLSKeyFile != ""
+}
+
+// envDuration lê uma duração (ex.: "30s") do ambiente, mantendo def se ausente ou inválida.
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return def
+}
+
+// parseCIDRs interpreta uma lista separada por vírgulas de CIDRs ou IPs isolados, ignorando entradas inválidas.
+func parseCIDRs(v string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			if ip := net.ParseIP(item); ip != nil && ip.To4() != nil {
+				item += "/32"
+			} else {
+				item += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(item)
+		if err != nil {
+			log.Printf("RATE_LIMIT_ALLOWLIST: entrada inválida %q ignorada", item)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// allowlisted indica se o IP pertence a alguma das redes isentas.
+func allowlisted(ip string, nets []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// WrapHandler aplica body limit, rate limiting (com tarpit opcional), limite de concorrência e headers de segurança ao handler HTTP.
+func WrapHandler(h http.Handler, cfg SecurityConfig, rl Limiter, cl *concurrencyLimiter) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		r.Body = http.MaxBytesReader(w, r.Body, int64(cfg.BodyLimitBytes))
 
@@ -83,17 +270,78 @@ func WrapHandler(h http.Handler, cfg SecurityConfig, rl *rateLimiter) http.Handl
 		if err != nil {
 			ip = r.RemoteAddr
 		}
-		if !rl.allow(ip) {
-			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		// A vaga de concorrência vem antes do tarpit: um IP em flood não prende mais goroutines dormindo
+		// do que MaxConcurrentPerIP, e o excedente recebe 429 na hora.
+		if !cl.acquire(ip) {
+			writeTooManyRequests(w, r, time.Second)
 			return
 		}
+		defer cl.release(ip)
 
-		w.Header().Set("X-Content-Type-Options", "nosniff")
-		w.Header().Set("X-Frame-Options", "DENY")
-		w.Header().Set("Referrer-Policy", "no-referrer")
-		w.Header().Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'; base-uri 'none'")
-		w.Header().Set("X-XSS-Protection", "0")
+		exempt := allowlisted(ip, cfg.RateLimitAllowlist)
+		if !exempt && !rl.Allow(ip) {
+			if cfg.TarpitEnabled {
+				offenders.flag(ip, cfg.TarpitWindow)
+				if !tarpitDelay(r.Context(), cfg.TarpitDelay) {
+					return
+				}
+			}
+			writeTooManyRequests(w, r, rl.RetryAfter())
+			return
+		}
+		if !exempt && cfg.TarpitEnabled && offenders.flagged(ip) {
+			if !tarpitDelay(r.Context(), cfg.TarpitDelay) {
+				return
+			}
+		}
+		setSecurityHeaders(w, cfg)
 
 		h.ServeHTTP(w, r)
 	})
 }
+
+// setSecurityHeaders aplica os headers de segurança configurados; HSTS só é enviado com ForceHTTPS.
+func setSecurityHeaders(w http.ResponseWriter, cfg SecurityConfig) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-XSS-Protection", "0")
+	if cfg.FrameOptions != "" {
+		w.Header().Set("X-Frame-Options", cfg.FrameOptions)
+	}
+	if cfg.ReferrerPolicy != "" {
+		w.Header().Set("Referrer-Policy", cfg.ReferrerPolicy)
+	}
+	if cfg.ContentSecurityPolicy != "" {
+		w.Header().Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+	}
+	if cfg.ForceHTTPS {
+		hsts := "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		w.Header().Set("Strict-Transport-Security", hsts)
+	}
+}
+
+// writeTooManyRequests responde 429 com o header Retry-After (em segundos). Clientes JSON recebem
+// um corpo no formato de ChatResponse com o código rate_limited; os demais, texto simples.
+func writeTooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+
+	if !wantsJSON(r) {
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write([]byte(`{"response":"","error":"Muitas requisições, tente novamente em instantes","code":"rate_limited"}`))
+}
+
+// wantsJSON indica se o cliente envia ou aceita JSON.
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json") ||
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+}
@@ -0,0 +1,137 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiterRejectsRequestsBeyondLimit(t *testing.T) {
+	const limit = 2
+	entered := make(chan struct{}, limit)
+	unblock := make(chan struct{})
+	h := WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		entered <- struct{}{}
+		<-unblock
+	}), SecurityConfig{BodyLimitBytes: 1024}, NewGlobalRateLimiter(1000), NewConcurrencyLimiter(limit))
+
+	request := func(ip string) int {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.RemoteAddr = ip + ":4000"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	var wg sync.WaitGroup
+	codes := make([]int, limit)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = request("203.0.113.7")
+		}(i)
+	}
+	for i := 0; i < limit; i++ {
+		select {
+		case <-entered:
+		case <-time.After(time.Second):
+			t.Fatalf("só %d de %d requisições chegaram ao handler", i, limit)
+		}
+	}
+
+	if code := request("203.0.113.7"); code != http.StatusTooManyRequests {
+		t.Errorf("requisição %d do mesmo IP = %d; esperado 429", limit+1, code)
+	}
+
+	// Outro IP tem as próprias vagas.
+	go func() { <-entered }()
+	done := make(chan int)
+	go func() { done <- request("198.51.100.1") }()
+
+	close(unblock)
+	wg.Wait()
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("requisição de outro IP = %d; esperado 200", code)
+	}
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("requisição %d = %d; esperado 200", i+1, code)
+		}
+	}
+
+	go func() { <-entered }()
+	if code := request("203.0.113.7"); code != http.StatusOK {
+		t.Errorf("requisição após liberar as vagas = %d; esperado 200", code)
+	}
+}
+
+func TestParseCIDRs(t *testing.T) {
+	nets := parseCIDRs(" 10.0.0.0/8, 203.0.113.7 ,2001:db8::1, não-é-ip, ")
+	if len(nets) != 3 {
+		t.Fatalf("parseCIDRs = %v; esperado 3 redes válidas", nets)
+	}
+	if got := nets[1].String(); got != "203.0.113.7/32" {
+		t.Errorf("IPv4 isolado = %s; esperado /32", got)
+	}
+	if got := nets[2].String(); got != "2001:db8::1/128" {
+		t.Errorf("IPv6 isolado = %s; esperado /128", got)
+	}
+}
+
+func TestAllowlistedIPBypassesRateLimit(t *testing.T) {
+	cfg := SecurityConfig{BodyLimitBytes: 1024, RateLimitAllowlist: parseCIDRs("10.0.0.0/8")}
+	h := WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}), cfg, NewGlobalRateLimiter(1), NewConcurrencyLimiter(10))
+
+	request := func(ip string) int {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.RemoteAddr = ip + ":4000"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for i := 0; i < 5; i++ {
+		if code := request("10.1.2.3"); code != http.StatusOK {
+			t.Fatalf("requisição %d do IP isento = %d; esperado 200", i+1, code)
+		}
+	}
+	request("198.51.100.1")
+	if code := request("198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Errorf("IP fora da lista = %d; esperado 429 após o limite", code)
+	}
+}
+
+func TestSecurityHeadersSendHSTSOnlyWithForceHTTPS(t *testing.T) {
+	t.Setenv("HSTS_INCLUDE_SUBDOMAINS", "false")
+	t.Setenv("HSTS_MAX_AGE", "600")
+	t.Setenv("X_FRAME_OPTIONS", "SAMEORIGIN")
+	cfg := LoadConfig()
+
+	rec := httptest.NewRecorder()
+	setSecurityHeaders(rec, cfg)
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("HSTS sem FORCE_HTTPS = %q; esperado ausente", got)
+	}
+	if got := rec.Header().Get("X-Frame-Options"); got != "SAMEORIGIN" {
+		t.Errorf("X-Frame-Options = %q; esperado o valor configurado", got)
+	}
+	if got := rec.Header().Get("Content-Security-Policy"); got != cfg.ContentSecurityPolicy || got == "" {
+		t.Errorf("Content-Security-Policy = %q; esperado o padrão", got)
+	}
+
+	t.Setenv("FORCE_HTTPS", "true")
+	rec = httptest.NewRecorder()
+	setSecurityHeaders(rec, LoadConfig())
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "max-age=600" {
+		t.Errorf("HSTS = %q; esperado \"max-age=600\" sem includeSubDomains", got)
+	}
+
+	t.Setenv("HSTS_INCLUDE_SUBDOMAINS", "true")
+	rec = httptest.NewRecorder()
+	setSecurityHeaders(rec, LoadConfig())
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "max-age=600; includeSubDomains" {
+		t.Errorf("HSTS = %q; esperado includeSubDomains", got)
+	}
+}
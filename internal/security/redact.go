@@ -0,0 +1,159 @@
+package security
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	// emailPattern reconhece endereços de e-mail.
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// cpfPattern reconhece CPFs formatados (000.000.000-00) ou sequências de 11 dígitos.
+	cpfPattern = regexp.MustCompile(`\d{3}\.\d{3}\.\d{3}-\d{2}|\d{11}`)
+	// phonePattern reconhece telefones brasileiros com ou sem +55, DDD entre parênteses e separadores.
+	phonePattern = regexp.MustCompile(`\+?(?:55[\s-]?)?\(?\d{2}\)?[\s-]?9?\d{4}[\s.-]?\d{4}`)
+)
+
+// RedactPII mascara dados pessoais em uma linha de log: e-mails mantêm só a primeira letra e o domínio,
+// CPFs são ocultados por completo e telefones mantêm apenas os 4 últimos dígitos.
+// Sequências de 11 dígitos só são tratadas como CPF quando os dígitos verificadores conferem.
+func RedactPII(line string) string {
+	line = emailPattern.ReplaceAllStringFunc(line, func(email string) string {
+		at := strings.IndexByte(email, '@')
+		return email[:1] + "***" + email[at:]
+	})
+	line = replaceIsolated(cpfPattern, line, func(cpf string) (string, bool) {
+		if len(cpf) == 11 && !validCPF(cpf) {
+			return "", false
+		}
+		return "***.***.***-**", true
+	})
+	return replaceIsolated(phonePattern, line, func(phone string) (string, bool) {
+		return maskDigits(phone, 4), true
+	})
+}
+
+// replaceIsolated substitui as ocorrências de re que não estão coladas a letras ou dígitos
+// (evitando mascarar trechos de IDs e hashes). mask pode recusar a ocorrência retornando false.
+func replaceIsolated(re *regexp.Regexp, s string, mask func(string) (string, bool)) string {
+	matches := re.FindAllStringIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if (start > 0 && isWordByte(s[start-1])) || (end < len(s) && isWordByte(s[end])) {
+			continue
+		}
+		masked, ok := mask(s[start:end])
+		if !ok {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(masked)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// isWordByte indica se o byte é letra ASCII, dígito ou sublinhado.
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// maskDigits troca por "*" todos os dígitos de s exceto os keep últimos, preservando a formatação.
+func maskDigits(s string, keep int) string {
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits++
+		}
+	}
+	out := []byte(s)
+	for i := range out {
+		if out[i] >= '0' && out[i] <= '9' {
+			if digits > keep {
+				out[i] = '*'
+			}
+			digits--
+		}
+	}
+	return string(out)
+}
+
+// validCPF confere os dois dígitos verificadores de um CPF com 11 dígitos.
+func validCPF(cpf string) bool {
+	allSame := true
+	for i := 1; i < 11; i++ {
+		if cpf[i] != cpf[0] {
+			allSame = false
+		}
+	}
+	if allSame {
+		return false
+	}
+	for _, n := range []int{9, 10} {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(cpf[i]-'0') * (n + 1 - i)
+		}
+		d := sum * 10 % 11
+		if d == 10 {
+			d = 0
+		}
+		if d != int(cpf[n]-'0') {
+			return false
+		}
+	}
+	return true
+}
+
+// RedactingWriter mascara dados pessoais (RedactPII) em tudo que é escrito em Out. Pode ser ligado e desligado
+// em tempo de execução (LOG_REDACT_PII=false), para depuração.
+type RedactingWriter struct {
+	Out     io.Writer
+	enabled atomic.Bool
+}
+
+// NewRedactingWriter cria um RedactingWriter, já ligado, sobre out.
+func NewRedactingWriter(out io.Writer) *RedactingWriter {
+	w := &RedactingWriter{Out: out}
+	w.enabled.Store(true)
+	return w
+}
+
+// SetEnabled liga ou desliga a redação.
+func (w *RedactingWriter) SetEnabled(enabled bool) {
+	w.enabled.Store(enabled)
+}
+
+// Write grava p em Out, mascarado quando a redação está ligada. Retorna len(p) em caso de sucesso,
+// mesmo que o texto gravado tenha outro tamanho, como esperam os loggers.
+func (w *RedactingWriter) Write(p []byte) (int, error) {
+	if !w.enabled.Load() {
+		return w.Out.Write(p)
+	}
+	if _, err := io.WriteString(w.Out, RedactPII(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// MaskPhone mascara todos os dígitos do telefone exceto os 4 últimos, preservando a formatação.
+func MaskPhone(phone string) string {
+	return maskDigits(phone, 4)
+}
+
+// MaskName mantém apenas a inicial de cada palavra do nome ("Maria da Silva" → "M*** d*** S***").
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		words[i] = string([]rune(w)[:1]) + "***"
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,58 @@
+package security
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRedactPII(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"telefone com DDI", "enviando para +55 (44) 99999-8888", "enviando para +** (**) *****-8888"},
+		{"telefone só dígitos", "to=5544999998888 ok", "to=*********8888 ok"},
+		{"e-mail", "contato maria.silva@example.com", "contato m***@example.com"},
+		{"CPF formatado", "cpf 123.456.789-09", "cpf ***.***.***-**"},
+		{"CPF sem formatação válido", "cpf 52998224725", "cpf ***.***.***-**"},
+		{"ID de mensagem preservado", "wamid.HBgMNTU0NDk5OTk5ODg4OBUCABEYEj", "wamid.HBgMNTU0NDk5OTk5ODg4OBUCABEYEj"},
+		{"hash preservado", "chave a1b2c3d4e5f60718293a4b5c", "chave a1b2c3d4e5f60718293a4b5c"},
+	}
+	for _, tc := range cases {
+		if got := RedactPII(tc.in); got != tc.want {
+			t.Errorf("%s: RedactPII(%q) = %q, quer %q", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRedactingWriterMasksLoggerOutput(t *testing.T) {
+	var out bytes.Buffer
+	w := NewRedactingWriter(&out)
+	logger := zerolog.New(w)
+
+	logger.Warn().Str("body", `{"error":{"message":"Recipient 5544999998888 not in allowed list"}}`).Msg("WhatsApp Cloud API recusou o envio")
+	if strings.Contains(out.String(), "5544999998888") {
+		t.Errorf("telefone não mascarado no log: %s", out.String())
+	}
+	if !strings.Contains(out.String(), "8888") {
+		t.Errorf("os 4 últimos dígitos deveriam ser mantidos: %s", out.String())
+	}
+
+	out.Reset()
+	w.SetEnabled(false)
+	logger.Info().Str("to", "5544999998888").Msg("depuração")
+	if !strings.Contains(out.String(), "5544999998888") {
+		t.Errorf("com a máscara desligada o telefone deveria aparecer: %s", out.String())
+	}
+}
+
+func TestMaskNameAndPhone(t *testing.T) {
+	if got := MaskName("  Ana   Júlia da Costa "); got != "A*** J*** d*** C***" {
+		t.Errorf("MaskName = %q; esperado só as iniciais", got)
+	}
+	if got := MaskPhone("+55 (44) 99999-8888"); got != "+** (**) *****-8888" {
+		t.Errorf("MaskPhone = %q; esperado só os 4 últimos dígitos", got)
+	}
+}
@@ -0,0 +1,74 @@
+package security
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+)
+
+// Limiter é a interface comum aos limitadores de taxa usados por WrapHandler. Implementações de fora do
+// pacote (outro backend, um stub em testes) podem ser passadas no lugar das de NewGlobalRateLimiter e
+// NewRedisRateLimiter.
+type Limiter interface {
+	// Allow contabiliza uma requisição da chave (o IP) e indica se ela está dentro do limite.
+	Allow(key string) bool
+	// RetryAfter indica quanto falta para o limite ser renovado, usado no header Retry-After.
+	RetryAfter() time.Duration
+}
+
+// redisRateLimiter implementa rate limiting compartilhado entre réplicas usando INCR + EXPIRE
+// em janelas fixas. Se o Redis falhar, recorre ao limitador em memória; a queda e a volta do Redis são
+// logadas uma vez cada, não a cada requisição.
+type redisRateLimiter struct {
+	client   *redis.Client
+	limit    int
+	interval time.Duration
+	fallback *rateLimiter
+	down     atomic.Bool
+}
+
+// NewRedisRateLimiter cria um rate limiter distribuído com janela de 1 minuto.
+func NewRedisRateLimiter(client *redis.Client, perMinute int) Limiter {
+	if perMinute <= 0 {
+		perMinute = 60
+	}
+	return &redisRateLimiter{
+		client:   client,
+		limit:    perMinute,
+		interval: time.Minute,
+		fallback: newRateLimiter(perMinute, time.Minute),
+	}
+}
+
+// Allow incrementa o contador do IP na janela atual e verifica o limite.
+func (r *redisRateLimiter) Allow(key string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	window := time.Now().UnixNano() / int64(r.interval)
+	redisKey := "ratelimit:" + key + ":" + strconv.FormatInt(window, 10)
+
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, redisKey)
+	pipe.Expire(ctx, redisKey, r.interval)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if r.down.CompareAndSwap(false, true) {
+			log.Warn().Err(err).Msg("Rate limit via Redis indisponível, usando limitador em memória")
+		}
+		return r.fallback.Allow(key)
+	}
+	if r.down.CompareAndSwap(true, false) {
+		log.Info().Msg("Rate limit via Redis restabelecido")
+	}
+	return incr.Val() <= int64(r.limit)
+}
+
+// RetryAfter retorna quanto falta para o início da próxima janela.
+func (r *redisRateLimiter) RetryAfter() time.Duration {
+	elapsed := time.Duration(time.Now().UnixNano() % int64(r.interval))
+	return r.interval - elapsed
+}
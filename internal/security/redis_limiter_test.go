@@ -0,0 +1,66 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newMiniredisClient(t *testing.T, mr *miniredis.Miniredis) *redis.Client {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRedisRateLimiterSharesLimitAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a := NewRedisRateLimiter(newMiniredisClient(t, mr), 3)
+	b := NewRedisRateLimiter(newMiniredisClient(t, mr), 3)
+
+	allowed := 0
+	for i, rl := range []Limiter{a, b, a, b, a} {
+		if rl.Allow("203.0.113.7") {
+			allowed++
+		} else if i < 3 {
+			t.Errorf("requisição %d bloqueada antes do limite", i+1)
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("liberadas = %d; esperado 3 somando as duas réplicas", allowed)
+	}
+	if !a.Allow("198.51.100.1") {
+		t.Error("outro IP bloqueado pelo contador do primeiro")
+	}
+}
+
+func TestRedisRateLimiterFallsBackToMemoryWhileRedisIsDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rl := NewRedisRateLimiter(newMiniredisClient(t, mr), 2).(*redisRateLimiter)
+
+	if !rl.Allow("203.0.113.7") {
+		t.Fatal("primeira requisição bloqueada com o Redis no ar")
+	}
+	mr.Close()
+
+	if !rl.Allow("203.0.113.7") || !rl.Allow("203.0.113.7") {
+		t.Error("requisições dentro do limite em memória bloqueadas com o Redis fora")
+	}
+	if rl.Allow("203.0.113.7") {
+		t.Error("limite em memória não aplicado com o Redis fora")
+	}
+	if !rl.down.Load() {
+		t.Error("queda do Redis não registrada")
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	if !rl.Allow("198.51.100.1") {
+		t.Error("requisição bloqueada após o Redis voltar")
+	}
+	if rl.down.Load() {
+		t.Error("volta do Redis não registrada")
+	}
+}
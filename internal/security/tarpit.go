@@ -0,0 +1,86 @@
+package security
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tarpit registra os IPs que estouraram o rate limit recentemente e atrasa suas requisições,
+// encarecendo floods de força bruta sem afetar usuários que respeitam o limite.
+type tarpit struct {
+	mu        sync.Mutex
+	offenders map[string]time.Time
+}
+
+// offenders é compartilhado por todos os handlers, assim como o rate limiter.
+var offenders = &tarpit{offenders: make(map[string]time.Time)}
+
+// tarpitSweepInterval é o intervalo entre as limpezas dos IPs com janela expirada.
+const tarpitSweepInterval = time.Minute
+
+// flag marca o IP como infrator até now+window. As janelas expiradas são removidas por sweep,
+// para que um flood de IPs distintos não torne cada marcação proporcional ao tamanho do mapa.
+func (t *tarpit) flag(ip string, window time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.offenders[ip] = time.Now().Add(window)
+}
+
+// sweep remove os IPs cuja janela já expirou.
+func (t *tarpit) sweep(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for ip, until := range t.offenders {
+		if now.After(until) {
+			delete(t.offenders, ip)
+		}
+	}
+}
+
+// StartTarpitSweeper limpa periodicamente os IPs com janela de tarpit expirada até o contexto ser cancelado.
+func StartTarpitSweeper(ctx context.Context) {
+	ticker := time.NewTicker(tarpitSweepInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				offenders.sweep(now)
+			}
+		}
+	}()
+}
+
+// flagged indica se o IP estourou o limite dentro da janela configurada.
+func (t *tarpit) flagged(ip string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	until, ok := t.offenders[ip]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(t.offenders, ip)
+		return false
+	}
+	return true
+}
+
+// tarpitDelay espera d ou até o contexto ser cancelado, retornando false nesse caso
+// para que a goroutine não fique presa durante o desligamento.
+func tarpitDelay(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
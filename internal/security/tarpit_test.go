@@ -0,0 +1,54 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTarpitHoldsConcurrencySlotWhileSleeping(t *testing.T) {
+	const ip = "203.0.113.7"
+	offenders.flag(ip, time.Minute)
+	t.Cleanup(func() { offenders.sweep(time.Now().Add(2 * time.Minute)) })
+
+	cfg := SecurityConfig{BodyLimitBytes: 1024, TarpitEnabled: true, TarpitDelay: time.Second, TarpitWindow: time.Minute}
+	h := WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}), cfg, NewGlobalRateLimiter(1000), NewConcurrencyLimiter(1))
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.RemoteAddr = ip + ":4000"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := make(chan int)
+	go func() { first <- request().Code }()
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	if code := request().Code; code != http.StatusTooManyRequests {
+		t.Errorf("segunda requisição = %d, quer 429 com a vaga do IP ocupada pelo tarpit", code)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("segunda requisição levou %s; sem vaga deveria responder sem dormir", elapsed)
+	}
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("primeira requisição = %d, quer 200 após o atraso", code)
+	}
+}
+
+func TestTarpitSweepRemovesExpiredOffenders(t *testing.T) {
+	tp := &tarpit{offenders: make(map[string]time.Time)}
+	tp.flag("198.51.100.1", time.Minute)
+	tp.flag("198.51.100.2", time.Hour)
+
+	tp.sweep(time.Now().Add(2 * time.Minute))
+	if len(tp.offenders) != 1 {
+		t.Fatalf("offenders = %d, quer 1 após o sweep", len(tp.offenders))
+	}
+	if !tp.flagged("198.51.100.2") {
+		t.Error("IP dentro da janela não deveria sair no sweep")
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Políticas para chamadas à IA acima de AIMaxConcurrent.
+const (
+	// AIOverflowQueue aguarda até AIQueueWait por uma vaga antes de recorrer à resposta estática.
+	AIOverflowQueue = "queue"
+	// AIOverflowFallback usa a resposta estática imediatamente.
+	AIOverflowFallback = "fallback"
+)
+
+// errAIBusy indica que o limite global de chamadas simultâneas à IA foi atingido.
+var errAIBusy = errors.New("limite de chamadas simultâneas à IA atingido")
+
+// aiLimiter é um semáforo global que limita as chamadas simultâneas ao Gemini, evitando estourar
+// o rate limit do provedor em picos de tráfego.
+type aiLimiter struct {
+	slots    chan struct{}
+	wait     time.Duration
+	inFlight atomic.Int64
+}
+
+// newAILimiter cria o semáforo com max vagas; max <= 0 não impõe limite.
+func newAILimiter(max int, policy string, wait time.Duration) *aiLimiter {
+	l := &aiLimiter{}
+	if max > 0 {
+		l.slots = make(chan struct{}, max)
+	}
+	if policy == AIOverflowQueue {
+		l.wait = wait
+	}
+	return l
+}
+
+// acquire reserva uma vaga, esperando no máximo wait (ou até o cancelamento do ctx).
+func (l *aiLimiter) acquire(ctx context.Context) error {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			if l.wait <= 0 {
+				return errAIBusy
+			}
+			timer := time.NewTimer(l.wait)
+			defer timer.Stop()
+			select {
+			case l.slots <- struct{}{}:
+			case <-timer.C:
+				return errAIBusy
+			case <-ctx.Done():
+				return errAIBusy
+			}
+		}
+	}
+	l.inFlight.Add(1)
+	return nil
+}
+
+// release libera a vaga reservada por acquire.
+func (l *aiLimiter) release() {
+	l.inFlight.Add(-1)
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// AIInFlight retorna quantas chamadas à IA estão em andamento.
+func (s *ChatbotService) AIInFlight() int64 {
+	return s.aiLimiter.inFlight.Load()
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAILimiterFallbackRejectsAboveMax(t *testing.T) {
+	l := newAILimiter(1, AIOverflowFallback, time.Second)
+	ctx := context.Background()
+
+	if err := l.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.acquire(ctx); !errors.Is(err, errAIBusy) {
+		t.Errorf("segunda chamada err = %v; esperado errAIBusy sem esperar", err)
+	}
+	l.release()
+	if err := l.acquire(ctx); err != nil {
+		t.Errorf("após liberar err = %v; esperado reservar a vaga", err)
+	}
+}
+
+func TestAILimiterQueueWaitsForSlot(t *testing.T) {
+	l := newAILimiter(1, AIOverflowQueue, time.Second)
+	ctx := context.Background()
+	l.acquire(ctx)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		l.release()
+	}()
+	if err := l.acquire(ctx); err != nil {
+		t.Errorf("err = %v; esperado conseguir a vaga liberada durante a espera", err)
+	}
+
+	short := newAILimiter(1, AIOverflowQueue, 10*time.Millisecond)
+	short.acquire(ctx)
+	if err := short.acquire(ctx); !errors.Is(err, errAIBusy) {
+		t.Errorf("err = %v; esperado errAIBusy ao esgotar a espera", err)
+	}
+
+	long := newAILimiter(1, AIOverflowQueue, time.Hour)
+	long.acquire(ctx)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := long.acquire(canceled); !errors.Is(err, errAIBusy) {
+		t.Errorf("err = %v; esperado errAIBusy com o contexto cancelado", err)
+	}
+}
+
+func TestAILimiterDisabledCountsInFlight(t *testing.T) {
+	l := newAILimiter(0, AIOverflowFallback, 0)
+	for i := 0; i < 3; i++ {
+		if err := l.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := l.inFlight.Load(); n != 3 {
+		t.Errorf("inFlight = %d; esperado 3 sem limite", n)
+	}
+}
+
+func TestTechnicalSupportFallsBackWhenAIBusy(t *testing.T) {
+	client := &fakeAI{text: "Reinicie o roteador."}
+	s := newTestService(t, client, func(cfg *Config) {
+		cfg.AIMaxConcurrent = 1
+		cfg.AIOverflowPolicy = AIOverflowFallback
+	})
+	s.aiLimiter.acquire(context.Background())
+
+	response, err := s.startTechnicalSupport(context.Background(), "5544999990000", "internet lenta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.calls != 0 || !strings.Contains(response, "Verifique as conexões") {
+		t.Errorf("resposta = %q, chamadas = %d; esperado a resposta estática sem chamar a IA", response, client.calls)
+	}
+	if n := s.AIInFlight(); n != 1 {
+		t.Errorf("AIInFlight = %d; esperado só a vaga ocupada pelo teste", n)
+	}
+}
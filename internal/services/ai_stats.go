@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// aiStatsBuckets é o número de fatias da janela móvel; cada uma cobre AIFallbackWindow/aiStatsBuckets.
+const aiStatsBuckets = 30
+
+// aiFallbackAlertMinCalls evita alertas com poucas chamadas na janela, onde uma falha isolada já é 100%.
+const aiFallbackAlertMinCalls = 20
+
+// AIModeStats conta, desde o início do processo, as chamadas à IA de um modo que tiveram resposta
+// (Success) e as que caíram na resposta estática (Fallback): erro ou timeout do Gemini, resposta vazia,
+// bloqueio de segurança (ai.ErrFallback) ou limite de concorrência.
+type AIModeStats struct {
+	Success  int64 `json:"success"`
+	Fallback int64 `json:"fallback"`
+}
+
+// AIStats é a confiabilidade da IA: contadores por modo (technical/free) e a taxa de fallback na janela móvel.
+type AIStats struct {
+	Modes         map[string]AIModeStats `json:"modes"`
+	WindowSeconds int64                  `json:"window_seconds"`
+	WindowCalls   int64                  `json:"window_calls"`
+	FallbackRatio float64                `json:"fallback_ratio"`
+}
+
+// aiModeCounters são os contadores acumulados de um modo.
+type aiModeCounters struct {
+	success  atomic.Int64
+	fallback atomic.Int64
+}
+
+// aiBucket guarda as contagens de uma fatia da janela; slot identifica a fatia de tempo a que pertence.
+type aiBucket struct {
+	slot     int64
+	success  int64
+	fallback int64
+}
+
+// aiStats acumula sucessos e fallbacks da IA, seguro para uso concorrente.
+type aiStats struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	modes     map[string]*aiModeCounters
+	buckets   [aiStatsBuckets]aiBucket
+	lastAlert time.Time
+}
+
+// newAIStats cria os contadores com a janela móvel informada (padrão 5m se não positiva).
+func newAIStats(window time.Duration, now func() time.Time) *aiStats {
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	return &aiStats{window: window, now: now, modes: make(map[string]*aiModeCounters)}
+}
+
+// slot retorna a fatia de tempo do instante t.
+func (a *aiStats) slot(t time.Time) int64 {
+	width := int64(a.window / aiStatsBuckets)
+	if width <= 0 {
+		width = 1
+	}
+	return t.UnixNano() / width
+}
+
+// record conta uma chamada do modo, com sucesso ou fallback.
+func (a *aiStats) record(mode string, success bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c, ok := a.modes[mode]
+	if !ok {
+		c = &aiModeCounters{}
+		a.modes[mode] = c
+	}
+	slot := a.slot(a.now())
+	b := &a.buckets[slot%aiStatsBuckets]
+	if b.slot != slot {
+		*b = aiBucket{slot: slot}
+	}
+	if success {
+		c.success.Add(1)
+		b.success++
+	} else {
+		c.fallback.Add(1)
+		b.fallback++
+	}
+}
+
+// windowCounts soma as fatias ainda dentro da janela. Deve ser chamado com mu travado.
+func (a *aiStats) windowCounts() (success, fallback int64) {
+	current := a.slot(a.now())
+	for _, b := range a.buckets {
+		if b.slot > current-aiStatsBuckets && b.slot <= current {
+			success += b.success
+			fallback += b.fallback
+		}
+	}
+	return success, fallback
+}
+
+// snapshot retorna os contadores acumulados e a taxa de fallback na janela.
+func (a *aiStats) snapshot() AIStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := AIStats{Modes: make(map[string]AIModeStats, len(a.modes)), WindowSeconds: int64(a.window / time.Second)}
+	for mode, c := range a.modes {
+		stats.Modes[mode] = AIModeStats{Success: c.success.Load(), Fallback: c.fallback.Load()}
+	}
+	success, fallback := a.windowCounts()
+	stats.WindowCalls = success + fallback
+	if stats.WindowCalls > 0 {
+		stats.FallbackRatio = float64(fallback) / float64(stats.WindowCalls)
+	}
+	return stats
+}
+
+// windowRatio retorna a taxa de fallback e o número de chamadas na janela; enough é false abaixo de
+// aiFallbackAlertMinCalls. Deve ser chamado com mu travado.
+func (a *aiStats) windowRatio() (ratio float64, calls int64, enough bool) {
+	success, fallback := a.windowCounts()
+	calls = success + fallback
+	if calls < aiFallbackAlertMinCalls {
+		return 0, calls, false
+	}
+	return float64(fallback) / float64(calls), calls, true
+}
+
+// degraded indica, sem disparar alerta, se a taxa de fallback na janela está em threshold ou acima,
+// com chamadas suficientes.
+func (a *aiStats) degraded(threshold float64) (ratio float64, bad bool) {
+	if threshold <= 0 {
+		return 0, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ratio, _, enough := a.windowRatio()
+	return ratio, enough && ratio >= threshold
+}
+
+// shouldAlert indica se a taxa de fallback na janela atingiu threshold com chamadas suficientes,
+// no máximo uma vez por janela.
+func (a *aiStats) shouldAlert(threshold float64) (ratio float64, calls int64, alert bool) {
+	if threshold <= 0 {
+		return 0, 0, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ratio, calls, enough := a.windowRatio()
+	if !enough {
+		return 0, calls, false
+	}
+	now := a.now()
+	if ratio < threshold || (!a.lastAlert.IsZero() && now.Sub(a.lastAlert) < a.window) {
+		return ratio, calls, false
+	}
+	a.lastAlert = now
+	return ratio, calls, true
+}
+
+// AIStats retorna os contadores de sucesso e fallback da IA por modo e a taxa de fallback na janela móvel.
+func (s *ChatbotService) AIStats() AIStats {
+	return s.aiStats.snapshot()
+}
+
+// recordAIResult conta o resultado de uma chamada à IA e, se a taxa de fallback passar de
+// AIFallbackAlertRatio, avisa os notificadores configurados.
+func (s *ChatbotService) recordAIResult(mode string, err error) {
+	s.aiStats.record(mode, err == nil)
+	ratio, calls, alert := s.aiStats.shouldAlert(s.cfg.AIFallbackAlertRatio)
+	if !alert {
+		return
+	}
+	log.Printf("Taxa de fallback da IA em %.0f%% (%d chamadas nos últimos %s)", ratio*100, calls, s.cfg.AIFallbackWindow)
+	s.notify("Alerta: IA com alta taxa de fallback",
+		fmt.Sprintf("%.0f%% das %d chamadas à IA nos últimos %s caíram na resposta estática. Verifique o Gemini.", ratio*100, calls, s.cfg.AIFallbackWindow))
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"leadprojectarrumado/internal/ai"
+)
+
+func TestAIStatsCountsPerMode(t *testing.T) {
+	clock := &fixedClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	stats := newAIStats(5*time.Minute, clock.now)
+
+	stats.record("technical", true)
+	stats.record("technical", false)
+	stats.record("free", true)
+	stats.record("free", true)
+
+	got := stats.snapshot()
+	if got.Modes["technical"] != (AIModeStats{Success: 1, Fallback: 1}) {
+		t.Errorf("technical = %+v", got.Modes["technical"])
+	}
+	if got.Modes["free"] != (AIModeStats{Success: 2}) {
+		t.Errorf("free = %+v", got.Modes["free"])
+	}
+	if got.WindowCalls != 4 || got.FallbackRatio != 0.25 {
+		t.Errorf("janela = %d chamadas, taxa %v; esperado 4 e 0.25", got.WindowCalls, got.FallbackRatio)
+	}
+}
+
+func TestAIStatsRatioDropsOldBuckets(t *testing.T) {
+	clock := &fixedClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	stats := newAIStats(5*time.Minute, clock.now)
+
+	for i := 0; i < 3; i++ {
+		stats.record("free", false)
+	}
+	clock.advance(6 * time.Minute)
+	stats.record("free", true)
+
+	got := stats.snapshot()
+	if got.WindowCalls != 1 || got.FallbackRatio != 0 {
+		t.Errorf("janela = %d chamadas, taxa %v; esperado só a chamada recente", got.WindowCalls, got.FallbackRatio)
+	}
+	if got.Modes["free"] != (AIModeStats{Success: 1, Fallback: 3}) {
+		t.Errorf("acumulado = %+v; deve manter as chamadas fora da janela", got.Modes["free"])
+	}
+}
+
+func TestAIStatsAlertOncePerWindow(t *testing.T) {
+	clock := &fixedClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	stats := newAIStats(5*time.Minute, clock.now)
+
+	for i := 0; i < aiFallbackAlertMinCalls-1; i++ {
+		stats.record("free", false)
+	}
+	if _, _, alert := stats.shouldAlert(0.5); alert {
+		t.Fatal("alertou com menos chamadas que o mínimo")
+	}
+	stats.record("free", false)
+	if ratio, _, alert := stats.shouldAlert(0.5); !alert || ratio != 1 {
+		t.Fatalf("alert = %v, ratio = %v; esperado alerta com taxa 1", alert, ratio)
+	}
+	if _, _, alert := stats.shouldAlert(0.5); alert {
+		t.Error("alertou de novo dentro da mesma janela")
+	}
+}
+
+func TestGenerateAICountsClientFallbackAsFallback(t *testing.T) {
+	client := &fakeAI{text: "resposta estática", err: fmt.Errorf("%w: timeout", ai.ErrFallback)}
+	s := newTestService(t, client, nil)
+
+	response, err := s.generateAI(context.Background(), "free", func(ctx context.Context) (string, error) { return client.GenerateFreeResponse(ctx, "oi") })
+	if err != nil {
+		t.Fatalf("err = %v; o texto estático do cliente deve ser usado", err)
+	}
+	if response == "" {
+		t.Error("resposta vazia; esperado o texto estático do cliente")
+	}
+	if got := s.AIStats().Modes["free"]; got != (AIModeStats{Fallback: 1}) {
+		t.Errorf("free = %+v; esperado 1 fallback", got)
+	}
+}
+
+func TestGenerateAICountsModelAnswerAsSuccess(t *testing.T) {
+	client := &fakeAI{text: "reinicie o roteador"}
+	s := newTestService(t, client, nil)
+
+	if _, err := s.generateAI(context.Background(), "technical", func(ctx context.Context) (string, error) { return client.GenerateResponse(ctx, "sem internet") }); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.AIStats().Modes["technical"]; got != (AIModeStats{Success: 1}) {
+		t.Errorf("technical = %+v; esperado 1 sucesso", got)
+	}
+}
+
+func TestGenerateAICountsErrorAsFallback(t *testing.T) {
+	client := &fakeAI{err: errors.New("falha")}
+	s := newTestService(t, client, nil)
+
+	if _, err := s.generateAI(context.Background(), "technical", func(ctx context.Context) (string, error) { return client.GenerateResponse(ctx, "x") }); err == nil {
+		t.Fatal("esperado erro sem texto utilizável")
+	}
+	if got := s.AIStats().Modes["technical"]; got != (AIModeStats{Fallback: 1}) {
+		t.Errorf("technical = %+v; esperado 1 fallback", got)
+	}
+}
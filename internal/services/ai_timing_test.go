@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"leadprojectarrumado/internal/ai"
+)
+
+// blockingAI simula um Gemini que não responde: cada chamada aguarda o cancelamento do contexto recebido.
+type blockingAI struct{}
+
+func (blockingAI) GenerateResponse(ctx context.Context, _ string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (blockingAI) GenerateFreeResponse(ctx context.Context, _ string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestGenerateAIStopsAtResponseBudget(t *testing.T) {
+	s := newTestService(t, blockingAI{}, func(cfg *Config) { cfg.AIResponseBudget = 50 * time.Millisecond })
+	client := s.ai
+
+	start := time.Now()
+	_, err := s.generateAI(context.Background(), "technical", func(ctx context.Context) (string, error) {
+		return client.GenerateResponse(ctx, "sem internet")
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, quer context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("generateAI levou %s, deveria parar no prazo de 50ms", elapsed)
+	}
+}
+
+func TestGenerateAIUsesRequestContext(t *testing.T) {
+	s := newTestService(t, blockingAI{}, nil)
+	client := s.ai
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.generateAI(ctx, "free", func(ctx context.Context) (string, error) {
+		return client.GenerateFreeResponse(ctx, "oi")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, quer context.Canceled da requisição", err)
+	}
+}
+
+func TestLoadConfigReadsAILimits(t *testing.T) {
+	t.Setenv("AI_TECH_TIMEOUT", "5s")
+	t.Setenv("AI_TECH_RETRIES", "2")
+	t.Setenv("AI_FREE_MAX_CHARS", "0")
+	t.Setenv("AI_RESPONSE_BUDGET", "20s")
+
+	cfg := LoadConfig()
+	def := ai.DefaultLimits()
+	if cfg.AILimits.Tech.Timeout != 5*time.Second || cfg.AILimits.Tech.Retries != 2 {
+		t.Errorf("Tech = %+v, quer Timeout 5s e Retries 2", cfg.AILimits.Tech)
+	}
+	if cfg.AILimits.Tech.Words != def.Tech.Words {
+		t.Errorf("Tech.Words = %d, quer o padrão %d", cfg.AILimits.Tech.Words, def.Tech.Words)
+	}
+	if cfg.AILimits.Free.Chars != 0 || cfg.AILimits.Free.Timeout != def.Free.Timeout {
+		t.Errorf("Free = %+v, quer Chars 0 e Timeout padrão", cfg.AILimits.Free)
+	}
+	if cfg.AIResponseBudget != 20*time.Second {
+		t.Errorf("AIResponseBudget = %s, quer 20s", cfg.AIResponseBudget)
+	}
+}
+
+func TestDefaultAILimitsFitWriteTimeout(t *testing.T) {
+	const writeTimeout = 30 * time.Second
+	cfg := LoadConfig()
+	if cfg.AIResponseBudget >= writeTimeout {
+		t.Errorf("AIResponseBudget = %s, deveria ficar abaixo de %s", cfg.AIResponseBudget, writeTimeout)
+	}
+	for name, l := range map[string]ai.ModeLimit{"tech": cfg.AILimits.Tech, "free": cfg.AILimits.Free} {
+		if worst := l.Timeout * time.Duration(l.Retries+1); worst > cfg.AIResponseBudget {
+			t.Errorf("%s: pior caso %s excede o prazo %s", name, worst, cfg.AIResponseBudget)
+		}
+	}
+}
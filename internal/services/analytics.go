@@ -0,0 +1,42 @@
+package services
+
+import "time"
+
+// eventMenuSelection identifica a escolha de uma opção do menu principal.
+const eventMenuSelection = "menu_selection"
+
+// recordEvent grava de forma assíncrona um evento de analytics.
+func (s *ChatbotService) recordEvent(eventType, option, channel string) {
+	if s.writer == nil {
+		return
+	}
+	s.writer.enqueue("evento de analytics",
+		`INSERT INTO analytics_events (event_type, option, channel, created_at) VALUES (?, ?, ?, ?)`,
+		eventType, option, channel, s.now().UTC(),
+	)
+}
+
+// MenuSelectionCounts retorna quantas vezes cada opção do menu foi escolhida no intervalo [from, to).
+func (s *ChatbotService) MenuSelectionCounts(from, to time.Time) (map[string]int, error) {
+	rows, err := s.db.Query(
+		`SELECT option, COUNT(*) FROM analytics_events
+		WHERE event_type = ? AND created_at >= ? AND created_at < ?
+		GROUP BY option`,
+		eventMenuSelection, from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var option string
+		var count int
+		if err := rows.Scan(&option, &count); err != nil {
+			return nil, err
+		}
+		counts[option] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMenuSelectionCountsByOptionAndPeriod(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestServiceWith(t, db, nil, nil, nil)
+	clock := &fixedClock{t: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	s.now = clock.now
+
+	for i, option := range []string{"1", "2", "1", "9", "menu"} {
+		if _, err := s.handleMenuSelection(ChannelWhatsApp, fmt.Sprintf("u%d", i), option); err != nil {
+			t.Fatalf("handleMenuSelection(%q): %v", option, err)
+		}
+	}
+	clock.advance(48 * time.Hour)
+	s.handleMenuSelection(ChannelWeb, "u-depois", "3")
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	counts, err := s.MenuSelectionCounts(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"1": 2, "2": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("contagem do dia = %v; esperado %v (opções inválidas não contam)", counts, want)
+	}
+	counts, _ = s.MenuSelectionCounts(day, day.AddDate(0, 0, 7))
+	if counts["3"] != 1 || counts["1"] != 2 {
+		t.Errorf("contagem da semana = %v; esperado incluir a escolha posterior", counts)
+	}
+}
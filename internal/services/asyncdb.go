@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// asyncQueueSize limita quantas gravações podem aguardar na fila do banco.
+const asyncQueueSize = 256
+
+// Erros de enqueue: a gravação não foi agendada e nada será escrito no banco.
+var (
+	errWriteQueueFull = errors.New("fila de gravação do banco cheia")
+	errWriterClosed   = errors.New("gravação no banco encerrada")
+)
+
+// dbJob representa uma instrução SQL pendente de execução.
+type dbJob struct {
+	desc  string
+	query string
+	args  []interface{}
+}
+
+// asyncWriter executa gravações no banco fora do caminho da resposta ao usuário. Erros passageiros
+// (banco travado, conexão derrubada) são repetidos até attempts vezes, com backoff dobrado a cada falha.
+// ctx é cancelado quando o prazo de drain expira, interrompendo a espera entre tentativas.
+type asyncWriter struct {
+	exec     func(query string, args ...interface{}) error
+	queue    chan dbJob
+	attempts int
+	backoff  time.Duration
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// newAsyncWriter cria o writer e inicia o worker que executa as gravações.
+func newAsyncWriter(db *sql.DB, attempts int, backoff time.Duration) *asyncWriter {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &asyncWriter{
+		exec: func(query string, args ...interface{}) error {
+			_, err := db.Exec(query, args...)
+			return err
+		},
+		queue:    make(chan dbJob, asyncQueueSize),
+		attempts: attempts,
+		backoff:  backoff,
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	go w.run()
+	return w
+}
+
+// enqueue agenda uma gravação sem bloquear. Com a fila cheia, ou após drain, a gravação é descartada
+// e o erro retornado, para que quem depende dela possa avisar o usuário.
+func (w *asyncWriter) enqueue(desc, query string, args ...interface{}) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		log.Printf("Gravação no banco encerrada, %s descartado", desc)
+		return errWriterClosed
+	}
+	select {
+	case w.queue <- dbJob{desc: desc, query: query, args: args}:
+		return nil
+	default:
+		log.Printf("Fila de gravação cheia, %s descartado", desc)
+		return errWriteQueueFull
+	}
+}
+
+// drain para de aceitar gravações e aguarda as enfileiradas serem executadas, ou ctx expirar. Expirado o
+// prazo, as gravações restantes são tentadas uma única vez, sem esperar entre tentativas.
+func (w *asyncWriter) drain(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		return ctx.Err()
+	}
+}
+
+// run consome a fila executando cada gravação, cada uma em seu próprio span.
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for job := range w.queue {
+		span := tracer.StartSpan("db.exec", tracer.SpanType(ext.SpanTypeSQL), tracer.ResourceName(job.desc), tracer.Tag("operation", job.desc))
+		tries, err := retryDB(w.ctx, w.attempts, w.backoff, func() error { return w.exec(job.query, job.args...) })
+		if err != nil {
+			log.Printf("Erro ao gravar %s (%d tentativa(s)): %v", job.desc, tries, err)
+		} else if tries > 1 {
+			log.Printf("%s gravado após %d tentativas", job.desc, tries)
+		}
+		span.SetTag("attempts", tries)
+		span.Finish(tracer.WithError(err))
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"log"
+	"strings"
+)
+
+// maxAttachments limita quantos anexos são guardados por atendimento.
+const maxAttachments = 5
+
+// ReceiveAttachment anexa a referência de uma mídia recebida (foto do modem, tela de erro etc.)
+// aos dados da sessão, para ser registrada junto com o atendimento de suporte.
+func (s *ChatbotService) ReceiveAttachment(channel, userID, ref string) (string, error) {
+	if s.denylist.contains(userID) {
+		log.Printf("Anexo de usuário bloqueado ignorado (canal %s, usuário %s)", channel, userID)
+		return s.cfg.DeniedMessage, nil
+	}
+	userData := s.getUserData(userID)
+	if len(userData.Anexos) >= maxAttachments {
+		return "📎 Limite de anexos atingido para este atendimento.", nil
+	}
+	userData.Anexos = append(userData.Anexos, ref)
+	s.setUserData(userID, userData)
+	s.touchSession(userID, channel)
+	log.Printf("Anexo recebido de %s: %s", userID, ref)
+	return "📎 *Anexo recebido!* Ele será incluído no seu atendimento.", nil
+}
+
+// supportDescription acrescenta à descrição do problema as referências dos anexos recebidos.
+func supportDescription(userData UserData) string {
+	if len(userData.Anexos) == 0 {
+		return userData.Descricao
+	}
+	return userData.Descricao + " | Anexos: " + strings.Join(userData.Anexos, ", ")
+}
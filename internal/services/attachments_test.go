@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAttachmentSentBeforeSupportIsKept(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	if _, err := s.ReceiveAttachment(ChannelWhatsApp, user, "media/modem.jpg"); err != nil {
+		t.Fatalf("ReceiveAttachment: %v", err)
+	}
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "1")
+
+	userData := s.getUserData(user)
+	if len(userData.Anexos) != 1 || userData.Anexos[0] != "media/modem.jpg" {
+		t.Errorf("Anexos = %v; esperado manter a foto ao escolher o suporte", userData.Anexos)
+	}
+	if userData.TipoAtendimento != "Suporte Técnico" {
+		t.Errorf("TipoAtendimento = %q", userData.TipoAtendimento)
+	}
+}
+
+func TestSupportDescriptionListsAttachments(t *testing.T) {
+	got := supportDescription(UserData{Descricao: "sem sinal", Anexos: []string{"a.jpg", "b.pdf"}})
+	if got != "sem sinal | Anexos: a.jpg, b.pdf" {
+		t.Errorf("supportDescription = %q", got)
+	}
+}
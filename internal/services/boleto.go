@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrInvoiceNotFound indica que não há fatura em aberto para o identificador informado.
+var ErrInvoiceNotFound = errors.New("fatura não encontrada")
+
+// Invoice representa a segunda via de uma fatura.
+type Invoice struct {
+	URL     string
+	DueDate time.Time
+	Amount  string
+}
+
+// BoletoProvider busca a segunda via da fatura de um cliente a partir do CPF/CNPJ ou número do contrato.
+type BoletoProvider interface {
+	SecondCopy(ctx context.Context, identifier string) (Invoice, error)
+}
+
+// StubBoletoProvider é um provedor provisório que apenas monta o link do portal de faturas,
+// até a integração com o sistema de cobrança estar disponível.
+type StubBoletoProvider struct {
+	BaseURL string
+}
+
+// SecondCopy retorna o link do portal com o identificador do cliente.
+func (p StubBoletoProvider) SecondCopy(ctx context.Context, identifier string) (Invoice, error) {
+	if p.BaseURL == "" {
+		return Invoice{}, ErrInvoiceNotFound
+	}
+	return Invoice{URL: p.BaseURL + "?id=" + url.QueryEscape(identifier)}, nil
+}
+
+// SetBoletoProvider habilita o fluxo de segunda via; sem provedor, a opção exibe apenas os contatos.
+func (s *ChatbotService) SetBoletoProvider(p BoletoProvider) {
+	s.boleto = p
+}
+
+// handleBoletoIdentifier valida o CPF/CNPJ ou contrato informado e retorna o link da segunda via.
+func (s *ChatbotService) handleBoletoIdentifier(userID, message string) (string, error) {
+	identifier := onlyDigits(message)
+	if len(identifier) < 4 || len(identifier) > 14 {
+		return "❌ Identificador inválido. Informe o *CPF*, *CNPJ* ou *número do contrato* (somente números):", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	invoice, err := s.boleto.SecondCopy(ctx, identifier)
+	if errors.Is(err, ErrInvoiceNotFound) {
+		return "🔎 Não encontramos fatura em aberto para esse identificador. Confira os números e tente novamente, ou digite *MENU* para voltar.", nil
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar segunda via: %v", err)
+		s.setState(userID, StateMenu)
+		return "⚠️ Não foi possível consultar sua fatura agora.\n\n" + s.boletoContacts(), nil
+	}
+
+	var b strings.Builder
+	b.WriteString("💰 *Segunda via da fatura*\n\n")
+	if !invoice.DueDate.IsZero() {
+		fmt.Fprintf(&b, "*Vencimento*: %s\n", invoice.DueDate.Format("02/01/2006"))
+	}
+	if invoice.Amount != "" {
+		fmt.Fprintf(&b, "*Valor*: %s\n", invoice.Amount)
+	}
+	fmt.Fprintf(&b, "*Link*: %s\n\nDigite MENU para voltar ao menu principal.", invoice.URL)
+	return s.finishFlow(userID, b.String())
+}
+
+// boletoContacts retorna o bloco de contatos financeiros das unidades.
+func (s *ChatbotService) boletoContacts() string {
+	return s.render.get("boleto_contacts", func(d displaySettings) string {
+		return "Para *segunda via* ou dúvidas financeiras, utilize os canais oficiais:\n\n" +
+			renderUnits(d.units) + "\n" +
+			"Digite MENU para voltar ao menu principal."
+	})
+}
+
+// onlyDigits remove tudo que não for dígito (pontuação de CPF/CNPJ, espaços etc.).
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBoleto é um BoletoProvider que devolve invoice e err e guarda o último identificador consultado.
+type fakeBoleto struct {
+	invoice    Invoice
+	err        error
+	identifier string
+}
+
+func (f *fakeBoleto) SecondCopy(_ context.Context, identifier string) (Invoice, error) {
+	f.identifier = identifier
+	return f.invoice, f.err
+}
+
+// startBoleto leva o usuário até o pedido do identificador da segunda via.
+func startBoleto(t *testing.T, provider BoletoProvider) (*ChatbotService, string) {
+	t.Helper()
+	s := newTestService(t, nil, func(cfg *Config) { cfg.NPSEnabled = false })
+	s.SetBoletoProvider(provider)
+	const user = "5544999998888"
+	s.ProcessMessage(context.Background(), ChannelWhatsApp, user, "oi")
+	s.ProcessMessage(context.Background(), ChannelWhatsApp, user, "3")
+	if state := s.sessions.state(context.Background(), user); state != StateBoletoIdentifier {
+		t.Fatalf("estado = %q; esperado %q", state, StateBoletoIdentifier)
+	}
+	return s, user
+}
+
+func TestBoletoSecondCopy(t *testing.T) {
+	provider := &fakeBoleto{invoice: Invoice{URL: "https://faturas.exemplo/1", DueDate: time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC), Amount: "R$ 99,90"}}
+	s, user := startBoleto(t, provider)
+
+	response, err := s.ProcessMessage(context.Background(), ChannelWhatsApp, user, "123.456.789-09")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.identifier != "12345678909" {
+		t.Errorf("identificador consultado = %q; esperado só os dígitos", provider.identifier)
+	}
+	for _, want := range []string{"*Vencimento*: 10/11/2026", "*Valor*: R$ 99,90", "*Link*: https://faturas.exemplo/1"} {
+		if !strings.Contains(response, want) {
+			t.Errorf("resposta = %q; esperado %q", response, want)
+		}
+	}
+	if state := s.sessions.state(context.Background(), user); state != StateMenu {
+		t.Errorf("estado = %q; esperado voltar ao menu", state)
+	}
+}
+
+func TestBoletoInvalidAndNotFound(t *testing.T) {
+	provider := &fakeBoleto{err: ErrInvoiceNotFound}
+	s, user := startBoleto(t, provider)
+	ctx := context.Background()
+
+	if response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "12"); !strings.HasPrefix(response, "❌ Identificador inválido") || provider.identifier != "" {
+		t.Errorf("identificador curto = %q; esperado recusa sem consultar o provedor", response)
+	}
+	if response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "98765"); !strings.Contains(response, "Não encontramos fatura") {
+		t.Errorf("fatura inexistente = %q", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateBoletoIdentifier {
+		t.Errorf("estado = %q; esperado continuar pedindo o identificador", state)
+	}
+}
+
+func TestBoletoProviderFailureFallsBackToContacts(t *testing.T) {
+	s, user := startBoleto(t, &fakeBoleto{err: errors.New("timeout")})
+
+	response, _ := s.ProcessMessage(context.Background(), ChannelWhatsApp, user, "98765")
+	if !strings.Contains(response, "Não foi possível consultar") || !strings.Contains(response, s.boletoContacts()) {
+		t.Errorf("resposta = %q; esperado os contatos financeiros", response)
+	}
+	if state := s.sessions.state(context.Background(), user); state != StateMenu {
+		t.Errorf("estado = %q; esperado voltar ao menu", state)
+	}
+}
+
+func TestStubBoletoProvider(t *testing.T) {
+	invoice, err := StubBoletoProvider{BaseURL: "https://portal.exemplo/faturas"}.SecondCopy(context.Background(), "123 45")
+	if err != nil || invoice.URL != "https://portal.exemplo/faturas?id=123+45" {
+		t.Errorf("SecondCopy = %+v, %v; esperado o link com o identificador", invoice, err)
+	}
+	if _, err := (StubBoletoProvider{}).SecondCopy(context.Background(), "12345"); !errors.Is(err, ErrInvoiceNotFound) {
+		t.Errorf("sem BaseURL err = %v; esperado ErrInvoiceNotFound", err)
+	}
+}
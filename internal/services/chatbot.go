@@ -4,13 +4,17 @@ package services
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 // ChatbotService implementa o fluxo de atendimento do chatbot, integrando Redis, banco de dados, Google Sheets e IA.
@@ -19,31 +23,70 @@ type ChatbotService struct {
 	db     *sql.DB
 	sheets SheetsClient
 	ai     AIClient
+	writer *asyncWriter
+	cfg    Config
+	now    func() time.Time
+
+	greetings   map[string]bool
+	pushers     map[string]Pusher
+	maintenance atomic.Bool
+	denylist    *denylist
+	render      *renderCache
+	aiLimiter   *aiLimiter
+	aiStats     *aiStats
+	aiProbe     aiProbe
+	preprocess  inputPipeline
+	// sheetsPausedUntil (UnixNano) suspende os envios ao Sheets após cota excedida.
+	sheetsPausedUntil atomic.Int64
+	flags             *featureFlags
+	sessions          *sessionStore
+	boleto            BoletoProvider
+	customers         CustomerLookup
+	moderator         OutputModerator
+	sheetsPool        *sheetsPool
+	redisHealth       *redisHealth
+	stores            []namedStore
+	events            *EventBus
+	notifiers         []namedNotifier
+	// sheetsFlush serializa o reenvio da fila local, disparado pelo ticker e ao religar o Sheets.
+	sheetsFlush sync.Mutex
+	// notifyWG acompanha as entregas e reenvios de avisos em segundo plano, aguardados no desligamento;
+	// notifyPending guarda as entregas ainda sem resultado, levadas à dead-letter se o desligamento não
+	// puder esperá-las; notifyReplaying garante um único reenvio da dead-letter por vez.
+	notifyWG        sync.WaitGroup
+	notifyMu        sync.Mutex
+	notifyPending   map[*pendingNotification]struct{}
+	notifyReplaying atomic.Bool
+	// storesWG acompanha as gravações e reenvios em andamento nos backends adicionais, aguardados no desligamento;
+	// storeReplaying garante um único reenvio da dead-letter dos backends por vez.
+	storesWG       sync.WaitGroup
+	storeReplaying atomic.Bool
 }
 
-const planList = `• *QI FIBRA BASIC*
-  300 Mega + QI TV PLAY + IPV6
+// planOptions lista os nomes dos planos na ordem numerada apresentada ao usuário.
+var planOptions = planNames(planCatalog)
 
-• *QI FIBRA PREMIUM*
-  600 Mega + QI TV PLAY + IPV6 + QUALIDADE QI
-
-• *QI FIBRA PREMIUM (MELHOR)*
-  650 Mega + QI TV PLAY + IPV6 + PARAMOUNT + WATCH TV
-
-• *QI FIBRA PREMIUM TOP*
-  700 Mega + QI TV PLAY + IPV6 + PARAMOUNT + WATCH TV`
+// planNames retorna os nomes dos planos do catálogo, na mesma ordem.
+func planNames(plans []Plan) []string {
+	names := make([]string, len(plans))
+	for i, p := range plans {
+		names[i] = p.Name
+	}
+	return names
+}
 
-// SheetsClient define interface para persistência de dados em Google Sheets.
+// SheetsClient define interface para persistência de dados em Google Sheets, o backend principal.
 type SheetsClient interface {
-	SaveSupport(nome, problema, descricao, status string) error
-	SavePlans(nome, situacao, planoAtual, planoDesejado, telefone, observacoes string) error
-	SaveFeedback(nome, tipoAtendimento, feedback, sugestoes string) error
+	SupportStore
+	LeadStore
+	FeedbackStore
+	NPSStore
 }
 
 // AIClient define interface para geração de respostas automáticas por IA.
 type AIClient interface {
-	GenerateResponse(problema string) (string, error)
-	GenerateFreeResponse(pergunta string) (string, error)
+	GenerateResponse(ctx context.Context, problema string) (string, error)
+	GenerateFreeResponse(ctx context.Context, pergunta string) (string, error)
 }
 
 // UserData armazena o estado da sessão do usuário durante o atendimento.
@@ -59,186 +102,339 @@ type UserData struct {
 	TipoAtendimento    string `json:"tipo_atendimento"`
 	AguardandoFeedback bool   `json:"aguardando_feedback"`
 	UltimaAtividade    int64  `json:"ultima_atividade"`
+	// CriadaEm é o início da sessão (Unix), base do limite absoluto MaxSessionLifetime.
+	CriadaEm          int64        `json:"criada_em,omitempty"`
+	Anexos            []string     `json:"anexos,omitempty"`
+	IntencaoPendente  string       `json:"intencao_pendente,omitempty"`
+	EntradasInvalidas int          `json:"entradas_invalidas,omitempty"`
+	CorrigindoCampo   string       `json:"corrigindo_campo,omitempty"`
+	EstadoAnterior    SessionState `json:"estado_anterior,omitempty"`
+	NomeSugerido      string       `json:"nome_sugerido,omitempty"`
+	// ClienteIdentificado indica que o telefone foi encontrado no cadastro de clientes (CustomerLookup);
+	// PlanoSugerido é o plano desse cadastro, usado se o cliente confirmar.
+	ClienteIdentificado bool   `json:"cliente_identificado,omitempty"`
+	PlanoSugerido       string `json:"plano_sugerido,omitempty"`
+	// IntencoesRecusadas são as intenções que o usuário recusou no assistente livre e não são oferecidas de novo.
+	IntencoesRecusadas []string `json:"intencoes_recusadas,omitempty"`
+	// HorarioContato é a janela preferida para o contato comercial (contactTimeOptions) ou contactTimeSkipped.
+	HorarioContato string `json:"horario_contato,omitempty"`
 }
 
-// NewChatbotService cria instância do serviço de chatbot.
 // NewChatbotService cria uma nova instância do serviço de chatbot.
-func NewChatbotService(redis *redis.Client, db *sql.DB, sheets SheetsClient, ai AIClient) *ChatbotService {
-	return &ChatbotService{
-		redis:  redis,
-		db:     db,
-		sheets: sheets,
-		ai:     ai,
+func NewChatbotService(redis *redis.Client, db *sql.DB, sheets SheetsClient, ai AIClient, cfg Config) *ChatbotService {
+	s := &ChatbotService{
+		redis:      redis,
+		db:         db,
+		sheets:     sheets,
+		ai:         ai,
+		cfg:        cfg,
+		now:        time.Now,
+		greetings:  make(map[string]bool),
+		pushers:    make(map[string]Pusher),
+		sessions:   &sessionStore{redis: redis, ttl: cfg.SessionTTLs},
+		events:     NewEventBus(),
+		notifiers:  notifiersFromConfig(cfg),
+		denylist:   newDenylist(cfg.Denylist, cfg.DenylistFile),
+		render:     newRenderCache(displaySettingsFrom(cfg)),
+		aiLimiter:  newAILimiter(cfg.AIMaxConcurrent, cfg.AIOverflowPolicy, cfg.AIQueueWait),
+		aiStats:    newAIStats(cfg.AIFallbackWindow, time.Now),
+		preprocess: buildInputPipeline(cfg.InputPreprocessors),
+		flags: newFeatureFlags(map[string]bool{
+			FlagAI:       cfg.AIEnabled,
+			FlagSheets:   cfg.SheetsEnabled,
+			FlagWhatsApp: cfg.WhatsAppEnabled,
+		}),
+	}
+	s.sessions.now = func() time.Time { return s.now() }
+	s.sessions.breaker = newRedisBreaker(cfg, s.sessions.now)
+	s.sessions.memory = newMemoryStore(s.sessions.now)
+	s.sessions.breaker.onRecover = s.sessions.reconcile
+	s.moderator = newKeywordModerator(cfg.ModerationKeywords)
+	s.redisHealth = &redisHealth{ping: func(ctx context.Context) error { return redis.Ping(ctx).Err() }}
+	s.redisHealth.up.Store(true)
+	for _, g := range cfg.GreetingKeywords {
+		s.greetings[normalizeCommand(g)] = true
+	}
+	if db != nil {
+		s.writer = newAsyncWriter(db, cfg.DBRetryAttempts, cfg.DBRetryBackoff)
+	}
+	if cfg.SheetsWorkers > 0 {
+		s.sheetsPool = newSheetsPool(s, cfg.SheetsWorkers, cfg.SheetsQueueSize)
+	}
+	s.maintenance.Store(cfg.MaintenanceMode)
+	s.subscribeConsumers()
+	return s
+}
+
+// ProcessMessage roteia a mensagem do usuário, recebida pelo canal informado, conforme o estado atual da sessão.
+// O ctx da requisição é usado como pai dos spans do atendimento e das chamadas externas.
+func (s *ChatbotService) ProcessMessage(ctx context.Context, channel, userID, message string) (string, error) {
+	span, ctx := tracer.StartSpanFromContext(ctx, "chatbot.process_message", tracer.ResourceName(channel), tracer.Tag("channel", channel))
+	defer span.Finish()
+
+	// Usuários bloqueados recebem apenas o aviso; nada é lido ou gravado.
+	if s.denylist.contains(userID) {
+		span.SetTag("denied", true)
+		log.Printf("Mensagem de usuário bloqueado ignorada (canal %s, usuário %s)", channel, userID)
+		return s.cfg.DeniedMessage, nil
+	}
+
+	// Em manutenção nenhum estado é lido ou gravado.
+	if s.InMaintenance() {
+		span.SetTag("maintenance", true)
+		return s.cfg.MaintenanceMessage, nil
+	}
+
+	message = s.preprocess.apply(message)
+	response, err := s.route(ctx, channel, userID, message)
+	if err != nil {
+		span.SetTag(ext.Error, err)
+		log.Printf("Falha irrecuperável no atendimento de %s: %v", userID, err)
+		return s.lastResortMessage(), nil
 	}
+	return response, nil
 }
 
-// ProcessMessage roteia a mensagem do usuário conforme o estado atual da sessão.
-func (s *ChatbotService) ProcessMessage(userID, message string) (string, error) {
+// route atualiza a atividade da sessão e despacha a mensagem para o handler do estado atual.
+func (s *ChatbotService) route(ctx context.Context, channel, userID, message string) (string, error) {
 	userData := s.getUserData(userID)
-	now := time.Now().Unix()
-	if userData.UltimaAtividade > 0 && now-userData.UltimaAtividade > 600 {
-		ctx := context.Background()
-		s.redis.Del(ctx, "chat:"+userID)
-		s.redis.Del(ctx, "data:"+userID)
+	now := s.now().Unix()
+	if userData.UltimaAtividade > 0 && now-userData.UltimaAtividade > int64(s.cfg.IdleTimeout.Seconds()) {
+		s.sessions.clear(context.Background(), userID)
 		userData = UserData{}
 	}
+	// O limite absoluto vale mesmo com atividade recente, para que nenhuma sessão viva indefinidamente.
+	if s.cfg.MaxSessionLifetime > 0 && userData.CriadaEm > 0 && now-userData.CriadaEm > int64(s.cfg.MaxSessionLifetime.Seconds()) {
+		log.Printf("Sessão de %s excedeu a duração máxima de %s; reiniciando", userID, s.cfg.MaxSessionLifetime)
+		s.sessions.clear(context.Background(), userID)
+		userData = UserData{}
+	}
+	if userData.CriadaEm == 0 {
+		userData.CriadaEm = now
+	}
 	userData.UltimaAtividade = now
 	s.setUserData(userID, userData)
-	ctx := context.Background()
+	s.touchSession(userID, channel)
+	s.sessions.refresh(context.Background(), userID)
 
-	msgLower := strings.ToLower(strings.TrimSpace(message))
-	if msgLower == "oi" || msgLower == "menu" {
+	state := s.sessions.state(context.Background(), userID)
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		span.SetTag("state", string(state))
+	}
+
+	// MENU sempre reinicia; saudações oferecem retomar um fluxo em andamento em vez de descartá-lo.
+	cmd := normalizeCommand(message)
+	switch {
+	case cmd == "menu":
+		return s.showMainMenu(userID)
+	case cmd == "continuar":
+		return s.resumeFlow(userID, state)
+	case s.isGreeting(cmd):
+		if isResumable(state) {
+			return s.offerResume(userID, state)
+		}
 		return s.showMainMenu(userID)
+	case state.IsPlans() && isCompareCommand(cmd):
+		a, b, _ := parseCompareCommand(cmd)
+		return s.comparePlans(state, a, b)
+	case isCorrectionCommand(cmd):
+		return s.startCorrection(userID, state, cmd)
+	case isHandoffRequest(cmd):
+		return s.handleHandoffRequest(userID)
 	}
 
-	state, _ := s.redis.Get(ctx, "chat:"+userID).Result()
 	if state == "" {
 		return s.showMainMenu(userID)
 	}
+	if !state.Valid() {
+		return s.recoverUnknownState(channel, userID, state)
+	}
+	if response, rejected, err := s.rejectMeaninglessText(userID, state, message); rejected {
+		return response, err
+	}
+	var response string
+	var err error
+	if t, ok := s.cfg.StateTimeouts[state]; ok {
+		response, err = s.dispatchWithTimeout(ctx, channel, userID, state, message, t)
+	} else {
+		response, err = s.dispatch(ctx, channel, userID, state, message)
+	}
+	if err == nil && userData.EntradasInvalidas > 0 {
+		s.resetInvalidInputs(userID, userData.EntradasInvalidas)
+	}
+	return response, err
+}
 
+// dispatch encaminha a mensagem ao handler do estado atual.
+func (s *ChatbotService) dispatch(ctx context.Context, channel, userID string, state SessionState, message string) (string, error) {
 	switch state {
-	case "menu":
-		return s.handleMenuSelection(userID, message)
-	case "support_name":
+	case StateMenu:
+		return s.handleMenuSelection(channel, userID, message)
+	case StateSupportName:
 		return s.handleSupportName(userID, message)
-	case "support_problem":
-		return s.handleSupportProblem(userID, message)
-	case "support_ia":
-		return s.handleSupportIA(userID, message)
-	case "support_feedback":
-		return s.handleSupportFeedback(userID, message)
-	case "plans_client_check":
+	case StateSupportProblem:
+		return s.handleSupportProblem(ctx, userID, message)
+	case StateSupportIA:
+		return s.handleSupportIA(ctx, userID, message)
+	case StateSupportFeedback:
+		return s.handleSupportFeedback(ctx, userID, message)
+	case StatePlansClientCheck:
 		return s.handlePlansClientCheck(userID, message)
-	case "plans_current":
+	case StatePlansCurrent:
 		return s.handlePlansCurrent(userID, message)
-	case "plans_name":
-		return s.handlePlansName(userID, message)
-	case "plans_phone":
-		return s.handlePlansPhone(userID, message)
-	case "plans_selection":
+	case StatePlansName:
+		return s.handlePlansName(ctx, channel, userID, message)
+	case StatePlansPhone:
+		return s.handlePlansPhone(ctx, userID, message)
+	case StatePlansContactTime:
+		return s.handlePlansContactTime(ctx, userID, message)
+	case StatePlansSelection:
 		return s.handlePlansSelection(userID, message)
-	case "ai_free":
-		return s.handleFreeAI(userID, message)
+	case StatePlansRetention:
+		return s.handlePlansRetention(userID, message)
+	case StateBoletoIdentifier:
+		return s.handleBoletoIdentifier(userID, message)
+	case StateAIFree:
+		return s.handleFreeAI(ctx, userID, message)
+	case StateCorrection:
+		return s.handleCorrection(userID, message)
+	case StateNPS:
+		return s.handleNPS(ctx, userID, message)
 	default:
-		return s.showMainMenu(userID)
+		return s.recoverUnknownState(channel, userID, state)
 	}
 }
 
-// showMainMenu reinicia o estado e retorna o menu principal do chatbot.
+// isGreeting indica se o comando normalizado é uma das saudações configuradas.
+func (s *ChatbotService) isGreeting(cmd string) bool {
+	return s.greetings[cmd]
+}
+
+// showMainMenu reinicia o estado, descartando os dados coletados, e retorna o menu principal.
+// É o reset explícito (MENU, saudações); entradas inválidas usam repromptMenu. O início da sessão é
+// mantido, para que voltar ao menu não renove MaxSessionLifetime.
 func (s *ChatbotService) showMainMenu(userID string) (string, error) {
 	ctx := context.Background()
 
-	s.redis.Del(ctx, "chat:+"+userID)
-	s.redis.Del(ctx, "data:"+userID)
-
-	s.redis.Set(ctx, "chat:"+userID, "menu", time.Hour)
-
-	return `*QI TELECOM | Menu Principal 🛰️*
-
-Bem-vindo ao QIChatBot!
-Digite apenas o *número* da opção desejada:
-
-[1] Suporte Técnico
-    - Problemas com internet, modem ou instalação
-
-[2] Planos e Serviços
-    - Conhecer planos ou solicitar upgrade
+	prev := s.getUserData(userID)
+	s.sessions.clear(ctx, userID)
+	s.setUserData(userID, sessionData(prev, ""))
 
-[3] Boleto e Financeiro
-    - Segunda via e questões financeiras
+	s.setState(userID, StateMenu)
 
-[4] Assistente Livre
-    - Chat livre para qualquer dúvida
-
-Digite sua opção (1-4):`, nil
+	if s.firstVisit(userID) {
+		return s.cfg.OnboardingMessage + "\n\n" + s.mainMenu(userID), nil
+	}
+	return s.mainMenu(userID), nil
 }
 
 // handleMenuSelection processa a escolha do menu principal pelo usuário.
-func (s *ChatbotService) handleMenuSelection(userID, message string) (string, error) {
-	ctx := context.Background()
-	option := strings.TrimSpace(message)
+func (s *ChatbotService) handleMenuSelection(channel, userID, message string) (string, error) {
+	option := message
+	switch option {
+	case "1", "2", "3", "4":
+		s.recordEvent(eventMenuSelection, option, channel)
+	}
 
 	switch option {
 	case "1":
-		s.redis.Set(ctx, "chat:"+userID, "support_name", time.Hour)
-		userData := UserData{TipoAtendimento: "Suporte Técnico", TentativasIA: 0}
-		s.setUserData(userID, userData)
-		return "🔧 *Suporte Técnico Selecionado*\n\nPara melhor atendê-lo, preciso do seu *nome completo*:", nil
+		s.setState(userID, StateSupportName)
+		s.setUserData(userID, sessionData(s.getUserData(userID), "Suporte Técnico"))
+		return s.askName(userID, "🔧 *Suporte Técnico Selecionado*\n\n", "Para melhor atendê-lo, preciso do seu *nome completo*:"), nil
 
 	case "2":
-		s.redis.Set(ctx, "chat:"+userID, "plans_client_check", time.Hour)
-		userData := UserData{TipoAtendimento: "Planos e Serviços"}
-		s.setUserData(userID, userData)
-		return "📋 *Planos e Serviços*\n\nVocê já é cliente QI TELECOM? Responda *SIM* ou *NÃO*.\n\n(Após responder, mostrarei as opções de planos.)", nil
+		s.setState(userID, StatePlansClientCheck)
+		s.setUserData(userID, sessionData(s.getUserData(userID), "Planos e Serviços"))
+		return s.plansClientCheckPrompt(channel, userID), nil
 
 	case "3":
 		return s.showBoletoInfo(userID)
 
 	case "4":
-		s.redis.Set(ctx, "chat:"+userID, "ai_free", time.Hour)
-		userData := UserData{TipoAtendimento: "IA Livre"}
-		s.setUserData(userID, userData)
+		s.setState(userID, StateAIFree)
+		s.setUserData(userID, sessionData(s.getUserData(userID), "IA Livre"))
 		return "🤖 *Assistente Livre Ativado*\n\nAgora você pode fazer qualquer pergunta que quiser! Estou aqui para ajudar.", nil
 
 	default:
-		return s.showMainMenu(userID)
+		return s.repromptMenu(userID)
 	}
 }
 
-// showBoletoInfo retorna informações financeiras e canais de contato.
-func (s *ChatbotService) showBoletoInfo(userID string) (string, error) {
-	ctx := context.Background()
-	s.redis.Set(ctx, "chat:"+userID, "menu", time.Hour)
-
-	return `💰 *Boleto e Financeiro*
-
-Para *segunda via* ou dúvidas financeiras, utilize os canais oficiais:
-
-*Unidade / Responsável*
-Francisco Alves: Av. Brigadeiro Faria Lima 703 - Centro | (44) 3643-1736
-
-Iporã: Rua Katsuo Nakata 1115 - Centro | (44) 98402-7130 / (44) 3199-9115
-
-Palotina: Aldir Pedron 1319 - Centro | (44) 3649-1486
+// sessionData retorna os dados vazios de um novo fluxo (tipo), mantendo os metadados da sessão anterior:
+// o início (CriadaEm) e a última atividade. Todo reset de fluxo deve partir daqui, e não de UserData{}.
+// Os anexos ainda não registrados também são mantidos, para que a foto enviada antes de escolher o
+// suporte entre no chamado; eles são descartados quando o chamado é gravado.
+func sessionData(prev UserData, tipo string) UserData {
+	return UserData{TipoAtendimento: tipo, CriadaEm: prev.CriadaEm, UltimaAtividade: prev.UltimaAtividade, Anexos: prev.Anexos}
+}
 
-Terra Roxa: Av. da Saudade 369 - Centro | (44) 3645-3257
+// repromptMenu reapresenta o menu após uma opção inválida, sem alterar o estado nem os dados do usuário.
+func (s *ChatbotService) repromptMenu(userID string) (string, error) {
+	return s.invalidInput(userID, "❓ *Opção inválida.* Digite apenas o *número* da opção desejada.\n\n"+s.mainMenu(userID))
+}
 
-⚠️ *Aplicativo de boletos em desenvolvimento. Em breve novidades.*
+// showBoletoInfo inicia a consulta de segunda via quando há provedor configurado;
+// caso contrário, retorna os canais de contato financeiros.
+func (s *ChatbotService) showBoletoInfo(userID string) (string, error) {
+	if s.boleto != nil {
+		s.setUserData(userID, sessionData(s.getUserData(userID), "Boleto e Financeiro"))
+		s.setState(userID, StateBoletoIdentifier)
+		return "💰 *Boleto e Financeiro*\n\nPara gerar a *segunda via*, informe o *CPF*, *CNPJ* ou *número do contrato* do titular (somente números):", nil
+	}
 
-Digite MENU para voltar ao menu principal.`, nil
+	s.setState(userID, StateMenu)
+	return "💰 *Boleto e Financeiro*\n\n⚠️ *Aplicativo de boletos em desenvolvimento. Em breve novidades.*\n\n" + s.boletoContacts(), nil
 }
 
 // handleSupportName armazena o nome do usuário e avança para o próximo passo do suporte.
 func (s *ChatbotService) handleSupportName(userID, message string) (string, error) {
-	ctx := context.Background()
+	nome, reprompt := s.resolveName(userID, message)
+	if reprompt != "" {
+		return reprompt, nil
+	}
+	if nome = s.collectedName(nome); nome == "" {
+		return s.invalidInput(userID, invalidNamePrompt)
+	}
 	userData := s.getUserData(userID)
-	userData.Nome = strings.TrimSpace(message)
+	userData.Nome = nome
 	s.setUserData(userID, userData)
 
-	s.redis.Set(ctx, "chat:"+userID, "support_problem", time.Hour)
+	s.setState(userID, StateSupportProblem)
 	return fmt.Sprintf("Obrigado, %s! 👋\n\nAgora, descreva detalhadamente o problema técnico que você está enfrentando:", userData.Nome), nil
 }
 
 // handleSupportProblem armazena o problema relatado e inicia o suporte técnico.
-func (s *ChatbotService) handleSupportProblem(userID, message string) (string, error) {
-	ctx := context.Background()
+func (s *ChatbotService) handleSupportProblem(ctx context.Context, userID, message string) (string, error) {
+	problema, reprompt := s.limitAIInput(message)
+	if reprompt != "" {
+		return reprompt, nil
+	}
+
 	userData := s.getUserData(userID)
-	userData.Problema = strings.TrimSpace(message)
+	userData.Problema = problema
 	userData.Descricao = message
 	s.setUserData(userID, userData)
-
-	s.redis.Set(ctx, "chat:"+userID, "support_ia", time.Hour)
-	return s.startTechnicalSupport(userID, message)
+	s.recordEvent(eventTicketOpened, "", s.sessionChannel(userID))
+
+	s.setState(userID, StateSupportIA)
+	s.resetTranscript(userID)
+	s.recordTranscript(userID, TranscriptCustomer, problema)
+	response, err := s.startTechnicalSupport(ctx, userID, problema)
+	if err == nil {
+		s.recordTranscript(userID, TranscriptBot, response)
+	}
+	return response, err
 }
 
 // startTechnicalSupport inicia o atendimento técnico, usando IA se disponível.
-func (s *ChatbotService) startTechnicalSupport(userID, problema string) (string, error) {
+func (s *ChatbotService) startTechnicalSupport(ctx context.Context, userID, problema string) (string, error) {
 	userData := s.getUserData(userID)
 	userData.TentativasIA = 1
 	s.setUserData(userID, userData)
 
-	prompt := fmt.Sprintf(`Você é um técnico especializado em internet, modem e instalações da QI TELECOM. 
+	prompt := s.assemblePrompt(userID, s.technicalPersona()+` 
 		Analise o problema relatado pelo cliente e forneça uma solução técnica detalhada e prática.
+		%s
 		O nome do cliente é: %s
 		PROBLEMA: %s
    
@@ -247,10 +443,12 @@ func (s *ChatbotService) startTechnicalSupport(userID, problema string) (string,
 		2. Solução passo a passo 
 		3. Se não funcionar, próximos passos
    
-		Seja técnico mas didático, lembrando que você está se relacionando com pessoas leigas no assunto. Não repita o problema ou o nome do cliente na resposta.`, userData.Nome, problema)
+		`+s.technicalTone()+` Não repita o problema ou o nome do cliente na resposta.`,
+		promptPart{text: userData.Nome, priority: 2},
+		promptPart{text: problema, priority: 1})
 
-	if s.ai != nil {
-		response, err := s.ai.GenerateResponse(prompt)
+	if s.aiEnabled() {
+		response, err := s.generateAI(ctx, "technical", func(ctx context.Context) (string, error) { return s.ai.GenerateResponse(ctx, prompt) })
 		if err == nil {
 			return fmt.Sprintf("🔧 Analise Técnica - Tentativa 1/5\n\n%s\n\n---\nIsso resolveu seu problema?\n- Digite SIM se resolveu\n- Digite NAO se não resolveu", response), nil
 		}
@@ -261,14 +459,18 @@ func (s *ChatbotService) startTechnicalSupport(userID, problema string) (string,
 }
 
 // continueTechnicalSupport gera novas tentativas de solução técnica para o problema do usuário.
-func (s *ChatbotService) continueTechnicalSupport(userID string, tentativa int, problema string) (string, error) {
-	prompt := fmt.Sprintf(`Esta é a tentativa %d/5 de resolver este problema técnico. 
-	Problema anterior: %s
+func (s *ChatbotService) continueTechnicalSupport(ctx context.Context, userID string, tentativa int, problema string) (string, error) {
+	prompt := s.assemblePrompt(userID, s.technicalPersona()+fmt.Sprintf(`
+	Esta é a tentativa %d/5 de resolver este problema técnico. 
+	%%s
+	Problema anterior: %%s
 	
-	Forneça uma solução DIFERENTE e mais avançada. Seja mais específico e didatico para uma pessoa leiga. tente ser direto ao ponto, sem muita escrita.`, tentativa, problema)
+	Forneça uma solução DIFERENTE e mais avançada. Seja mais específico e didatico para uma pessoa leiga. tente ser direto ao ponto, sem muita escrita.
+	`, tentativa)+s.technicalTone(),
+		promptPart{text: problema, priority: 1})
 
-	if s.ai != nil {
-		response, err := s.ai.GenerateResponse(prompt)
+	if s.aiEnabled() {
+		response, err := s.generateAI(ctx, "technical", func(ctx context.Context) (string, error) { return s.ai.GenerateResponse(ctx, prompt) })
 		if err == nil {
 			return fmt.Sprintf("🔧 *Nova Análise Técnica - Tentativa %d/5*\n\n%s\n\n---\n*Isso resolveu seu problema?*\n- Digite *SIM* se resolveu\n- Digite *NÃO* se não resolveu", tentativa, response), nil
 		}
@@ -287,165 +489,154 @@ func (s *ChatbotService) continueTechnicalSupport(userID string, tentativa int,
 
 // handlePlansClientCheck identifica se o usuário é cliente atual ou novo e direciona o fluxo.
 func (s *ChatbotService) handlePlansClientCheck(userID, message string) (string, error) {
-	ctx := context.Background()
-	response := strings.ToLower(strings.TrimSpace(message))
+	response := normalizeCommand(message)
 	userData := s.getUserData(userID)
 
-	if response == "sim" {
-		userData.Situacao = "Cliente Atual"
-		s.setUserData(userID, userData)
-		s.redis.Set(ctx, "chat:"+userID, "plans_current", time.Hour)
-		planOptions := []string{
-			"QI FIBRA BASIC",
-			"QI FIBRA PREMIUM",
-			"QI FIBRA PREMIUM (MELHOR)",
-			"QI FIBRA PREMIUM TOP",
-		}
-		menu := "\nEscolha seu plano atual digitando o número correspondente:\n"
-		for i, p := range planOptions {
-			menu += fmt.Sprintf("[%d] *%s*\n", i+1, p)
+	if isYes(response) {
+		userData.Situacao = situacaoClienteAtual
+		if userData.ClienteIdentificado && userData.PlanoSugerido != "" {
+			userData.PlanoAtual = userData.PlanoSugerido
+			s.setUserData(userID, userData)
+			s.setState(userID, StatePlansSelection)
+			return "👤 *Cliente Atual Identificado*\n\nSeu plano atual: *" + userData.PlanoAtual + "*\n\nQual plano desperta seu interesse?\n\n" + s.planList(userID, true, true), nil
 		}
-		menu += "\n*Digite o número da opção desejada:*"
+		s.setUserData(userID, userData)
+		s.setState(userID, StatePlansCurrent)
+		menu := "\nEscolha seu plano atual digitando o número correspondente:\n" +
+			s.planList(userID, false, true) +
+			"\n*Digite o número da opção desejada:*"
 		return "👤 *Cliente Atual Identificado*\n\nQual seu *plano atual*?" + menu, nil
 	}
 
-	if response == "não" || response == "nao" {
+	if isNo(response) {
 		userData.Situacao = "Novo Cliente"
 		userData.PlanoAtual = "Nenhum"
 		s.setUserData(userID, userData)
-		s.redis.Set(ctx, "chat:"+userID, "plans_selection", time.Hour)
-		return "🆕 *Novo Cliente - Bem-vindo!*\n\nPerfeito! Qual plano desperta seu interesse?\n\n" + planList, nil
+		s.setState(userID, StatePlansSelection)
+		return "🆕 *Novo Cliente - Bem-vindo!*\n\nPerfeito! Qual plano desperta seu interesse?\n\n" + s.planList(userID, true, true), nil
 	}
 
-	return "Por favor, responda *SIM* ou *NÃO*.", nil
+	return s.invalidInput(userID, "Por favor, responda *SIM* ou *NÃO*.")
 }
 
 // handlePlansCurrent armazena o plano atual informado pelo usuário.
 func (s *ChatbotService) handlePlansCurrent(userID, message string) (string, error) {
-	ctx := context.Background()
 	userData := s.getUserData(userID)
-	userData.PlanoAtual = strings.TrimSpace(message)
+	userData.PlanoAtual = message
+	if idx := planIndex(userData.PlanoAtual); idx != -1 {
+		userData.PlanoAtual = planOptions[idx]
+	}
 	s.setUserData(userID, userData)
 
 	// Apresenta opções numeradas e inclui "manter o mesmo plano"
-	planOptions := []string{
-		"QI FIBRA BASIC",
-		"QI FIBRA PREMIUM",
-		"QI FIBRA PREMIUM (MELHOR)",
-		"QI FIBRA PREMIUM TOP",
-	}
+	menu := "\nEscolha o número do plano desejado para upgrade ou digite o número do seu plano atual para manter:\n" +
+		s.planList(userID, false, false) +
+		"\n*Digite o número da opção desejada:*"
 
-	menu := "\nEscolha o número do plano desejado para upgrade ou digite o número do seu plano atual para manter:\n"
-	for i, p := range planOptions {
-		menu += fmt.Sprintf("[%d] %s\n", i+1, p)
-	}
-	menu += "\n*Digite o número da opção desejada:*"
-
-	s.redis.Set(ctx, "chat:"+userID, "plans_selection", time.Hour)
+	s.setState(userID, StatePlansSelection)
 	return fmt.Sprintf("📋 *Plano Atual: %s*\n\nGostaria de fazer *upgrade* ou manter o mesmo plano?%s", userData.PlanoAtual, menu), nil
 }
 
 // handlePlansSelection armazena o plano desejado e avança para coleta de dados do usuário.
 func (s *ChatbotService) handlePlansSelection(userID, message string) (string, error) {
-	ctx := context.Background()
 	userData := s.getUserData(userID)
-	option := strings.TrimSpace(message)
+	option := message
 
-	planOptions := []string{
-		"QI FIBRA BASIC",
-		"QI FIBRA PREMIUM",
-		"QI FIBRA PREMIUM (MELHOR)",
-		"QI FIBRA PREMIUM TOP",
-	}
-
-	selectedIndex := -1
-	for i := range planOptions {
-		if option == fmt.Sprintf("%d", i+1) {
-			selectedIndex = i
-			break
-		}
-	}
+	selectedIndex := planIndex(option)
 
 	if selectedIndex != -1 {
 		// Se o plano escolhido for igual ao atual, manter
 		if strings.EqualFold(planOptions[selectedIndex], userData.PlanoAtual) {
 			userData.PlanoDesejado = userData.PlanoAtual
 			s.setUserData(userID, userData)
-			s.redis.Set(ctx, "chat:"+userID, "menu", time.Hour)
-			return "✅ *Entendido!*\n\nVocê optou por manter seu plano atual. Se mudar de ideia, estaremos aqui!\n\nDigite *MENU* para voltar ao menu principal.", nil
+			if s.cfg.RetentionEnabled {
+				return s.offerRetention(userID)
+			}
+			return s.confirmKeepPlan(userID)
 		} else {
 			userData.PlanoDesejado = planOptions[selectedIndex]
 			s.setUserData(userID, userData)
-			s.redis.Set(ctx, "chat:"+userID, "plans_name", time.Hour)
-			return "📝 *Dados para Contato*\n\nPara avançar, preciso do seu *nome completo*:", nil
+			s.setState(userID, StatePlansName)
+			return s.askName(userID, "📝 *Dados para Contato*\n\n", "Para avançar, preciso do seu *nome completo*:"), nil
 		}
 	}
 
 	// Caso digite o nome do plano manualmente
 	userData.PlanoDesejado = option
 	s.setUserData(userID, userData)
-	s.redis.Set(ctx, "chat:"+userID, "plans_name", time.Hour)
-	return "📝 *Dados para Contato*\n\nPara avançar, preciso do seu *nome completo*:", nil
+	s.setState(userID, StatePlansName)
+	return s.askName(userID, "📝 *Dados para Contato*\n\n", "Para avançar, preciso do seu *nome completo*:"), nil
 }
 
-// handlePlansName armazena o nome do usuário e coleta telefone, se necessário.
-func (s *ChatbotService) handlePlansName(userID, message string) (string, error) {
-	ctx := context.Background()
+// planIndex retorna o índice em planOptions correspondente ao número digitado, ou -1.
+func planIndex(option string) int {
+	for i := range planOptions {
+		if option == fmt.Sprintf("%d", i+1) {
+			return i
+		}
+	}
+	return -1
+}
+
+// handlePlansName armazena o nome do usuário e coleta telefone, se o canal não o informar.
+func (s *ChatbotService) handlePlansName(ctx context.Context, channel, userID, message string) (string, error) {
+	nome, reprompt := s.resolveName(userID, message)
+	if reprompt != "" {
+		return reprompt, nil
+	}
+	if nome = s.collectedName(nome); nome == "" {
+		return s.invalidInput(userID, invalidNamePrompt)
+	}
 	userData := s.getUserData(userID)
-	userData.Nome = strings.TrimSpace(message)
+	userData.Nome = nome
 
-	if userData.Telefone == "" && len(userID) >= 10 && len(userID) <= 15 && isAllDigits(userID) {
-		userData.Telefone = userID
+	if userData.Telefone == "" {
+		userData.Telefone = extractPhoneFromUserID(userID, channel)
 	}
 	s.setUserData(userID, userData)
 
 	if userData.Telefone != "" {
-		observacoes := fmt.Sprintf("Interesse em: %s | Plano atual: %s", userData.PlanoDesejado, userData.PlanoAtual)
-		s.sheets.SavePlans(userData.Nome, userData.Situacao, userData.PlanoAtual, userData.PlanoDesejado, userData.Telefone, observacoes)
-		s.redis.Set(ctx, "chat:"+userID, "menu", time.Hour)
-		return fmt.Sprintf("🎉 *Dados Registrados com Sucesso!*\n\n*Nome*: %s\n*Situação*: %s\n*Plano Interesse*: %s\n*Telefone*: %s\n\n📞 *Próximos Passos*:\nNossa equipe comercial entrará em contato em até 24 horas para finalizar!\n\nDigite *MENU* para voltar ao menu principal.", userData.Nome, userData.Situacao, userData.PlanoDesejado, userData.Telefone), nil
+		return s.askContactTimeOrComplete(ctx, userID)
 	}
 
-	s.redis.Set(ctx, "chat:"+userID, "plans_phone", time.Hour)
+	s.setState(userID, StatePlansPhone)
 	return "📞 Agora informe um *telefone/WhatsApp* para contato (somente números ou formato (XX) XXXXX-XXXX):", nil
 }
 
-// isAllDigits retorna true se a string contém apenas dígitos.
-func isAllDigits(s string) bool {
-	for _, r := range s {
-		if r < '0' || r > '9' {
-			return false
-		}
-	}
-	return true
-}
-
 // handlePlansPhone armazena o telefone informado e finaliza o fluxo de planos.
-func (s *ChatbotService) handlePlansPhone(userID, message string) (string, error) {
-	ctx := context.Background()
+func (s *ChatbotService) handlePlansPhone(ctx context.Context, userID, message string) (string, error) {
+	telefone, ok := parseContactPhone(message)
+	if !ok {
+		return s.invalidInput(userID, "Telefone inválido. "+resumePrompts[StatePlansPhone])
+	}
 	userData := s.getUserData(userID)
-	telefone := strings.TrimSpace(message)
-	telefone = strings.ReplaceAll(telefone, " ", "")
 	userData.Telefone = telefone
 	s.setUserData(userID, userData)
-
-	observacoes := fmt.Sprintf("Interesse em: %s | Plano atual: %s", userData.PlanoDesejado, userData.PlanoAtual)
-	s.sheets.SavePlans(userData.Nome, userData.Situacao, userData.PlanoAtual, userData.PlanoDesejado, userData.Telefone, observacoes)
-
-	s.redis.Set(ctx, "chat:"+userID, "menu", time.Hour)
-
-	return fmt.Sprintf("🎉 *Dados Registrados com Sucesso!*\n\n*Nome*: %s\n*Situação*: %s\n*Plano Interesse*: %s\n*Telefone*: %s\n\n📞 *Próximos Passos*:\nNossa equipe comercial entrará em contato em até 24 horas para finalizar!\n\nDigite *MENU* para voltar ao menu principal.", userData.Nome, userData.Situacao, userData.PlanoDesejado, userData.Telefone), nil
+	return s.askContactTimeOrComplete(ctx, userID)
 }
 
 // handleFreeAI processa perguntas livres para a IA.
-func (s *ChatbotService) handleFreeAI(userID, message string) (string, error) {
-	if strings.ToLower(strings.TrimSpace(message)) == "menu" {
+func (s *ChatbotService) handleFreeAI(ctx context.Context, userID, message string) (string, error) {
+	if normalizeCommand(message) == "menu" {
 		return s.showMainMenu(userID)
 	}
 
-	if s.ai != nil {
-		response, err := s.ai.GenerateFreeResponse(message)
+	if response, handled, err := s.routeFreeIntent(userID, message); handled {
+		return response, err
+	}
+
+	pergunta, reprompt := s.limitAIInput(message)
+	if reprompt != "" {
+		return reprompt, nil
+	}
+
+	if s.aiEnabled() {
+		prompt := s.assemblePrompt(userID, "%s\n%s"+s.freeTone(), promptPart{text: pergunta, priority: 1})
+		response, err := s.generateAI(ctx, "free", func(ctx context.Context) (string, error) { return s.ai.GenerateFreeResponse(ctx, prompt) })
 		if err == nil {
+			if moderated := s.moderateAIOutput(userID, response); moderated != response {
+				return moderated, nil
+			}
 			return fmt.Sprintf("🤖 %s\n\n---\n*Digite *MENU* para voltar ao menu principal*", response), nil
 		}
 	}
@@ -454,42 +645,64 @@ func (s *ChatbotService) handleFreeAI(userID, message string) (string, error) {
 }
 
 // handleSupportIA processa a resposta do usuário sobre a resolução do problema técnico.
-func (s *ChatbotService) handleSupportIA(userID, message string) (string, error) {
-	ctx := context.Background()
-	response := strings.ToLower(strings.TrimSpace(message))
+func (s *ChatbotService) handleSupportIA(ctx context.Context, userID, message string) (string, error) {
+	response := normalizeCommand(message)
 	userData := s.getUserData(userID)
 
-	if response == "sim" {
-		s.sheets.SaveSupport(userData.Nome, userData.Problema, userData.Descricao, "Resolvido pela IA")
+	if isYes(response) {
+		if err := s.saveSupport(ctx, userData.Nome, userData.Problema, supportDescription(userData), "Resolvido pela IA", ""); err != nil {
+			return "", fmt.Errorf("erro ao registrar atendimento resolvido: %w", err)
+		}
+		s.recordEvent(eventTicketResolved, strconv.Itoa(userData.TentativasIA), s.sessionChannel(userID))
 		userData.AguardandoFeedback = false
+		userData.Anexos = nil
 		s.setUserData(userID, userData)
-		s.redis.Set(ctx, "chat:"+userID, "support_feedback", time.Hour)
+		s.setState(userID, StateSupportFeedback)
 		return "🎉 *Ótimo! Problema resolvido!*\n\nPoderia nos dar um *feedback/opinião* sobre nosso atendimento? (Ex: Excelente, Bom, Regular...)", nil
 	}
 
-	if response == "não" || response == "nao" {
+	if isNo(response) {
+		s.recordTranscript(userID, TranscriptCustomer, message)
 		userData.TentativasIA++
 		if userData.TentativasIA >= 5 {
-			s.sheets.SaveSupport(userData.Nome, userData.Problema, userData.Descricao, "Encaminhado para Técnico Humano")
+			transcript := s.transcript(userID)
+			historico := formatTranscript(transcript, s.location())
+			if err := s.saveSupport(ctx, userData.Nome, userData.Problema, supportDescription(userData), "Encaminhado para Técnico Humano", historico); err != nil {
+				return "", fmt.Errorf("erro ao registrar encaminhamento: %w", err)
+			}
 			userData.AguardandoFeedback = false
+			userData.Anexos = nil
 			s.setUserData(userID, userData)
-			s.redis.Set(ctx, "chat:"+userID, "support_feedback", time.Hour)
-			return "🚨 *Encaminhamento para Técnico Especializado*\n\n📅 Prazo: 24-48 horas\n📞 Entraremos em contato.\n\nAntes de finalizar, poderia avaliar nosso atendimento? (Ex: Excelente, Bom, Regular...)", nil
+			s.setState(userID, StateSupportFeedback)
+			fila := ""
+			position, err := s.enqueueHandoff(userID)
+			if err == nil {
+				fila = fmt.Sprintf("\n👥 Sua posição na fila: *%dº*", position)
+			}
+			s.events.Publish(TicketEscalated{
+				UserID: userID, Channel: s.sessionChannel(userID), Nome: userData.Nome, Problema: userData.Problema,
+				Categoria: s.classifyProblem(userData.Problema), SLA: s.escalationSLA(userData.Problema),
+				Tentativas: userData.TentativasIA, QueuePosition: position, Transcript: transcript, At: s.now(),
+			})
+			return "🚨 *Encaminhamento para Técnico Especializado*\n\n" + s.escalationDeadline(userData.Problema) + "\n📞 Entraremos em contato." + fila + "\n\nAntes de finalizar, poderia avaliar nosso atendimento? (Ex: Excelente, Bom, Regular...)", nil
 		}
 		s.setUserData(userID, userData)
-		return s.continueTechnicalSupport(userID, userData.TentativasIA, userData.Problema)
+		reply, err := s.continueTechnicalSupport(ctx, userID, userData.TentativasIA, userData.Problema)
+		if err == nil {
+			s.recordTranscript(userID, TranscriptBot, reply)
+		}
+		return reply, err
 	}
 
-	return "Por favor, responda apenas *SIM* ou *NÃO* para que eu possa ajudá-lo melhor.", nil
+	return s.invalidInput(userID, "Por favor, responda apenas *SIM* ou *NÃO* para que eu possa ajudá-lo melhor.")
 }
 
 // handleSupportFeedback armazena feedback e sugestões do usuário após o atendimento.
-func (s *ChatbotService) handleSupportFeedback(userID, message string) (string, error) {
-	ctx := context.Background()
+func (s *ChatbotService) handleSupportFeedback(ctx context.Context, userID, message string) (string, error) {
 	userData := s.getUserData(userID)
 
 	if !userData.AguardandoFeedback {
-		feedback := strings.TrimSpace(message)
+		feedback := message
 		userData.Problema = feedback
 		userData.AguardandoFeedback = true
 		s.setUserData(userID, userData)
@@ -497,35 +710,27 @@ func (s *ChatbotService) handleSupportFeedback(userID, message string) (string,
 		return "💭 *Obrigado pela avaliação!*\n\nPara finalizar, tem alguma *sugestão* ou *comentário* para melhorarmos nosso atendimento?\n\n*(Digite sua sugestão ou 'NÃO' se não tiver)*", nil
 	}
 
-	sugestoes := strings.TrimSpace(message)
-	if strings.ToLower(sugestoes) == "não" || strings.ToLower(sugestoes) == "nao" {
+	sugestoes := message
+	if isNo(normalizeCommand(sugestoes)) {
 		sugestoes = ""
 	}
 	avaliacao := userData.Problema
-	s.sheets.SaveFeedback(userData.Nome, userData.TipoAtendimento, avaliacao, sugestoes)
+	if err := s.saveFeedback(ctx, userID, userData.Nome, userData.TipoAtendimento, avaliacao, sugestoes); err != nil {
+		return "", fmt.Errorf("erro ao registrar feedback: %w", err)
+	}
 
-	s.redis.Set(ctx, "chat:"+userID, "menu", time.Hour)
-	return "🙏 *Feedback registrado com sucesso!* \n\nSua opinião é muito importante para melhorarmos nossos serviços.\n\nDigite *MENU* para voltar ao menu principal.", nil
+	return s.finishFlow(userID, "🙏 *Feedback registrado com sucesso!* \n\nSua opinião é muito importante para melhorarmos nossos serviços.\n\nDigite *MENU* para voltar ao menu principal.")
 }
 
 // getUserData lê o estado do usuário do Redis.
 func (s *ChatbotService) getUserData(userID string) UserData {
-	ctx := context.Background()
-	data, err := s.redis.Get(ctx, "data:"+userID).Result()
-	if err != nil {
-		return UserData{}
-	}
-
-	var userData UserData
-	json.Unmarshal([]byte(data), &userData)
+	userData, _ := s.sessions.data(context.Background(), userID)
 	return userData
 }
 
 // setUserData grava o estado do usuário no Redis.
 func (s *ChatbotService) setUserData(userID string, userData UserData) {
-	ctx := context.Background()
-	data, _ := json.Marshal(userData)
-	s.redis.Set(ctx, "data:"+userID, data, time.Hour)
+	s.sessions.setData(context.Background(), userID, userData)
 }
 
 //Copyright 2025 Kauan Botura
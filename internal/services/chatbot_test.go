@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestInvalidMenuOptionRepromptsWithoutReset(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.MaxInvalidInputs = 0 })
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	userData := s.getUserData(user)
+	userData.Anexos = []string{"media/modem.jpg"}
+	s.setUserData(user, userData)
+
+	response, err := s.ProcessMessage(ctx, ChannelWhatsApp, user, "9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(response, "❓ *Opção inválida.*") || !strings.Contains(response, s.mainMenu(user)) {
+		t.Errorf("resposta = %q; esperado o aviso seguido do menu", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateMenu {
+		t.Errorf("estado = %q; esperado continuar em %q", state, StateMenu)
+	}
+	got := s.getUserData(user)
+	if got.CriadaEm != userData.CriadaEm || len(got.Anexos) != 1 {
+		t.Errorf("dados = %+v; esperado manter o início da sessão e os anexos", got)
+	}
+}
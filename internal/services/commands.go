@@ -0,0 +1,31 @@
+package services
+
+import "strings"
+
+// diacriticsReplacer remove os acentos usados no português para comparação de comandos.
+var diacriticsReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// normalizeCommand prepara a mensagem para comparação com palavras-chave:
+// minúsculas, sem acentos, sem pontuação nas pontas e com espaços simples.
+func normalizeCommand(s string) string {
+	s = diacriticsReplacer.Replace(strings.ToLower(s))
+	s = strings.Trim(s, "!?.,;:~ \t\r\n")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// isYes indica se a mensagem normalizada é uma resposta afirmativa.
+func isYes(cmd string) bool {
+	return cmd == "sim" || cmd == "s"
+}
+
+// isNo indica se a mensagem normalizada é uma resposta negativa.
+func isNo(cmd string) bool {
+	return cmd == "nao" || cmd == "n"
+}
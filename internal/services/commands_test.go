@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalizeCommand(t *testing.T) {
+	cases := map[string]string{
+		"MENU":                      "menu",
+		"Menu!":                     "menu",
+		"NÃO":                       "nao",
+		"Não.":                      "nao",
+		"  Falar   com ATENDENTE. ": "falar com atendente",
+		"Início":                    "inicio",
+		"ÇÃO Ü Ñ":                   "cao u n",
+		"olá?!":                     "ola",
+		"sim\n":                     "sim",
+		"":                          "",
+		"500 MEGA":                  "500 mega",
+		"ótimo, obrigado":           "otimo, obrigado",
+	}
+	for input, want := range cases {
+		if got := normalizeCommand(input); got != want {
+			t.Errorf("normalizeCommand(%q) = %q; esperado %q", input, got, want)
+		}
+	}
+}
+
+func TestCommandVariantsMatchKeywords(t *testing.T) {
+	for _, msg := range []string{"Sim", "SIM!", "s"} {
+		if !isYes(normalizeCommand(msg)) {
+			t.Errorf("%q não reconhecido como sim", msg)
+		}
+	}
+	for _, msg := range []string{"Não", "NAO", "nao.", "N"} {
+		if !isNo(normalizeCommand(msg)) {
+			t.Errorf("%q não reconhecido como não", msg)
+		}
+	}
+	for _, msg := range []string{"Atendente", "FALAR COM ATENDENTE!"} {
+		if !isHandoffRequest(normalizeCommand(msg)) {
+			t.Errorf("%q não reconhecido como pedido de atendente", msg)
+		}
+	}
+}
+
+func TestMenuCommandAcceptsCaseVariants(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	for _, msg := range []string{"MENU", "Menu.", " menu "} {
+		s.setState(user, StatePlansName)
+		if _, err := s.route(ctx, ChannelWhatsApp, user, msg); err != nil {
+			t.Fatal(err)
+		}
+		if state := s.sessions.state(ctx, user); state != StateMenu {
+			t.Errorf("estado após %q = %q; esperado %q", msg, state, StateMenu)
+		}
+	}
+}
@@ -0,0 +1,582 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"leadprojectarrumado/internal/ai"
+)
+
+// defaultGreetingKeywords são as saudações que levam o usuário ao menu principal.
+var defaultGreetingKeywords = []string{
+	"oi", "oie", "olá", "ola", "bom dia", "boa tarde", "boa noite",
+	"começar", "comecar", "início", "inicio", "start", "hello",
+}
+
+// defaultMainMenuMessage é o menu principal exibido ao iniciar ou reiniciar o atendimento. Enquanto não
+// for substituído por MAIN_MENU_MESSAGE, o menu é renderizado no estilo de lista de cada canal.
+var defaultMainMenuMessage = renderMainMenu(listStyles[defaultListStyle])
+
+// defaultOnboardingMessage é exibido antes do menu apenas na primeira visita do usuário.
+const defaultOnboardingMessage = `👋 *Olá! Eu sou o QIChatBot, o assistente virtual da QI TELECOM.*
+
+Funciono assim:
+• Escolha uma opção do menu digitando apenas o *número*
+• Digite *MENU* a qualquer momento para recomeçar
+• Digite *ATENDENTE* se preferir falar com uma pessoa`
+
+// Config reúne parâmetros configuráveis do fluxo de atendimento.
+type Config struct {
+	GreetingKeywords []string
+
+	MainMenuMessage   string
+	OnboardingMessage string
+	// SeenTTL é por quanto tempo o usuário é lembrado como já atendido (renovado a cada visita).
+	SeenTTL time.Duration
+
+	// IdleTimeout é o tempo sem mensagens após o qual a sessão é reiniciada.
+	IdleTimeout time.Duration
+	// MaxSessionLifetime é a duração máxima de uma sessão desde o início, independente da atividade (0 desativa).
+	MaxSessionLifetime time.Duration
+	// ReminderEnabled habilita o lembrete "ainda está aí?" em canais com push.
+	ReminderEnabled bool
+	// ReminderFraction é a fração de IdleTimeout após a qual o lembrete é enviado.
+	ReminderFraction float64
+	ReminderMessage  string
+	SweepInterval    time.Duration
+
+	// RetentionEnabled oferece um incentivo ao cliente que decide manter o plano atual.
+	RetentionEnabled bool
+	RetentionMessage string
+
+	// MaintenanceMode faz o serviço responder apenas MaintenanceMessage, sem gravar dados.
+	MaintenanceMode    bool
+	MaintenanceMessage string
+
+	// Estado inicial das feature flags; podem ser alteradas em tempo de execução via /admin/flags.
+	AIEnabled       bool
+	SheetsEnabled   bool
+	WhatsAppEnabled bool
+
+	// Contato de "último recurso" exibido quando o atendimento falha de forma irrecuperável.
+	// LastResortMessage, se definida, substitui o texto montado a partir de telefone e e-mail.
+	SupportPhone      string
+	SupportEmail      string
+	LastResortMessage string
+
+	// AIInputMaxChars limita o tamanho das mensagens enviadas à IA (0 desativa);
+	// AIInputPolicy define se o excesso é recusado ("reject") ou cortado ("truncate").
+	AIInputMaxChars int
+	AIInputPolicy   string
+
+	// AIMaxConcurrent limita as chamadas simultâneas à IA em todo o serviço (0 desativa). Acima dele,
+	// AIOverflowPolicy "queue" aguarda até AIQueueWait por uma vaga e "fallback" usa a resposta estática na hora.
+	AIMaxConcurrent  int
+	AIOverflowPolicy string
+	AIQueueWait      time.Duration
+
+	// AIFallbackWindow é a janela móvel da taxa de fallback da IA (AI_FALLBACK_WINDOW). Com AIFallbackAlertRatio
+	// (AI_FALLBACK_ALERT_RATIO, 0 desativa) os notificadores são avisados quando a taxa na janela o atinge,
+	// e /status passa a dar a IA como indisponível.
+	AIFallbackWindow     time.Duration
+	AIFallbackAlertRatio float64
+
+	// AIProbeInterval é por quanto tempo /status reaproveita a última verificação da IA (AI_PROBE_INTERVAL);
+	// AIUnavailableNotice é o aviso exibido pelo widget enquanto ela está indisponível (AI_UNAVAILABLE_NOTICE).
+	AIProbeInterval     time.Duration
+	AIUnavailableNotice string
+
+	// AIPromptMaxChars é o tamanho máximo do prompt montado; acima dele as entradas do usuário são cortadas (0 desativa).
+	AIPromptMaxChars int
+
+	// AILimits são os limites por modo repassados ao cliente Gemini (AI_{TECH,FREE}_MAX_WORDS, _MAX_CHARS,
+	// _TIMEOUT e _RETRIES). AIResponseBudget (AI_RESPONSE_BUDGET) é o prazo total de uma resposta da IA, com a
+	// espera por vaga e as novas tentativas; deve ficar abaixo de HTTP_WRITE_TIMEOUT.
+	AILimits         ai.Limits
+	AIResponseBudget time.Duration
+
+	// AIDisclaimerEnabled anexa às respostas da IA o aviso de AIDisclaimers no idioma Language (BOT_LANGUAGE).
+	AIDisclaimerEnabled bool
+	AIDisclaimers       map[string]string
+	Language            string
+
+	// NPSEnabled pergunta, ao fim dos fluxos de suporte, planos e boleto, a nota de 0 a 10 (NPSQuestion),
+	// respondida com NPSThanks.
+	NPSEnabled  bool
+	NPSQuestion string
+	NPSThanks   string
+
+	// Denylist (DENYLIST) e DenylistFile (DENYLIST_FILE, uma entrada por linha) listam IDs e telefones bloqueados,
+	// que recebem apenas DeniedMessage.
+	Denylist      []string
+	DenylistFile  string
+	DeniedMessage string
+
+	// QuickRepliesEnabled anexa às respostas as sugestões de QuickReplies para o estado da sessão (QUICK_REPLIES).
+	QuickRepliesEnabled bool
+	QuickReplies        map[SessionState][]QuickReply
+
+	// AIPersona (AI_PERSONA) e AITone (AI_TONE) definem quem a IA é e como responde no suporte técnico;
+	// AIFreeTone (AI_FREE_TONE) orienta o tom do assistente livre.
+	AIPersona  string
+	AITone     string
+	AIFreeTone string
+
+	// PromptGuardEnabled neutraliza tentativas de prompt injection antes de chamar a IA;
+	// PromptGuardStripRoleplay também remove pedidos de mudança de papel ("finja ser...").
+	PromptGuardEnabled       bool
+	PromptGuardStripRoleplay bool
+
+	SessionTTLs SessionTTLs
+
+	// IntentKeywords define os termos que, no assistente livre, sugerem um fluxo estruturado (AI_INTENT_KEYWORDS).
+	IntentKeywords map[string][]string
+
+	// ModerationEnabled revisa as respostas do assistente livre, trocando por ModerationMessage
+	// as que contenham algum termo de ModerationKeywords.
+	ModerationEnabled  bool
+	ModerationKeywords []string
+	ModerationMessage  string
+
+	// LeadFollowupEnabled envia, LeadFollowupDelay após a sessão expirar, um lembrete (LeadFollowupMessage,
+	// com {nome} e {plano}) a quem parou na coleta de dados do fluxo de planos pelo WhatsApp. Cada usuário
+	// recebe no máximo um lembrete por LeadFollowupCooldown. Desligado por padrão, por ser uma mensagem proativa.
+	LeadFollowupEnabled  bool
+	LeadFollowupDelay    time.Duration
+	LeadFollowupCooldown time.Duration
+	LeadFollowupMessage  string
+
+	// SheetsWorkers é o número de workers que gravam no Sheets em segundo plano (0 grava de forma síncrona);
+	// SheetsQueueSize limita a fila em memória, cujo excedente vai para o banco e é reenviado a cada SheetsReplayInterval.
+	SheetsWorkers        int
+	SheetsQueueSize      int
+	SheetsReplayInterval time.Duration
+	// SheetsQuotaBackoff é a pausa nos envios ao Sheets após um erro de cota (429), com os registros guardados no banco.
+	SheetsQuotaBackoff time.Duration
+	// SheetsMaxAttempts é quantas vezes um registro da fila local pode ser recusado pelo Sheets antes de ir
+	// para sheets_dead_letters (SHEETS_MAX_ATTEMPTS); falhas passageiras não contam.
+	SheetsMaxAttempts int
+
+	// RedisHeartbeatInterval é o intervalo entre os pings de verificação do Redis.
+	RedisHeartbeatInterval time.Duration
+
+	// RedisRetries é o número de novas tentativas das operações de sessão em erros transitórios, com
+	// RedisRetryBackoff entre elas; após RedisBreakerThreshold falhas seguidas o circuito abre por
+	// RedisBreakerCooldown e as sessões passam a usar a memória local.
+	RedisRetries          int
+	RedisRetryBackoff     time.Duration
+	RedisBreakerThreshold int
+	RedisBreakerCooldown  time.Duration
+
+	// ProblemKeywords classifica o problema relatado em categorias (PROBLEM_CATEGORY_KEYWORDS) e
+	// EscalationSLAs define o prazo informado no encaminhamento de cada uma (ESCALATION_SLAS).
+	ProblemKeywords map[string][]string
+	EscalationSLAs  map[string]string
+
+	// ListStyles define, por canal, o estilo das listas do menu e dos planos (LIST_STYLES, ex.: "whatsapp=emoji,web=plain").
+	ListStyles map[string]string
+
+	// MaxInvalidInputs é o número de respostas inválidas seguidas após o qual o usuário volta ao menu (0 desativa).
+	MaxInvalidInputs int
+	// NameCleanup padroniza os nomes coletados (espaços e maiúsculas, ver cleanName). Nomes inválidos
+	// são recusados mesmo com a padronização desligada.
+	NameCleanup bool
+	// InputPreprocessors são os pré-processadores aplicados, em ordem, a toda mensagem recebida antes do
+	// roteamento (INPUT_PREPROCESSORS, nomes separados por vírgula; ver preprocessors).
+	InputPreprocessors []string
+	// TextRequiredStates são os estados que recusam respostas só com emoji ou pontuação (TEXT_REQUIRED_STATES,
+	// padrão: nome e descrição do problema). O chat livre não entra na lista.
+	TextRequiredStates map[SessionState]bool
+
+	// StateTimeouts define, por estado, o tempo máximo sem avançar no fluxo (STATE_TIMEOUTS).
+	StateTimeouts map[SessionState]StateTimeout
+
+	// ReplyReplayInterval é o intervalo de reenvio das respostas que falharam após todas as tentativas.
+	ReplyReplayInterval time.Duration
+
+	// StoreAttempts e StoreBackoff controlam as tentativas de gravação nos backends adicionais de AddStore
+	// (STORE_ATTEMPTS, STORE_BACKOFF, dobrado a cada falha); esgotadas, o registro vai para store_dead_letters.
+	StoreAttempts int
+	StoreBackoff  time.Duration
+
+	// Units é o diretório de unidades exibido no atendimento financeiro (UNITS_JSON).
+	Units []BusinessUnit
+
+	// EscalationTranscript guarda o histórico do suporte técnico (problema, sugestões e respostas) e o anexa ao
+	// chamado encaminhado ao técnico humano (ESCALATION_TRANSCRIPT).
+	EscalationTranscript bool
+
+	// ContactTimeEnabled pergunta, após o telefone, o melhor horário para contato (manhã/tarde/noite),
+	// gravado em uma coluna extra da aba de planos (CONTACT_TIME_ENABLED).
+	ContactTimeEnabled bool
+
+	// CustomerLookupEnabled consulta o telefone do WhatsApp no cadastro de clientes (CustomerLookup) ao abrir o
+	// fluxo de planos, trocando a pergunta "já é cliente?" pela confirmação do cadastro encontrado.
+	CustomerLookupEnabled bool
+
+	// Location é o fuso horário do serviço (APP_TIMEZONE, ou TZ), usado nos relatórios, agendamentos e datas do Sheets.
+	Location *time.Location
+
+	// DailyReportEnabled envia todo dia, em DailyReportTime (HH:MM no fuso do serviço), o resumo do atendimento
+	// para DailyReportWebhookURL e/ou DailyReportEmails (via SMTP_ADDR, SMTP_FROM, SMTP_USER, SMTP_PASSWORD).
+	DailyReportEnabled    bool
+	DailyReportTime       string
+	DailyReportWebhookURL string
+	DailyReportEmails     []string
+	SMTPAddr              string
+	SMTPFrom              string
+	SMTPUser              string
+	SMTPPassword          string
+
+	// NotifyAttempts e NotifyBackoff controlam as tentativas de entrega dos avisos (NOTIFY_ATTEMPTS, NOTIFY_BACKOFF,
+	// dobrado a cada falha); esgotadas, o aviso vai para a tabela notification_dead_letters.
+	NotifyAttempts int
+	NotifyBackoff  time.Duration
+
+	// NotifyEscalations avisa os notificadores a cada chamado encaminhado a um técnico humano (NOTIFY_ESCALATIONS).
+	NotifyEscalations bool
+
+	// DBRetryAttempts e DBRetryBackoff controlam as novas tentativas das gravações no banco local em erros
+	// passageiros, como banco travado ou conexão derrubada (DB_RETRY_ATTEMPTS, DB_RETRY_BACKOFF, dobrado a cada falha).
+	DBRetryAttempts int
+	DBRetryBackoff  time.Duration
+
+	// PeakHours são os intervalos diários de alta demanda (PEAK_HOURS, ex.: "18:00-21:00"), no fuso do serviço.
+	// Neles, o prazo das mensagens de encerramento do lead e do encaminhamento é substituído por PeakHoursMessage (PEAK_HOURS_MESSAGE).
+	PeakHours        []ClockRange
+	PeakHoursMessage string
+}
+
+// LoadConfig carrega a configuração do serviço a partir das variáveis de ambiente.
+func LoadConfig() Config {
+	cfg := Config{
+		GreetingKeywords:   defaultGreetingKeywords,
+		MainMenuMessage:    defaultMainMenuMessage,
+		OnboardingMessage:  defaultOnboardingMessage,
+		SeenTTL:            180 * 24 * time.Hour,
+		IdleTimeout:        10 * time.Minute,
+		MaxSessionLifetime: 24 * time.Hour,
+		ReminderEnabled:    true,
+		ReminderFraction:   0.7,
+		ReminderMessage:    "⏳ Ainda está aí? Sua sessão vai expirar em breve por inatividade. Responda qualquer mensagem para continuar.",
+		SweepInterval:      30 * time.Second,
+		RetentionMessage:   "Que tal *100MB a mais pelo mesmo valor*?",
+		SupportPhone:       "(44) 3643-1736",
+		Units:              defaultUnits,
+		MaxInvalidInputs:   3,
+		NameCleanup:        true,
+		InputPreprocessors: defaultPreprocessors,
+
+		LeadFollowupDelay:    2 * time.Hour,
+		LeadFollowupCooldown: 7 * 24 * time.Hour,
+		LeadFollowupMessage:  "👋 Olá{nome}! Ainda tem interesse no plano *{plano}*? Continuamos de onde paramos:",
+
+		Language: "pt",
+
+		AIPersona: defaultAIPersona,
+		AITone:    defaultAITone,
+
+		DeniedMessage: defaultDeniedMessage,
+		NPSQuestion:   defaultNPSQuestion,
+		NPSThanks:     defaultNPSThanks,
+
+		SheetsWorkers:        4,
+		SheetsQueueSize:      100,
+		SheetsReplayInterval: time.Minute,
+		SheetsMaxAttempts:    5,
+		SheetsQuotaBackoff:   time.Minute,
+
+		RedisHeartbeatInterval: 10 * time.Second,
+		RedisRetries:           2,
+		RedisRetryBackoff:      50 * time.Millisecond,
+		RedisBreakerThreshold:  5,
+		RedisBreakerCooldown:   30 * time.Second,
+		ReplyReplayInterval:    time.Minute,
+		StoreAttempts:          3,
+		StoreBackoff:           time.Second,
+
+		DailyReportTime:      "19:00",
+		PeakHoursMessage:     defaultPeakHoursMessage,
+		NotifyAttempts:       3,
+		DBRetryAttempts:      3,
+		DBRetryBackoff:       100 * time.Millisecond,
+		NotifyBackoff:        2 * time.Second,
+		EscalationTranscript: true,
+
+		ModerationKeywords:  defaultModerationKeywords,
+		ModerationMessage:   defaultModerationMessage,
+		AIInputMaxChars:     500,
+		AIInputPolicy:       AIInputReject,
+		AIPromptMaxChars:    6000,
+		AILimits:            ai.DefaultLimits(),
+		AIResponseBudget:    25 * time.Second,
+		AIMaxConcurrent:     10,
+		AIOverflowPolicy:    AIOverflowQueue,
+		AIQueueWait:         2 * time.Second,
+		AIFallbackWindow:    5 * time.Minute,
+		AIProbeInterval:     30 * time.Second,
+		AIUnavailableNotice: defaultAIUnavailableNotice,
+		SessionTTLs: SessionTTLs{
+			State:       time.Hour,
+			Data:        time.Hour,
+			Dedupe:      24 * time.Hour,
+			Idempotency: 24 * time.Hour,
+			History:     24 * time.Hour,
+		},
+		MaintenanceMessage: "🛠️ *Estamos em manutenção*\n\nVolte em instantes, por favor. Agradecemos a compreensão!",
+	}
+	if v := os.Getenv("GREETING_KEYWORDS"); v != "" {
+		if list := splitList(v); len(list) > 0 {
+			cfg.GreetingKeywords = list
+		}
+	}
+	if v := os.Getenv("MAIN_MENU_MESSAGE"); v != "" {
+		cfg.MainMenuMessage = v
+	}
+	if v := os.Getenv("ONBOARDING_MESSAGE"); v != "" {
+		cfg.OnboardingMessage = v
+	}
+	cfg.SeenTTL = envDuration("SEEN_TTL", cfg.SeenTTL)
+	cfg.IdleTimeout = envDuration("SESSION_IDLE_TIMEOUT", cfg.IdleTimeout)
+	if v := os.Getenv("SESSION_MAX_LIFETIME"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.MaxSessionLifetime = d
+		}
+	}
+	cfg.ReminderEnabled = envBool("INACTIVITY_REMINDER_ENABLED", cfg.ReminderEnabled)
+	if v := os.Getenv("INACTIVITY_REMINDER_FRACTION"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 && f < 1 {
+			cfg.ReminderFraction = f
+		}
+	}
+	if v := os.Getenv("INACTIVITY_REMINDER_MESSAGE"); v != "" {
+		cfg.ReminderMessage = v
+	}
+	cfg.SweepInterval = envDuration("INACTIVITY_SWEEP_INTERVAL", cfg.SweepInterval)
+	cfg.RetentionEnabled = envBool("RETENTION_OFFER_ENABLED", cfg.RetentionEnabled)
+	if v := os.Getenv("RETENTION_OFFER_MESSAGE"); v != "" {
+		cfg.RetentionMessage = v
+	}
+	cfg.MaintenanceMode = envBool("MAINTENANCE_MODE", cfg.MaintenanceMode)
+	cfg.AIEnabled = envBool("FEATURE_AI", true)
+	cfg.SheetsEnabled = envBool("FEATURE_SHEETS", true)
+	cfg.WhatsAppEnabled = envBool("FEATURE_WHATSAPP", true)
+	if v := os.Getenv("SUPPORT_PHONE"); v != "" {
+		cfg.SupportPhone = v
+	}
+	cfg.SupportEmail = os.Getenv("SUPPORT_EMAIL")
+	cfg.LastResortMessage = os.Getenv("LAST_RESORT_MESSAGE")
+	cfg.SessionTTLs.State = envDuration("SESSION_STATE_TTL", cfg.SessionTTLs.State)
+	cfg.SessionTTLs.Data = envDuration("SESSION_DATA_TTL", cfg.SessionTTLs.Data)
+	cfg.SessionTTLs.History = envDuration("HISTORY_TTL", cfg.SessionTTLs.History)
+	// LEAD_DEDUPE_WINDOW é aceito como sinônimo de DEDUPE_TTL; nos dois, 0 desativa.
+	for _, key := range []string{"LEAD_DEDUPE_WINDOW", "DEDUPE_TTL"} {
+		if v := os.Getenv(key); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+				cfg.SessionTTLs.Dedupe = d
+			}
+		}
+	}
+	if v := os.Getenv("IDEMPOTENCY_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.SessionTTLs.Idempotency = d
+		}
+	}
+	cfg.AIInputMaxChars = envInt("AI_INPUT_MAX_CHARS", cfg.AIInputMaxChars)
+	if v := strings.ToLower(os.Getenv("AI_INPUT_POLICY")); v == AIInputReject || v == AIInputTruncate {
+		cfg.AIInputPolicy = v
+	}
+	if v := os.Getenv("UNITS_JSON"); v != "" {
+		cfg.Units = parseUnits(v)
+	}
+	cfg.SheetsWorkers = envInt("SHEETS_WORKERS", cfg.SheetsWorkers)
+	cfg.SheetsQueueSize = envInt("SHEETS_QUEUE_SIZE", cfg.SheetsQueueSize)
+	cfg.SheetsReplayInterval = envDuration("SHEETS_REPLAY_INTERVAL", cfg.SheetsReplayInterval)
+	cfg.SheetsMaxAttempts = envInt("SHEETS_MAX_ATTEMPTS", cfg.SheetsMaxAttempts)
+	cfg.SheetsQuotaBackoff = envDuration("SHEETS_QUOTA_BACKOFF", cfg.SheetsQuotaBackoff)
+	cfg.ReplyReplayInterval = envDuration("REPLY_REPLAY_INTERVAL", cfg.ReplyReplayInterval)
+	cfg.RedisHeartbeatInterval = envDuration("REDIS_HEARTBEAT_INTERVAL", cfg.RedisHeartbeatInterval)
+	cfg.RedisRetries = envInt("REDIS_RETRIES", cfg.RedisRetries)
+	cfg.RedisRetryBackoff = envDuration("REDIS_RETRY_BACKOFF", cfg.RedisRetryBackoff)
+	cfg.RedisBreakerThreshold = envInt("REDIS_BREAKER_THRESHOLD", cfg.RedisBreakerThreshold)
+	cfg.RedisBreakerCooldown = envDuration("REDIS_BREAKER_COOLDOWN", cfg.RedisBreakerCooldown)
+	cfg.StoreAttempts = envInt("STORE_ATTEMPTS", cfg.StoreAttempts)
+	cfg.StoreBackoff = envDuration("STORE_BACKOFF", cfg.StoreBackoff)
+	cfg.LeadFollowupEnabled = envBool("LEAD_FOLLOWUP_ENABLED", cfg.LeadFollowupEnabled)
+	cfg.LeadFollowupDelay = envDuration("LEAD_FOLLOWUP_DELAY", cfg.LeadFollowupDelay)
+	cfg.LeadFollowupCooldown = envDuration("LEAD_FOLLOWUP_COOLDOWN", cfg.LeadFollowupCooldown)
+	if v := os.Getenv("LEAD_FOLLOWUP_MESSAGE"); v != "" {
+		cfg.LeadFollowupMessage = v
+	}
+	cfg.ProblemKeywords = parseProblemKeywords(os.Getenv("PROBLEM_CATEGORY_KEYWORDS"))
+	cfg.EscalationSLAs = parseCategoryMap("ESCALATION_SLAS", os.Getenv("ESCALATION_SLAS"), defaultEscalationSLAs)
+	cfg.ListStyles = parseListStyles(os.Getenv("LIST_STYLES"))
+	cfg.MaxInvalidInputs = envInt("MAX_INVALID_INPUTS", cfg.MaxInvalidInputs)
+	cfg.NameCleanup = envBool("NAME_CLEANUP", cfg.NameCleanup)
+	if v := os.Getenv("INPUT_PREPROCESSORS"); v != "" {
+		cfg.InputPreprocessors = splitList(v)
+	}
+	cfg.TextRequiredStates = parseTextRequiredStates(os.Getenv("TEXT_REQUIRED_STATES"))
+	cfg.StateTimeouts = parseStateTimeouts(os.Getenv("STATE_TIMEOUTS"))
+	cfg.IntentKeywords = parseIntentKeywords(os.Getenv("AI_INTENT_KEYWORDS"))
+	cfg.ModerationEnabled = envBool("AI_MODERATION_ENABLED", cfg.ModerationEnabled)
+	if v := os.Getenv("AI_MODERATION_KEYWORDS"); v != "" {
+		cfg.ModerationKeywords = splitList(v)
+	}
+	if v := os.Getenv("AI_MODERATION_MESSAGE"); v != "" {
+		cfg.ModerationMessage = v
+	}
+	cfg.AIPromptMaxChars = envInt("AI_PROMPT_MAX_CHARS", cfg.AIPromptMaxChars)
+	cfg.AIMaxConcurrent = envInt("AI_MAX_CONCURRENT", cfg.AIMaxConcurrent)
+	if v := strings.ToLower(os.Getenv("AI_OVERFLOW_POLICY")); v == AIOverflowQueue || v == AIOverflowFallback {
+		cfg.AIOverflowPolicy = v
+	}
+	cfg.AIQueueWait = envDuration("AI_QUEUE_WAIT", cfg.AIQueueWait)
+	cfg.AILimits.Tech = envModeLimit("AI_TECH", cfg.AILimits.Tech)
+	cfg.AILimits.Free = envModeLimit("AI_FREE", cfg.AILimits.Free)
+	cfg.AIResponseBudget = envDuration("AI_RESPONSE_BUDGET", cfg.AIResponseBudget)
+	for prefix, limit := range map[string]ai.ModeLimit{"AI_TECH": cfg.AILimits.Tech, "AI_FREE": cfg.AILimits.Free} {
+		if worst := limit.Timeout * time.Duration(limit.Retries+1); worst > cfg.AIResponseBudget {
+			log.Printf("%s_TIMEOUT × (%s_RETRIES+1) = %s excede AI_RESPONSE_BUDGET (%s); as tentativas excedentes serão cortadas",
+				prefix, prefix, worst, cfg.AIResponseBudget)
+		}
+	}
+	cfg.AIFallbackWindow = envDuration("AI_FALLBACK_WINDOW", cfg.AIFallbackWindow)
+	cfg.AIProbeInterval = envDuration("AI_PROBE_INTERVAL", cfg.AIProbeInterval)
+	if v := os.Getenv("AI_UNAVAILABLE_NOTICE"); v != "" {
+		cfg.AIUnavailableNotice = v
+	}
+	if v := os.Getenv("AI_FALLBACK_ALERT_RATIO"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			cfg.AIFallbackAlertRatio = f
+		}
+	}
+	cfg.AIDisclaimerEnabled = envBool("AI_DISCLAIMER_ENABLED", cfg.AIDisclaimerEnabled)
+	cfg.AIDisclaimers = parseAIDisclaimers(os.Getenv("AI_DISCLAIMERS"))
+	if v := strings.ToLower(os.Getenv("BOT_LANGUAGE")); v != "" {
+		cfg.Language = v
+	}
+	tz := os.Getenv("APP_TIMEZONE")
+	if tz == "" {
+		tz = os.Getenv("TZ")
+	}
+	cfg.Location = loadLocation(tz)
+	cfg.EscalationTranscript = envBool("ESCALATION_TRANSCRIPT", cfg.EscalationTranscript)
+	cfg.ContactTimeEnabled = envBool("CONTACT_TIME_ENABLED", cfg.ContactTimeEnabled)
+	cfg.CustomerLookupEnabled = envBool("CUSTOMER_LOOKUP_ENABLED", cfg.CustomerLookupEnabled)
+	cfg.DailyReportEnabled = envBool("DAILY_REPORT_ENABLED", cfg.DailyReportEnabled)
+	if v := strings.TrimSpace(os.Getenv("DAILY_REPORT_TIME")); v != "" {
+		cfg.DailyReportTime = v
+	}
+	cfg.DailyReportWebhookURL = os.Getenv("DAILY_REPORT_WEBHOOK_URL")
+	cfg.DailyReportEmails = splitList(os.Getenv("DAILY_REPORT_EMAILS"))
+	cfg.SMTPAddr = os.Getenv("SMTP_ADDR")
+	cfg.SMTPFrom = os.Getenv("SMTP_FROM")
+	cfg.SMTPUser = os.Getenv("SMTP_USER")
+	cfg.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	cfg.NotifyAttempts = envInt("NOTIFY_ATTEMPTS", cfg.NotifyAttempts)
+	cfg.NotifyBackoff = envDuration("NOTIFY_BACKOFF", cfg.NotifyBackoff)
+	cfg.NotifyEscalations = envBool("NOTIFY_ESCALATIONS", cfg.NotifyEscalations)
+	cfg.DBRetryAttempts = envInt("DB_RETRY_ATTEMPTS", cfg.DBRetryAttempts)
+	cfg.DBRetryBackoff = envDuration("DB_RETRY_BACKOFF", cfg.DBRetryBackoff)
+	cfg.PeakHours = parsePeakHours(os.Getenv("PEAK_HOURS"))
+	if v := os.Getenv("PEAK_HOURS_MESSAGE"); v != "" {
+		cfg.PeakHoursMessage = v
+	}
+	cfg.NPSEnabled = envBool("NPS_ENABLED", cfg.NPSEnabled)
+	if v := os.Getenv("NPS_QUESTION"); v != "" {
+		cfg.NPSQuestion = v
+	}
+	if v := os.Getenv("NPS_THANKS_MESSAGE"); v != "" {
+		cfg.NPSThanks = v
+	}
+	cfg.Denylist, cfg.DenylistFile = denylistSources()
+	if v := os.Getenv("DENIED_MESSAGE"); v != "" {
+		cfg.DeniedMessage = v
+	}
+	cfg.QuickRepliesEnabled = envBool("QUICK_REPLIES_ENABLED", cfg.QuickRepliesEnabled)
+	cfg.QuickReplies = parseQuickReplies(os.Getenv("QUICK_REPLIES"))
+	if v := strings.TrimSpace(os.Getenv("AI_PERSONA")); v != "" {
+		cfg.AIPersona = v
+	}
+	if v := strings.TrimSpace(os.Getenv("AI_TONE")); v != "" {
+		cfg.AITone = v
+	}
+	cfg.AIFreeTone = strings.TrimSpace(os.Getenv("AI_FREE_TONE"))
+	cfg.PromptGuardEnabled = envBool("AI_PROMPT_GUARD", true)
+	cfg.PromptGuardStripRoleplay = envBool("AI_PROMPT_GUARD_STRIP_ROLEPLAY", false)
+	if v := os.Getenv("MAINTENANCE_MESSAGE"); v != "" {
+		cfg.MaintenanceMessage = v
+	}
+	return cfg
+}
+
+// envDuration lê uma duração (ex.: "10m", "30s") da variável de ambiente, mantendo o padrão se inválida.
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return def
+}
+
+// envModeLimit lê <prefix>_MAX_WORDS, _MAX_CHARS, _TIMEOUT e _RETRIES sobre os limites padrão do modo.
+func envModeLimit(prefix string, def ai.ModeLimit) ai.ModeLimit {
+	return ai.ModeLimit{
+		Words:   envInt(prefix+"_MAX_WORDS", def.Words),
+		Chars:   envInt(prefix+"_MAX_CHARS", def.Chars),
+		Timeout: envDuration(prefix+"_TIMEOUT", def.Timeout),
+		Retries: envInt(prefix+"_RETRIES", def.Retries),
+	}
+}
+
+// envInt lê um inteiro não negativo da variável de ambiente, mantendo o padrão se inválido.
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return def
+}
+
+// envBool lê um booleano (true/false, 1/0) da variável de ambiente, mantendo o padrão se inválido.
+func envBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// splitList separa uma lista de valores delimitada por vírgulas, descartando itens vazios.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// defaultTimezone é o fuso usado quando APP_TIMEZONE não é informado.
+const defaultTimezone = "America/Sao_Paulo"
+
+// loadLocation carrega o fuso horário informado (ou o padrão). Sem a base tz no sistema,
+// cai para UTC-3 fixo, equivalente ao horário de Brasília.
+func loadLocation(name string) *time.Location {
+	if name = strings.TrimSpace(name); name == "" {
+		name = defaultTimezone
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	log.Printf("Fuso horário %q indisponível, usando UTC-3", name)
+	return time.FixedZone("BRT", -3*60*60)
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfigTimezone(t *testing.T) {
+	t.Setenv("APP_TIMEZONE", "")
+	t.Setenv("TZ", "UTC")
+	if loc := LoadConfig().Location; loc.String() != "UTC" {
+		t.Errorf("Location com TZ = %s; esperado UTC", loc)
+	}
+
+	t.Setenv("APP_TIMEZONE", "America/Sao_Paulo")
+	loc := LoadConfig().Location
+	if _, offset := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC).In(loc).Zone(); offset != -3*60*60 {
+		t.Errorf("Location com APP_TIMEZONE = %s (offset %d); esperado o fuso de São Paulo sobre TZ", loc, offset)
+	}
+
+	t.Setenv("APP_TIMEZONE", "Lugar/Inexistente")
+	if _, offset := time.Now().In(LoadConfig().Location).Zone(); offset != -3*60*60 {
+		t.Errorf("fuso inválido com offset %d; esperado UTC-3", offset)
+	}
+}
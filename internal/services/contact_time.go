@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"fmt"
+)
+
+// contactTimeOption é uma janela de contato aceita, com as respostas (normalizadas) que a escolhem.
+type contactTimeOption struct {
+	answers []string
+	label   string
+}
+
+// contactTimeOptions são as janelas oferecidas na etapa de horário preferido, na ordem numerada.
+var contactTimeOptions = []contactTimeOption{
+	{[]string{"1", "manha"}, "Manhã (8h às 12h)"},
+	{[]string{"2", "tarde"}, "Tarde (12h às 18h)"},
+	{[]string{"3", "noite"}, "Noite (18h às 21h)"},
+	{[]string{"4", "qualquer", "qualquer horario"}, "Qualquer horário"},
+}
+
+// contactTimeSkipped é o valor gravado quando o usuário pula a etapa.
+const contactTimeSkipped = "Sem preferência"
+
+// contactTimePrompt é a pergunta da etapa de horário preferido.
+const contactTimePrompt = "🕐 Qual o *melhor horário* para nossa equipe entrar em contato?\n\n1️⃣ Manhã (8h às 12h)\n2️⃣ Tarde (12h às 18h)\n3️⃣ Noite (18h às 21h)\n4️⃣ Qualquer horário\n\nOu digite *PULAR* para seguir sem preferência."
+
+// parseContactTime interpreta a resposta da etapa de horário; ok é falso se não for uma opção nem PULAR.
+func parseContactTime(message string) (label string, ok bool) {
+	cmd := normalizeCommand(message)
+	if cmd == "pular" || cmd == "0" {
+		return contactTimeSkipped, true
+	}
+	for _, opt := range contactTimeOptions {
+		for _, a := range opt.answers {
+			if cmd == a {
+				return opt.label, true
+			}
+		}
+	}
+	return "", false
+}
+
+// askContactTimeOrComplete segue o fluxo de planos após o telefone: pergunta o horário preferido,
+// se a etapa estiver habilitada (CONTACT_TIME_ENABLED), ou registra o interesse direto.
+func (s *ChatbotService) askContactTimeOrComplete(ctx context.Context, userID string) (string, error) {
+	if !s.cfg.ContactTimeEnabled {
+		return s.completePlans(ctx, userID)
+	}
+	s.setState(userID, StatePlansContactTime)
+	return contactTimePrompt, nil
+}
+
+// handlePlansContactTime grava a janela de contato escolhida e registra o interesse.
+func (s *ChatbotService) handlePlansContactTime(ctx context.Context, userID, message string) (string, error) {
+	label, ok := parseContactTime(message)
+	if !ok {
+		return s.invalidInput(userID, "Por favor, escolha uma opção de *1* a *4* ou digite *PULAR*.\n\n"+contactTimePrompt)
+	}
+	userData := s.getUserData(userID)
+	userData.HorarioContato = label
+	s.setUserData(userID, userData)
+	return s.completePlans(ctx, userID)
+}
+
+// completePlans registra o interesse em planos com os dados da sessão e encerra o fluxo.
+func (s *ChatbotService) completePlans(ctx context.Context, userID string) (string, error) {
+	userData := s.getUserData(userID)
+	observacoes := fmt.Sprintf("Interesse em: %s | Plano atual: %s", userData.PlanoDesejado, userData.PlanoAtual)
+	if err := s.savePlans(ctx, userID, userData.Nome, userData.Situacao, userData.PlanoAtual, userData.PlanoDesejado, userData.Telefone, userData.HorarioContato, observacoes); err != nil {
+		return "", fmt.Errorf("erro ao registrar interesse em planos: %w", err)
+	}
+
+	horario := ""
+	if userData.HorarioContato != "" {
+		horario = "\n*Horário para contato*: " + userData.HorarioContato
+	}
+	return s.finishFlow(userID, fmt.Sprintf("🎉 *Dados Registrados com Sucesso!*\n\n*Nome*: %s\n*Situação*: %s\n*Plano Interesse*: %s\n*Telefone*: %s%s\n\n📞 *Próximos Passos*:\n%s\n\nDigite *MENU* para voltar ao menu principal.", userData.Nome, userData.Situacao, userData.PlanoDesejado, userData.Telefone, horario, s.leadContactDeadline()))
+}
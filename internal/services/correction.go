@@ -0,0 +1,125 @@
+package services
+
+import (
+	"strings"
+)
+
+// Campos que podem ser corrigidos com "corrigir <campo>".
+const (
+	fieldNome     = "nome"
+	fieldTelefone = "telefone"
+	fieldPlano    = "plano"
+)
+
+// correctionAliases mapeia os nomes aceitos no comando para o campo correspondente.
+var correctionAliases = map[string]string{
+	"nome":     fieldNome,
+	"name":     fieldNome,
+	"telefone": fieldTelefone,
+	"fone":     fieldTelefone,
+	"celular":  fieldTelefone,
+	"whatsapp": fieldTelefone,
+	"phone":    fieldTelefone,
+	"plano":    fieldPlano,
+	"plan":     fieldPlano,
+}
+
+// correctionPrompts traz a pergunta feita ao usuário para cada campo corrigível; a do plano é montada
+// por correctionPrompt, com a lista no estilo do canal.
+var correctionPrompts = map[string]string{
+	fieldNome:     "✏️ Informe o *nome completo* correto:",
+	fieldTelefone: "✏️ Informe o *telefone/WhatsApp* correto (somente números ou formato (XX) XXXXX-XXXX):",
+}
+
+// correctionPrompt retorna a pergunta do campo para o usuário, com a lista de planos no estilo do seu canal.
+func (s *ChatbotService) correctionPrompt(userID, field string) string {
+	if field == fieldPlano {
+		return "✏️ Qual o plano desejado? Digite o número correspondente:\n" + s.planList(userID, false, true)
+	}
+	return correctionPrompts[field]
+}
+
+// correctionLabels é o nome do campo exibido na confirmação.
+var correctionLabels = map[string]string{
+	fieldNome:     "Nome",
+	fieldTelefone: "Telefone",
+	fieldPlano:    "Plano",
+}
+
+// isCorrectionCommand indica se o comando normalizado é "corrigir" ou "corrigir <campo>".
+func isCorrectionCommand(cmd string) bool {
+	return cmd == "corrigir" || strings.HasPrefix(cmd, "corrigir ")
+}
+
+// startCorrection leva o usuário a reinformar um campo, preservando os demais dados e o estado
+// em que estava, para onde volta após a correção.
+func (s *ChatbotService) startCorrection(userID string, state SessionState, cmd string) (string, error) {
+	if !isResumable(state) && state != StateCorrection {
+		return "Não há atendimento em andamento para corrigir. Digite *MENU* para ver as opções.", nil
+	}
+
+	name := strings.TrimSpace(strings.TrimPrefix(cmd, "corrigir"))
+	field, ok := correctionAliases[name]
+	if !ok {
+		return "❓ Não reconheci o campo. Você pode corrigir: *nome*, *telefone* ou *plano*.\n\nEx.: *CORRIGIR NOME*", nil
+	}
+
+	userData := s.getUserData(userID)
+	userData.CorrigindoCampo = field
+	if state != StateCorrection {
+		userData.EstadoAnterior = state
+	}
+	s.setUserData(userID, userData)
+	s.setState(userID, StateCorrection)
+	return s.correctionPrompt(userID, field), nil
+}
+
+// handleCorrection grava o novo valor do campo e retoma o fluxo no estado anterior. Sem campo em
+// correção (sessão perdida ou gravada pela metade), apenas retoma o fluxo anterior.
+func (s *ChatbotService) handleCorrection(userID, message string) (string, error) {
+	userData := s.getUserData(userID)
+	if _, ok := correctionLabels[userData.CorrigindoCampo]; !ok {
+		previous := userData.EstadoAnterior
+		userData.CorrigindoCampo = ""
+		userData.EstadoAnterior = ""
+		s.setUserData(userID, userData)
+		s.setState(userID, previous)
+		return s.resumeFlow(userID, previous)
+	}
+	value := message
+	if value == "" {
+		return s.invalidInput(userID, s.correctionPrompt(userID, userData.CorrigindoCampo))
+	}
+
+	switch userData.CorrigindoCampo {
+	case fieldNome:
+		if value = s.collectedName(value); value == "" {
+			return s.invalidInput(userID, invalidNamePrompt)
+		}
+		userData.Nome = value
+	case fieldTelefone:
+		telefone, ok := parseContactPhone(value)
+		if !ok {
+			return s.invalidInput(userID, "Telefone inválido. "+correctionPrompts[fieldTelefone])
+		}
+		userData.Telefone = telefone
+	case fieldPlano:
+		if idx := planIndex(value); idx != -1 {
+			value = planOptions[idx]
+		}
+		userData.PlanoDesejado = value
+	}
+
+	label := correctionLabels[userData.CorrigindoCampo]
+	previous := userData.EstadoAnterior
+	userData.CorrigindoCampo = ""
+	userData.EstadoAnterior = ""
+	s.setUserData(userID, userData)
+	s.setState(userID, previous)
+
+	resumed, err := s.resumeFlow(userID, previous)
+	if err != nil {
+		return "", err
+	}
+	return "✅ *" + label + " corrigido!*\n\n" + resumed, nil
+}
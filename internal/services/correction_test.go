@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCorrectionPlanPromptUsesChannelStyle(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	// Sem Redis o canal da sessão é vazio; o estilo é configurado para ele.
+	s.render.reload(displaySettings{listStyles: map[string]string{"": "emoji"}})
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.setState(user, StatePlansPhone)
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "corrigir plano")
+	if !strings.Contains(response, emojiNumber(1)) || strings.Contains(response, "[1]") {
+		t.Errorf("pergunta do plano fora do estilo do canal: %q", response)
+	}
+}
+
+func TestCorrectionRejectsInvalidPhone(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.setState(user, StatePlansPhone)
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "corrigir telefone")
+	for _, invalid := range []string{"1234", "5544999998888123456"} {
+		response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, invalid)
+		if !strings.HasPrefix(response, "Telefone inválido.") {
+			t.Errorf("%q aceito na correção: %q", invalid, response)
+		}
+	}
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "(44) 99999-8888")
+	if got := s.getUserData(user).Telefone; got != "(44)99999-8888" {
+		t.Errorf("Telefone = %q", got)
+	}
+}
+
+func TestPlansPhoneUsesSameValidator(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.setState(user, StatePlansPhone)
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "1234")
+	if !strings.HasPrefix(response, "Telefone inválido.") {
+		t.Errorf("telefone curto aceito no fluxo de planos: %q", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StatePlansPhone {
+		t.Errorf("estado = %q; esperado continuar pedindo o telefone", state)
+	}
+}
+
+func TestCorrectionWithoutFieldResumesPreviousState(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.setUserData(user, UserData{Nome: "Ana", EstadoAnterior: StatePlansPhone})
+	s.setState(user, StateCorrection)
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "qualquer coisa")
+	if !strings.Contains(response, "Retomando") {
+		t.Errorf("resposta = %q; esperado retomar o fluxo anterior", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StatePlansPhone {
+		t.Errorf("estado = %q; esperado voltar a %q", state, StatePlansPhone)
+	}
+	if got := s.getUserData(user).Nome; got != "Ana" {
+		t.Errorf("Nome = %q; os dados coletados devem ser mantidos", got)
+	}
+}
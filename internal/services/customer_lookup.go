@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// customerLookupTimeout limita a consulta de cliente para não atrasar a resposta do menu.
+const customerLookupTimeout = 2 * time.Second
+
+// Customer é o cadastro de cliente encontrado para um telefone. Plano é opcional.
+type Customer struct {
+	Nome  string
+	Plano string
+}
+
+// CustomerLookup identifica clientes atuais pelo telefone (somente dígitos, com código do país).
+// found é falso quando o telefone não pertence a um cliente.
+type CustomerLookup interface {
+	LookupCustomer(ctx context.Context, phone string) (customer Customer, found bool, err error)
+}
+
+// DBCustomerLookup consulta a tabela leads do banco local, considerando clientes os registros com tipo 'Cliente'.
+// A tabela é alimentada pelo fluxo de planos (recordLead) e pode receber também uma importação do cadastro.
+type DBCustomerLookup struct {
+	DB *sql.DB
+}
+
+// LookupCustomer busca o telefone com e sem o código do país (55), já que os cadastros costumam omiti-lo.
+func (l DBCustomerLookup) LookupCustomer(ctx context.Context, phone string) (Customer, bool, error) {
+	local := strings.TrimPrefix(phone, "55")
+	var c Customer
+	err := l.DB.QueryRowContext(ctx,
+		`SELECT nome, COALESCE(plano, '') FROM leads WHERE tipo = 'Cliente' AND telefone IN (?, ?) ORDER BY created_at DESC LIMIT 1`,
+		phone, local,
+	).Scan(&c.Nome, &c.Plano)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Customer{}, false, nil
+	}
+	if err != nil {
+		return Customer{}, false, err
+	}
+	return c, true, nil
+}
+
+// situacaoClienteAtual é a situação gravada quando o usuário confirma que já é cliente.
+const situacaoClienteAtual = "Cliente Atual"
+
+// recordLead guarda o contato do fluxo de planos na tabela leads, consultada por DBCustomerLookup: quem se
+// declarou cliente atual fica com tipo 'Cliente' e o plano informado; os demais, com tipo 'Lead'.
+func (s *ChatbotService) recordLead(nome, telefone, situacao, planoAtual string) {
+	if s.writer == nil || onlyDigits(telefone) == "" {
+		return
+	}
+	tipo, plano := "Lead", ""
+	if situacao == situacaoClienteAtual {
+		tipo, plano = "Cliente", planoAtual
+	}
+	s.writer.enqueue("lead",
+		`INSERT INTO leads (nome, telefone, tipo, plano, created_at) VALUES (?, ?, ?, ?, ?)`,
+		nome, onlyDigits(telefone), tipo, plano, s.now().UTC(),
+	)
+}
+
+// SetCustomerLookup registra a consulta de clientes usada no fluxo de planos (CUSTOMER_LOOKUP_ENABLED).
+func (s *ChatbotService) SetCustomerLookup(l CustomerLookup) {
+	s.customers = l
+}
+
+// lookupCustomer procura o cadastro do usuário pelo telefone do canal. Sem consulta configurada,
+// fora do WhatsApp ou em falha, retorna found=false e o fluxo pergunta normalmente.
+func (s *ChatbotService) lookupCustomer(channel, userID string) (Customer, bool) {
+	if !s.cfg.CustomerLookupEnabled || s.customers == nil {
+		return Customer{}, false
+	}
+	phone := extractPhoneFromUserID(userID, channel)
+	if phone == "" {
+		return Customer{}, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), customerLookupTimeout)
+	defer cancel()
+	customer, found, err := s.customers.LookupCustomer(ctx, phone)
+	if err != nil {
+		log.Printf("Erro ao consultar cliente pelo telefone de %s: %v", userID, err)
+		return Customer{}, false
+	}
+	return customer, found
+}
+
+// plansClientCheckPrompt abre o fluxo de planos. Para um cliente identificado pelo telefone, pede só a
+// confirmação do cadastro em vez de perguntar se já é cliente; o plano cadastrado, se houver, fica sugerido.
+func (s *ChatbotService) plansClientCheckPrompt(channel, userID string) string {
+	customer, found := s.lookupCustomer(channel, userID)
+	if !found {
+		return "📋 *Planos e Serviços*\n\nVocê já é cliente QI TELECOM? Responda *SIM* ou *NÃO*.\n\n(Após responder, mostrarei as opções de planos.)"
+	}
+
+	userData := s.getUserData(userID)
+	userData.ClienteIdentificado = true
+	userData.PlanoSugerido = customer.Plano
+	s.setUserData(userID, userData)
+
+	cadastro := "um cadastro de cliente"
+	if customer.Nome != "" {
+		cadastro = fmt.Sprintf("o cadastro de cliente de *%s*", customer.Nome)
+	}
+	return "📋 *Planos e Serviços*\n\n👤 Encontramos " + cadastro + " para este número. Confirma que você já é cliente QI TELECOM? Responda *SIM* ou *NÃO*."
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// fakeLookup é um CustomerLookup com um único cliente cadastrado.
+type fakeLookup struct {
+	phone    string
+	customer Customer
+}
+
+func (f fakeLookup) LookupCustomer(_ context.Context, phone string) (Customer, bool, error) {
+	if phone != f.phone {
+		return Customer{}, false, nil
+	}
+	return f.customer, true, nil
+}
+
+func TestPlansFlowMatchedCustomerSkipsQuestion(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.CustomerLookupEnabled = true })
+	s.SetCustomerLookup(fakeLookup{phone: "5544999998888", customer: Customer{Nome: "Ana Souza", Plano: "QI FIBRA 300"}})
+	ctx := context.Background()
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, "5544999998888", "oi")
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, "5544999998888", "2")
+	if !strings.Contains(response, "Ana Souza") {
+		t.Fatalf("resposta sem a confirmação do cadastro: %q", response)
+	}
+
+	response, _ = s.ProcessMessage(ctx, ChannelWhatsApp, "5544999998888", "sim")
+	if !strings.Contains(response, "QI FIBRA 300") {
+		t.Errorf("resposta sem o plano cadastrado: %q", response)
+	}
+	if state := s.sessions.state(ctx, "5544999998888"); state != StatePlansSelection {
+		t.Errorf("estado = %q; esperado pular a pergunta do plano atual", state)
+	}
+}
+
+func TestPlansFlowUnmatchedCustomerAsks(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.CustomerLookupEnabled = true })
+	s.SetCustomerLookup(fakeLookup{phone: "5544999998888", customer: Customer{Nome: "Ana Souza"}})
+	ctx := context.Background()
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, "5511988887777", "oi")
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, "5511988887777", "2")
+	if !strings.Contains(response, "Você já é cliente") || strings.Contains(response, "Encontramos") {
+		t.Errorf("sem cadastro, deve perguntar se já é cliente: %q", response)
+	}
+}
+
+func TestDBCustomerLookupFindsRecordedClients(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestServiceWith(t, db, &fakeSheets{}, nil, nil)
+
+	s.recordLead("Ana Souza", "(44) 99999-8888", situacaoClienteAtual, "QI FIBRA 300")
+	s.recordLead("Bruno Lima", "(11) 98888-7777", "Novo Cliente", "Nenhum")
+	if err := s.writer.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lookup := DBCustomerLookup{DB: db}
+	customer, found, err := lookup.LookupCustomer(context.Background(), "5544999998888")
+	if err != nil || !found || customer != (Customer{Nome: "Ana Souza", Plano: "QI FIBRA 300"}) {
+		t.Errorf("cliente = %+v, found = %v, err = %v", customer, found, err)
+	}
+	if _, found, _ := lookup.LookupCustomer(context.Background(), "5511988887777"); found {
+		t.Error("lead que não é cliente não deve ser identificado")
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Eventos de analytics usados no relatório diário.
+const (
+	eventTicketOpened     = "ticket_opened"
+	eventTicketResolved   = "ticket_resolved"
+	eventTicketEscalated  = "ticket_escalated"
+	eventLeadCreated      = "lead_created"
+	eventFeedbackReceived = "feedback_received"
+)
+
+// DailyReport resume o atendimento do período [From, To); Date é o dia (fuso do serviço) em que ele termina.
+type DailyReport struct {
+	Date             string         `json:"date"`
+	From             time.Time      `json:"from"`
+	To               time.Time      `json:"to"`
+	Leads            int            `json:"leads"`
+	TicketsOpened    int            `json:"tickets_opened"`
+	TicketsResolved  int            `json:"tickets_resolved"`
+	TicketsEscalated int            `json:"tickets_escalated"`
+	AvgAIAttempts    float64        `json:"avg_ai_attempts"`
+	Feedback         map[string]int `json:"feedback"`
+	NPSResponses     int            `json:"nps_responses"`
+	NPSAverage       float64        `json:"nps_average"`
+}
+
+// location retorna o fuso do serviço (APP_TIMEZONE).
+func (s *ChatbotService) location() *time.Location {
+	if s.cfg.Location == nil {
+		return loadLocation("")
+	}
+	return s.cfg.Location
+}
+
+// dayBounds retorna o intervalo [início, fim) do dia de t no fuso do serviço.
+func (s *ChatbotService) dayBounds(t time.Time) (time.Time, time.Time) {
+	loc := s.location()
+	local := t.In(loc)
+	from := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return from, from.AddDate(0, 0, 1)
+}
+
+// BuildDailyReport agrega os eventos e notas NPS do dia de calendário de day (no fuso do serviço).
+func (s *ChatbotService) BuildDailyReport(day time.Time) (DailyReport, error) {
+	from, to := s.dayBounds(day)
+	return s.buildReport(from, to)
+}
+
+// buildReport agrega os eventos e notas NPS registrados em [from, to).
+func (s *ChatbotService) buildReport(from, to time.Time) (DailyReport, error) {
+	loc := s.location()
+	report := DailyReport{
+		Date:     to.Add(-time.Nanosecond).In(loc).Format("2006-01-02"),
+		From:     from.In(loc),
+		To:       to.In(loc),
+		Feedback: make(map[string]int),
+	}
+	if s.db == nil {
+		return report, fmt.Errorf("banco local não configurado")
+	}
+
+	rows, err := s.db.Query(
+		`SELECT event_type, option FROM analytics_events
+		WHERE event_type IN (?, ?, ?, ?, ?) AND created_at >= ? AND created_at < ?`,
+		eventTicketOpened, eventTicketResolved, eventTicketEscalated, eventLeadCreated, eventFeedbackReceived,
+		from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return report, fmt.Errorf("erro ao consultar eventos do período: %w", err)
+	}
+	defer rows.Close()
+
+	attempts, closed := 0, 0
+	for rows.Next() {
+		var eventType, option string
+		if err := rows.Scan(&eventType, &option); err != nil {
+			return report, err
+		}
+		switch eventType {
+		case eventTicketOpened:
+			report.TicketsOpened++
+		case eventTicketResolved, eventTicketEscalated:
+			if eventType == eventTicketResolved {
+				report.TicketsResolved++
+			} else {
+				report.TicketsEscalated++
+			}
+			if n, err := strconv.Atoi(option); err == nil {
+				attempts += n
+				closed++
+			}
+		case eventLeadCreated:
+			report.Leads++
+		case eventFeedbackReceived:
+			report.Feedback[feedbackBucket(option)]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+	if closed > 0 {
+		report.AvgAIAttempts = float64(attempts) / float64(closed)
+	}
+
+	var avg *float64
+	err = s.db.QueryRow(
+		`SELECT COUNT(*), AVG(nota) FROM nps_scores WHERE created_at >= ? AND created_at < ?`,
+		from.UTC(), to.UTC(),
+	).Scan(&report.NPSResponses, &avg)
+	if err != nil {
+		return report, fmt.Errorf("erro ao consultar NPS do período: %w", err)
+	}
+	if avg != nil {
+		report.NPSAverage = *avg
+	}
+	return report, nil
+}
+
+// feedbackBucket normaliza a avaliação livre ("excelente!!", "Bom") para agrupamento no relatório.
+func feedbackBucket(feedback string) string {
+	b := strings.Trim(normalizeCommand(feedback), " .!")
+	if b == "" {
+		return "(vazio)"
+	}
+	return b
+}
+
+// renderDailyReport formata o relatório para envio por webhook ou e-mail.
+func renderDailyReport(r DailyReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📅 Período: %s a %s\n\n", r.From.Format("02/01 15:04"), r.To.Format("02/01 15:04"))
+	fmt.Fprintf(&b, "📋 Leads de planos: %d\n", r.Leads)
+	fmt.Fprintf(&b, "🔧 Chamados abertos: %d\n", r.TicketsOpened)
+	fmt.Fprintf(&b, "✅ Resolvidos pela IA: %d\n", r.TicketsResolved)
+	fmt.Fprintf(&b, "🚨 Encaminhados a técnicos: %d\n", r.TicketsEscalated)
+	fmt.Fprintf(&b, "🤖 Média de tentativas da IA: %.1f\n", r.AvgAIAttempts)
+	if r.NPSResponses > 0 {
+		fmt.Fprintf(&b, "📊 NPS: média %.1f (%d respostas)\n", r.NPSAverage, r.NPSResponses)
+	}
+	if len(r.Feedback) > 0 {
+		keys := make([]string, 0, len(r.Feedback))
+		for k := range r.Feedback {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if r.Feedback[keys[i]] != r.Feedback[keys[j]] {
+				return r.Feedback[keys[i]] > r.Feedback[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+		b.WriteString("\n💭 Avaliações:\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "- %s: %d\n", k, r.Feedback[k])
+		}
+	}
+	return b.String()
+}
+
+// nextReportRun retorna o próximo horário DailyReportTime (HH:MM, fuso do serviço) após now.
+func (s *ChatbotService) nextReportRun(now time.Time) time.Time {
+	hour, minute, ok := parseClock(s.cfg.DailyReportTime)
+	if !ok {
+		hour, minute = 19, 0
+	}
+	loc := s.location()
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// StartDailyReport envia, todo dia em DailyReportTime, o resumo das 24h anteriores aos notificadores registrados.
+func (s *ChatbotService) StartDailyReport(ctx context.Context) {
+	if !s.cfg.DailyReportEnabled || len(s.notifiers) == 0 {
+		return
+	}
+	go func() {
+		for {
+			run := s.nextReportRun(s.now())
+			timer := time.NewTimer(time.Until(run))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.sendDailyReport(run)
+			}
+		}
+	}()
+}
+
+// reportWindow retorna o período coberto pelo envio agendado para run: o dia anterior até o mesmo horário,
+// de modo que envios consecutivos cobrem todo o tempo sem lacunas nem sobreposição, mesmo se o timer atrasar.
+func (s *ChatbotService) reportWindow(run time.Time) (time.Time, time.Time) {
+	local := run.In(s.location())
+	return local.AddDate(0, 0, -1), local
+}
+
+// sendDailyReport monta o relatório do período que termina em run e o entrega a cada notificador
+// (com novas tentativas e dead-letter).
+func (s *ChatbotService) sendDailyReport(run time.Time) {
+	report, err := s.buildReport(s.reportWindow(run))
+	if err != nil {
+		log.Printf("Erro ao montar relatório diário: %v", err)
+		return
+	}
+	subject := "QI TELECOM | Resumo do atendimento " + report.Date
+	body := renderDailyReport(report)
+	s.notify(subject, body)
+}
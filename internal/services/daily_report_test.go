@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyReportCoversTheDayBeforeTheRun(t *testing.T) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("fuso America/Sao_Paulo indisponível")
+	}
+	db := newTestDB(t)
+	s := newTestServiceWith(t, db, nil, nil, func(cfg *Config) {
+		cfg.Location = loc
+		cfg.DailyReportTime = "19:00"
+	})
+
+	run := s.nextReportRun(time.Date(2026, 3, 10, 12, 0, 0, 0, loc))
+	if want := time.Date(2026, 3, 10, 19, 0, 0, 0, loc); !run.Equal(want) {
+		t.Fatalf("próximo envio = %s, quer %s", run, want)
+	}
+	for _, at := range []time.Time{
+		time.Date(2026, 3, 9, 18, 59, 0, 0, loc), // antes do período
+		time.Date(2026, 3, 9, 19, 0, 0, 0, loc),  // início do período
+		time.Date(2026, 3, 10, 8, 0, 0, 0, loc),  // manhã do dia do envio
+		time.Date(2026, 3, 10, 18, 59, 0, 0, loc),
+		time.Date(2026, 3, 10, 19, 0, 0, 0, loc), // já pertence ao próximo relatório
+	} {
+		if _, err := db.Exec(`INSERT INTO analytics_events (event_type, option, channel, created_at) VALUES (?, ?, ?, ?)`,
+			eventLeadCreated, "500 MEGA", ChannelWeb, at.UTC()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := s.buildReport(s.reportWindow(run))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Leads != 3 {
+		t.Errorf("leads = %d, quer 3 (de 09/03 19:00 até 10/03 19:00)", report.Leads)
+	}
+	if report.Date != "2026-03-10" {
+		t.Errorf("Date = %q, quer 2026-03-10", report.Date)
+	}
+
+	next, err := s.buildReport(s.reportWindow(run.AddDate(0, 0, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Leads != 1 {
+		t.Errorf("relatório seguinte: leads = %d, quer 1, sem lacuna nem sobreposição", next.Leads)
+	}
+}
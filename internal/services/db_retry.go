@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// isTransientDBError indica se o erro de banco é passageiro: o SQLite ocupado ou travado por outra
+// conexão (SQLITE_BUSY, SQLITE_LOCKED) ou uma conexão derrubada. Erros permanentes, como violação de
+// constraint ou SQL inválido, não são repetidos.
+func isTransientDBError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var se sqlite3.Error
+	if errors.As(err, &se) {
+		return se.Code == sqlite3.ErrBusy || se.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+// maxDBRetryBackoff limita a espera entre tentativas, que dobra a cada falha.
+const maxDBRetryBackoff = time.Second
+
+// retryDB executa op até attempts vezes, dobrando backoff (até maxDBRetryBackoff) entre as tentativas,
+// enquanto o erro for passageiro. Com ctx encerrado para de esperar e retorna o último erro. Retorna também
+// quantas tentativas foram feitas.
+func retryDB(ctx context.Context, attempts int, backoff time.Duration, op func() error) (tries int, err error) {
+	if attempts < 1 {
+		attempts = 1
+	}
+	for tries = 1; ; tries++ {
+		err = op()
+		if err == nil || tries >= attempts || !isTransientDBError(err) {
+			return tries, err
+		}
+		select {
+		case <-ctx.Done():
+			return tries, err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxDBRetryBackoff {
+			backoff = maxDBRetryBackoff
+		}
+	}
+}
+
+// execDB executa a instrução no banco local fora do asyncWriter, com as mesmas novas tentativas em erros
+// passageiros (DB_RETRY_ATTEMPTS, DB_RETRY_BACKOFF). Sem contexto, a espera total é limitada só pelo teto
+// do backoff.
+func (s *ChatbotService) execDB(query string, args ...interface{}) error {
+	_, err := retryDB(context.Background(), s.cfg.DBRetryAttempts, s.cfg.DBRetryBackoff, func() error {
+		_, err := s.db.Exec(query, args...)
+		return err
+	})
+	return err
+}
+
+// queryDB é o equivalente de execDB para consultas.
+func (s *ChatbotService) queryDB(query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	_, err := retryDB(context.Background(), s.cfg.DBRetryAttempts, s.cfg.DBRetryBackoff, func() error {
+		var err error
+		rows, err = s.db.Query(query, args...)
+		return err
+	})
+	return rows, err
+}
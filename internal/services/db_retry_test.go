@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestIsTransientDBError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"busy", sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{"locked", sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{"busy embrulhado", fmt.Errorf("erro ao gravar: %w", sqlite3.Error{Code: sqlite3.ErrBusy}), true},
+		{"conexão derrubada", driver.ErrBadConn, true},
+		{"constraint", sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{"mensagem parecida sem código", errors.New("database is locked"), false},
+		{"nil", nil, false},
+	}
+	for _, c := range cases {
+		if got := isTransientDBError(c.err); got != c.want {
+			t.Errorf("%s: isTransientDBError = %v; esperado %v", c.name, got, c.want)
+		}
+	}
+}
+
+// flakyExec é um exec de asyncWriter que falha com errs em sequência e depois grava.
+type flakyExec struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyExec) exec(string, ...interface{}) error {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return f.errs[f.calls-1]
+	}
+	return nil
+}
+
+func runAsyncWriter(t *testing.T, exec func(string, ...interface{}) error) {
+	t.Helper()
+	w := &asyncWriter{exec: exec, queue: make(chan dbJob, 1), attempts: 3, backoff: time.Millisecond, done: make(chan struct{})}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	go w.run()
+	if err := w.enqueue("teste", "INSERT INTO t VALUES (?)", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsyncWriterRetriesBusyDatabase(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	f := &flakyExec{errs: []error{busy, sqlite3.Error{Code: sqlite3.ErrLocked}}}
+	runAsyncWriter(t, f.exec)
+	if f.calls != 3 {
+		t.Errorf("calls = %d; esperado gravar na terceira tentativa", f.calls)
+	}
+}
+
+func TestAsyncWriterStopsAfterAttempts(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	f := &flakyExec{errs: []error{busy, busy, busy, busy}}
+	runAsyncWriter(t, f.exec)
+	if f.calls != 3 {
+		t.Errorf("calls = %d; esperado parar em DB_RETRY_ATTEMPTS", f.calls)
+	}
+}
+
+func TestAsyncWriterDoesNotRetryPermanentErrors(t *testing.T) {
+	f := &flakyExec{errs: []error{sqlite3.Error{Code: sqlite3.ErrConstraint}}}
+	runAsyncWriter(t, f.exec)
+	if f.calls != 1 {
+		t.Errorf("calls = %d; violação de constraint não deve ser repetida", f.calls)
+	}
+}
+
+func TestRetryDBStopsWaitingWhenContextEnds(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	tries, err := retryDB(ctx, 5, time.Hour, func() error { return busy })
+	if tries != 1 || !errors.Is(err, busy) {
+		t.Errorf("retryDB = %d, %v; esperado parar após a primeira tentativa", tries, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("retryDB esperou %s com o contexto encerrado", elapsed)
+	}
+}
+
+func TestAsyncWriterDrainDeadlineInterruptsBackoff(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	f := &flakyExec{errs: []error{busy, busy}}
+	w := &asyncWriter{exec: f.exec, queue: make(chan dbJob, 1), attempts: 3, backoff: time.Hour, done: make(chan struct{})}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	go w.run()
+	w.enqueue("teste", "INSERT INTO t VALUES (?)", 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("drain = %v; esperado o prazo esgotado", err)
+	}
+	select {
+	case <-w.done:
+	case <-time.After(time.Second):
+		t.Fatal("o worker continuou esperando o backoff após o prazo do drain")
+	}
+	if f.calls != 1 {
+		t.Errorf("calls = %d; esperado desistir sem novas tentativas após o prazo", f.calls)
+	}
+}
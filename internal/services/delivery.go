@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	deliveryStatusPrefix = "delivery:"
+	deliveryStatusTTL    = 7 * 24 * time.Hour
+)
+
+// RecordDeliveryStatus grava no Redis o último estado de entrega conhecido de uma mensagem enviada.
+func (s *ChatbotService) RecordDeliveryStatus(messageID, status, recipientID string) error {
+	ctx := context.Background()
+	key := deliveryStatusPrefix + messageID
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, key, map[string]interface{}{
+		"status":     status,
+		"recipient":  recipientID,
+		"updated_at": time.Now().Unix(),
+	})
+	pipe.Expire(ctx, key, deliveryStatusTTL)
+	err := s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err })
+	s.updateOutboundStatus(messageID, status)
+	return err
+}
+
+// DeliveryStatus retorna o último estado de entrega registrado para a mensagem.
+func (s *ChatbotService) DeliveryStatus(messageID string) (string, error) {
+	ctx := context.Background()
+	var status string
+	err := s.redisDo(ctx, func() (err error) {
+		status, err = s.redis.HGet(ctx, deliveryStatusPrefix+messageID, "status").Result()
+		return err
+	})
+	return status, err
+}
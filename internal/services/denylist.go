@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// defaultDeniedMessage é a resposta curta enviada a usuários bloqueados.
+const defaultDeniedMessage = "🚫 Seu acesso ao atendimento foi bloqueado."
+
+// denylist guarda os IDs de usuário e telefones bloqueados. As entradas vêm de DENYLIST e,
+// opcionalmente, de DENYLIST_FILE, que pode ser relido sem reiniciar o serviço.
+type denylist struct {
+	mu      sync.RWMutex
+	entries map[string]bool
+	static  []string
+	file    string
+}
+
+// newDenylist monta a lista a partir das entradas fixas e do arquivo, se configurado.
+func newDenylist(static []string, file string) *denylist {
+	d := &denylist{static: static, file: file, entries: make(map[string]bool)}
+	if _, err := d.reload(); err != nil {
+		log.Printf("Erro ao carregar denylist: %v", err)
+	}
+	return d
+}
+
+// denylistSources lê do ambiente as entradas fixas (DENYLIST) e o arquivo (DENYLIST_FILE) da denylist.
+func denylistSources() (static []string, file string) {
+	return splitList(os.Getenv("DENYLIST")), os.Getenv("DENYLIST_FILE")
+}
+
+// setSources troca as entradas fixas e o arquivo usados nas próximas recargas.
+func (d *denylist) setSources(static []string, file string) {
+	d.mu.Lock()
+	d.static, d.file = static, file
+	d.mu.Unlock()
+}
+
+// reload relê o arquivo e substitui as entradas, retornando quantas estão ativas.
+// Em erro de leitura as entradas anteriores são mantidas.
+func (d *denylist) reload() (int, error) {
+	d.mu.RLock()
+	static, file := d.static, d.file
+	d.mu.RUnlock()
+	entries := make(map[string]bool, len(static))
+	for _, e := range static {
+		if e = normalizeDenyEntry(e); e != "" {
+			entries[e] = true
+		}
+	}
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return d.size(), fmt.Errorf("erro ao abrir %s: %w", file, err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line, _, _ := strings.Cut(scanner.Text(), "#")
+			if e := normalizeDenyEntry(line); e != "" {
+				entries[e] = true
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return d.size(), fmt.Errorf("erro ao ler %s: %w", file, err)
+		}
+	}
+
+	d.mu.Lock()
+	d.entries = entries
+	d.mu.Unlock()
+	return len(entries), nil
+}
+
+// size retorna o número de entradas ativas.
+func (d *denylist) size() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.entries)
+}
+
+// contains indica se o usuário está bloqueado.
+func (d *denylist) contains(userID string) bool {
+	key := normalizeDenyEntry(userID)
+	if key == "" {
+		return false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.entries[key]
+}
+
+// normalizeDenyEntry reduz telefones formatados ("+55 (44) 99999-0000") aos dígitos, para casar com o
+// "from" do WhatsApp; demais IDs são comparados como informados.
+func normalizeDenyEntry(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return ""
+	}
+	for _, r := range v {
+		if !isPhoneRune(r) {
+			return v
+		}
+	}
+	return onlyDigits(v)
+}
+
+// DeniedReply indica se o usuário está bloqueado e, nesse caso, a resposta única que ele deve receber.
+// Os canais consultam antes de baixar mídias ou guardar dados do remetente.
+func (s *ChatbotService) DeniedReply(userID string) (string, bool) {
+	if !s.denylist.contains(userID) {
+		return "", false
+	}
+	return s.cfg.DeniedMessage, true
+}
+
+// ReloadDenylist relê do ambiente DENYLIST e DENYLIST_FILE, relê o arquivo e retorna o número de
+// entradas bloqueadas. Após o SIGHUP, reflete as mudanças feitas no .env.
+func (s *ChatbotService) ReloadDenylist() (int, error) {
+	s.denylist.setSources(denylistSources())
+	n, err := s.denylist.reload()
+	if err != nil {
+		return n, err
+	}
+	log.Printf("Denylist recarregada: %d entradas", n)
+	return n, nil
+}
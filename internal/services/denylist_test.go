@@ -0,0 +1,41 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadDenylistRereadsEnvironment(t *testing.T) {
+	t.Setenv("DENYLIST", "")
+	t.Setenv("DENYLIST_FILE", "")
+	s := newTestService(t, nil, nil)
+	t.Setenv("DENYLIST", "5544999990001")
+
+	if s.denylist.contains("5544999990001") {
+		t.Fatal("entrada bloqueada antes da recarga")
+	}
+	if _, err := s.ReloadDenylist(); err != nil {
+		t.Fatalf("ReloadDenylist = %v", err)
+	}
+	if !s.denylist.contains("+55 (44) 99999-0001") {
+		t.Error("DENYLIST alterado não foi aplicado na recarga")
+	}
+
+	file := filepath.Join(t.TempDir(), "denylist.txt")
+	if err := os.WriteFile(file, []byte("# bloqueados\n5544999990002\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DENYLIST", "")
+	t.Setenv("DENYLIST_FILE", file)
+	n, err := s.ReloadDenylist()
+	if err != nil || n != 1 {
+		t.Fatalf("ReloadDenylist = %d, %v; esperado 1 entrada do arquivo", n, err)
+	}
+	if s.denylist.contains("5544999990001") {
+		t.Error("entrada removida de DENYLIST continua bloqueada")
+	}
+	if !s.denylist.contains("5544999990002") {
+		t.Error("entrada do novo DENYLIST_FILE não foi carregada")
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"log"
+	"strings"
+)
+
+// defaultAIDisclaimers é o aviso anexado às respostas geradas pela IA, por idioma.
+var defaultAIDisclaimers = map[string]string{
+	"pt": "ℹ️ _Resposta gerada por inteligência artificial; pode conter imprecisões._",
+	"en": "ℹ️ _This answer was generated by AI and may contain inaccuracies._",
+	"es": "ℹ️ _Respuesta generada por inteligencia artificial; puede contener imprecisiones._",
+}
+
+// parseAIDisclaimers interpreta AI_DISCLAIMERS no formato "idioma=texto;idioma=texto".
+// Idiomas não informados mantêm o aviso padrão.
+func parseAIDisclaimers(v string) map[string]string {
+	disclaimers := make(map[string]string, len(defaultAIDisclaimers))
+	for lang, text := range defaultAIDisclaimers {
+		disclaimers[lang] = text
+	}
+	for _, item := range strings.Split(v, ";") {
+		lang, text, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			if item = strings.TrimSpace(item); item != "" {
+				log.Printf("AI_DISCLAIMERS: entrada inválida %q ignorada", item)
+			}
+			continue
+		}
+		disclaimers[strings.ToLower(strings.TrimSpace(lang))] = strings.TrimSpace(text)
+	}
+	return disclaimers
+}
+
+// aiDisclaimer retorna o aviso no idioma configurado, recorrendo ao português se não houver tradução.
+func (s *ChatbotService) aiDisclaimer() string {
+	if d, ok := s.cfg.AIDisclaimers[s.cfg.Language]; ok {
+		return d
+	}
+	return s.cfg.AIDisclaimers["pt"]
+}
+
+// withDisclaimer anexa o aviso de IA à resposta gerada, uma única vez.
+func (s *ChatbotService) withDisclaimer(response string) string {
+	d := s.aiDisclaimer()
+	if !s.cfg.AIDisclaimerEnabled || d == "" || strings.HasSuffix(strings.TrimSpace(response), d) {
+		return response
+	}
+	return strings.TrimRight(response, "\n ") + "\n\n" + d
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"leadprojectarrumado/internal/ai"
+)
+
+func TestGenerateAIAppendsDisclaimerToModelOutput(t *testing.T) {
+	client := &fakeAI{text: "Reinicie o roteador."}
+	s := newTestService(t, client, func(cfg *Config) { cfg.AIDisclaimerEnabled = true })
+
+	response, err := s.generateAI(context.Background(), "free", func(ctx context.Context) (string, error) { return client.GenerateFreeResponse(ctx, "x") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(response, s.aiDisclaimer()) {
+		t.Errorf("resposta do modelo sem aviso: %q", response)
+	}
+}
+
+func TestGenerateAISkipsDisclaimerOnStaticFallback(t *testing.T) {
+	client := &fakeAI{text: "Assistente temporariamente indisponível.", err: fmt.Errorf("%w: timeout", ai.ErrFallback)}
+	s := newTestService(t, client, func(cfg *Config) { cfg.AIDisclaimerEnabled = true })
+
+	response, err := s.generateAI(context.Background(), "free", func(ctx context.Context) (string, error) { return client.GenerateFreeResponse(ctx, "x") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != client.text {
+		t.Errorf("fallback alterado: %q; esperado o texto estático sem aviso", response)
+	}
+}
+
+func TestWithDisclaimerAppendsOnce(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.AIDisclaimerEnabled = true })
+
+	once := s.withDisclaimer("Resposta.")
+	if twice := s.withDisclaimer(once); twice != once {
+		t.Errorf("aviso duplicado: %q", twice)
+	}
+}
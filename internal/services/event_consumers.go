@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// subscribeConsumers registra os consumidores internos dos eventos de domínio: analytics, backends adicionais
+// (CRM), auditoria de mensagens enviadas e, com NotifyEscalations, o aviso de encaminhamento à equipe.
+func (s *ChatbotService) subscribeConsumers() {
+	s.events.SubscribeAll(s.recordDomainEvent)
+	s.events.SubscribeAll(s.syncStores)
+	s.events.SubscribeAll(s.logOutboundEvent)
+	if s.cfg.NotifyEscalations {
+		s.events.Subscribe(EventTicketEscalated, s.notifyEscalation)
+	}
+}
+
+// recordDomainEvent grava em analytics_events os eventos usados pelo relatório diário.
+func (s *ChatbotService) recordDomainEvent(e Event) {
+	switch e := e.(type) {
+	case LeadCreated:
+		s.recordEvent(eventLeadCreated, e.PlanoDesejado, e.Channel)
+	case TicketEscalated:
+		s.recordEvent(eventTicketEscalated, strconv.Itoa(e.Tentativas), e.Channel)
+	case FeedbackReceived:
+		s.recordEvent(eventFeedbackReceived, e.Feedback, e.Channel)
+	}
+}
+
+// syncStores repassa os leads e feedbacks aos backends adicionais registrados com AddStore.
+func (s *ChatbotService) syncStores(e Event) {
+	ctx := context.Background()
+	switch e := e.(type) {
+	case LeadCreated:
+		s.fanOutPlans(ctx, sheetsPlansRecord{e.Nome, e.Situacao, e.PlanoAtual, e.PlanoDesejado, e.Telefone, e.HorarioContato, e.Observacoes})
+	case FeedbackReceived:
+		s.fanOutFeedback(ctx, sheetsFeedbackRecord{e.Nome, e.TipoAtendimento, e.Feedback, e.Sugestoes})
+	}
+}
+
+// logOutboundEvent grava a auditoria das mensagens enviadas. Inserção e atualização de status passam pela
+// mesma fila, na ordem dos eventos. Como o webhook de status pode chegar antes de MessageSent (publicado só
+// depois que o envio retorna), a inserção já usa o último status registrado por RecordDeliveryStatus.
+func (s *ChatbotService) logOutboundEvent(e Event) {
+	if s.writer == nil {
+		return
+	}
+	switch e := e.(type) {
+	case MessageSent:
+		status := e.Status
+		if e.MessageID != "" {
+			if known, err := s.DeliveryStatus(e.MessageID); err == nil && known != "" {
+				status = known
+			}
+		}
+		s.writer.enqueue("auditoria de mensagem enviada",
+			`INSERT INTO outbound_messages (channel, recipient, message_id, text, status, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			e.Channel, e.Recipient, e.MessageID, e.Text, status, e.At.UTC(),
+		)
+	case MessageStatus:
+		s.writer.enqueue("status de mensagem enviada",
+			`UPDATE outbound_messages SET status = ? WHERE message_id = ?`,
+			e.Status, e.MessageID,
+		)
+	}
+}
+
+// notifyEscalation avisa a equipe sobre o chamado encaminhado a um técnico humano.
+func (s *ChatbotService) notifyEscalation(e Event) {
+	t, ok := e.(TicketEscalated)
+	if !ok {
+		return
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Cliente: %s\nProblema: %s\nCategoria: %s\nPrazo: %s\n", t.Nome, t.Problema, t.Categoria, t.SLA)
+	if t.QueuePosition > 0 {
+		fmt.Fprintf(&body, "Posição na fila: %d\n", t.QueuePosition)
+	}
+	s.notify("Chamado encaminhado ao técnico: "+t.Nome, body.String())
+}
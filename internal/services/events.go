@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Nomes dos eventos de domínio publicados pelo serviço.
+const (
+	EventLeadCreated      = "lead_created"
+	EventTicketEscalated  = "ticket_escalated"
+	EventFeedbackReceived = "feedback_received"
+	EventMessageSent      = "message_sent"
+	EventMessageStatus    = "message_status"
+)
+
+// Event é um evento de domínio entregue aos consumidores do EventBus.
+type Event interface {
+	EventName() string
+}
+
+// LeadCreated é publicado quando um interesse em planos é registrado (duplicatas não geram evento).
+type LeadCreated struct {
+	UserID        string
+	Channel       string
+	Nome          string
+	Situacao      string
+	PlanoAtual    string
+	PlanoDesejado string
+	Telefone      string
+	// HorarioContato é a janela de contato preferida, vazia se a etapa estiver desligada.
+	HorarioContato string
+	Observacoes    string
+	At             time.Time
+}
+
+// TicketEscalated é publicado quando o suporte técnico é encaminhado a um técnico humano.
+type TicketEscalated struct {
+	UserID        string
+	Channel       string
+	Nome          string
+	Problema      string
+	Categoria     string
+	SLA           string
+	Tentativas    int
+	QueuePosition int64
+	// Transcript traz o problema, cada sugestão do bot e as respostas do cliente, para o técnico não repetir orientações.
+	Transcript []TranscriptEntry
+	At         time.Time
+}
+
+// FeedbackReceived é publicado quando o usuário conclui a avaliação do atendimento.
+type FeedbackReceived struct {
+	UserID          string
+	Channel         string
+	Nome            string
+	TipoAtendimento string
+	Feedback        string
+	Sugestoes       string
+	At              time.Time
+}
+
+// MessageSent é publicado a cada mensagem enviada pelo bot, em qualquer canal.
+type MessageSent struct {
+	Channel   string
+	Recipient string
+	MessageID string
+	Text      string
+	Status    string
+	At        time.Time
+}
+
+// MessageStatus é publicado quando o canal informa um novo status de entrega de uma mensagem enviada.
+type MessageStatus struct {
+	MessageID string
+	Status    string
+}
+
+func (LeadCreated) EventName() string      { return EventLeadCreated }
+func (TicketEscalated) EventName() string  { return EventTicketEscalated }
+func (FeedbackReceived) EventName() string { return EventFeedbackReceived }
+func (MessageSent) EventName() string      { return EventMessageSent }
+func (MessageStatus) EventName() string    { return EventMessageStatus }
+
+// EventHandler consome um evento publicado.
+type EventHandler func(Event)
+
+// eventBusBuffer limita quantos eventos podem aguardar entrega em cada consumidor.
+const eventBusBuffer = 256
+
+// subscriber é um consumidor com fila própria, para que um consumidor lento (ex.: CRM fora do ar) não
+// atrase nem descarte os eventos dos demais. name vazio recebe todos os eventos.
+type subscriber struct {
+	name    string
+	handler EventHandler
+	queue   chan Event
+}
+
+// EventBus entrega os eventos de domínio aos consumidores registrados (notificador, analytics, CRM...),
+// cada um em sua goroutine e na ordem de publicação, para que a publicação nunca atrase a resposta ao usuário.
+type EventBus struct {
+	mu     sync.RWMutex
+	subs   []*subscriber
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewEventBus cria o barramento; cada consumidor registrado inicia a própria entrega.
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registra um consumidor para os eventos com o nome informado.
+func (b *EventBus) Subscribe(name string, h EventHandler) {
+	b.subscribe(name, h)
+}
+
+// SubscribeAll registra um consumidor para todos os eventos.
+func (b *EventBus) SubscribeAll(h EventHandler) {
+	b.subscribe("", h)
+}
+
+// subscribe cria a fila do consumidor e a goroutine que a esvazia. Após drain não registra mais nada.
+func (b *EventBus) subscribe(name string, h EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	sub := &subscriber{name: name, handler: h, queue: make(chan Event, eventBusBuffer)}
+	b.subs = append(b.subs, sub)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for e := range sub.queue {
+			deliver(sub.handler, e)
+		}
+	}()
+}
+
+// Publish enfileira o evento para cada consumidor interessado sem bloquear; com a fila de um consumidor
+// cheia (ou o barramento encerrado) o evento é descartado para ele e logado.
+func (b *EventBus) Publish(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		log.Printf("Barramento encerrado, evento %s descartado", e.EventName())
+		return
+	}
+	for _, sub := range b.subs {
+		if sub.name != "" && sub.name != e.EventName() {
+			continue
+		}
+		select {
+		case sub.queue <- e:
+		default:
+			log.Printf("Fila de eventos cheia, evento %s descartado para um consumidor", e.EventName())
+		}
+	}
+}
+
+// drain para de aceitar eventos e aguarda os consumidores processarem os já enfileirados, ou ctx expirar.
+func (b *EventBus) drain(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, sub := range b.subs {
+			close(sub.queue)
+		}
+	}
+	b.mu.Unlock()
+	return waitGroup(ctx, &b.wg)
+}
+
+// deliver chama o consumidor isolando panics, para que um consumidor com falha não derrube os demais.
+func deliver(h EventHandler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Consumidor do evento %s falhou: %v", e.EventName(), r)
+		}
+	}()
+	h(e)
+}
+
+// Events retorna o barramento de eventos do serviço, para registro de consumidores na inicialização.
+func (s *ChatbotService) Events() *EventBus {
+	return s.events
+}
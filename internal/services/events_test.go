@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRecorder guarda os nomes dos eventos recebidos por um consumidor.
+type eventRecorder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (r *eventRecorder) handle(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names = append(r.names, e.EventName())
+}
+
+func (r *eventRecorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.names...)
+}
+
+func TestEventBusDeliversToSubscribersAndDrains(t *testing.T) {
+	b := NewEventBus()
+	var leads, all eventRecorder
+	b.Subscribe(EventLeadCreated, leads.handle)
+	b.SubscribeAll(all.handle)
+
+	b.Publish(LeadCreated{Nome: "Ana"})
+	b.Publish(FeedbackReceived{Nome: "Ana"})
+	if err := b.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := leads.received(); len(got) != 1 || got[0] != EventLeadCreated {
+		t.Errorf("consumidor de leads recebeu %v", got)
+	}
+	if got := all.received(); len(got) != 2 || got[0] != EventLeadCreated || got[1] != EventFeedbackReceived {
+		t.Errorf("consumidor de todos recebeu %v, quer a ordem de publicação", got)
+	}
+}
+
+func TestEventBusSlowSubscriberDoesNotBlockOthers(t *testing.T) {
+	b := NewEventBus()
+	release := make(chan struct{})
+	b.SubscribeAll(func(Event) { <-release })
+	var fast eventRecorder
+	b.SubscribeAll(fast.handle)
+
+	b.Publish(LeadCreated{})
+	deadline := time.Now().Add(time.Second)
+	for len(fast.received()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(fast.received()) != 1 {
+		t.Error("consumidor rápido não recebeu o evento enquanto o lento estava parado")
+	}
+	close(release)
+	b.drain(context.Background())
+}
+
+func TestEventBusDropsAfterDrain(t *testing.T) {
+	b := NewEventBus()
+	var r eventRecorder
+	b.SubscribeAll(r.handle)
+	b.drain(context.Background())
+
+	b.Publish(LeadCreated{})
+	if got := r.received(); len(got) != 0 {
+		t.Errorf("evento publicado após drain foi entregue: %v", got)
+	}
+}
+
+func TestDomainEventsReachAnalyticsAndOutboundLog(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestServiceWith(t, db, nil, nil, nil)
+
+	s.events.Publish(LeadCreated{PlanoDesejado: "500 MEGA", Channel: ChannelWhatsApp})
+	s.events.Publish(TicketEscalated{Tentativas: 5, Channel: ChannelWeb})
+	s.LogOutbound(ChannelWhatsApp, "5544999998888", "wamid.1", "olá", "sent")
+	s.updateOutboundStatus("wamid.1", "delivered")
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := count(t, db, "analytics_events"); got != 2 {
+		t.Errorf("analytics_events = %d, quer 2", got)
+	}
+	var status string
+	if err := db.QueryRow(`SELECT status FROM outbound_messages WHERE message_id = 'wamid.1'`).Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != "delivered" {
+		t.Errorf("status = %q, quer delivered: a atualização deve ser aplicada depois da inserção", status)
+	}
+}
+
+func TestEscalationNotifiesWhenEnabled(t *testing.T) {
+	n := &flakyNotifier{}
+	s := newTestService(t, nil, func(cfg *Config) { cfg.NotifyEscalations = true })
+	s.notifiers = []namedNotifier{{name: "webhook", Notifier: n}}
+
+	s.events.Publish(TicketEscalated{Nome: "Ana", Problema: "sem internet"})
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := n.callCount(); got != 1 {
+		t.Errorf("avisos = %d, quer 1", got)
+	}
+}
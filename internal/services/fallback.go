@@ -0,0 +1,24 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lastResortMessage monta a resposta de "último recurso", usada quando uma dependência falha
+// de forma irrecuperável e o fluxo não consegue continuar. Sempre traz um canal de contato real.
+func (s *ChatbotService) lastResortMessage() string {
+	if s.cfg.LastResortMessage != "" {
+		return s.cfg.LastResortMessage
+	}
+
+	var contato strings.Builder
+	if s.cfg.SupportPhone != "" {
+		fmt.Fprintf(&contato, "\n📞 %s", s.cfg.SupportPhone)
+	}
+	if s.cfg.SupportEmail != "" {
+		fmt.Fprintf(&contato, "\n✉️ %s", s.cfg.SupportEmail)
+	}
+	return "⚠️ *Não conseguimos concluir seu atendimento agora.*\n\nFale diretamente com nossa central:" +
+		contato.String() + "\n\nDigite *MENU* para tentar novamente."
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Subsistemas que podem ser desligados em tempo de execução.
+const (
+	FlagAI       = "ai"
+	FlagSheets   = "sheets"
+	FlagWhatsApp = "whatsapp"
+)
+
+// featureFlags guarda o estado ligado/desligado de cada subsistema.
+type featureFlags struct {
+	mu    sync.RWMutex
+	flags map[string]bool
+}
+
+// newFeatureFlags cria o registro com os valores iniciais vindos da configuração.
+func newFeatureFlags(initial map[string]bool) *featureFlags {
+	f := &featureFlags{flags: make(map[string]bool, len(initial))}
+	for name, enabled := range initial {
+		f.flags[name] = enabled
+	}
+	return f
+}
+
+// enabled indica se o subsistema está ligado. Flags desconhecidas são tratadas como ligadas.
+func (f *featureFlags) enabled(name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	enabled, ok := f.flags[name]
+	return !ok || enabled
+}
+
+// set altera o estado de uma flag existente.
+func (f *featureFlags) set(name string, enabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.flags[name]; !ok {
+		return fmt.Errorf("flag desconhecida: %s", name)
+	}
+	f.flags[name] = enabled
+	return nil
+}
+
+// snapshot retorna uma cópia do estado atual de todas as flags.
+func (f *featureFlags) snapshot() map[string]bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make(map[string]bool, len(f.flags))
+	for name, enabled := range f.flags {
+		out[name] = enabled
+	}
+	return out
+}
+
+// FeatureEnabled indica se o subsistema informado está ligado.
+func (s *ChatbotService) FeatureEnabled(name string) bool {
+	return s.flags.enabled(name)
+}
+
+// FeatureFlags retorna o estado atual de todas as flags.
+func (s *ChatbotService) FeatureFlags() map[string]bool {
+	return s.flags.snapshot()
+}
+
+// SetFeatureFlag liga ou desliga um subsistema. Ao religar o Sheets, os registros
+// enfileirados localmente enquanto ele estava desligado são reenviados.
+func (s *ChatbotService) SetFeatureFlag(name string, enabled bool) error {
+	if err := s.flags.set(name, enabled); err != nil {
+		return err
+	}
+	if name == FlagSheets && enabled {
+		go s.flushSheetsQueue()
+	}
+	return nil
+}
+
+// aiEnabled indica se há cliente de IA e se o subsistema está ligado.
+func (s *ChatbotService) aiEnabled() bool {
+	return s.ai != nil && s.flags.enabled(FlagAI)
+}
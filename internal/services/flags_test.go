@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAIFlagOffUsesStaticAnswer(t *testing.T) {
+	client := &fakeAI{text: "resposta do modelo"}
+	s := newTestService(t, client, nil)
+	if err := s.SetFeatureFlag(FlagAI, false); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := s.handleFreeAI(context.Background(), "u1", "qual a velocidade ideal?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.calls != 0 || strings.Contains(response, client.text) {
+		t.Errorf("IA chamada com a flag desligada: %q", response)
+	}
+}
+
+func TestSheetsFlagOffQueuesLocally(t *testing.T) {
+	db := newTestDB(t)
+	fake := &fakeSheets{}
+	s := newTestServiceWith(t, db, fake, nil, nil)
+	if err := s.SetFeatureFlag(FlagSheets, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.saveSupport(context.Background(), "Ana", "internet", "", "Aberto", ""); err != nil {
+		t.Fatal(err)
+	}
+	s.writer.drain(context.Background())
+	if len(fake.names()) != 0 || count(t, db, "sheets_queue") != 1 {
+		t.Errorf("gravados = %v, fila = %d; esperado só a fila local", fake.names(), count(t, db, "sheets_queue"))
+	}
+}
+
+func TestSheetsFlagOnSendsAndFlushRemovesQueued(t *testing.T) {
+	db := newTestDB(t)
+	fake := &fakeSheets{}
+	s := newTestServiceWith(t, db, fake, nil, nil)
+
+	s.queueSheets(sheetsKindSupport, sheetsSupportRecord{Nome: "Ana"})
+	s.writer.drain(context.Background())
+	s.flushSheetsQueue()
+
+	if got := fake.names(); len(got) != 1 || count(t, db, "sheets_queue") != 0 {
+		t.Errorf("gravados = %v, fila = %d; esperado o registro enviado e removido", got, count(t, db, "sheets_queue"))
+	}
+}
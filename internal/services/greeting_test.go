@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDefaultGreetingsIgnoreCaseAndAccents(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	for _, msg := range []string{"oi", "Olá!", "BOM DIA", "  boa   noite ", "Começar", "inicio"} {
+		if !s.isGreeting(normalizeCommand(msg)) {
+			t.Errorf("%q não reconhecida como saudação", msg)
+		}
+	}
+	for _, msg := range []string{"oi tudo bem com voce", "1", "boleto"} {
+		if s.isGreeting(normalizeCommand(msg)) {
+			t.Errorf("%q reconhecida como saudação", msg)
+		}
+	}
+}
+
+func TestGreetingKeywordsFromEnvReplaceDefaults(t *testing.T) {
+	t.Setenv("GREETING_KEYWORDS", "E aí, salve , ")
+	cfg := LoadConfig()
+	if len(cfg.GreetingKeywords) != 2 {
+		t.Fatalf("GreetingKeywords = %q; esperado as duas saudações configuradas", cfg.GreetingKeywords)
+	}
+	s := newTestService(t, nil, func(c *Config) { c.GreetingKeywords = cfg.GreetingKeywords })
+
+	if !s.isGreeting(normalizeCommand("e ai!")) || !s.isGreeting(normalizeCommand("SALVE")) {
+		t.Error("saudações configuradas não reconhecidas")
+	}
+	if s.isGreeting(normalizeCommand("oi")) {
+		t.Error("saudação padrão mantida após GREETING_KEYWORDS")
+	}
+
+	response, err := s.route(context.Background(), ChannelWhatsApp, "5544999998888", "Salve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(response, s.mainMenu("5544999998888")) {
+		t.Errorf("resposta a uma saudação configurada = %q; esperado o menu principal", response)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// handoffQueueKey é um sorted set com os usuários aguardando atendimento, pontuados pelo horário de entrada.
+	handoffQueueKey = "handoff:waiting"
+	handoffUserTTL  = 24 * time.Hour
+)
+
+var (
+	// ErrHandoffUserRequired indica que o atendimento a resolver não informou o usuário.
+	ErrHandoffUserRequired = errors.New("user_id obrigatório para resolver o atendimento")
+	// ErrHandoffNotQueued indica que o usuário informado não está na fila de atendimento humano.
+	ErrHandoffNotQueued = errors.New("usuário não está na fila de atendimento")
+)
+
+// pruneHandoffs descarta da fila os pedidos com mais de handoffUserTTL, que ninguém resolveu.
+func (s *ChatbotService) pruneHandoffs(ctx context.Context, pipe redis.Pipeliner) {
+	pipe.ZRemRangeByScore(ctx, handoffQueueKey, "-inf", "("+strconv.FormatInt(s.now().Add(-handoffUserTTL).Unix(), 10))
+}
+
+// enqueueHandoff coloca o usuário na fila de atendimento humano e retorna sua posição.
+// Pedidos repetidos do mesmo usuário mantêm a posição original em vez de entrar de novo no fim da fila.
+func (s *ChatbotService) enqueueHandoff(userID string) (int64, error) {
+	ctx := context.Background()
+	pipe := s.redis.TxPipeline()
+	s.pruneHandoffs(ctx, pipe)
+	pipe.ZAddNX(ctx, handoffQueueKey, &redis.Z{Score: float64(s.now().Unix()), Member: userID})
+	rank := pipe.ZRank(ctx, handoffQueueKey, userID)
+	if err := s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err }); err != nil {
+		return 0, err
+	}
+	return rank.Val() + 1, nil
+}
+
+// HandoffQueueDepth retorna a quantidade atual de usuários aguardando atendimento humano.
+func (s *ChatbotService) HandoffQueueDepth() (int64, error) {
+	ctx := context.Background()
+	pipe := s.redis.TxPipeline()
+	s.pruneHandoffs(ctx, pipe)
+	depth := pipe.ZCard(ctx, handoffQueueKey)
+	if err := s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err }); err != nil {
+		return 0, err
+	}
+	return depth.Val(), nil
+}
+
+// ResolveHandoff retira o usuário informado da fila e retorna a nova profundidade. A remoção é atômica:
+// um usuário fora da fila (ou já resolvido) retorna ErrHandoffNotQueued sem alterar a contagem.
+func (s *ChatbotService) ResolveHandoff(userID string) (int64, error) {
+	if userID == "" {
+		return 0, ErrHandoffUserRequired
+	}
+	ctx := context.Background()
+	pipe := s.redis.TxPipeline()
+	removed := pipe.ZRem(ctx, handoffQueueKey, userID)
+	depth := pipe.ZCard(ctx, handoffQueueKey)
+	if err := s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err }); err != nil {
+		return 0, err
+	}
+	if removed.Val() == 0 {
+		return depth.Val(), ErrHandoffNotQueued
+	}
+	return depth.Val(), nil
+}
+
+// handleHandoffRequest atende o pedido de "falar com atendente", informando a posição na fila.
+func (s *ChatbotService) handleHandoffRequest(userID string) (string, error) {
+	s.setState(userID, StateMenu)
+
+	position, err := s.enqueueHandoff(userID)
+	if err != nil {
+		return "", fmt.Errorf("erro ao entrar na fila de atendimento: %w", err)
+	}
+	return fmt.Sprintf("👤 *Encaminhando para um atendente*\n\nVocê é o *%dº* da fila. Um atendente falará com você em breve.\n\nDigite *MENU* para voltar ao menu principal.", position), nil
+}
+
+// isHandoffRequest indica se o comando normalizado é um pedido de atendimento humano.
+func isHandoffRequest(cmd string) bool {
+	return cmd == "atendente" || cmd == "falar com atendente"
+}
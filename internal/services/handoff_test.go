@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolveHandoffRejectsEmptyUser(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	if _, err := s.ResolveHandoff(""); !errors.Is(err, ErrHandoffUserRequired) {
+		t.Fatalf("err = %v, quer ErrHandoffUserRequired", err)
+	}
+}
+
+func TestEnqueueHandoffReportsPositionWithoutDuplicates(t *testing.T) {
+	s, mr := newRedisTestService(t, nil, nil)
+	clock := &fixedClock{t: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	s.now = clock.now
+
+	for i, userID := range []string{"5544900000001", "5544900000002", "5544900000003"} {
+		clock.advance(time.Second)
+		pos, err := s.enqueueHandoff(userID)
+		if err != nil {
+			t.Fatalf("enqueueHandoff(%s): %v", userID, err)
+		}
+		if pos != int64(i+1) {
+			t.Errorf("posição de %s = %d; esperado %d", userID, pos, i+1)
+		}
+	}
+
+	// Um novo pedido mantém a posição original (ZADD NX) e não duplica o usuário.
+	clock.advance(time.Minute)
+	if pos, err := s.enqueueHandoff("5544900000001"); err != nil || pos != 1 {
+		t.Errorf("pedido repetido: posição = %d, err = %v; esperado 1", pos, err)
+	}
+	members, _ := mr.ZMembers(handoffQueueKey)
+	if len(members) != 3 {
+		t.Errorf("fila = %v; esperado 3 usuários sem duplicata", members)
+	}
+	if depth, err := s.HandoffQueueDepth(); err != nil || depth != 3 {
+		t.Errorf("HandoffQueueDepth = %d, %v; esperado 3", depth, err)
+	}
+}
+
+func TestHandoffQueuePrunesRequestsOlderThanTTL(t *testing.T) {
+	s, _ := newRedisTestService(t, nil, nil)
+	clock := &fixedClock{t: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	s.now = clock.now
+
+	if _, err := s.enqueueHandoff("5544900000001"); err != nil {
+		t.Fatalf("enqueueHandoff: %v", err)
+	}
+	clock.advance(handoffUserTTL - time.Hour)
+	if _, err := s.enqueueHandoff("5544900000002"); err != nil {
+		t.Fatalf("enqueueHandoff: %v", err)
+	}
+	clock.advance(2 * time.Hour)
+
+	if depth, err := s.HandoffQueueDepth(); err != nil || depth != 1 {
+		t.Errorf("HandoffQueueDepth após 24h = %d, %v; esperado 1 (pedido antigo descartado)", depth, err)
+	}
+	if pos, err := s.enqueueHandoff("5544900000003"); err != nil || pos != 2 {
+		t.Errorf("posição do novo pedido = %d, %v; esperado 2", pos, err)
+	}
+}
+
+func TestResolveHandoffRemovesUser(t *testing.T) {
+	s, _ := newRedisTestService(t, nil, nil)
+	for _, userID := range []string{"5544900000001", "5544900000002"} {
+		if _, err := s.enqueueHandoff(userID); err != nil {
+			t.Fatalf("enqueueHandoff: %v", err)
+		}
+	}
+
+	depth, err := s.ResolveHandoff("5544900000001")
+	if err != nil || depth != 1 {
+		t.Fatalf("ResolveHandoff = %d, %v; esperado 1, nil", depth, err)
+	}
+	if depth, err := s.ResolveHandoff("5544900000001"); !errors.Is(err, ErrHandoffNotQueued) || depth != 1 {
+		t.Errorf("resolver de novo = %d, %v; esperado 1, ErrHandoffNotQueued", depth, err)
+	}
+	if pos, _ := s.enqueueHandoff("5544900000002"); pos != 1 {
+		t.Errorf("posição após a resolução = %d; esperado 1", pos)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// fakeAI é um AIClient controlado pelo teste: devolve text e err em todas as chamadas e guarda o último prompt.
+type fakeAI struct {
+	text   string
+	err    error
+	calls  int
+	prompt string
+}
+
+func (f *fakeAI) GenerateResponse(_ context.Context, prompt string) (string, error) {
+	f.calls++
+	f.prompt = prompt
+	return f.text, f.err
+}
+
+func (f *fakeAI) GenerateFreeResponse(_ context.Context, prompt string) (string, error) {
+	f.calls++
+	f.prompt = prompt
+	return f.text, f.err
+}
+
+// newTestService cria o serviço sem Redis, banco ou Sheets: o Redis aponta para uma porta fechada e o
+// breaker abre na primeira falha, de modo que a sessão fica na memória local. configure ajusta a Config
+// padrão antes da criação.
+func newTestService(t *testing.T, aiClient AIClient, configure func(*Config)) *ChatbotService {
+	t.Helper()
+	return newTestServiceWith(t, nil, nil, aiClient, configure)
+}
+
+// newTestServiceWith é newTestService com banco e Sheets informados (nil desliga cada um).
+func newTestServiceWith(t *testing.T, db *sql.DB, sheets SheetsClient, aiClient AIClient, configure func(*Config)) *ChatbotService {
+	t.Helper()
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond, MaxRetries: -1})
+	return newTestServiceOn(t, rdb, db, sheets, aiClient, configure)
+}
+
+// newRedisTestService cria o serviço sobre um Redis em memória (miniredis), para os testes que verificam
+// chaves, TTLs e filas no Redis. O relógio do miniredis não anda sozinho: use mr.FastForward para expirar chaves.
+func newRedisTestService(t *testing.T, db *sql.DB, configure func(*Config)) (*ChatbotService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return newTestServiceOn(t, rdb, db, nil, nil, configure), mr
+}
+
+// newTestServiceOn cria o serviço sobre o cliente Redis informado, que é fechado ao fim do teste.
+func newTestServiceOn(t *testing.T, rdb *redis.Client, db *sql.DB, sheets SheetsClient, aiClient AIClient, configure func(*Config)) *ChatbotService {
+	t.Helper()
+	cfg := LoadConfig()
+	cfg.RedisRetries = 0
+	cfg.RedisBreakerThreshold = 1
+	cfg.RedisBreakerCooldown = time.Hour
+	cfg.SheetsWorkers = 0
+	cfg.SheetsReplayInterval = 0
+	if configure != nil {
+		configure(&cfg)
+	}
+	t.Cleanup(func() { rdb.Close() })
+	s := NewChatbotService(rdb, db, sheets, aiClient, cfg)
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+	return s
+}
+
+// fixedClock é um relógio manual para testes que dependem do tempo.
+type fixedClock struct{ t time.Time }
+
+func (c *fixedClock) now() time.Time          { return c.t }
+func (c *fixedClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// fakeSheets é um SheetsClient em memória; fail, se definido, decide o erro de cada gravação pelo nome.
+type fakeSheets struct {
+	mu    sync.Mutex
+	saved []string
+	fail  func(nome string) error
+}
+
+func (f *fakeSheets) save(nome string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail != nil {
+		if err := f.fail(nome); err != nil {
+			return err
+		}
+	}
+	f.saved = append(f.saved, nome)
+	return nil
+}
+
+func (f *fakeSheets) SaveSupport(nome, _, _, _ string) error     { return f.save(nome) }
+func (f *fakeSheets) SaveFeedback(nome, _, _, _ string) error    { return f.save(nome) }
+func (f *fakeSheets) SaveNPS(nome, _ string, _ int) error        { return f.save(nome) }
+func (f *fakeSheets) SavePlans(nome, _, _, _, _, _ string) error { return f.save(nome) }
+
+// names retorna os nomes gravados com sucesso, na ordem.
+func (f *fakeSheets) names() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.saved...)
+}
+
+// newTestDB abre um SQLite temporário com as tabelas usadas pelo serviço.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := SetupSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// count retorna o número de linhas da tabela.
+func count(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+	"log"
+)
+
+// messageSeenKeyPrefix marca as mensagens já recebidas pelos webhooks, por canal e ID.
+const messageSeenKeyPrefix = "msgseen:"
+
+// ClaimMessage registra a mensagem recebida pelo webhook e retorna false se ela já foi recebida dentro
+// do TTL de idempotência (SessionTTLs.Idempotency), como nos reenvios de lote do Meta. Mensagens sem ID,
+// ou com a idempotência desligada, são sempre processadas.
+func (s *ChatbotService) ClaimMessage(channel, messageID string) bool {
+	if messageID == "" || s.cfg.SessionTTLs.Idempotency <= 0 {
+		return true
+	}
+	fresh, err := s.sessions.claimIdempotency(context.Background(), messageSeenKeyPrefix+channel+":"+messageID)
+	if err != nil {
+		log.Printf("Redis indisponível ao verificar mensagem repetida, usando memória local: %v", err)
+	}
+	return fresh
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	activeSessionsKey   = "sessions:active"
+	sessionChannelsKey  = "sessions:channel"
+	remindedSessionsKey = "sessions:reminded"
+)
+
+// Canais de entrada suportados pelo serviço.
+const (
+	ChannelWeb       = "web"
+	ChannelWhatsApp  = "whatsapp"
+	ChannelMessenger = "messenger"
+)
+
+// Pusher envia uma mensagem proativa ao usuário em um canal que permite push.
+type Pusher func(to, message string) error
+
+// RegisterPusher associa um canal a uma função de envio proativo (ex.: WhatsApp).
+func (s *ChatbotService) RegisterPusher(channel string, p Pusher) {
+	s.pushers[channel] = p
+}
+
+// touchSession registra a atividade do usuário no índice de sessões ativas.
+func (s *ChatbotService) touchSession(userID, channel string) {
+	ctx := context.Background()
+	pipe := s.redis.Pipeline()
+	pipe.ZAdd(ctx, activeSessionsKey, &redis.Z{Score: float64(s.now().Unix()), Member: userID})
+	pipe.HSet(ctx, sessionChannelsKey, userID, channel)
+	pipe.SRem(ctx, remindedSessionsKey, userID)
+	s.forgetAbandonedLead(ctx, pipe, userID)
+	s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err })
+}
+
+// sessionChannel retorna o canal pelo qual o usuário conversou por último, ou "" se desconhecido
+// (inclusive com o Redis fora).
+func (s *ChatbotService) sessionChannel(userID string) string {
+	ctx := context.Background()
+	var channel string
+	s.redisDo(ctx, func() (err error) {
+		channel, err = s.redis.HGet(ctx, sessionChannelsKey, userID).Result()
+		return err
+	})
+	return channel
+}
+
+// expireSession descarta a sessão (estado, dados e histórico, no Redis e na memória local) e a retira
+// do índice de sessões ativas.
+func (s *ChatbotService) expireSession(userID string) error {
+	ctx := context.Background()
+	err := s.sessions.clear(ctx, userID)
+	pipe := s.redis.Pipeline()
+	pipe.ZRem(ctx, activeSessionsKey, userID)
+	pipe.HDel(ctx, sessionChannelsKey, userID)
+	pipe.SRem(ctx, remindedSessionsKey, userID)
+	if perr := s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err }); err == nil {
+		err = perr
+	}
+	return err
+}
+
+// StartInactivitySweeper percorre periodicamente as sessões ativas até o contexto ser cancelado,
+// enviando o lembrete de inatividade e expirando as sessões sem resposta.
+func (s *ChatbotService) StartInactivitySweeper(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.sweepInactiveSessions()
+			}
+		}
+	}()
+}
+
+// sweepInactiveSessions envia o lembrete às sessões ociosas além da fração configurada do timeout
+// e expira as que ultrapassaram o timeout sem nova mensagem.
+func (s *ChatbotService) sweepInactiveSessions() {
+	ctx := context.Background()
+	now := s.now()
+	reminderAfter := time.Duration(float64(s.cfg.IdleTimeout) * s.cfg.ReminderFraction)
+	if !s.cfg.ReminderEnabled {
+		reminderAfter = s.cfg.IdleTimeout
+	}
+
+	var sessions []redis.Z
+	err := s.redisDo(ctx, func() (err error) {
+		sessions, err = s.redis.ZRangeByScoreWithScores(ctx, activeSessionsKey, &redis.ZRangeBy{
+			Min: "-inf",
+			Max: strconv.FormatInt(now.Add(-reminderAfter).Unix(), 10),
+		}).Result()
+		return err
+	})
+	if err != nil {
+		// Com o circuito aberto a varredura espera o Redis voltar, sem repetir o log a cada ciclo.
+		if !errors.Is(err, errCircuitOpen) {
+			log.Printf("Erro ao listar sessões ativas: %v", err)
+		}
+		return
+	}
+
+	for _, z := range sessions {
+		userID, _ := z.Member.(string)
+		idle := now.Sub(time.Unix(int64(z.Score), 0))
+		if idle >= s.cfg.IdleTimeout {
+			s.recordAbandonedLead(userID)
+			s.expireSession(userID)
+			continue
+		}
+		s.sendInactivityReminder(userID)
+	}
+	s.sendLeadFollowups()
+}
+
+// sendInactivityReminder envia o lembrete uma única vez por período de inatividade,
+// apenas para canais com push registrado.
+func (s *ChatbotService) sendInactivityReminder(userID string) {
+	ctx := context.Background()
+	channel := s.sessionChannel(userID)
+	push, ok := s.pushers[channel]
+	if !ok || (channel == ChannelWhatsApp && !s.flags.enabled(FlagWhatsApp)) {
+		return
+	}
+	var added int64
+	err := s.redisDo(ctx, func() (err error) {
+		added, err = s.redis.SAdd(ctx, remindedSessionsKey, userID).Result()
+		return err
+	})
+	if err != nil || added == 0 {
+		return
+	}
+
+	status := "sent"
+	if err := push(userID, s.cfg.ReminderMessage); err != nil {
+		log.Printf("Erro ao enviar lembrete de inatividade para %s: %v", userID, err)
+		status = "failed"
+	}
+	s.LogOutbound(channel, userID, "", s.cfg.ReminderMessage, status)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingPusher registra as mensagens proativas enviadas.
+type recordingPusher struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (p *recordingPusher) push(to, message string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, to+": "+message)
+	return nil
+}
+
+func (p *recordingPusher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.sent)
+}
+
+func newInactivityTestService(t *testing.T) (*ChatbotService, *fixedClock, *recordingPusher) {
+	t.Helper()
+	s, _ := newRedisTestService(t, nil, func(cfg *Config) {
+		cfg.IdleTimeout = 10 * time.Minute
+		cfg.ReminderEnabled = true
+		cfg.ReminderFraction = 0.5
+		cfg.LeadFollowupEnabled = false
+	})
+	clock := &fixedClock{t: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	s.now = clock.now
+	pusher := &recordingPusher{}
+	s.RegisterPusher(ChannelWhatsApp, pusher.push)
+	return s, clock, pusher
+}
+
+func TestInactivityReminderIsSentOncePerIdlePeriod(t *testing.T) {
+	s, clock, pusher := newInactivityTestService(t)
+	const user = "5544999998888"
+	s.touchSession(user, ChannelWhatsApp)
+
+	clock.advance(4 * time.Minute)
+	s.sweepInactiveSessions()
+	if n := pusher.count(); n != 0 {
+		t.Fatalf("lembretes antes da metade do timeout = %d; esperado 0", n)
+	}
+
+	clock.advance(2 * time.Minute)
+	s.sweepInactiveSessions()
+	s.sweepInactiveSessions()
+	if n := pusher.count(); n != 1 {
+		t.Fatalf("lembretes = %d; esperado 1 mesmo com duas varreduras", n)
+	}
+	if reminded, _ := s.redis.SIsMember(context.Background(), remindedSessionsKey, user).Result(); !reminded {
+		t.Error("usuário lembrado fora de sessions:reminded")
+	}
+
+	// Uma nova mensagem abre outro período de inatividade, com direito a outro lembrete.
+	s.touchSession(user, ChannelWhatsApp)
+	clock.advance(6 * time.Minute)
+	s.sweepInactiveSessions()
+	if n := pusher.count(); n != 2 {
+		t.Errorf("lembretes após nova atividade = %d; esperado 2", n)
+	}
+}
+
+func TestInactivityReminderSkipsChannelsWithoutPush(t *testing.T) {
+	s, clock, pusher := newInactivityTestService(t)
+	s.touchSession("web-uuid", ChannelWeb)
+
+	clock.advance(6 * time.Minute)
+	s.sweepInactiveSessions()
+	if n := pusher.count(); n != 0 {
+		t.Errorf("lembretes para o canal web = %d; esperado 0", n)
+	}
+}
+
+func TestIdleSessionExpiresAndResets(t *testing.T) {
+	s, clock, _ := newInactivityTestService(t)
+	ctx := context.Background()
+	const user = "5544999998888"
+	s.setState(user, StatePlansPhone)
+	s.setUserData(user, UserData{Nome: "Ana Souza", PlanoDesejado: "500 MEGA"})
+	s.touchSession(user, ChannelWhatsApp)
+
+	clock.advance(11 * time.Minute)
+	s.sweepInactiveSessions()
+
+	if state := s.sessions.state(ctx, user); state == StatePlansPhone {
+		t.Errorf("estado = %q após expirar; esperado a sessão reiniciada", state)
+	}
+	if data := s.getUserData(user); data.Nome != "" || data.PlanoDesejado != "" {
+		t.Errorf("dados = %+v após expirar; esperado vazio", data)
+	}
+	if n, _ := s.redis.ZCard(ctx, activeSessionsKey).Result(); n != 0 {
+		t.Errorf("sessões ativas = %d; esperado 0", n)
+	}
+	if ch := s.sessionChannel(user); ch != "" {
+		t.Errorf("canal = %q após expirar; esperado vazio", ch)
+	}
+}
@@ -0,0 +1,23 @@
+package services
+
+import "fmt"
+
+// Políticas para mensagens acima do limite de entrada da IA.
+const (
+	AIInputReject   = "reject"
+	AIInputTruncate = "truncate"
+)
+
+// limitAIInput aplica o limite de caracteres antes de montar o prompt da IA.
+// Retorna o texto a ser usado e, quando a mensagem deve ser recusada, o pedido de resumo ao usuário.
+func (s *ChatbotService) limitAIInput(message string) (string, string) {
+	limit := s.cfg.AIInputMaxChars
+	runes := []rune(message)
+	if limit <= 0 || len(runes) <= limit {
+		return message, ""
+	}
+	if s.cfg.AIInputPolicy == AIInputTruncate {
+		return string(runes[:limit]) + "…", ""
+	}
+	return "", fmt.Sprintf("✂️ Sua mensagem ficou muito longa. Por favor, *resuma sua pergunta* em até %d caracteres.", limit)
+}
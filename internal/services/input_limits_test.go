@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLimitAIInputRejectsLongMessages(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.AIInputMaxChars = 10; cfg.AIInputPolicy = AIInputReject })
+
+	if text, reprompt := s.limitAIInput("sem sinal"); text != "sem sinal" || reprompt != "" {
+		t.Errorf("mensagem curta = %q, %q; esperada sem alteração", text, reprompt)
+	}
+	// O limite conta caracteres, não bytes: acentos não fazem a mensagem passar do limite.
+	if text, reprompt := s.limitAIInput("conexão lá"); text != "conexão lá" || reprompt != "" {
+		t.Errorf("mensagem acentuada no limite = %q, %q; esperada sem alteração", text, reprompt)
+	}
+	text, reprompt := s.limitAIInput(strings.Repeat("a", 11))
+	if text != "" || !strings.Contains(reprompt, "até 10 caracteres") {
+		t.Errorf("mensagem longa = %q, %q; esperado pedido de resumo", text, reprompt)
+	}
+}
+
+func TestLimitAIInputTruncates(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.AIInputMaxChars = 5; cfg.AIInputPolicy = AIInputTruncate })
+
+	if text, reprompt := s.limitAIInput("ééééééé"); text != "ééééé…" || reprompt != "" {
+		t.Errorf("truncate = %q, %q; esperado \"ééééé…\"", text, reprompt)
+	}
+}
+
+func TestLimitAIInputDisabled(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.AIInputMaxChars = 0 })
+
+	long := strings.Repeat("a", 5000)
+	if text, reprompt := s.limitAIInput(long); text != long || reprompt != "" {
+		t.Error("com limite 0 a mensagem deveria passar sem alteração")
+	}
+}
+
+func TestLongSupportProblemSkipsAI(t *testing.T) {
+	client := &fakeAI{text: "Reinicie o roteador."}
+	s := newTestService(t, client, func(cfg *Config) { cfg.AIInputMaxChars = 20; cfg.AIInputPolicy = AIInputReject })
+	user := "5544999990000"
+	s.setState(user, StateSupportProblem)
+
+	response, err := s.handleSupportProblem(context.Background(), user, strings.Repeat("internet caindo ", 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(response, "resuma sua pergunta") {
+		t.Errorf("resposta = %q; esperado pedido de resumo", response)
+	}
+	if client.calls != 0 {
+		t.Errorf("IA chamada %d vezes; esperado nenhuma chamada", client.calls)
+	}
+	if state := s.sessions.state(context.Background(), user); state != StateSupportProblem {
+		t.Errorf("estado = %q; esperado continuar em %q", state, StateSupportProblem)
+	}
+}
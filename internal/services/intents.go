@@ -0,0 +1,93 @@
+package services
+
+import (
+	"log"
+	"slices"
+	"strings"
+)
+
+// Intenções reconhecidas no assistente livre que têm um fluxo estruturado correspondente.
+const (
+	IntentPlans   = "planos"
+	IntentSupport = "suporte"
+	IntentBoleto  = "boleto"
+)
+
+// intentFlows associa cada intenção à opção do menu principal e ao nome exibido ao usuário.
+var intentFlows = map[string]struct{ option, label string }{
+	IntentSupport: {"1", "Suporte Técnico"},
+	IntentPlans:   {"2", "Planos e Serviços"},
+	IntentBoleto:  {"3", "Boleto e Financeiro"},
+}
+
+// defaultIntentKeywords são os termos que indicam cada intenção.
+var defaultIntentKeywords = map[string][]string{
+	IntentPlans:   {"plano", "planos", "contratar", "upgrade", "mudar de plano", "assinar"},
+	IntentSupport: {"sem internet", "internet caiu", "internet lenta", "modem", "roteador", "sem sinal", "suporte tecnico"},
+	IntentBoleto:  {"boleto", "fatura", "segunda via", "2 via", "pagamento", "conta atrasada"},
+}
+
+// parseIntentKeywords interpreta AI_INTENT_KEYWORDS no formato "intenção=termo|termo;intenção=termo".
+// Apenas as intenções informadas são substituídas; intenções desconhecidas são ignoradas.
+func parseIntentKeywords(v string) map[string][]string {
+	keywords := make(map[string][]string, len(defaultIntentKeywords))
+	for intent, kws := range defaultIntentKeywords {
+		keywords[intent] = kws
+	}
+	for _, item := range strings.Split(v, ";") {
+		intent, list, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		intent = strings.ToLower(strings.TrimSpace(intent))
+		if _, known := intentFlows[intent]; !known {
+			log.Printf("AI_INTENT_KEYWORDS: intenção desconhecida %q ignorada", intent)
+			continue
+		}
+		keywords[intent] = strings.Split(list, "|")
+	}
+	return keywords
+}
+
+// classifyIntent retorna a intenção cujo termo aparece na mensagem, ou "" para perguntas livres.
+func (s *ChatbotService) classifyIntent(message string) string {
+	words := " " + wordsOnly(message) + " "
+	for _, intent := range []string{IntentBoleto, IntentPlans, IntentSupport} {
+		for _, k := range s.cfg.IntentKeywords[intent] {
+			if k = wordsOnly(k); k != "" && strings.Contains(words, " "+k+" ") {
+				return intent
+			}
+		}
+	}
+	return ""
+}
+
+// routeFreeIntent trata a intenção detectada no assistente livre: oferece o fluxo correspondente
+// e, na mensagem seguinte, leva o usuário a ele se confirmar. Uma intenção recusada fica registrada na
+// sessão e não é oferecida de novo. handled é falso quando a mensagem deve seguir para a IA.
+func (s *ChatbotService) routeFreeIntent(userID, message string) (response string, handled bool, err error) {
+	userData := s.getUserData(userID)
+	if pending := userData.IntencaoPendente; pending != "" {
+		userData.IntencaoPendente = ""
+		s.setUserData(userID, userData)
+		cmd := normalizeCommand(message)
+		switch {
+		case isYes(cmd):
+			response, err = s.handleMenuSelection(s.sessionChannel(userID), userID, intentFlows[pending].option)
+			return response, true, err
+		case isNo(cmd):
+			userData.IntencoesRecusadas = append(userData.IntencoesRecusadas, pending)
+			s.setUserData(userID, userData)
+			return "👍 Tudo bem! Pode fazer sua pergunta ao assistente.", true, nil
+		}
+	}
+
+	intent := s.classifyIntent(message)
+	if intent == "" || slices.Contains(userData.IntencoesRecusadas, intent) {
+		return "", false, nil
+	}
+	userData.IntencaoPendente = intent
+	s.setUserData(userID, userData)
+	return "💡 Parece que você quer falar sobre *" + intentFlows[intent].label + "*.\n\n" +
+		"Digite *SIM* para ir para esse atendimento ou *NÃO* para continuar com o assistente.", true, nil
+}
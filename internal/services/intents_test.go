@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDeclinedIntentIsNotOfferedAgain(t *testing.T) {
+	ai := &fakeAI{text: "Resposta da IA."}
+	s := newTestService(t, ai, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWeb, user, "oi")
+	s.ProcessMessage(ctx, ChannelWeb, user, "4")
+
+	response, _ := s.ProcessMessage(ctx, ChannelWeb, user, "quero ver os planos")
+	if !strings.Contains(response, "Planos e Serviços") {
+		t.Fatalf("primeira menção sem oferta do fluxo: %q", response)
+	}
+	response, _ = s.ProcessMessage(ctx, ChannelWeb, user, "não")
+	if !strings.Contains(response, "assistente") {
+		t.Fatalf("recusa não confirmada: %q", response)
+	}
+
+	calls := ai.calls
+	response, _ = s.ProcessMessage(ctx, ChannelWeb, user, "e o plano de 500 mega?")
+	if strings.Contains(response, "Parece que você quer falar") {
+		t.Errorf("intenção recusada oferecida de novo: %q", response)
+	}
+	if ai.calls != calls+1 {
+		t.Errorf("mensagem não seguiu para a IA depois da recusa")
+	}
+	if state := s.sessions.state(ctx, user); state != StateAIFree {
+		t.Errorf("estado = %q; esperado continuar no assistente livre", state)
+	}
+}
+
+func TestDeclinedIntentStillOffersOthers(t *testing.T) {
+	s := newTestService(t, &fakeAI{text: "Resposta da IA."}, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWeb, user, "oi")
+	s.ProcessMessage(ctx, ChannelWeb, user, "4")
+	s.ProcessMessage(ctx, ChannelWeb, user, "quero ver os planos")
+	s.ProcessMessage(ctx, ChannelWeb, user, "não")
+
+	response, _ := s.ProcessMessage(ctx, ChannelWeb, user, "preciso da segunda via do boleto")
+	if !strings.Contains(response, "Boleto e Financeiro") {
+		t.Errorf("outra intenção deixou de ser oferecida: %q", response)
+	}
+}
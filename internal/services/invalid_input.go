@@ -0,0 +1,30 @@
+package services
+
+// invalidInput conta uma resposta não reconhecida e devolve o reprompt do estado. Ao atingir
+// MaxInvalidInputs respostas inválidas seguidas, volta ao menu sugerindo o atendente em vez de repetir a pergunta.
+func (s *ChatbotService) invalidInput(userID, reprompt string) (string, error) {
+	userData := s.getUserData(userID)
+	userData.EntradasInvalidas++
+	if s.cfg.MaxInvalidInputs <= 0 || userData.EntradasInvalidas < s.cfg.MaxInvalidInputs {
+		s.setUserData(userID, userData)
+		return reprompt, nil
+	}
+
+	// showMainMenu descarta os dados da sessão, o que também zera o contador.
+	menu, err := s.showMainMenu(userID)
+	if err != nil {
+		return "", err
+	}
+	return "🤔 *Não consegui entender suas últimas respostas.*\nSe preferir, digite *ATENDENTE* para falar com uma pessoa.\n\n" + menu, nil
+}
+
+// resetInvalidInputs zera o contador se a mensagem foi aceita, isto é, se o handler não o incrementou
+// (pending é o valor lido antes de despachar a mensagem).
+func (s *ChatbotService) resetInvalidInputs(userID string, pending int) {
+	userData := s.getUserData(userID)
+	if userData.EntradasInvalidas != pending {
+		return
+	}
+	userData.EntradasInvalidas = 0
+	s.setUserData(userID, userData)
+}
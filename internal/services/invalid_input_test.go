@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRepeatedInvalidInputsReturnToMenu(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.MaxInvalidInputs = 3 })
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	s.setState(user, StatePlansPhone)
+	for i := 1; i < 3; i++ {
+		response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "abc")
+		if !strings.HasPrefix(response, "Telefone inválido.") {
+			t.Fatalf("tentativa %d = %q; esperado repetir a pergunta", i, response)
+		}
+	}
+
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "abc")
+	if !strings.Contains(response, "Não consegui entender suas últimas respostas") || !strings.Contains(response, "ATENDENTE") {
+		t.Errorf("terceira tentativa = %q; esperado voltar ao menu sugerindo o atendente", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateMenu {
+		t.Errorf("estado = %q; esperado %q", state, StateMenu)
+	}
+	if n := s.getUserData(user).EntradasInvalidas; n != 0 {
+		t.Errorf("EntradasInvalidas = %d; esperado zerar no reset", n)
+	}
+}
+
+func TestValidAnswerResetsInvalidCount(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.MaxInvalidInputs = 2 })
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "9")
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "2")
+	if n := s.getUserData(user).EntradasInvalidas; n != 0 {
+		t.Fatalf("EntradasInvalidas = %d após resposta válida; esperado 0", n)
+	}
+	if response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "talvez"); strings.Contains(response, "Não consegui entender") {
+		t.Errorf("resposta = %q; a contagem anterior à resposta válida não deveria valer", response)
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Chaves do acompanhamento de leads abandonados.
+const (
+	abandonedLeadsKey    = "leads:abandoned"
+	abandonedLeadDataKey = "leads:abandoned:data"
+	leadNudgedKeyPrefix  = "leads:nudged:"
+)
+
+// abandonedLeadMaxAge é por quanto tempo, além de LeadFollowupDelay, um lead abandonado aguarda o lembrete
+// (ex.: WhatsApp desligado) antes de ser descartado sem ele.
+const abandonedLeadMaxAge = 24 * time.Hour
+
+// eventLeadFollowupSent é o evento de analytics registrado a cada lembrete de lead enviado.
+const eventLeadFollowupSent = "lead_followup_sent"
+
+// leadFollowupStates são as etapas de coleta de dados do fluxo de planos em que o plano já foi escolhido.
+var leadFollowupStates = map[SessionState]bool{
+	StatePlansRetention:   true,
+	StatePlansName:        true,
+	StatePlansPhone:       true,
+	StatePlansContactTime: true,
+}
+
+// abandonedLead guarda o necessário para retomar o fluxo quando o usuário responder ao lembrete.
+type abandonedLead struct {
+	State SessionState `json:"state"`
+	Data  UserData     `json:"data"`
+}
+
+// recordAbandonedLead registra, antes de a sessão expirar, o lead que parou na coleta de dados.
+// Só canais com push (WhatsApp) podem receber o lembrete.
+func (s *ChatbotService) recordAbandonedLead(userID string) {
+	if !s.cfg.LeadFollowupEnabled || s.sessionChannel(userID) != ChannelWhatsApp {
+		return
+	}
+	ctx := context.Background()
+	state := s.sessions.state(ctx, userID)
+	if !leadFollowupStates[state] {
+		return
+	}
+	userData := s.getUserData(userID)
+	if userData.PlanoDesejado == "" {
+		return
+	}
+
+	raw, err := json.Marshal(abandonedLead{State: state, Data: userData})
+	if err != nil {
+		return
+	}
+	pipe := s.redis.TxPipeline()
+	pipe.ZAdd(ctx, abandonedLeadsKey, &redis.Z{Score: float64(s.now().Unix()), Member: userID})
+	pipe.HSet(ctx, abandonedLeadDataKey, userID, raw)
+	if err := s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err }); err != nil {
+		log.Printf("Erro ao registrar lead abandonado de %s: %v", userID, err)
+	}
+}
+
+// forgetAbandonedLead descarta o lead abandonado quando o usuário volta a conversar por conta própria.
+func (s *ChatbotService) forgetAbandonedLead(ctx context.Context, pipe redis.Pipeliner, userID string) {
+	pipe.ZRem(ctx, abandonedLeadsKey, userID)
+	pipe.HDel(ctx, abandonedLeadDataKey, userID)
+}
+
+// abandonedLeadCutoff retorna o instante antes do qual os leads abandonados são descartados sem lembrete:
+// todos, com o acompanhamento desligado, ou os que passaram de LeadFollowupDelay + abandonedLeadMaxAge.
+func (s *ChatbotService) abandonedLeadCutoff(now time.Time) time.Time {
+	if !s.cfg.LeadFollowupEnabled {
+		return now
+	}
+	return now.Add(-s.cfg.LeadFollowupDelay - abandonedLeadMaxAge)
+}
+
+// pruneAbandonedLeads descarta do índice e de leads:abandoned:data os leads anteriores ao corte, para que
+// o hash não cresça sem limite enquanto os lembretes não puderem ser enviados.
+func (s *ChatbotService) pruneAbandonedLeads(ctx context.Context) {
+	var stale []string
+	err := s.redisDo(ctx, func() (err error) {
+		stale, err = s.redis.ZRangeByScore(ctx, abandonedLeadsKey, &redis.ZRangeBy{
+			Min: "-inf",
+			Max: "(" + strconv.FormatInt(s.abandonedLeadCutoff(s.now()).Unix(), 10),
+		}).Result()
+		return err
+	})
+	if err != nil || len(stale) == 0 {
+		return
+	}
+	members := make([]interface{}, len(stale))
+	for i, userID := range stale {
+		members[i] = userID
+	}
+	pipe := s.redis.TxPipeline()
+	pipe.ZRem(ctx, abandonedLeadsKey, members...)
+	pipe.HDel(ctx, abandonedLeadDataKey, stale...)
+	if err := s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err }); err != nil {
+		log.Printf("Erro ao descartar leads abandonados antigos: %v", err)
+	}
+}
+
+// sendLeadFollowups envia o lembrete aos leads abandonados há mais de LeadFollowupDelay. Cada usuário
+// recebe no máximo um lembrete por LeadFollowupCooldown; os que não puderam ser lembrados a tempo são descartados.
+func (s *ChatbotService) sendLeadFollowups() {
+	ctx := context.Background()
+	s.pruneAbandonedLeads(ctx)
+	if !s.cfg.LeadFollowupEnabled {
+		return
+	}
+	push, ok := s.pushers[ChannelWhatsApp]
+	if !ok || !s.flags.enabled(FlagWhatsApp) {
+		return
+	}
+	var due []string
+	err := s.redisDo(ctx, func() (err error) {
+		due, err = s.redis.ZRangeByScore(ctx, abandonedLeadsKey, &redis.ZRangeBy{
+			Min: "-inf",
+			Max: strconv.FormatInt(s.now().Add(-s.cfg.LeadFollowupDelay).Unix(), 10),
+		}).Result()
+		return err
+	})
+	if err != nil {
+		log.Printf("Erro ao listar leads abandonados: %v", err)
+		return
+	}
+
+	for _, userID := range due {
+		var raw string
+		s.redisDo(ctx, func() (err error) {
+			raw, err = s.redis.HGet(ctx, abandonedLeadDataKey, userID).Result()
+			return err
+		})
+		pipe := s.redis.Pipeline()
+		s.forgetAbandonedLead(ctx, pipe, userID)
+		s.redisDo(ctx, func() error { _, err := pipe.Exec(ctx); return err })
+
+		var lead abandonedLead
+		if err := json.Unmarshal([]byte(raw), &lead); err != nil {
+			continue
+		}
+		var fresh bool
+		err := s.redisDo(ctx, func() (err error) {
+			fresh, err = s.redis.SetNX(ctx, leadNudgedKeyPrefix+userID, 1, s.cfg.LeadFollowupCooldown).Result()
+			return err
+		})
+		if err != nil || !fresh {
+			continue
+		}
+
+		// Restaura a sessão para que a resposta ao lembrete continue o fluxo de onde parou.
+		lead.Data.UltimaAtividade = s.now().Unix()
+		s.setUserData(userID, lead.Data)
+		s.setState(userID, lead.State)
+
+		message := leadFollowupMessage(s.cfg.LeadFollowupMessage, lead)
+		status := "sent"
+		if err := push(userID, message); err != nil {
+			log.Printf("Erro ao enviar lembrete de lead para %s: %v", userID, err)
+			status = "failed"
+		} else {
+			s.recordEvent(eventLeadFollowupSent, string(lead.State), ChannelWhatsApp)
+		}
+		s.LogOutbound(ChannelWhatsApp, userID, "", message, status)
+	}
+}
+
+// leadFollowupMessage preenche {nome} e {plano} no modelo e acrescenta a pergunta da etapa pendente.
+func leadFollowupMessage(template string, lead abandonedLead) string {
+	nome := ""
+	if fields := strings.Fields(lead.Data.Nome); len(fields) > 0 {
+		nome = ", " + fields[0]
+	}
+	message := strings.NewReplacer("{nome}", nome, "{plano}", lead.Data.PlanoDesejado).Replace(template)
+	if prompt, ok := resumePrompts[lead.State]; ok {
+		message += "\n\n" + prompt
+	}
+	return message
+}
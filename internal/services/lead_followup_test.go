@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeadFollowupDisabledByDefault(t *testing.T) {
+	if LoadConfig().LeadFollowupEnabled {
+		t.Error("LeadFollowupEnabled deveria vir desligado sem LEAD_FOLLOWUP_ENABLED")
+	}
+	t.Setenv("LEAD_FOLLOWUP_ENABLED", "true")
+	if !LoadConfig().LeadFollowupEnabled {
+		t.Error("LEAD_FOLLOWUP_ENABLED=true deveria ligar o acompanhamento")
+	}
+}
+
+func TestAbandonedLeadCutoff(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	disabled := newTestService(t, nil, nil)
+	if got := disabled.abandonedLeadCutoff(now); !got.Equal(now) {
+		t.Errorf("desligado: corte = %s, quer %s (descarta todos)", got, now)
+	}
+
+	enabled := newTestService(t, nil, func(cfg *Config) {
+		cfg.LeadFollowupEnabled = true
+		cfg.LeadFollowupDelay = 2 * time.Hour
+	})
+	if got, want := enabled.abandonedLeadCutoff(now), now.Add(-26*time.Hour); !got.Equal(want) {
+		t.Errorf("ligado: corte = %s, quer %s", got, want)
+	}
+}
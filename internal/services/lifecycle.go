@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Shutdown conclui o trabalho em segundo plano na ordem de dependência: primeiro os consumidores de eventos,
+// que ainda gravam em backends, avisam e auditam; depois os backends adicionais, a fila do Sheets e as entregas
+// de avisos, cujas falhas ainda vão para o banco; por fim as gravações pendentes no banco. Deve ser chamado após
+// o servidor HTTP parar de aceitar requisições; se ctx expirar antes, retorna o que ficou pendente. Os avisos
+// ainda em entrega nesse caso vão para a dead-letter, para serem reenviados após o reinício.
+func (s *ChatbotService) Shutdown(ctx context.Context) error {
+	var errs []error
+	if err := s.events.drain(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("consumidores de eventos: %w", err))
+	}
+	if err := waitGroup(ctx, &s.storesWG); err != nil {
+		errs = append(errs, fmt.Errorf("backends adicionais: %w", err))
+	}
+	if s.sheetsPool != nil {
+		if err := s.sheetsPool.drain(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("fila do Sheets: %w", err))
+		}
+	}
+	if err := waitGroup(ctx, &s.notifyWG); err != nil {
+		saved := s.deadLetterPendingNotifications()
+		errs = append(errs, fmt.Errorf("entregas de avisos (%d guardadas na dead-letter): %w", saved, err))
+	}
+	if s.writer != nil {
+		if err := s.writer.drain(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("gravações no banco: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// waitGroup aguarda wg terminar ou ctx expirar, retornando ctx.Err() nesse caso.
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
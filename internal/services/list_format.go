@@ -0,0 +1,148 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// listStyle define como listas numeradas e com marcadores são exibidas em um canal.
+type listStyle struct {
+	number func(i int) string
+	bullet string
+	// detail é o marcador da linha de detalhe abaixo de um item numerado.
+	detail string
+	// bold mantém o negrito (*texto*) dos itens; o estilo "plain" o remove para leitores de tela.
+	bold bool
+}
+
+// Estilos de lista disponíveis em LIST_STYLES.
+var listStyles = map[string]listStyle{
+	"brackets": {number: func(i int) string { return fmt.Sprintf("[%d] ", i) }, bullet: "• ", detail: "- ", bold: true},
+	"numbered": {number: func(i int) string { return fmt.Sprintf("%d. ", i) }, bullet: "- ", detail: "- ", bold: true},
+	"emoji":    {number: emojiNumber, bullet: "▪️ ", detail: "↳ ", bold: true},
+	"plain":    {number: func(i int) string { return fmt.Sprintf("%d - ", i) }, bullet: "- ", detail: "", bold: false},
+}
+
+// defaultListStyle é o estilo usado pelos canais sem configuração.
+const defaultListStyle = "brackets"
+
+// listItem é um item de lista com detalhe opcional exibido na linha seguinte.
+type listItem struct {
+	label  string
+	detail string
+}
+
+// parseListStyles interpreta LIST_STYLES no formato "canal=estilo,canal=estilo".
+func parseListStyles(v string) map[string]string {
+	styles := make(map[string]string)
+	for _, item := range splitList(v) {
+		channel, style, ok := strings.Cut(item, "=")
+		style = strings.ToLower(strings.TrimSpace(style))
+		if _, known := listStyles[style]; !ok || !known {
+			log.Printf("LIST_STYLES: entrada inválida %q ignorada", item)
+			continue
+		}
+		styles[strings.ToLower(strings.TrimSpace(channel))] = style
+	}
+	return styles
+}
+
+// numbered renderiza os itens numerados a partir de 1, um por linha.
+func (st listStyle) numbered(items []listItem, bold bool) string {
+	var b strings.Builder
+	for i, item := range items {
+		b.WriteString(st.numberedItem(i+1, item, bold))
+	}
+	return b.String()
+}
+
+// numberedItem renderiza um item numerado e sua linha de detalhe.
+func (st listStyle) numberedItem(n int, item listItem, bold bool) string {
+	line := st.number(n) + st.emphasis(item.label, bold) + "\n"
+	if item.detail != "" {
+		line += "    " + st.detail + item.detail + "\n"
+	}
+	return line
+}
+
+// bulleted renderiza os itens com o marcador do estilo; itens com detalhe são separados por linha em branco.
+func (st listStyle) bulleted(items []listItem, bold bool) string {
+	entries := make([]string, len(items))
+	sep := ""
+	for i, item := range items {
+		entries[i] = st.bullet + st.emphasis(item.label, bold) + "\n"
+		if item.detail != "" {
+			entries[i] += "  " + item.detail + "\n"
+			sep = "\n"
+		}
+	}
+	return strings.Join(entries, sep)
+}
+
+// emphasis aplica o negrito do WhatsApp quando pedido e suportado pelo estilo.
+func (st listStyle) emphasis(text string, bold bool) string {
+	if bold && st.bold {
+		return "*" + text + "*"
+	}
+	return text
+}
+
+// plain remove a marcação de negrito de um texto fixo quando o estilo não a usa.
+func (st listStyle) plain(text string) string {
+	if st.bold {
+		return text
+	}
+	return strings.ReplaceAll(text, "*", "")
+}
+
+// emojiNumber retorna o número como emoji de tecla (1️⃣), recorrendo a "10." acima de 9.
+func emojiNumber(i int) string {
+	if i < 0 || i > 9 {
+		return fmt.Sprintf("%d. ", i)
+	}
+	return fmt.Sprintf("%d️⃣ ", i)
+}
+
+// mainMenuItems são as opções do menu principal, na ordem numerada.
+var mainMenuItems = []listItem{
+	{"Suporte Técnico", "Problemas com internet, modem ou instalação"},
+	{"Planos e Serviços", "Conhecer planos ou solicitar upgrade"},
+	{"Boleto e Financeiro", "Segunda via e questões financeiras"},
+	{"Assistente Livre", "Chat livre para qualquer dúvida"},
+}
+
+// renderMainMenu monta o menu principal padrão no estilo informado.
+func renderMainMenu(st listStyle) string {
+	options := make([]string, len(mainMenuItems))
+	for i, item := range mainMenuItems {
+		options[i] = st.numberedItem(i+1, item, false)
+	}
+	return st.plain("*QI TELECOM | Menu Principal 🛰️*\n\nBem-vindo ao QIChatBot!\nDigite apenas o *número* da opção desejada:\n\n") +
+		strings.Join(options, "\n") + fmt.Sprintf("\nDigite sua opção (1-%d):\n", len(mainMenuItems)) +
+		st.plain("Para falar com uma pessoa, digite *ATENDENTE* a qualquer momento.")
+}
+
+// mainMenu retorna o menu principal no estilo do canal do usuário, a partir do cache de textos.
+// Um MAIN_MENU_MESSAGE personalizado é exibido sem alterações.
+func (s *ChatbotService) mainMenu(userID string) string {
+	style := s.listStyleName(userID)
+	return s.render.get("menu:"+style, func(d displaySettings) string {
+		if d.mainMenuMessage != defaultMainMenuMessage {
+			return d.mainMenuMessage
+		}
+		return renderMainMenu(listStyles[style])
+	})
+}
+
+// planItems retorna os planos do catálogo como itens de lista, com velocidade e benefícios no detalhe.
+func planItems(withDetail bool) []listItem {
+	items := make([]listItem, len(planCatalog))
+	for i, p := range planCatalog {
+		items[i] = listItem{label: p.Name}
+		if withDetail {
+			items[i].detail = fmt.Sprintf("%d Mega + %s", p.SpeedMbps, strings.Join(p.Features, " + "))
+		}
+	}
+	return items
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseListStyles(t *testing.T) {
+	got := parseListStyles(" WhatsApp=Emoji, web=plain, messenger=tabela, sem-estilo")
+	want := map[string]string{"whatsapp": "emoji", "web": "plain"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseListStyles = %v; esperado %v", got, want)
+	}
+}
+
+func TestListStylesRenderItems(t *testing.T) {
+	items := []listItem{{label: "Suporte", detail: "Internet"}, {label: "Planos"}}
+
+	if got := listStyles["brackets"].numbered(items, true); got != "[1] *Suporte*\n    - Internet\n[2] *Planos*\n" {
+		t.Errorf("brackets = %q", got)
+	}
+	if got := listStyles["emoji"].numbered(items, false); got != "1️⃣ Suporte\n    ↳ Internet\n2️⃣ Planos\n" {
+		t.Errorf("emoji = %q", got)
+	}
+	if got := listStyles["plain"].numbered(items, true); got != "1 - Suporte\n    Internet\n2 - Planos\n" {
+		t.Errorf("plain = %q; esperado sem negrito", got)
+	}
+	if got := listStyles["numbered"].bulleted(items, false); got != "- Suporte\n  Internet\n\n- Planos\n" {
+		t.Errorf("bulleted = %q", got)
+	}
+	if got := emojiNumber(10); got != "10. " {
+		t.Errorf("emojiNumber(10) = %q; esperado recorrer a \"10. \"", got)
+	}
+}
+
+func TestMainMenuFollowsChannelStyle(t *testing.T) {
+	s, _ := newRedisTestService(t, nil, func(cfg *Config) { cfg.ListStyles = map[string]string{ChannelWeb: "plain"} })
+	ctx := context.Background()
+
+	s.ProcessMessage(ctx, ChannelWeb, "web-1", "oi")
+	web, _ := s.ProcessMessage(ctx, ChannelWeb, "web-1", "menu")
+	if !strings.Contains(web, "1 - Suporte Técnico") || strings.Contains(web, "*") {
+		t.Errorf("menu na web = %q; esperado o estilo plain sem negrito", web)
+	}
+	s.ProcessMessage(ctx, ChannelWhatsApp, "5544999998888", "oi")
+	whatsapp, _ := s.ProcessMessage(ctx, ChannelWhatsApp, "5544999998888", "menu")
+	if !strings.Contains(whatsapp, "[1] Suporte Técnico") {
+		t.Errorf("menu no WhatsApp = %q; esperado o estilo padrão", whatsapp)
+	}
+}
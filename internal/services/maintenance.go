@@ -0,0 +1,28 @@
+package services
+
+// SetMaintenance liga ou desliga o modo de manutenção em tempo de execução.
+func (s *ChatbotService) SetMaintenance(enabled bool) {
+	s.maintenance.Store(enabled)
+}
+
+// InMaintenance indica se o serviço está em modo de manutenção.
+func (s *ChatbotService) InMaintenance() bool {
+	return s.maintenance.Load()
+}
+
+// Readiness informa se o serviço está apto a receber tráfego, com o detalhe de cada verificação.
+func (s *ChatbotService) Readiness() (bool, map[string]interface{}) {
+	ready := true
+	checks := map[string]interface{}{
+		"maintenance":   s.InMaintenance(),
+		"sheets_queue":  s.SheetsQueueDepth(),
+		"redis":         s.RedisAvailable(),
+		"redis_circuit": s.RedisCircuitState(),
+		"ai_in_flight":  s.AIInFlight(),
+		"ai_fallback":   s.AIStats(),
+	}
+	if s.InMaintenance() || !s.RedisAvailable() {
+		ready = false
+	}
+	return ready, checks
+}
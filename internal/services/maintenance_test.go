@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// assertMaintenanceIsReadOnly envia um fluxo que normalmente grava sessão, lead e Sheets e verifica que,
+// em manutenção, só a mensagem de manutenção volta e nada é gravado.
+func assertMaintenanceIsReadOnly(t *testing.T, s *ChatbotService, mr *miniredis.Miniredis, sheets *fakeSheets) {
+	t.Helper()
+	user := "5544999998888"
+	for _, msg := range []string{"oi", "1", "Ana Souza", "44999998888"} {
+		response, err := s.ProcessMessage(context.Background(), ChannelWhatsApp, user, msg)
+		if err != nil {
+			t.Fatalf("ProcessMessage(%q): %v", msg, err)
+		}
+		if response != s.cfg.MaintenanceMessage {
+			t.Errorf("resposta a %q = %q; esperado a mensagem de manutenção", msg, response)
+		}
+	}
+	for _, table := range []string{"leads", "analytics_events", "outbound_messages", "sheets_queue"} {
+		if n := count(t, s.db, table); n != 0 {
+			t.Errorf("%s tem %d linhas em manutenção; esperado 0", table, n)
+		}
+	}
+	if names := sheets.names(); len(names) != 0 {
+		t.Errorf("Sheets gravou %v em manutenção", names)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("chaves gravadas no Redis em manutenção: %v", keys)
+	}
+	if ready, _ := s.Readiness(); ready {
+		t.Error("Readiness = true em manutenção")
+	}
+}
+
+func TestMaintenanceFromEnvAnswersWithoutWriting(t *testing.T) {
+	t.Setenv("MAINTENANCE_MODE", "true")
+	mr := miniredis.RunT(t)
+	sheets := &fakeSheets{}
+	s := newTestServiceOn(t, redis.NewClient(&redis.Options{Addr: mr.Addr()}), newTestDB(t), sheets, nil, nil)
+
+	assertMaintenanceIsReadOnly(t, s, mr, sheets)
+}
+
+func TestMaintenanceToggledAtRuntimeAnswersWithoutWriting(t *testing.T) {
+	mr := miniredis.RunT(t)
+	sheets := &fakeSheets{}
+	s := newTestServiceOn(t, redis.NewClient(&redis.Options{Addr: mr.Addr()}), newTestDB(t), sheets, nil, nil)
+
+	s.SetMaintenance(true)
+	assertMaintenanceIsReadOnly(t, s, mr, sheets)
+
+	s.SetMaintenance(false)
+	if response, _ := s.ProcessMessage(context.Background(), ChannelWhatsApp, "5544999998888", "oi"); response == s.cfg.MaintenanceMessage {
+		t.Error("mensagem de manutenção após desligar o modo")
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultTextRequiredStates são os estados em que a resposta vira dado gravado (nome, descrição do problema)
+// e, por isso, precisa conter texto de verdade.
+var defaultTextRequiredStates = []SessionState{StateSupportName, StateSupportProblem, StatePlansName}
+
+// textRequiredPrompts é o reprompt, por estado, para respostas sem texto; os demais usam meaningfulTextPrompt.
+var textRequiredPrompts = map[SessionState]string{
+	StateSupportName:    "✍️ Não consegui ler um nome aí. Por favor, digite seu *nome completo*:",
+	StatePlansName:      "✍️ Não consegui ler um nome aí. Por favor, digite seu *nome completo*:",
+	StateSupportProblem: "✍️ Preciso de uma descrição em texto. Conte com suas palavras qual *problema* está acontecendo:",
+}
+
+// meaningfulTextPrompt é o reprompt genérico para respostas só com emoji ou pontuação.
+const meaningfulTextPrompt = "✍️ Por favor, responda com *texto* (só emoji ou pontuação não dá para registrar):"
+
+// hasMeaningfulText indica se a mensagem tem ao menos uma letra ou dígito, isto é, não é só emoji,
+// pontuação ou espaços ("👍", "...", "?!").
+func hasMeaningfulText(message string) bool {
+	return strings.IndexFunc(message, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) != -1
+}
+
+// parseTextRequiredStates interpreta TEXT_REQUIRED_STATES (estados separados por vírgula).
+// Sem configuração, vale defaultTextRequiredStates.
+func parseTextRequiredStates(v string) map[SessionState]bool {
+	states := make(map[SessionState]bool)
+	list := splitList(v)
+	if len(list) == 0 {
+		for _, st := range defaultTextRequiredStates {
+			states[st] = true
+		}
+		return states
+	}
+	for _, raw := range list {
+		states[configState("TEXT_REQUIRED_STATES", raw)] = true
+	}
+	return states
+}
+
+// rejectMeaninglessText devolve o reprompt quando o estado exige texto e a mensagem não tem nenhum.
+func (s *ChatbotService) rejectMeaninglessText(userID string, state SessionState, message string) (string, bool, error) {
+	if !s.cfg.TextRequiredStates[state] || hasMeaningfulText(message) {
+		return "", false, nil
+	}
+	prompt, ok := textRequiredPrompts[state]
+	if !ok {
+		prompt = meaningfulTextPrompt
+	}
+	response, err := s.invalidInput(userID, prompt)
+	return response, true, err
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHasMeaningfulText(t *testing.T) {
+	for msg, want := range map[string]bool{
+		"👍":         false,
+		"...":       false,
+		" ?! ":      false,
+		"ok 👍":      true,
+		"3":         true,
+		"sem sinal": true,
+	} {
+		if got := hasMeaningfulText(msg); got != want {
+			t.Errorf("hasMeaningfulText(%q) = %v; esperado %v", msg, got, want)
+		}
+	}
+}
+
+func TestParseTextRequiredStates(t *testing.T) {
+	defaults := parseTextRequiredStates("")
+	if !defaults[StateSupportName] || !defaults[StateSupportProblem] || !defaults[StatePlansName] || defaults[StateAIFree] {
+		t.Errorf("padrão = %v; esperado nome e problema, sem o chat livre", defaults)
+	}
+	if got := parseTextRequiredStates("plans_phone, ai_free"); len(got) != 2 || !got[StatePlansPhone] || !got[StateAIFree] {
+		t.Errorf("TEXT_REQUIRED_STATES = %v; esperado só os estados informados", got)
+	}
+}
+
+func TestEmojiOnlyReplyIsRepromptedInTextStates(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+	s.setState(user, StateSupportProblem)
+
+	response, err := s.ProcessMessage(ctx, ChannelWhatsApp, user, "👍")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != textRequiredPrompts[StateSupportProblem] {
+		t.Errorf("resposta = %q; esperado o reprompt do problema", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateSupportProblem {
+		t.Errorf("estado = %q; esperado continuar em %q", state, StateSupportProblem)
+	}
+	if got := s.getUserData(user).Problema; got != "" {
+		t.Errorf("Problema = %q; o emoji não deveria ser gravado", got)
+	}
+
+	const lead = "5544999990001"
+	s.setState(lead, StatePlansName)
+	if response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, lead, "..."); response != textRequiredPrompts[StatePlansName] {
+		t.Errorf("resposta = %q; esperado o reprompt do nome", response)
+	}
+	if got := s.getUserData(lead).Nome; got != "" {
+		t.Errorf("Nome = %q; a pontuação não deveria ser gravada", got)
+	}
+}
+
+func TestEmojiAllowedInFreeChat(t *testing.T) {
+	s := newTestService(t, &fakeAI{text: "😊 Posso ajudar?"}, nil)
+	const user = "web-emoji"
+	s.setState(user, StateAIFree)
+
+	response, _ := s.ProcessMessage(context.Background(), ChannelWeb, user, "👍")
+	if response == meaningfulTextPrompt {
+		t.Errorf("resposta = %q; o chat livre deveria aceitar emoji", response)
+	}
+}
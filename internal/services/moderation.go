@@ -0,0 +1,76 @@
+package services
+
+import (
+	"log"
+	"strings"
+	"unicode"
+)
+
+// defaultModerationKeywords são termos que não devem aparecer em respostas do assistente livre.
+var defaultModerationKeywords = []string{
+	"porra", "caralho", "puta", "merda", "foda-se", "viado", "arrombado",
+	"nazista", "suicidio", "se matar", "pornografia",
+}
+
+// defaultModerationMessage substitui respostas da IA barradas pela moderação.
+const defaultModerationMessage = "🤖 Desculpe, não posso ajudar com isso. Posso responder dúvidas sobre internet, planos e serviços da QI TELECOM.\n\nDigite *MENU* para voltar ao menu principal."
+
+// OutputModerator avalia se uma resposta gerada pela IA pode ser exibida ao cliente.
+// Retorna true e o motivo quando o conteúdo deve ser barrado.
+type OutputModerator interface {
+	Flagged(text string) (bool, string)
+}
+
+// keywordModerator barra respostas que contenham algum dos termos configurados.
+type keywordModerator struct {
+	keywords []string
+}
+
+// newKeywordModerator normaliza os termos para comparação sem acentos e caixa.
+func newKeywordModerator(keywords []string) *keywordModerator {
+	m := &keywordModerator{}
+	for _, k := range keywords {
+		if k = wordsOnly(k); k != "" {
+			m.keywords = append(m.keywords, k)
+		}
+	}
+	return m
+}
+
+// Flagged procura os termos como palavras ou expressões inteiras no texto normalizado.
+func (m *keywordModerator) Flagged(text string) (bool, string) {
+	words := " " + wordsOnly(text) + " "
+	for _, k := range m.keywords {
+		if strings.Contains(words, " "+k+" ") {
+			return true, k
+		}
+	}
+	return false, ""
+}
+
+// wordsOnly normaliza o texto e troca pontuação por espaços, mantendo hífens internos.
+func wordsOnly(text string) string {
+	return normalizeCommand(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			return r
+		}
+		return ' '
+	}, text))
+}
+
+// SetModerator substitui o moderador padrão por palavras-chave (ex.: uma segunda chamada à IA).
+func (s *ChatbotService) SetModerator(m OutputModerator) {
+	s.moderator = m
+}
+
+// moderateAIOutput aplica a moderação configurada à resposta da IA, devolvendo a mensagem segura se barrada.
+func (s *ChatbotService) moderateAIOutput(userID, response string) string {
+	if !s.cfg.ModerationEnabled || s.moderator == nil {
+		return response
+	}
+	if flagged, reason := s.moderator.Flagged(response); flagged {
+		log.Printf("Resposta da IA barrada pela moderação (usuário %s, motivo %q)", userID, reason)
+		return s.cfg.ModerationMessage
+	}
+	return response
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestKeywordModeratorMatchesWholeWords(t *testing.T) {
+	m := newKeywordModerator([]string{"Suicídio", "se matar", "  "})
+
+	for _, text := range []string{"Fale sobre SUICIDIO.", "como se   matar?"} {
+		if flagged, _ := m.Flagged(text); !flagged {
+			t.Errorf("Flagged(%q) = false; esperado barrar sem depender de acento, caixa ou espaços", text)
+		}
+	}
+	for _, text := range []string{"Reinicie o computador.", "suicidiologia", "se matarem"} {
+		if flagged, reason := m.Flagged(text); flagged {
+			t.Errorf("Flagged(%q) = true (%q); esperado liberar termos dentro de outras palavras", text, reason)
+		}
+	}
+}
+
+func TestFreeChatResponseIsModerated(t *testing.T) {
+	client := &fakeAI{text: "Isso é uma merda de pergunta."}
+	s := newTestService(t, client, func(cfg *Config) { cfg.ModerationEnabled = true })
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWeb, user, "oi")
+	s.ProcessMessage(ctx, ChannelWeb, user, "4")
+	response, err := s.ProcessMessage(ctx, ChannelWeb, user, "qual a velocidade ideal?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != s.cfg.ModerationMessage {
+		t.Errorf("resposta = %q; esperado a mensagem de moderação", response)
+	}
+
+	client.text = "A velocidade ideal depende do uso."
+	if response, _ := s.ProcessMessage(ctx, ChannelWeb, user, "e para jogos?"); response == s.cfg.ModerationMessage {
+		t.Error("resposta comum barrada pela moderação")
+	}
+}
+
+func TestModerationDisabled(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.ModerationEnabled = false })
+	if got := s.moderateAIOutput("5544999998888", "que merda"); got != "que merda" {
+		t.Errorf("moderação desligada = %q; esperado a resposta original", got)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// nameParticles são as partículas mantidas em minúsculas no meio do nome ("Maria da Silva").
+var nameParticles = map[string]bool{
+	"da": true, "das": true, "de": true, "do": true, "dos": true, "e": true,
+}
+
+// invalidNamePrompt é o reprompt para nomes rejeitados por cleanName.
+const invalidNamePrompt = "❓ Não entendi seu nome. Informe seu *nome completo* (ex.: *Maria da Silva*):"
+
+// cleanName apara o nome, colapsa os espaços e aplica maiúscula na inicial de cada palavra, mantendo as
+// partículas (da, de, dos...) em minúsculas fora do início. Retorna "" para nomes inválidos: com um único
+// caractere ou sem nenhuma letra (só números, por exemplo).
+func cleanName(raw string) string {
+	words := strings.Fields(raw)
+	name := strings.Join(words, " ")
+	if utf8.RuneCountInString(name) < 2 || strings.IndexFunc(name, unicode.IsLetter) == -1 {
+		return ""
+	}
+	for i, w := range words {
+		lower := strings.ToLower(w)
+		if i > 0 && nameParticles[lower] {
+			words[i] = lower
+			continue
+		}
+		words[i] = titleWord(lower)
+	}
+	return strings.Join(words, " ")
+}
+
+// titleWord coloca em maiúscula a primeira letra da palavra e de cada parte após hífen ou apóstrofo
+// ("ana-maria" → "Ana-Maria", "d'ávila" → "D'Ávila").
+func titleWord(w string) string {
+	runes := []rune(w)
+	upper := true
+	for i, r := range runes {
+		if upper && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+			upper = false
+		}
+		if r == '-' || r == '\'' {
+			upper = true
+		}
+	}
+	return string(runes)
+}
+
+// collectedName valida o nome informado e, com NameCleanup, o padroniza com cleanName.
+// Retorna "" se o nome for inválido.
+func (s *ChatbotService) collectedName(raw string) string {
+	name := cleanName(raw)
+	if name == "" || s.cfg.NameCleanup {
+		return name
+	}
+	return strings.TrimSpace(raw)
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCleanName(t *testing.T) {
+	cases := []struct{ raw, want string }{
+		{"  joão   da silva ", "João da Silva"},
+		{"MARIA DOS SANTOS", "Maria dos Santos"},
+		{"da costa", "Da Costa"},
+		{"ana-maria d'ávila", "Ana-Maria D'Ávila"},
+		{"Pedro e Paulo", "Pedro e Paulo"},
+		{"123456", ""},
+		{"a", ""},
+		{"  ", ""},
+		{"👍 !!", ""},
+	}
+	for _, c := range cases {
+		if got := cleanName(c.raw); got != c.want {
+			t.Errorf("cleanName(%q) = %q; esperado %q", c.raw, got, c.want)
+		}
+	}
+}
+
+func TestSupportNameIsCleanedAndValidated(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	const user = "5544999998888"
+	s.setState(user, StateSupportName)
+
+	if response, _ := s.handleSupportName(user, "12345"); response != invalidNamePrompt {
+		t.Errorf("nome inválido = %q; esperado o reprompt do nome", response)
+	}
+	if state := s.sessions.state(context.Background(), user); state != StateSupportName {
+		t.Errorf("estado = %q; esperado continuar pedindo o nome", state)
+	}
+
+	s.handleSupportName(user, "joão  DA silva")
+	if got := s.getUserData(user).Nome; got != "João da Silva" {
+		t.Errorf("Nome = %q; esperado o nome padronizado", got)
+	}
+}
+
+func TestNameCleanupDisabledKeepsTypedName(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.NameCleanup = false })
+	const user = "5544999998888"
+	s.setState(user, StateSupportName)
+
+	if response, _ := s.handleSupportName(user, "42"); response != invalidNamePrompt {
+		t.Errorf("nome inválido = %q; esperado recusar mesmo sem padronização", response)
+	}
+	s.handleSupportName(user, " joão DA silva ")
+	if got := s.getUserData(user).Nome; got != "joão DA silva" {
+		t.Errorf("Nome = %q; esperado o nome como digitado, só aparado", got)
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Notifier entrega avisos à equipe (relatórios, alertas) por um canal externo.
+type Notifier interface {
+	Notify(ctx context.Context, subject, body string) error
+}
+
+// WebhookNotifier envia o aviso como JSON ({"text": ...}, compatível com Slack e Teams) para uma URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier cria um WebhookNotifier com timeout de 10 segundos.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify envia assunto e corpo ao webhook.
+func (n *WebhookNotifier) Notify(ctx context.Context, subject, body string) error {
+	payload, err := json.Marshal(map[string]string{"text": "*" + subject + "*\n\n" + body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook de notificação retornou status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier envia o aviso por e-mail via SMTP (com autenticação PLAIN se User for informado).
+type EmailNotifier struct {
+	Addr     string
+	From     string
+	To       []string
+	User     string
+	Password string
+}
+
+// Notify envia assunto e corpo aos destinatários.
+func (n *EmailNotifier) Notify(ctx context.Context, subject, body string) error {
+	var auth smtp.Auth
+	if n.User != "" {
+		host := n.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.User, n.Password, host)
+	}
+	msg := "From: " + n.From + "\r\n" +
+		"To: " + strings.Join(n.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" +
+		body
+	return smtp.SendMail(n.Addr, auth, n.From, n.To, []byte(msg))
+}
+
+// notifiersFromConfig monta os destinos configurados para o relatório diário (webhook e/ou e-mail).
+func notifiersFromConfig(cfg Config) []namedNotifier {
+	var out []namedNotifier
+	if cfg.DailyReportWebhookURL != "" {
+		out = append(out, namedNotifier{"webhook", NewWebhookNotifier(cfg.DailyReportWebhookURL)})
+	}
+	if len(cfg.DailyReportEmails) > 0 && cfg.SMTPAddr != "" {
+		out = append(out, namedNotifier{"email", &EmailNotifier{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			To:       cfg.DailyReportEmails,
+			User:     cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+		}})
+	}
+	return out
+}
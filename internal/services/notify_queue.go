@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// notifyTimeout limita cada tentativa de entrega de um aviso.
+const notifyTimeout = 15 * time.Second
+
+// maxNotificationReplay limita quantos avisos da dead-letter são reenviados por chamada.
+const maxNotificationReplay = 100
+
+// ErrReplayInProgress indica que já há um reenvio da dead-letter de avisos em andamento.
+var ErrReplayInProgress = errors.New("reenvio de avisos já em andamento")
+
+// namedNotifier identifica o notificador, para que os avisos da dead-letter voltem ao mesmo destino.
+type namedNotifier struct {
+	name string
+	Notifier
+}
+
+// pendingNotification é uma entrega de aviso em andamento.
+type pendingNotification struct {
+	n             namedNotifier
+	subject, body string
+}
+
+// notify entrega o aviso a cada notificador em segundo plano, sem bloquear quem chamou. Cada entrega tem
+// NotifyAttempts tentativas com backoff exponencial; esgotadas, o aviso vai para a dead-letter. As entregas
+// em andamento são aguardadas por Shutdown, que leva à dead-letter as que não terminarem no prazo.
+func (s *ChatbotService) notify(subject, body string) {
+	for _, n := range s.notifiers {
+		p := &pendingNotification{n: n, subject: subject, body: body}
+		s.notifyMu.Lock()
+		if s.notifyPending == nil {
+			s.notifyPending = make(map[*pendingNotification]struct{})
+		}
+		s.notifyPending[p] = struct{}{}
+		s.notifyMu.Unlock()
+		s.notifyWG.Add(1)
+		go func() {
+			defer s.notifyWG.Done()
+			err := s.deliverNotification(p.n, subject, body)
+			if !s.settleNotification(p) || err == nil {
+				return
+			}
+			log.Printf("Aviso %q via %s falhou após todas as tentativas, guardado na dead-letter: %v", subject, p.n.name, err)
+			if s.writer == nil {
+				return
+			}
+			s.writer.enqueue("aviso na dead-letter",
+				`INSERT INTO notification_dead_letters (notifier, subject, body, error, created_at) VALUES (?, ?, ?, ?, ?)`,
+				p.n.name, subject, body, err.Error(), s.now().UTC(),
+			)
+		}()
+	}
+}
+
+// settleNotification retira a entrega das pendentes. Retorna false se o desligamento já a levou à dead-letter.
+func (s *ChatbotService) settleNotification(p *pendingNotification) bool {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	if _, ok := s.notifyPending[p]; !ok {
+		return false
+	}
+	delete(s.notifyPending, p)
+	return true
+}
+
+// deadLetterPendingNotifications grava na dead-letter, de forma síncrona, as entregas que o desligamento não
+// pôde esperar, para que sejam reenviadas após o reinício. Retorna quantas foram guardadas.
+func (s *ChatbotService) deadLetterPendingNotifications() int {
+	s.notifyMu.Lock()
+	pending := s.notifyPending
+	s.notifyPending = nil
+	s.notifyMu.Unlock()
+	saved := 0
+	for p := range pending {
+		if s.db == nil {
+			break
+		}
+		err := s.execDB(`INSERT INTO notification_dead_letters (notifier, subject, body, error, created_at) VALUES (?, ?, ?, ?, ?)`,
+			p.n.name, p.subject, p.body, "entrega interrompida pelo desligamento", s.now().UTC())
+		if err != nil {
+			log.Printf("Aviso %q via %s perdido no desligamento: %v", p.subject, p.n.name, err)
+			continue
+		}
+		saved++
+	}
+	if len(pending) > 0 {
+		log.Printf("Desligamento antes do fim das entregas: %d de %d avisos guardados na dead-letter", saved, len(pending))
+	}
+	return saved
+}
+
+// deliverNotification tenta entregar o aviso a n até NotifyAttempts vezes e retorna o erro da última tentativa.
+func (s *ChatbotService) deliverNotification(n namedNotifier, subject, body string) error {
+	attempts := s.cfg.NotifyAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := s.cfg.NotifyBackoff
+	var err error
+	for i := 1; i <= attempts; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		err = n.Notify(ctx, subject, body)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if i < attempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
+
+// StartNotificationReplay dispara ReplayNotifications em segundo plano e retorna em seguida; o resultado vai
+// para o log. Com outro reenvio em andamento retorna ErrReplayInProgress.
+func (s *ChatbotService) StartNotificationReplay() error {
+	if s.db == nil {
+		return fmt.Errorf("banco local não configurado")
+	}
+	if !s.notifyReplaying.CompareAndSwap(false, true) {
+		return ErrReplayInProgress
+	}
+	s.notifyWG.Add(1)
+	go func() {
+		defer s.notifyWG.Done()
+		defer s.notifyReplaying.Store(false)
+		replayed, failed, err := s.ReplayNotifications()
+		if err != nil {
+			log.Printf("Erro ao reenviar avisos da dead-letter: %v", err)
+			return
+		}
+		log.Printf("Avisos da dead-letter reenviados: %d entregues, %d pendentes", replayed, failed)
+	}()
+	return nil
+}
+
+// ReplayNotifications reenvia os avisos pendentes da dead-letter aos seus notificadores (uma tentativa cada),
+// marcando os entregues. Retorna quantos foram reenviados e quantos continuam pendentes.
+func (s *ChatbotService) ReplayNotifications() (replayed, failed int, err error) {
+	if s.db == nil {
+		return 0, 0, fmt.Errorf("banco local não configurado")
+	}
+	rows, err := s.queryDB(
+		`SELECT id, notifier, subject, body FROM notification_dead_letters
+		WHERE replayed_at IS NULL ORDER BY id LIMIT ?`, maxNotificationReplay,
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("erro ao ler dead-letter de avisos: %w", err)
+	}
+	type deadLetter struct {
+		id                      int64
+		notifier, subject, body string
+	}
+	var pending []deadLetter
+	for rows.Next() {
+		var d deadLetter
+		if err := rows.Scan(&d.id, &d.notifier, &d.subject, &d.body); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		pending = append(pending, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, d := range pending {
+		n, ok := s.notifierByName(d.notifier)
+		if !ok {
+			failed++
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		sendErr := n.Notify(ctx, d.subject, d.body)
+		cancel()
+		if sendErr != nil {
+			failed++
+			if err := s.execDB(`UPDATE notification_dead_letters SET error = ? WHERE id = ?`, sendErr.Error(), d.id); err != nil {
+				log.Printf("Erro ao registrar falha do aviso %d: %v", d.id, err)
+			}
+			continue
+		}
+		if err := s.execDB(`UPDATE notification_dead_letters SET replayed_at = ? WHERE id = ?`, s.now().UTC(), d.id); err != nil {
+			log.Printf("Erro ao marcar aviso %d como reenviado: %v", d.id, err)
+		}
+		replayed++
+	}
+	return replayed, failed, nil
+}
+
+// notifierByName retorna o notificador configurado com o nome informado.
+func (s *ChatbotService) notifierByName(name string) (namedNotifier, bool) {
+	for _, n := range s.notifiers {
+		if n.name == name {
+			return n, true
+		}
+	}
+	return namedNotifier{}, false
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyNotifier falha nas primeiras failures chamadas; block, se definido, segura cada entrega até ser fechado.
+type flakyNotifier struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	block    chan struct{}
+}
+
+func (f *flakyNotifier) Notify(ctx context.Context, _, _ string) error {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("destino indisponível")
+	}
+	return nil
+}
+
+func (f *flakyNotifier) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func newNotifyTestService(t *testing.T, n Notifier) (*ChatbotService, func()) {
+	t.Helper()
+	db := newTestDB(t)
+	s := newTestServiceWith(t, db, nil, nil, func(cfg *Config) {
+		cfg.NotifyAttempts = 3
+		cfg.NotifyBackoff = time.Millisecond
+	})
+	s.notifiers = []namedNotifier{{name: "webhook", Notifier: n}}
+	shutdown := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			t.Fatalf("Shutdown: %v", err)
+		}
+	}
+	return s, shutdown
+}
+
+func TestNotifyRetriesBeforeDeadLetter(t *testing.T) {
+	n := &flakyNotifier{failures: 2}
+	s, shutdown := newNotifyTestService(t, n)
+
+	s.notify("assunto", "corpo")
+	shutdown()
+
+	if got := n.callCount(); got != 3 {
+		t.Errorf("tentativas = %d, quer 3", got)
+	}
+	if got := count(t, s.db, "notification_dead_letters"); got != 0 {
+		t.Errorf("dead-letter = %d, quer 0: a terceira tentativa foi entregue", got)
+	}
+}
+
+func TestNotifyDeadLettersAfterLastAttempt(t *testing.T) {
+	n := &flakyNotifier{failures: 10}
+	s, shutdown := newNotifyTestService(t, n)
+
+	s.notify("assunto", "corpo")
+	shutdown()
+
+	if got := n.callCount(); got != 3 {
+		t.Errorf("tentativas = %d, quer 3", got)
+	}
+	if got := count(t, s.db, "notification_dead_letters"); got != 1 {
+		t.Fatalf("dead-letter = %d, quer 1", got)
+	}
+}
+
+func TestNotificationReplayRunsOnceAtATime(t *testing.T) {
+	n := &flakyNotifier{block: make(chan struct{})}
+	s, shutdown := newNotifyTestService(t, n)
+	if _, err := s.db.Exec(`INSERT INTO notification_dead_letters (notifier, subject, body, error) VALUES ('webhook', 'a', 'b', 'x')`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.StartNotificationReplay(); err != nil {
+		t.Fatalf("primeiro reenvio: %v", err)
+	}
+	if err := s.StartNotificationReplay(); !errors.Is(err, ErrReplayInProgress) {
+		t.Errorf("segundo reenvio: err = %v, quer ErrReplayInProgress", err)
+	}
+	close(n.block)
+	shutdown()
+
+	var pending int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM notification_dead_letters WHERE replayed_at IS NULL`).Scan(&pending); err != nil {
+		t.Fatal(err)
+	}
+	if pending != 0 {
+		t.Errorf("pendentes = %d, quer 0 após o reenvio", pending)
+	}
+	if err := s.StartNotificationReplay(); err != nil {
+		t.Errorf("reenvio após o término: %v", err)
+	}
+}
+
+func TestShutdownDeadLettersNotificationsStillInFlight(t *testing.T) {
+	n := &flakyNotifier{failures: 1, block: make(chan struct{})}
+	db := newTestDB(t)
+	s := newTestServiceWith(t, db, nil, nil, func(cfg *Config) { cfg.NotifyAttempts = 1 })
+	s.notifiers = []namedNotifier{{name: "webhook", Notifier: n}}
+
+	s.notify("assunto", "corpo")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v; esperado o prazo esgotado com a entrega presa", err)
+	}
+	if got := count(t, db, "notification_dead_letters"); got != 1 {
+		t.Fatalf("dead-letter = %d, quer 1: o aviso em entrega deve ser guardado no desligamento", got)
+	}
+
+	// A entrega que falha depois não grava o aviso de novo.
+	close(n.block)
+	s.notifyWG.Wait()
+	if got := count(t, db, "notification_dead_letters"); got != 1 {
+		t.Errorf("dead-letter = %d, quer 1 após a entrega atrasada", got)
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Valores padrão da pesquisa de satisfação (NPS).
+const (
+	defaultNPSQuestion = "📊 *Pesquisa rápida:* de *0 a 10*, quanto você recomendaria a QI TELECOM a um amigo ou familiar?"
+	defaultNPSThanks   = "🙏 *Obrigado pela nota!* Ela nos ajuda a melhorar.\n\nDigite *MENU* para voltar ao menu principal."
+)
+
+// finishFlow encerra o fluxo atual. Com NPSEnabled, a resposta final recebe a pergunta da pesquisa
+// e o usuário passa a StateNPS; sessões do assistente livre nunca recebem a pesquisa.
+func (s *ChatbotService) finishFlow(userID, response string) (string, error) {
+	if !s.cfg.NPSEnabled || s.getUserData(userID).TipoAtendimento == "IA Livre" {
+		s.setState(userID, StateMenu)
+		return response, nil
+	}
+	s.setState(userID, StateNPS)
+	return response + "\n\n" + s.cfg.NPSQuestion, nil
+}
+
+// parseNPSScore interpreta a nota da pesquisa, aceita apenas se for um inteiro de 0 a 10.
+func parseNPSScore(message string) (int, bool) {
+	n, err := strconv.Atoi(message)
+	if err != nil || n < 0 || n > 10 {
+		return 0, false
+	}
+	return n, true
+}
+
+// handleNPS registra a nota da pesquisa de satisfação, separada da avaliação qualitativa do atendimento.
+func (s *ChatbotService) handleNPS(ctx context.Context, userID, message string) (string, error) {
+	nota, ok := parseNPSScore(message)
+	if !ok {
+		return s.invalidInput(userID, "Por favor, responda apenas com um número de *0 a 10*.")
+	}
+
+	userData := s.getUserData(userID)
+	if err := s.saveNPS(ctx, userID, userData.Nome, userData.TipoAtendimento, nota); err != nil {
+		return "", fmt.Errorf("erro ao registrar NPS: %w", err)
+	}
+	s.setState(userID, StateMenu)
+	return s.cfg.NPSThanks, nil
+}
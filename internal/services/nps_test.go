@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseNPSScoreAcceptsOnlyZeroToTen(t *testing.T) {
+	cases := map[string]bool{
+		"0": true, "7": true, "10": true,
+		"-1": false, "11": false, "7.5": false, "dez": false, "": false, "nota 9": false,
+	}
+	for input, want := range cases {
+		if _, ok := parseNPSScore(input); ok != want {
+			t.Errorf("parseNPSScore(%q) ok = %v; esperado %v", input, ok, want)
+		}
+	}
+}
+
+func TestFinishFlowAsksNPSWhenEnabled(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.NPSEnabled = true })
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	response, _ := s.finishFlow(user, "Pronto!")
+	if !strings.HasSuffix(response, s.cfg.NPSQuestion) {
+		t.Errorf("resposta = %q; esperado a pergunta do NPS ao final", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateNPS {
+		t.Errorf("estado = %q; esperado %q", state, StateNPS)
+	}
+
+	s.setUserData(user, UserData{TipoAtendimento: "IA Livre"})
+	if response, _ := s.finishFlow(user, "Pronto!"); response != "Pronto!" {
+		t.Errorf("assistente livre recebeu a pesquisa: %q", response)
+	}
+}
+
+func TestNPSRepromptsUntilValidScoreThenPersists(t *testing.T) {
+	db := newTestDB(t)
+	sheets := &fakeSheets{}
+	s := newTestServiceWith(t, db, sheets, nil, func(cfg *Config) {
+		cfg.NPSEnabled = true
+		cfg.SheetsEnabled = true
+		cfg.MaxInvalidInputs = 0
+	})
+	ctx := context.Background()
+	const user = "5544999998888"
+	s.setUserData(user, UserData{Nome: "Ana Souza", TipoAtendimento: "Suporte Técnico"})
+	s.setState(user, StateNPS)
+
+	for _, invalid := range []string{"11", "-1", "ótimo"} {
+		response, err := s.route(ctx, ChannelWhatsApp, user, invalid)
+		if err != nil {
+			t.Fatalf("route(%q): %v", invalid, err)
+		}
+		if !strings.Contains(response, "0 a 10") {
+			t.Errorf("resposta a %q = %q; esperado pedir a nota de novo", invalid, response)
+		}
+		if state := s.sessions.state(ctx, user); state != StateNPS {
+			t.Errorf("estado após %q = %q; esperado continuar em %q", invalid, state, StateNPS)
+		}
+	}
+
+	response, err := s.route(ctx, ChannelWhatsApp, user, "9")
+	if err != nil {
+		t.Fatalf("route(9): %v", err)
+	}
+	if response != s.cfg.NPSThanks {
+		t.Errorf("resposta = %q; esperado o agradecimento", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateMenu {
+		t.Errorf("estado = %q; esperado %q", state, StateMenu)
+	}
+	if names := sheets.names(); len(names) != 1 || names[0] != "Ana Souza" {
+		t.Errorf("Sheets = %v; esperado a nota de Ana Souza", names)
+	}
+
+	// O desligamento esvazia a fila de gravação do banco.
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	var nota int
+	var tipo string
+	if err := db.QueryRow(`SELECT nota, tipo_atendimento FROM nps_scores WHERE user_id = ?`, user).Scan(&nota, &tipo); err != nil {
+		t.Fatalf("nota não gravada: %v", err)
+	}
+	if nota != 9 || tipo != "Suporte Técnico" {
+		t.Errorf("nps_scores = %d, %q; esperado 9, Suporte Técnico", nota, tipo)
+	}
+	if n := count(t, db, "nps_scores"); n != 1 {
+		t.Errorf("nps_scores tem %d linhas; esperado só a nota válida", n)
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+import "context"
+
+const seenPrefix = "seen:"
+
+// firstVisit indica se é a primeira vez que o usuário fala com o bot, marcando-o como visto.
+// A marca no Redis é renovada a cada visita; se ela tiver expirado, ou com o Redis fora, o histórico de
+// mensagens enviadas no banco evita repetir o onboarding para quem já foi atendido.
+func (s *ChatbotService) firstVisit(userID string) bool {
+	ctx := context.Background()
+	var added bool
+	err := s.redisDo(ctx, func() (err error) {
+		added, err = s.redis.SetNX(ctx, seenPrefix+userID, "1", s.cfg.SeenTTL).Result()
+		return err
+	})
+	if err == nil && !added {
+		s.redisDo(ctx, func() error { return s.redis.Expire(ctx, seenPrefix+userID, s.cfg.SeenTTL).Err() })
+		return false
+	}
+	return !s.hasOutboundHistory(userID)
+}
+
+// hasOutboundHistory verifica se o bot já enviou alguma mensagem ao usuário.
+func (s *ChatbotService) hasOutboundHistory(userID string) bool {
+	if s.db == nil {
+		return false
+	}
+	var exists int
+	err := s.db.QueryRow(`SELECT 1 FROM outbound_messages WHERE recipient = ? LIMIT 1`, userID).Scan(&exists)
+	return err == nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnboardingShownOnFirstVisitOnly(t *testing.T) {
+	s, mr := newRedisTestService(t, nil, nil)
+	const user = "5544999998888"
+
+	first, err := s.showMainMenu(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first, s.cfg.OnboardingMessage) {
+		t.Errorf("primeira visita = %q; esperado o onboarding antes do menu", first)
+	}
+	if ttl := mr.TTL(seenPrefix + user); ttl != s.cfg.SeenTTL {
+		t.Errorf("TTL da marca = %s; esperado SeenTTL (%s)", ttl, s.cfg.SeenTTL)
+	}
+
+	mr.FastForward(24 * time.Hour)
+	second, _ := s.showMainMenu(user)
+	if strings.Contains(second, s.cfg.OnboardingMessage) {
+		t.Error("onboarding repetido na segunda visita")
+	}
+	if ttl := mr.TTL(seenPrefix + user); ttl != s.cfg.SeenTTL {
+		t.Errorf("TTL da marca após a visita = %s; esperado renovado para %s", ttl, s.cfg.SeenTTL)
+	}
+}
+
+func TestFirstVisitFallsBackToOutboundHistory(t *testing.T) {
+	db := newTestDB(t)
+	// Redis fora: só o histórico no banco decide.
+	s := newTestServiceWith(t, db, nil, nil, nil)
+	const known, unknown = "5544999998888", "5544911112222"
+	if _, err := db.Exec(`INSERT INTO outbound_messages (channel, recipient, text, status) VALUES ('whatsapp', ?, 'menu', 'sent')`, known); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.firstVisit(known) {
+		t.Error("usuário com mensagens enviadas tratado como primeira visita com o Redis fora")
+	}
+	if !s.firstVisit(unknown) {
+		t.Error("usuário sem histórico não tratado como primeira visita com o Redis fora")
+	}
+}
+
+func TestFirstVisitUsesHistoryWhenMarkExpired(t *testing.T) {
+	db := newTestDB(t)
+	s, mr := newRedisTestService(t, db, nil)
+	const user = "5544999998888"
+	if !s.firstVisit(user) {
+		t.Fatal("primeira visita não reconhecida")
+	}
+	if _, err := db.Exec(`INSERT INTO outbound_messages (channel, recipient, text, status) VALUES ('whatsapp', ?, 'menu', 'sent')`, user); err != nil {
+		t.Fatal(err)
+	}
+
+	mr.FastForward(s.cfg.SeenTTL + time.Hour)
+	if s.firstVisit(user) {
+		t.Error("onboarding repetido após a marca expirar, apesar do histórico no banco")
+	}
+}
@@ -0,0 +1,14 @@
+package services
+
+// LogOutbound registra de forma assíncrona uma mensagem enviada pelo bot em qualquer canal,
+// publicando MessageSent para a auditoria (logOutboundEvent).
+func (s *ChatbotService) LogOutbound(channel, recipient, messageID, text, status string) {
+	s.events.Publish(MessageSent{
+		Channel: channel, Recipient: recipient, MessageID: messageID, Text: text, Status: status, At: s.now(),
+	})
+}
+
+// updateOutboundStatus atualiza de forma assíncrona o status de entrega de uma mensagem auditada.
+func (s *ChatbotService) updateOutboundStatus(messageID, status string) {
+	s.events.Publish(MessageStatus{MessageID: messageID, Status: status})
+}
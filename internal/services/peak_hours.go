@@ -0,0 +1,88 @@
+package services
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// defaultPeakHoursMessage substitui o prazo das mensagens de encerramento durante o horário de pico.
+const defaultPeakHoursMessage = "⏳ Devido à alta demanda, o retorno pode levar até 48h."
+
+// leadContactPromise é o prazo de retorno comercial prometido fora do horário de pico.
+const leadContactPromise = "Nossa equipe comercial entrará em contato em até 24 horas para finalizar!"
+
+// ClockRange é um intervalo diário em minutos desde a meia-noite, no fuso do serviço. Se End < Start,
+// o intervalo atravessa a meia-noite (ex.: 22:00-02:00).
+type ClockRange struct {
+	Start int
+	End   int
+}
+
+// contains indica se o minuto do dia está dentro do intervalo [Start, End).
+func (r ClockRange) contains(minute int) bool {
+	if r.Start <= r.End {
+		return minute >= r.Start && minute < r.End
+	}
+	return minute >= r.Start || minute < r.End
+}
+
+// parseClock interpreta um horário HH:MM, retornando hora e minuto.
+func parseClock(v string) (hour, minute int, ok bool) {
+	h, m, found := strings.Cut(strings.TrimSpace(v), ":")
+	if !found {
+		return 0, 0, false
+	}
+	hour, herr := strconv.Atoi(h)
+	minute, merr := strconv.Atoi(m)
+	if herr != nil || merr != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// parsePeakHours interpreta PEAK_HOURS no formato "HH:MM-HH:MM,HH:MM-HH:MM". Intervalos inválidos são ignorados.
+func parsePeakHours(v string) []ClockRange {
+	var ranges []ClockRange
+	for _, item := range splitList(v) {
+		from, to, ok := strings.Cut(item, "-")
+		fh, fm, okFrom := parseClock(from)
+		th, tm, okTo := parseClock(to)
+		if !ok || !okFrom || !okTo || fh*60+fm == th*60+tm {
+			log.Printf("PEAK_HOURS: intervalo inválido %q ignorado", item)
+			continue
+		}
+		ranges = append(ranges, ClockRange{Start: fh*60 + fm, End: th*60 + tm})
+	}
+	return ranges
+}
+
+// inPeakHours indica se o instante atual, no fuso do serviço, está em algum intervalo de PeakHours.
+func (s *ChatbotService) inPeakHours() bool {
+	local := s.now().In(s.location())
+	minute := local.Hour()*60 + local.Minute()
+	for _, r := range s.cfg.PeakHours {
+		if r.contains(minute) {
+			return true
+		}
+	}
+	return false
+}
+
+// leadContactDeadline retorna o prazo de retorno do cadastro de planos. No horário de pico PeakHoursMessage
+// substitui o prazo padrão, para que o usuário não receba duas promessas diferentes.
+func (s *ChatbotService) leadContactDeadline() string {
+	if s.inPeakHours() {
+		return s.cfg.PeakHoursMessage
+	}
+	return leadContactPromise
+}
+
+// escalationDeadline retorna a linha de prazo do encaminhamento ao técnico: o SLA da categoria do problema
+// ou, no horário de pico, PeakHoursMessage no lugar dele.
+func (s *ChatbotService) escalationDeadline(problema string) string {
+	if s.inPeakHours() {
+		return s.cfg.PeakHoursMessage
+	}
+	return "📅 Prazo: " + s.escalationSLA(problema)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newPeakTestService(t *testing.T, at time.Time) *ChatbotService {
+	t.Helper()
+	s := newTestService(t, nil, func(cfg *Config) {
+		cfg.Location = time.UTC
+		cfg.PeakHours = parsePeakHours("18:00-21:00")
+	})
+	clock := &fixedClock{t: at}
+	s.now = clock.now
+	return s
+}
+
+func TestLeadContactDeadlineOffPeak(t *testing.T) {
+	s := newPeakTestService(t, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC))
+
+	got := s.leadContactDeadline()
+	if !strings.Contains(got, "24 horas") {
+		t.Errorf("fora do pico = %q; esperado o prazo de 24 horas", got)
+	}
+	if strings.Contains(got, s.cfg.PeakHoursMessage) {
+		t.Errorf("fora do pico não deve conter o aviso de alta demanda: %q", got)
+	}
+}
+
+func TestLeadContactDeadlineReplacedDuringPeak(t *testing.T) {
+	s := newPeakTestService(t, time.Date(2025, 1, 1, 19, 30, 0, 0, time.UTC))
+
+	got := s.leadContactDeadline()
+	if got != s.cfg.PeakHoursMessage {
+		t.Errorf("no pico = %q; esperado %q", got, s.cfg.PeakHoursMessage)
+	}
+	if strings.Contains(got, "24 horas") {
+		t.Errorf("no pico o prazo de 24 horas não deve aparecer: %q", got)
+	}
+}
+
+func TestEscalationDeadlinePeakVsOffPeak(t *testing.T) {
+	s := newPeakTestService(t, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC))
+	sla := s.escalationSLA("sem conexão")
+
+	off := s.escalationDeadline("sem conexão")
+	if off != "📅 Prazo: "+sla {
+		t.Errorf("fora do pico = %q; esperado o SLA %q", off, sla)
+	}
+
+	s.now = (&fixedClock{t: time.Date(2025, 1, 1, 20, 59, 0, 0, time.UTC)}).now
+	peak := s.escalationDeadline("sem conexão")
+	if peak != s.cfg.PeakHoursMessage {
+		t.Errorf("no pico = %q; esperado %q", peak, s.cfg.PeakHoursMessage)
+	}
+	if strings.Contains(peak, sla) {
+		t.Errorf("no pico o SLA padrão não deve aparecer: %q", peak)
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// leadDedupeKeyPrefix guarda o hash telefone+plano dos interesses registrados recentemente.
+const leadDedupeKeyPrefix = "lead:dedupe:"
+
+// Tipos de registro aceitos na fila local do Sheets.
+const (
+	sheetsKindSupport  = "support"
+	sheetsKindPlans    = "plans"
+	sheetsKindFeedback = "feedback"
+	sheetsKindNPS      = "nps"
+)
+
+// sheetsSupportRecord, sheetsPlansRecord, sheetsFeedbackRecord e sheetsNPSRecord são os payloads enfileirados localmente.
+
+// sheetsSupportRecord é um atendimento de suporte técnico (Página2).
+type sheetsSupportRecord struct {
+	Nome      string `json:"nome"`
+	Problema  string `json:"problema"`
+	Descricao string `json:"descricao"`
+	Status    string `json:"status"`
+	// Historico é a transcrição do suporte técnico nos chamados encaminhados (formatTranscript).
+	Historico string `json:"historico,omitempty"`
+}
+
+// sheetsPlansRecord é um interesse em planos (Página3).
+type sheetsPlansRecord struct {
+	Nome          string `json:"nome"`
+	Situacao      string `json:"situacao"`
+	PlanoAtual    string `json:"plano_atual"`
+	PlanoDesejado string `json:"plano_desejado"`
+	Telefone      string `json:"telefone"`
+	Horario       string `json:"horario_contato,omitempty"`
+	Observacoes   string `json:"observacoes"`
+}
+
+// sheetsFeedbackRecord é uma avaliação do atendimento (Página1).
+type sheetsFeedbackRecord struct {
+	Nome            string `json:"nome"`
+	TipoAtendimento string `json:"tipo_atendimento"`
+	Feedback        string `json:"feedback"`
+	Sugestoes       string `json:"sugestoes"`
+}
+
+// sheetsNPSRecord é uma resposta da pesquisa NPS (Página4), também enviada aos backends adicionais como "record".
+type sheetsNPSRecord struct {
+	Nome            string `json:"nome"`
+	TipoAtendimento string `json:"tipo_atendimento"`
+	Nota            int    `json:"nota"`
+}
+
+// saveSupport grava o atendimento de suporte nos backends adicionais e no Sheets (ou na fila local, se desligado).
+// Retorna erro se o registro não chegou ao Sheets nem à fila local.
+func (s *ChatbotService) saveSupport(ctx context.Context, nome, problema, descricao, status, historico string) error {
+	record := sheetsSupportRecord{nome, problema, descricao, status, historico}
+	s.fanOutSupport(ctx, record)
+	if !s.flags.enabled(FlagSheets) {
+		return s.queueSheets(sheetsKindSupport, record)
+	}
+	return s.dispatchSheets(ctx, sheetsKindSupport, record, func() error {
+		return saveSupportRecord(s.sheets, record)
+	})
+}
+
+// savePlans grava o interesse em planos no Sheets (ou na fila local, se desligado) e publica LeadCreated,
+// que leva o lead aos backends adicionais e ao analytics. Reenvios do mesmo telefone para o mesmo plano
+// dentro do TTL de deduplicação (SessionTTLs.Dedupe) são ignorados. Retorna erro se o registro não chegou ao Sheets nem à fila local.
+func (s *ChatbotService) savePlans(ctx context.Context, userID, nome, situacao, planoAtual, planoDesejado, telefone, horario, observacoes string) error {
+	key, fresh := s.claimLead(telefone, planoDesejado)
+	if !fresh {
+		log.Printf("Interesse duplicado ignorado (telefone %s, plano %s)", telefone, planoDesejado)
+		return nil
+	}
+
+	s.events.Publish(LeadCreated{
+		UserID: userID, Channel: s.sessionChannel(userID), Nome: nome, Situacao: situacao, PlanoAtual: planoAtual,
+		PlanoDesejado: planoDesejado, Telefone: telefone, HorarioContato: horario, Observacoes: observacoes, At: s.now(),
+	})
+	s.recordLead(nome, telefone, situacao, planoAtual)
+	record := sheetsPlansRecord{nome, situacao, planoAtual, planoDesejado, telefone, horario, observacoes}
+	var err error
+	if s.flags.enabled(FlagSheets) {
+		err = s.dispatchSheets(ctx, sheetsKindPlans, record, func() error {
+			return savePlansRecord(s.sheets, record)
+		})
+	} else {
+		err = s.queueSheets(sheetsKindPlans, record)
+	}
+	if err != nil && key != "" {
+		// Libera o hash para que uma nova tentativa do cliente não seja tratada como duplicada.
+		s.sessions.release(context.Background(), key)
+	}
+	return err
+}
+
+// claimLead registra o par telefone+plano na janela de deduplicação. Retorna fresh=false se o par
+// já foi registrado na janela; com o Redis fora, a janela é verificada na memória local.
+func (s *ChatbotService) claimLead(telefone, plano string) (key string, fresh bool) {
+	if s.cfg.SessionTTLs.Dedupe <= 0 {
+		return "", true
+	}
+	sum := sha256.Sum256([]byte(onlyDigits(telefone) + "|" + strings.ToLower(strings.TrimSpace(plano))))
+	key = leadDedupeKeyPrefix + hex.EncodeToString(sum[:])
+
+	fresh, err := s.sessions.claimDedupe(context.Background(), key)
+	if err != nil {
+		log.Printf("Redis indisponível ao verificar duplicidade de interesse, usando memória local: %v", err)
+	}
+	return key, fresh
+}
+
+// saveFeedback grava o feedback no Sheets (ou na fila local, se desligado) e publica FeedbackReceived,
+// que o leva aos backends adicionais e ao analytics. Retorna erro se o registro não chegou ao Sheets nem à fila local.
+func (s *ChatbotService) saveFeedback(ctx context.Context, userID, nome, tipoAtendimento, feedback, sugestoes string) error {
+	s.events.Publish(FeedbackReceived{
+		UserID: userID, Channel: s.sessionChannel(userID), Nome: nome, TipoAtendimento: tipoAtendimento,
+		Feedback: feedback, Sugestoes: sugestoes, At: s.now(),
+	})
+	record := sheetsFeedbackRecord{nome, tipoAtendimento, feedback, sugestoes}
+	if !s.flags.enabled(FlagSheets) {
+		return s.queueSheets(sheetsKindFeedback, record)
+	}
+	return s.dispatchSheets(ctx, sheetsKindFeedback, record, func() error {
+		return s.sheets.SaveFeedback(nome, tipoAtendimento, feedback, sugestoes)
+	})
+}
+
+// saveNPS grava a nota da pesquisa de satisfação no banco local, nos backends adicionais e no Sheets
+// (ou na fila local, se desligado). Retorna erro se o registro não chegou ao Sheets nem à fila local.
+func (s *ChatbotService) saveNPS(ctx context.Context, userID, nome, tipoAtendimento string, nota int) error {
+	if s.writer != nil {
+		s.writer.enqueue("nota NPS",
+			`INSERT INTO nps_scores (user_id, nome, tipo_atendimento, nota, created_at) VALUES (?, ?, ?, ?, ?)`,
+			userID, nome, tipoAtendimento, nota, s.now().UTC(),
+		)
+	}
+	record := sheetsNPSRecord{nome, tipoAtendimento, nota}
+	s.fanOutNPS(ctx, record)
+	if !s.flags.enabled(FlagSheets) {
+		return s.queueSheets(sheetsKindNPS, record)
+	}
+	return s.dispatchSheets(ctx, sheetsKindNPS, record, func() error {
+		return s.sheets.SaveNPS(nome, tipoAtendimento, nota)
+	})
+}
+
+// errSheetsNotQueued indica que o registro não foi enviado ao Sheets nem guardado na fila local.
+var errSheetsNotQueued = errors.New("registro do Sheets não enviado nem guardado no banco")
+
+// queueSheets guarda o registro na tabela sheets_queue para envio posterior. Retorna erro se não
+// houver banco ou se a gravação não puder ser agendada; nesse caso o registro se perde.
+func (s *ChatbotService) queueSheets(kind string, record interface{}) error {
+	if s.writer == nil {
+		log.Printf("Sem banco local, registro %s do Sheets descartado", kind)
+		return errSheetsNotQueued
+	}
+	payload, _ := json.Marshal(record)
+	if err := s.writer.enqueue("registro pendente do Sheets",
+		`INSERT INTO sheets_queue (kind, payload, created_at) VALUES (?, ?, ?)`,
+		kind, string(payload), s.now().UTC(),
+	); err != nil {
+		return fmt.Errorf("%w: %v", errSheetsNotQueued, err)
+	}
+	return nil
+}
+
+// flushSheetsQueue reenvia ao Sheets os registros enfileirados localmente, removendo os enviados.
+// Uma execução por vez: o ticker e a religação do Sheets não reenviam o mesmo registro em paralelo.
+// Falhas passageiras (cota, indisponibilidade, credencial) interrompem o reenvio sem contar tentativa;
+// as demais contam, e após SheetsMaxAttempts o registro vai para sheets_dead_letters, liberando a fila.
+func (s *ChatbotService) flushSheetsQueue() {
+	if s.db == nil || s.sheetsPaused() {
+		return
+	}
+	s.sheetsFlush.Lock()
+	defer s.sheetsFlush.Unlock()
+
+	rows, err := s.queryDB(`SELECT id, kind, payload, attempts FROM sheets_queue ORDER BY id`)
+	if err != nil {
+		log.Printf("Erro ao ler fila local do Sheets: %v", err)
+		return
+	}
+	type pending struct {
+		id            int64
+		kind, payload string
+		attempts      int
+	}
+	var items []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.kind, &p.payload, &p.attempts); err == nil {
+			items = append(items, p)
+		}
+	}
+	rows.Close()
+
+	sent := 0
+	for _, p := range items {
+		err := s.replaySheets(p.kind, p.payload)
+		if err == nil {
+			// Sem a remoção o registro seria reenviado (e duplicado na planilha) no próximo ciclo.
+			if err := s.execDB(`DELETE FROM sheets_queue WHERE id = ?`, p.id); err != nil {
+				log.Printf("Registro %d reenviado ao Sheets mas não removido da fila local (será duplicado): %v", p.id, err)
+			}
+			sent++
+			continue
+		}
+		log.Printf("Erro ao reenviar registro %d ao Sheets: %v", p.id, err)
+		var se sheetsError
+		if errors.As(err, &se) && (se.Quota() || se.Temporary() || se.Auth()) {
+			if se.Quota() {
+				s.pauseSheets()
+			}
+			break
+		}
+		if p.attempts+1 >= s.cfg.SheetsMaxAttempts {
+			s.deadLetterSheets(p.id, p.attempts+1, err)
+			continue
+		}
+		if err := s.execDB(`UPDATE sheets_queue SET attempts = attempts + 1 WHERE id = ?`, p.id); err != nil {
+			log.Printf("Erro ao contar tentativa do registro %d do Sheets: %v", p.id, err)
+		}
+	}
+	if sent > 0 {
+		log.Printf("%d registros da fila local reenviados ao Sheets", sent)
+	}
+}
+
+// deadLetterSheets move o registro da fila local para sheets_dead_letters, com o último erro.
+func (s *ChatbotService) deadLetterSheets(id int64, attempts int, cause error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		log.Printf("Erro ao mover registro %d do Sheets para a dead-letter: %v", id, err)
+		return
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`INSERT INTO sheets_dead_letters (kind, payload, error, attempts, created_at, failed_at)
+		SELECT kind, payload, ?, ?, created_at, ? FROM sheets_queue WHERE id = ?`, cause.Error(), attempts, s.now().UTC(), id); err != nil {
+		log.Printf("Erro ao mover registro %d do Sheets para a dead-letter: %v", id, err)
+		return
+	}
+	if _, err := tx.Exec(`DELETE FROM sheets_queue WHERE id = ?`, id); err != nil {
+		log.Printf("Erro ao mover registro %d do Sheets para a dead-letter: %v", id, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Erro ao mover registro %d do Sheets para a dead-letter: %v", id, err)
+		return
+	}
+	log.Printf("Registro %d do Sheets desistido após %d tentativas, movido para sheets_dead_letters: %v", id, attempts, cause)
+}
+
+// replaySheets envia ao Sheets um registro serializado da fila local.
+func (s *ChatbotService) replaySheets(kind, payload string) error {
+	record, err := decodeRecord(kind, payload)
+	if err != nil {
+		return err
+	}
+	_, err = saveToStore(s.sheets, record)
+	return err
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestSaveReportsRecordsNotQueued(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) {
+		cfg.SheetsEnabled = false
+		cfg.SessionTTLs.Dedupe = 0
+	})
+	ctx := context.Background()
+
+	saves := map[string]func() error{
+		"support":  func() error { return s.saveSupport(ctx, "Ana", "sem sinal", "desc", "Resolvido pela IA", "") },
+		"plans":    func() error { return s.savePlans(ctx, "u1", "Ana", "Cliente", "100", "500", "44999998888", "", "") },
+		"feedback": func() error { return s.saveFeedback(ctx, "u1", "Ana", "Suporte Técnico", "Bom", "") },
+		"nps":      func() error { return s.saveNPS(ctx, "u1", "Ana", "Suporte Técnico", 9) },
+	}
+	for name, save := range saves {
+		if err := save(); !errors.Is(err, errSheetsNotQueued) {
+			t.Errorf("%s: err = %v; esperado errSheetsNotQueued sem Sheets nem banco", name, err)
+		}
+	}
+}
+
+func TestSaveQueuesLocallyWithSheetsOff(t *testing.T) {
+	s := newTestServiceWith(t, newTestDB(t), nil, nil, func(cfg *Config) {
+		cfg.SheetsEnabled = false
+		cfg.SessionTTLs.Dedupe = 0
+	})
+	ctx := context.Background()
+
+	if err := s.saveSupport(ctx, "Ana", "sem sinal", "desc", "Resolvido pela IA", ""); err != nil {
+		t.Errorf("saveSupport = %v; esperado enfileirar no banco local", err)
+	}
+	if err := s.saveFeedback(ctx, "u1", "Ana", "Suporte Técnico", "Bom", ""); err != nil {
+		t.Errorf("saveFeedback = %v; esperado enfileirar no banco local", err)
+	}
+}
+
+func TestFeedbackSaveFailureIsSurfaced(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.SheetsEnabled = false })
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.setUserData(user, UserData{Nome: "Ana", TipoAtendimento: "Suporte Técnico", Problema: "Bom", AguardandoFeedback: true})
+	s.setState(user, StateSupportFeedback)
+	response, err := s.route(ctx, ChannelWhatsApp, user, "nenhuma")
+	if !errors.Is(err, errSheetsNotQueued) {
+		t.Fatalf("route = %q, %v; esperado o erro de gravação", response, err)
+	}
+	if state := s.sessions.state(ctx, user); state != StateSupportFeedback {
+		t.Errorf("estado = %q; o fluxo não deve ser encerrado sem gravar o feedback", state)
+	}
+}
+
+func TestSavePlansSkipsDuplicateWithinDedupeWindow(t *testing.T) {
+	mr := miniredis.RunT(t)
+	sheets := &fakeSheets{}
+	s := newTestServiceOn(t, redis.NewClient(&redis.Options{Addr: mr.Addr()}), nil, sheets, nil, func(cfg *Config) {
+		cfg.SheetsEnabled = true
+		cfg.SessionTTLs.Dedupe = time.Hour
+	})
+	ctx := context.Background()
+
+	save := func(nome, telefone, plano string) {
+		t.Helper()
+		if err := s.savePlans(ctx, "u1", nome, "Cliente", "100", plano, telefone, "", ""); err != nil {
+			t.Fatalf("savePlans(%s, %s): %v", telefone, plano, err)
+		}
+	}
+	save("Ana", "(44) 99999-8888", "500 MEGA")
+	// Mesmo telefone e plano, com outra formatação: duplicado.
+	save("Ana de novo", "44999998888", " 500 mega")
+	// Outro plano do mesmo telefone: novo interesse.
+	save("Ana outro plano", "44999998888", "1 GIGA")
+	if names := sheets.names(); len(names) != 2 || names[0] != "Ana" || names[1] != "Ana outro plano" {
+		t.Fatalf("Sheets = %v; esperado o primeiro interesse de cada plano", names)
+	}
+
+	mr.FastForward(time.Hour)
+	save("Ana depois", "44999998888", "500 MEGA")
+	if names := sheets.names(); len(names) != 3 {
+		t.Errorf("Sheets = %v; esperado aceitar o par após a janela de deduplicação", names)
+	}
+}
+
+func TestSavePlansReleasesDedupeKeyOnFailure(t *testing.T) {
+	mr := miniredis.RunT(t)
+	sheets := &fakeSheets{fail: func(string) error { return errors.New("planilha fora") }}
+	s := newTestServiceOn(t, redis.NewClient(&redis.Options{Addr: mr.Addr()}), nil, sheets, nil, func(cfg *Config) {
+		cfg.SheetsEnabled = true
+		cfg.SessionTTLs.Dedupe = time.Hour
+	})
+	ctx := context.Background()
+
+	if err := s.savePlans(ctx, "u1", "Ana", "Cliente", "100", "500 MEGA", "44999998888", "", ""); err == nil {
+		t.Fatal("savePlans com o Sheets falhando e sem banco retornou nil")
+	}
+	for _, key := range mr.Keys() {
+		if strings.HasPrefix(key, leadDedupeKeyPrefix) {
+			t.Errorf("chave de deduplicação %s mantida após a falha", key)
+		}
+	}
+
+	sheets.fail = nil
+	if err := s.savePlans(ctx, "u1", "Ana", "Cliente", "100", "500 MEGA", "44999998888", "", ""); err != nil {
+		t.Fatalf("nova tentativa: %v", err)
+	}
+	if names := sheets.names(); len(names) != 1 {
+		t.Errorf("Sheets = %v; esperado a nova tentativa gravada, não tratada como duplicada", names)
+	}
+}
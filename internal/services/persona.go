@@ -0,0 +1,32 @@
+package services
+
+import "strings"
+
+// Persona e tom padrão do suporte técnico, usados quando AI_PERSONA e AI_TONE não são definidos.
+const (
+	defaultAIPersona = "Você é um técnico especializado em internet, modem e instalações da QI TELECOM."
+	defaultAITone    = "Seja técnico mas didático, lembrando que você está se relacionando com pessoas leigas no assunto."
+)
+
+// personaFormat escapa "%" no texto configurado, para que ele possa ser inserido no formato de assemblePrompt.
+func personaFormat(text string) string {
+	return strings.ReplaceAll(text, "%", "%%")
+}
+
+// technicalPersona retorna a persona do suporte técnico, pronta para o formato do prompt.
+func (s *ChatbotService) technicalPersona() string {
+	return personaFormat(s.cfg.AIPersona)
+}
+
+// technicalTone retorna o tom das respostas do suporte técnico, pronto para o formato do prompt.
+func (s *ChatbotService) technicalTone() string {
+	return personaFormat(s.cfg.AITone)
+}
+
+// freeTone retorna a orientação de tom do assistente livre, precedida de quebra de linha, ou "" se não configurada.
+func (s *ChatbotService) freeTone() string {
+	if s.cfg.AIFreeTone == "" {
+		return ""
+	}
+	return "\n" + personaFormat(s.cfg.AIFreeTone)
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTechnicalPromptUsesConfiguredPersonaAndTone(t *testing.T) {
+	client := &fakeAI{text: "Reinicie o roteador."}
+	s := newTestService(t, client, func(cfg *Config) {
+		cfg.AIPersona = "Você é a Qi, assistente 100% dedicada à fibra."
+		cfg.AITone = "Responda de forma descontraída."
+	})
+	const user = "5544999990000"
+
+	if _, err := s.startTechnicalSupport(context.Background(), user, "internet lenta"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Você é a Qi, assistente 100% dedicada à fibra.", "Responda de forma descontraída.", "internet lenta"} {
+		if !strings.Contains(client.prompt, want) {
+			t.Errorf("prompt = %q; esperado %q", client.prompt, want)
+		}
+	}
+	if strings.Contains(client.prompt, "%!") || strings.Contains(client.prompt, defaultAIPersona) {
+		t.Errorf("prompt = %q; esperado só a persona configurada, sem erros de formato", client.prompt)
+	}
+
+	if _, err := s.continueTechnicalSupport(context.Background(), user, 2, "internet lenta"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(client.prompt, s.cfg.AIPersona) || !strings.Contains(client.prompt, s.cfg.AITone) {
+		t.Errorf("prompt da nova tentativa = %q; esperado a persona e o tom configurados", client.prompt)
+	}
+}
+
+func TestFreeAIPromptAppendsTone(t *testing.T) {
+	client := &fakeAI{text: "Depende do plano."}
+	s := newTestService(t, client, func(cfg *Config) { cfg.AIFreeTone = "Use no máximo 3 frases." })
+	ctx := context.Background()
+	const user = "web-persona"
+
+	s.ProcessMessage(ctx, ChannelWeb, user, "oi")
+	s.ProcessMessage(ctx, ChannelWeb, user, "4")
+	s.ProcessMessage(ctx, ChannelWeb, user, "qual a velocidade ideal?")
+	if !strings.HasSuffix(client.prompt, "\nUse no máximo 3 frases.") {
+		t.Errorf("prompt = %q; esperado o tom do assistente livre no final", client.prompt)
+	}
+}
+
+func TestPersonaFromEnv(t *testing.T) {
+	if cfg := LoadConfig(); cfg.AIPersona != defaultAIPersona || cfg.AITone != defaultAITone || cfg.AIFreeTone != "" {
+		t.Errorf("padrão = %q / %q / %q; esperado a persona e o tom padrão", cfg.AIPersona, cfg.AITone, cfg.AIFreeTone)
+	}
+	t.Setenv("AI_PERSONA", "  Você é a Qi.  ")
+	t.Setenv("AI_TONE", "Seja breve.")
+	if cfg := LoadConfig(); cfg.AIPersona != "Você é a Qi." || cfg.AITone != "Seja breve." {
+		t.Errorf("AI_PERSONA/AI_TONE = %q / %q; esperado os valores do ambiente", cfg.AIPersona, cfg.AITone)
+	}
+}
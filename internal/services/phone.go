@@ -0,0 +1,48 @@
+package services
+
+import "strings"
+
+// Limites de dígitos de um número E.164 com código do país (ex.: 55 + DDD + número).
+const (
+	minPhoneDigits = 10
+	maxPhoneDigits = 15
+)
+
+// extractPhoneFromUserID retorna o telefone normalizado (somente dígitos, com código do país) contido no
+// userID do canal, ou "" se o canal não usa telefone como identificador. Só o WhatsApp usa: o "from" da
+// Cloud API é o número do remetente, às vezes com "+" ou formatação. IDs da web (UUIDs) e do Messenger
+// (PSIDs, também numéricos) nunca são tratados como telefone.
+func extractPhoneFromUserID(userID, channel string) string {
+	if channel != ChannelWhatsApp {
+		return ""
+	}
+	for _, r := range userID {
+		if !isPhoneRune(r) {
+			return ""
+		}
+	}
+	digits := onlyDigits(userID)
+	if len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits {
+		return ""
+	}
+	return digits
+}
+
+// parseContactPhone valida o telefone de contato digitado pelo usuário (entre minPhoneDigits e
+// maxPhoneDigits dígitos) e o retorna sem espaços; ok é falso se for inválido.
+func parseContactPhone(value string) (phone string, ok bool) {
+	digits := len(onlyDigits(value))
+	if digits < minPhoneDigits || digits > maxPhoneDigits {
+		return "", false
+	}
+	return strings.ReplaceAll(value, " ", ""), true
+}
+
+// isPhoneRune indica se o caractere pode aparecer em um número de telefone formatado.
+func isPhoneRune(r rune) bool {
+	switch r {
+	case '+', ' ', '-', '(', ')', '.':
+		return true
+	}
+	return r >= '0' && r <= '9'
+}
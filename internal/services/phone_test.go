@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExtractPhoneFromUserID(t *testing.T) {
+	cases := []struct {
+		userID, channel, want string
+	}{
+		{"5544999998888", ChannelWhatsApp, "5544999998888"},
+		{"+55 (44) 99999-8888", ChannelWhatsApp, "5544999998888"},
+		{"44999", ChannelWhatsApp, ""},
+		{"1234567890123456", ChannelWhatsApp, ""},
+		{"5544abc998888", ChannelWhatsApp, ""},
+		{"5544999998888", ChannelMessenger, ""},
+		{"3f2b8c1e-0d4a-4c1f-9a61-7e0b5d2c9a10", ChannelWeb, ""},
+	}
+	for _, c := range cases {
+		if got := extractPhoneFromUserID(c.userID, c.channel); got != c.want {
+			t.Errorf("extractPhoneFromUserID(%q, %q) = %q; esperado %q", c.userID, c.channel, got, c.want)
+		}
+	}
+}
+
+func TestParseContactPhone(t *testing.T) {
+	if phone, ok := parseContactPhone("(44) 99999-8888"); !ok || phone != "(44)99999-8888" {
+		t.Errorf("parseContactPhone = %q, %v; esperado o telefone sem espaços", phone, ok)
+	}
+	for _, invalid := range []string{"9999-8888", "abc", "+55 44 99999 8888 1234"} {
+		if _, ok := parseContactPhone(invalid); ok {
+			t.Errorf("parseContactPhone(%q) aceito; esperado inválido", invalid)
+		}
+	}
+}
+
+func TestPlansNameUsesWhatsAppNumberOnlyOnWhatsApp(t *testing.T) {
+	s := newTestServiceWith(t, nil, &fakeSheets{}, nil, nil)
+	ctx := context.Background()
+
+	s.setState("5544999998888", StatePlansName)
+	s.handlePlansName(ctx, ChannelWhatsApp, "5544999998888", "Maria Souza")
+	if got := s.getUserData("5544999998888").Telefone; got != "5544999998888" {
+		t.Errorf("Telefone no WhatsApp = %q; esperado o número do remetente", got)
+	}
+
+	s.setState("2345678901234", StatePlansName)
+	s.handlePlansName(ctx, ChannelMessenger, "2345678901234", "João Lima")
+	if got := s.getUserData("2345678901234").Telefone; got != "" {
+		t.Errorf("Telefone no Messenger = %q; o PSID não deveria ser usado como telefone", got)
+	}
+	if state := s.sessions.state(ctx, "2345678901234"); state != StatePlansPhone {
+		t.Errorf("estado no Messenger = %q; esperado pedir o telefone", state)
+	}
+}
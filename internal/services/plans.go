@@ -0,0 +1,145 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Plan descreve um plano comercial com velocidade e benefícios inclusos.
+type Plan struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	SpeedMbps int      `json:"speed_mbps"`
+	Features  []string `json:"features"`
+	// Aliases são os nomes curtos aceitos em comandos como "comparar premium e top".
+	Aliases []string `json:"-"`
+}
+
+// planCatalog é a lista de planos na ordem numerada apresentada ao usuário.
+var planCatalog = []Plan{
+	{ID: "basic", Name: "QI FIBRA BASIC", SpeedMbps: 300, Features: []string{"QI TV PLAY", "IPV6"}, Aliases: []string{"basic", "basico"}},
+	{ID: "premium", Name: "QI FIBRA PREMIUM", SpeedMbps: 600, Features: []string{"QI TV PLAY", "IPV6", "QUALIDADE QI"}, Aliases: []string{"premium"}},
+	{ID: "premium_melhor", Name: "QI FIBRA PREMIUM (MELHOR)", SpeedMbps: 650, Features: []string{"QI TV PLAY", "IPV6", "PARAMOUNT", "WATCH TV"}, Aliases: []string{"melhor", "premium melhor"}},
+	{ID: "premium_top", Name: "QI FIBRA PREMIUM TOP", SpeedMbps: 700, Features: []string{"QI TV PLAY", "IPV6", "PARAMOUNT", "WATCH TV"}, Aliases: []string{"top", "premium top"}},
+}
+
+// Plans retorna o catálogo de planos ativo, na ordem do menu.
+func (s *ChatbotService) Plans() []Plan {
+	return append([]Plan(nil), planCatalog...)
+}
+
+// findPlan localiza o plano pelo número do menu, ID, nome ou apelido; ok é falso se não houver.
+func findPlan(ref string) (Plan, bool) {
+	ref = normalizeCommand(ref)
+	if idx := planIndex(ref); idx != -1 {
+		return planCatalog[idx], true
+	}
+	for _, p := range planCatalog {
+		if ref == p.ID || ref == normalizeCommand(p.Name) {
+			return p, true
+		}
+		for _, a := range p.Aliases {
+			if ref == a {
+				return p, true
+			}
+		}
+	}
+	return Plan{}, false
+}
+
+// comparePrefixes são as formas aceitas de pedir a comparação entre dois planos.
+var comparePrefixes = []string{"comparar ", "compare ", "qual a diferenca entre ", "diferenca entre "}
+
+// parseCompareCommand extrai as duas referências de plano de "comparar X e Y"; ok é falso se o
+// comando não for uma comparação.
+func parseCompareCommand(cmd string) (a, b string, ok bool) {
+	for _, prefix := range comparePrefixes {
+		if rest, found := strings.CutPrefix(cmd, prefix); found {
+			for _, sep := range []string{" e ", " x ", " vs ", " com "} {
+				if a, b, found := strings.Cut(rest, sep); found {
+					return strings.TrimSpace(a), strings.TrimSpace(b), true
+				}
+			}
+			return strings.TrimSpace(rest), "", true
+		}
+	}
+	return "", "", false
+}
+
+// comparePlans responde ao pedido de comparação sem alterar o estado do fluxo de planos,
+// repetindo em seguida a pergunta pendente.
+func (s *ChatbotService) comparePlans(state SessionState, refA, refB string) (string, error) {
+	response := renderPlanComparison(refA, refB)
+	if prompt, ok := resumePrompts[state]; ok {
+		response += "\n\n" + prompt
+	}
+	return response, nil
+}
+
+// renderPlanComparison monta o comparativo lado a lado de velocidade e benefícios de dois planos.
+func renderPlanComparison(refA, refB string) string {
+	if refA == "" || refB == "" {
+		return "⚖️ Para comparar, informe dois planos. Ex.: *COMPARAR PREMIUM E TOP*\n\nPlanos: " + planAliasList()
+	}
+	a, okA := findPlan(refA)
+	b, okB := findPlan(refB)
+	var unknown []string
+	if !okA {
+		unknown = append(unknown, refA)
+	}
+	if !okB {
+		unknown = append(unknown, refB)
+	}
+	if len(unknown) > 0 {
+		return fmt.Sprintf("❓ Não encontrei o plano *%s*.\n\nPlanos disponíveis: %s", strings.Join(unknown, "* nem *"), planAliasList())
+	}
+	if a.ID == b.ID {
+		return fmt.Sprintf("⚖️ Os dois nomes se referem ao mesmo plano: *%s* (%d Mega).", a.Name, a.SpeedMbps)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "⚖️ *Comparativo de Planos*\n\n*%s* x *%s*\n", a.Name, b.Name)
+	fmt.Fprintf(&out, "🚀 Velocidade: %d Mega x %d Mega\n", a.SpeedMbps, b.SpeedMbps)
+	for _, f := range mergeFeatures(a.Features, b.Features) {
+		fmt.Fprintf(&out, "• %s: %s x %s\n", f, hasFeature(a, f), hasFeature(b, f))
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// mergeFeatures une os benefícios dos dois planos, preservando a ordem de aparição.
+func mergeFeatures(a, b []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, f := range append(append([]string{}, a...), b...) {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// hasFeature retorna "✅" se o plano inclui o benefício e "❌" caso contrário.
+func hasFeature(p Plan, feature string) string {
+	for _, f := range p.Features {
+		if f == feature {
+			return "✅"
+		}
+	}
+	return "❌"
+}
+
+// planAliasList lista os nomes curtos aceitos na comparação.
+func planAliasList() string {
+	names := make([]string, len(planCatalog))
+	for i, p := range planCatalog {
+		names[i] = "*" + p.Aliases[0] + "*"
+	}
+	return strings.Join(names, ", ")
+}
+
+// isCompareCommand indica se o comando normalizado pede a comparação entre planos.
+func isCompareCommand(cmd string) bool {
+	_, _, ok := parseCompareCommand(cmd)
+	return ok
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseCompareCommand(t *testing.T) {
+	cases := []struct {
+		cmd, a, b string
+		ok        bool
+	}{
+		{"comparar premium e top", "premium", "top", true},
+		{"qual a diferenca entre 1 x 4", "1", "4", true},
+		{"compare basic vs premium melhor", "basic", "premium melhor", true},
+		{"comparar premium", "premium", "", true},
+		{"quero o premium", "", "", false},
+	}
+	for _, c := range cases {
+		a, b, ok := parseCompareCommand(c.cmd)
+		if a != c.a || b != c.b || ok != c.ok {
+			t.Errorf("parseCompareCommand(%q) = %q, %q, %v; esperado %q, %q, %v", c.cmd, a, b, ok, c.a, c.b, c.ok)
+		}
+	}
+}
+
+func TestRenderPlanComparison(t *testing.T) {
+	got := renderPlanComparison("basic", "top")
+	for _, want := range []string{"*QI FIBRA BASIC* x *QI FIBRA PREMIUM TOP*", "Velocidade: 300 Mega x 700 Mega", "• IPV6: ✅ x ✅", "• PARAMOUNT: ❌ x ✅"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("comparativo sem %q:\n%s", want, got)
+		}
+	}
+
+	if got := renderPlanComparison("premium", "gold"); !strings.Contains(got, "Não encontrei o plano *gold*") {
+		t.Errorf("plano desconhecido = %q", got)
+	}
+	if got := renderPlanComparison("4", "premium top"); !strings.Contains(got, "mesmo plano") {
+		t.Errorf("mesmo plano por número e apelido = %q", got)
+	}
+	if got := renderPlanComparison("premium", ""); !strings.Contains(got, "informe dois planos") {
+		t.Errorf("só um plano = %q", got)
+	}
+}
+
+func TestCompareKeepsPlansFlowState(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	s.setState(user, StatePlansSelection)
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "Comparar Premium e Top")
+	if !strings.HasPrefix(response, "⚖️ *Comparativo de Planos*") || !strings.HasSuffix(response, resumePrompts[StatePlansSelection]) {
+		t.Errorf("resposta = %q; esperado o comparativo seguido da pergunta pendente", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StatePlansSelection {
+		t.Errorf("estado = %q; esperado continuar em %q", state, StatePlansSelection)
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"log"
+	"strings"
+	"unicode"
+)
+
+// Preprocessor transforma a mensagem recebida antes do roteamento.
+type Preprocessor func(string) string
+
+// preprocessors são os pré-processadores disponíveis em INPUT_PREPROCESSORS, por nome.
+var preprocessors = map[string]Preprocessor{
+	"trim":                 strings.TrimSpace,
+	"collapse_spaces":      collapseSpaces,
+	"strip_zero_width":     stripZeroWidth,
+	"normalize":            normalizePunctuation,
+	"expand_abbreviations": expandAbbreviations,
+}
+
+// defaultPreprocessors é o pipeline usado sem INPUT_PREPROCESSORS.
+var defaultPreprocessors = []string{"strip_zero_width", "normalize", "collapse_spaces", "trim"}
+
+// inputPipeline aplica os pré-processadores em sequência, na ordem configurada.
+type inputPipeline []Preprocessor
+
+// apply executa o pipeline sobre a mensagem. O resultado sai sempre sem espaços nas pontas, mesmo que
+// INPUT_PREPROCESSORS omita "trim": os handlers de estado contam com isso e não repetem a normalização.
+func (p inputPipeline) apply(message string) string {
+	for _, pre := range p {
+		message = pre(message)
+	}
+	return strings.TrimSpace(message)
+}
+
+// buildInputPipeline monta o pipeline a partir dos nomes configurados, ignorando (com aviso) os desconhecidos.
+func buildInputPipeline(names []string) inputPipeline {
+	var p inputPipeline
+	for _, name := range names {
+		pre, ok := preprocessors[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			log.Printf("INPUT_PREPROCESSORS: pré-processador desconhecido %q ignorado", name)
+			continue
+		}
+		p = append(p, pre)
+	}
+	return p
+}
+
+// collapseSpaces reduz sequências de espaços e tabulações a um espaço, preservando as quebras de linha.
+func collapseSpaces(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.FieldsFunc(line, func(r rune) bool {
+			return r != '\n' && unicode.IsSpace(r)
+		}), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// zeroWidthReplacer remove caracteres invisíveis colados por teclados e apps de mensagem.
+var zeroWidthReplacer = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\u2060", "", "\ufeff", "")
+
+// stripZeroWidth remove espaços de largura zero e marcas de ordem de bytes.
+func stripZeroWidth(s string) string {
+	return zeroWidthReplacer.Replace(s)
+}
+
+// punctuationReplacer troca aspas tipográficas, travessões e espaços especiais pelos equivalentes ASCII.
+var punctuationReplacer = strings.NewReplacer(
+	"\u00a0", " ", "‘", "'", "’", "'", "“", `"`, "”", `"`,
+	"–", "-", "—", "-", "…", "...",
+)
+
+// normalizePunctuation padroniza a pontuação tipográfica (comum em teclados de celular).
+func normalizePunctuation(s string) string {
+	return punctuationReplacer.Replace(s)
+}
+
+// abbreviations são as abreviações de chat expandidas por expand_abbreviations (palavras inteiras).
+var abbreviations = map[string]string{
+	"vc": "você", "vcs": "vocês", "pq": "porque", "tb": "também", "tbm": "também",
+	"q": "que", "qdo": "quando", "msg": "mensagem", "obg": "obrigado", "blz": "beleza",
+	"hj": "hoje", "td": "tudo", "cmg": "comigo", "net": "internet",
+}
+
+// expandAbbreviations troca as abreviações conhecidas pela palavra completa, sem diferenciar maiúsculas.
+func expandAbbreviations(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+			j++
+		}
+		word := string(runes[i:j])
+		if full, ok := abbreviations[strings.ToLower(word)]; ok {
+			word = full
+		}
+		b.WriteString(word)
+		i = j
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestInputPipelineRunsInConfiguredOrder(t *testing.T) {
+	// Com collapse_spaces antes de expand_abbreviations as abreviações já chegam separadas por um espaço.
+	got := buildInputPipeline([]string{"collapse_spaces", "expand_abbreviations"}).apply("vc   tb")
+	if got != "você também" {
+		t.Errorf("collapse→expand = %q", got)
+	}
+
+	// O espaço de largura zero não é espaço para collapse_spaces: só removido antes ele deixa de separar
+	// os espaços vizinhos, que então colapsam.
+	if got := buildInputPipeline([]string{"strip_zero_width", "collapse_spaces"}).apply("a \u200b b"); got != "a b" {
+		t.Errorf("strip→collapse = %q", got)
+	}
+	if got := buildInputPipeline([]string{"collapse_spaces", "strip_zero_width"}).apply("a \u200b b"); got != "a  b" {
+		t.Errorf("collapse→strip = %q", got)
+	}
+}
+
+func TestInputPipelineAlwaysTrims(t *testing.T) {
+	got := buildInputPipeline([]string{"strip_zero_width"}).apply("  \u200b1\u200b  ")
+	if got != "1" {
+		t.Errorf("apply = %q; esperado o resultado sem espaços nas pontas mesmo sem \"trim\"", got)
+	}
+	if got := buildInputPipeline(nil).apply(" 2 "); got != "2" {
+		t.Errorf("pipeline vazio = %q", got)
+	}
+}
+
+func TestInputPipelineIgnoresUnknownNames(t *testing.T) {
+	p := buildInputPipeline([]string{"nao_existe", " Collapse_Spaces "})
+	if len(p) != 1 {
+		t.Fatalf("len = %d; esperado só o pré-processador conhecido", len(p))
+	}
+}
+
+func TestHandlersReceivePreprocessedInput(t *testing.T) {
+	s := newTestServiceWith(t, nil, &fakeSheets{}, nil, func(cfg *Config) { cfg.InputPreprocessors = []string{"strip_zero_width"} })
+	ctx := context.Background()
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, "5544999998888", "oi")
+	s.ProcessMessage(ctx, ChannelWhatsApp, "5544999998888", " \u200b2\u200b ")
+	if state := s.sessions.state(ctx, "5544999998888"); state == StateMenu {
+		t.Errorf("opção com espaços e caracteres invisíveis não foi reconhecida; estado = %q", state)
+	}
+
+	s.setState("5544999998888", StateNPS)
+	s.ProcessMessage(ctx, ChannelWhatsApp, "5544999998888", "  9 ")
+	if state := s.sessions.state(ctx, "5544999998888"); state != StateMenu {
+		t.Errorf("estado = %q; esperado a nota com espaços aceita", state)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// profileNameKeyPrefix guarda o nome de perfil do WhatsApp informado no webhook, por usuário.
+const profileNameKeyPrefix = "profile:name:"
+
+// profileNameTTL limita por quanto tempo o nome de perfil é lembrado sem novas mensagens.
+const profileNameTTL = 24 * time.Hour
+
+// maxProfileNameRunes descarta nomes de perfil longos demais para serem um nome de pessoa.
+const maxProfileNameRunes = 60
+
+// SetProfileName guarda o nome de perfil do remetente (contacts[].profile.name do WhatsApp), usado para
+// sugerir o nome em vez de perguntá-lo. Nomes vazios ou sem letras são ignorados.
+func (s *ChatbotService) SetProfileName(userID, name string) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxProfileNameRunes || strings.IndexFunc(name, unicode.IsLetter) == -1 {
+		return
+	}
+	ctx := context.Background()
+	if err := s.redisDo(ctx, func() error { return s.redis.Set(ctx, profileNameKeyPrefix+userID, name, profileNameTTL).Err() }); err != nil {
+		log.Printf("Erro ao guardar nome de perfil de %s: %v", userID, err)
+	}
+}
+
+// profileName retorna o nome de perfil conhecido do usuário, ou "".
+func (s *ChatbotService) profileName(userID string) string {
+	ctx := context.Background()
+	var name string
+	s.redisDo(ctx, func() (err error) {
+		name, err = s.redis.Get(ctx, profileNameKeyPrefix+userID).Result()
+		return err
+	})
+	return name
+}
+
+// askName pede o nome completo (header + prompt) ou, se o canal informou o nome de perfil,
+// pede apenas a confirmação dele. Deve ser chamado depois de gravar os dados do fluxo.
+func (s *ChatbotService) askName(userID, header, prompt string) string {
+	name := s.profileName(userID)
+	if name == "" {
+		return header + prompt
+	}
+	userData := s.getUserData(userID)
+	userData.NomeSugerido = name
+	s.setUserData(userID, userData)
+	return header + fmt.Sprintf("É o seu nome, *%s*? Responda *SIM* ou digite seu *nome completo*:", name)
+}
+
+// resolveName interpreta a resposta à coleta do nome. Com um nome sugerido pendente, SIM o confirma,
+// NÃO volta a pedir o nome (reprompt) e qualquer outro texto é tratado como o nome digitado.
+func (s *ChatbotService) resolveName(userID, message string) (nome, reprompt string) {
+	userData := s.getUserData(userID)
+	if userData.NomeSugerido == "" {
+		return strings.TrimSpace(message), ""
+	}
+
+	suggested := userData.NomeSugerido
+	userData.NomeSugerido = ""
+	s.setUserData(userID, userData)
+
+	cmd := normalizeCommand(message)
+	switch {
+	case isYes(cmd):
+		return suggested, ""
+	case isNo(cmd):
+		return "", "Tudo bem! Então, qual o seu *nome completo*?"
+	}
+	return strings.TrimSpace(message), ""
+}
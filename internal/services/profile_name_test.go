@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSetProfileNameIgnoresInvalidNames(t *testing.T) {
+	s, _ := newRedisTestService(t, nil, nil)
+
+	for _, name := range []string{"", "   ", "12345 😀", strings.Repeat("a", maxProfileNameRunes+1)} {
+		s.SetProfileName("5544999998888", name)
+		if got := s.profileName("5544999998888"); got != "" {
+			t.Errorf("SetProfileName(%q) guardou %q; esperado ignorar", name, got)
+		}
+	}
+	s.SetProfileName("5544999998888", "  Ana Souza ")
+	if got := s.profileName("5544999998888"); got != "Ana Souza" {
+		t.Errorf("profileName = %q; esperado o nome sem espaços nas pontas", got)
+	}
+}
+
+func TestSupportConfirmsProfileName(t *testing.T) {
+	s, _ := newRedisTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+	s.SetProfileName(user, "Ana Souza")
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "1")
+	if !strings.Contains(response, "É o seu nome, *Ana Souza*?") {
+		t.Fatalf("resposta = %q; esperado confirmar o nome de perfil", response)
+	}
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "SIM")
+	if got := s.getUserData(user).Nome; got != "Ana Souza" {
+		t.Errorf("Nome = %q; esperado o nome de perfil confirmado", got)
+	}
+	if state := s.sessions.state(ctx, user); state != StateSupportProblem {
+		t.Errorf("estado = %q; esperado %q", state, StateSupportProblem)
+	}
+}
+
+func TestProfileNameRejectedOrReplaced(t *testing.T) {
+	s, _ := newRedisTestService(t, nil, nil)
+	ctx := context.Background()
+
+	const declined = "5544999990001"
+	s.SetProfileName(declined, "Ana")
+	s.ProcessMessage(ctx, ChannelWhatsApp, declined, "oi")
+	s.ProcessMessage(ctx, ChannelWhatsApp, declined, "1")
+	if response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, declined, "não"); !strings.Contains(response, "qual o seu *nome completo*?") {
+		t.Errorf("resposta ao NÃO = %q; esperado pedir o nome", response)
+	}
+	s.ProcessMessage(ctx, ChannelWhatsApp, declined, "Ana Maria Souza")
+	if got := s.getUserData(declined).Nome; got != "Ana Maria Souza" {
+		t.Errorf("Nome após recusar = %q; esperado o nome digitado", got)
+	}
+
+	const typed = "5544999990002"
+	s.SetProfileName(typed, "Ana")
+	s.ProcessMessage(ctx, ChannelWhatsApp, typed, "oi")
+	s.ProcessMessage(ctx, ChannelWhatsApp, typed, "1")
+	s.ProcessMessage(ctx, ChannelWhatsApp, typed, "Carlos Lima")
+	if got := s.getUserData(typed).Nome; got != "Carlos Lima" {
+		t.Errorf("Nome digitado = %q; esperado substituir o nome de perfil", got)
+	}
+}
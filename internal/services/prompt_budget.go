@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"unicode/utf8"
+)
+
+// promptPart é uma entrada do usuário inserida no prompt. Partes com menor priority são reduzidas
+// primeiro; keepEnd preserva o final do texto (ex.: histórico, cujas mensagens recentes importam mais).
+type promptPart struct {
+	text     string
+	priority int
+	keepEnd  bool
+}
+
+// assemblePrompt monta o prompt com as orientações de segurança (primeiro %s do formato) e as partes
+// sanitizadas e delimitadas, nesta ordem. Se o total passar de AIPromptMaxChars, as partes menos
+// importantes são cortadas até caber.
+func (s *ChatbotService) assemblePrompt(userID, format string, parts ...promptPart) string {
+	build := func() string {
+		args := []interface{}{promptGuardInstructions}
+		for _, p := range parts {
+			args = append(args, s.wrapUserInput(userID, p.text))
+		}
+		return fmt.Sprintf(format, args...)
+	}
+
+	prompt := build()
+	budget := s.cfg.AIPromptMaxChars
+	excess := utf8.RuneCountInString(prompt) - budget
+	if budget <= 0 || excess <= 0 {
+		return prompt
+	}
+
+	order := make([]int, len(parts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return parts[order[a]].priority < parts[order[b]].priority })
+	for _, i := range order {
+		if excess <= 0 {
+			break
+		}
+		runes := []rune(parts[i].text)
+		cut := excess
+		if cut > len(runes) {
+			cut = len(runes)
+		}
+		if parts[i].keepEnd {
+			parts[i].text = string(runes[cut:])
+		} else {
+			parts[i].text = string(runes[:len(runes)-cut])
+		}
+		excess -= cut
+	}
+
+	prompt = build()
+	log.Printf("Prompt da IA do usuário %s reduzido para caber em %d caracteres", userID, budget)
+	return prompt
+}
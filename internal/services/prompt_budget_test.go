@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAssemblePromptTrimsLeastImportantPartsFirst(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	history := "antigo-" + strings.Repeat("h", 40) + "-recente"
+	problem := "roteador-sem-sinal"
+	full := s.assemblePrompt("u1", "%s\n%s\n%s", promptPart{text: history, priority: 0, keepEnd: true}, promptPart{text: problem, priority: 1})
+
+	s.cfg.AIPromptMaxChars = utf8.RuneCountInString(full) - 10
+	got := s.assemblePrompt("u1", "%s\n%s\n%s", promptPart{text: history, priority: 0, keepEnd: true}, promptPart{text: problem, priority: 1})
+	if n := utf8.RuneCountInString(got); n > s.cfg.AIPromptMaxChars {
+		t.Errorf("prompt com %d caracteres; esperado até %d", n, s.cfg.AIPromptMaxChars)
+	}
+	if !strings.Contains(got, userInputOpen+problem+userInputClose) {
+		t.Errorf("parte mais importante cortada: %q", got)
+	}
+	if strings.Contains(got, "antigo-") || !strings.Contains(got, "-recente"+userInputClose) {
+		t.Errorf("histórico = %q; esperado cortar o início e manter as mensagens recentes", got)
+	}
+}
+
+func TestAssemblePromptCutsNextPartWhenFirstIsExhausted(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	parts := func() []promptPart {
+		return []promptPart{{text: "abc", priority: 0}, {text: "pergunta-longa-do-cliente", priority: 1}}
+	}
+	full := s.assemblePrompt("u1", "%s %s %s", parts()...)
+
+	s.cfg.AIPromptMaxChars = utf8.RuneCountInString(full) - 8
+	got := s.assemblePrompt("u1", "%s %s %s", parts()...)
+	if !strings.Contains(got, userInputOpen+userInputClose) || !strings.Contains(got, userInputOpen+"pergunta-longa-do-cl"+userInputClose) {
+		t.Errorf("prompt = %q; esperado esvaziar a parte menos importante e cortar o final da seguinte", got)
+	}
+}
+
+func TestAssemblePromptWithinBudgetIsUnchanged(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.AIPromptMaxChars = 0 })
+	long := strings.Repeat("x", 10000)
+	if got := s.assemblePrompt("u1", "%s %s", promptPart{text: long}); !strings.Contains(got, long) {
+		t.Error("com limite 0 o prompt não deveria ser cortado")
+	}
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// QuickReply é uma sugestão de próxima ação anexada à resposta. Title é o texto exibido
+// e Value o que é enviado ao bot quando o usuário toca na sugestão.
+type QuickReply struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// defaultQuickReplies são as sugestões por estado; os valores são entradas que o fluxo já entende.
+var defaultQuickReplies = map[SessionState][]QuickReply{
+	StateMenu:             {{"1", "1"}, {"2", "2"}, {"3", "3"}, {"4", "4"}},
+	StateSupportIA:        {{"Resolveu", "sim"}, {"Não resolveu", "nao"}, {"Menu", "menu"}},
+	StateSupportFeedback:  {{"Excelente", "Excelente"}, {"Bom", "Bom"}, {"Regular", "Regular"}},
+	StatePlansClientCheck: {{"Sim", "sim"}, {"Não", "nao"}},
+	StatePlansRetention:   {{"Sim", "sim"}, {"Não", "nao"}},
+	StatePlansContactTime: {{"Manhã", "1"}, {"Tarde", "2"}, {"Noite", "3"}},
+	StateAIFree:           {{"Menu", "menu"}},
+}
+
+// parseQuickReplies interpreta QUICK_REPLIES no formato "estado=Título:valor|Título;estado2=...".
+// Sem ":" o título também é o valor; estados informados substituem as sugestões padrão e uma lista vazia as remove.
+func parseQuickReplies(v string) map[SessionState][]QuickReply {
+	out := make(map[SessionState][]QuickReply, len(defaultQuickReplies))
+	for state, replies := range defaultQuickReplies {
+		out[state] = replies
+	}
+	for _, item := range strings.Split(v, ";") {
+		state, list, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			if item = strings.TrimSpace(item); item != "" {
+				log.Printf("QUICK_REPLIES: entrada inválida %q ignorada", item)
+			}
+			continue
+		}
+		var replies []QuickReply
+		for _, r := range strings.Split(list, "|") {
+			title, value, found := strings.Cut(strings.TrimSpace(r), ":")
+			title = strings.TrimSpace(title)
+			if title == "" {
+				continue
+			}
+			if !found {
+				value = title
+			}
+			replies = append(replies, QuickReply{Title: title, Value: strings.TrimSpace(value)})
+		}
+		out[configState("QUICK_REPLIES", state)] = replies
+	}
+	return out
+}
+
+// QuickReplies retorna as sugestões para o estado atual da sessão, ou nil se desativadas.
+// Deve ser chamado após ProcessMessage, para refletir o estado em que a resposta deixou o usuário.
+func (s *ChatbotService) QuickReplies(userID string) []QuickReply {
+	if !s.cfg.QuickRepliesEnabled {
+		return nil
+	}
+	return s.cfg.QuickReplies[s.sessions.state(context.Background(), userID)]
+}
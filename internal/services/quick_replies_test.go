@@ -0,0 +1,47 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuickReplies(t *testing.T) {
+	got := parseQuickReplies("menu=Planos:2|Suporte ; support_ia= ; linha inválida")
+
+	want := []QuickReply{{"Planos", "2"}, {"Suporte", "Suporte"}}
+	if !reflect.DeepEqual(got[StateMenu], want) {
+		t.Errorf("menu = %+v; esperado %+v", got[StateMenu], want)
+	}
+	if replies, ok := got[StateSupportIA]; !ok || len(replies) != 0 {
+		t.Errorf("support_ia = %+v; esperado lista vazia removendo as sugestões padrão", replies)
+	}
+	if !reflect.DeepEqual(got[StatePlansContactTime], defaultQuickReplies[StatePlansContactTime]) {
+		t.Errorf("plans_contact_time = %+v; esperado as sugestões padrão", got[StatePlansContactTime])
+	}
+	if reflect.DeepEqual(defaultQuickReplies[StateMenu], want) {
+		t.Error("parseQuickReplies alterou as sugestões padrão")
+	}
+}
+
+func TestQuickRepliesFollowSessionState(t *testing.T) {
+	const user = "web-qr"
+
+	s := newTestService(t, nil, func(cfg *Config) {
+		cfg.QuickRepliesEnabled = true
+		cfg.QuickReplies = parseQuickReplies("")
+	})
+	s.setState(user, StateMenu)
+	if got := s.QuickReplies(user); !reflect.DeepEqual(got, defaultQuickReplies[StateMenu]) {
+		t.Errorf("QuickReplies no menu = %+v; esperado as opções do menu", got)
+	}
+	s.setState(user, StatePlansClientCheck)
+	if got := s.QuickReplies(user); len(got) != 2 || got[0].Value != "sim" {
+		t.Errorf("QuickReplies em plans_client_check = %+v; esperado sim/não", got)
+	}
+
+	disabled := newTestService(t, nil, func(cfg *Config) { cfg.QuickReplies = parseQuickReplies("") })
+	disabled.setState(user, StateMenu)
+	if got := disabled.QuickReplies(user); got != nil {
+		t.Errorf("QuickReplies desativado = %+v; esperado nil", got)
+	}
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// errCircuitOpen é retornado sem chamar o Redis enquanto o circuito está aberto.
+var errCircuitOpen = errors.New("circuito do Redis aberto")
+
+// Estados do circuito do Redis.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// transientRedisReplies são os prefixos de respostas de erro do Redis que indicam indisponibilidade
+// passageira do servidor (carregando dados, réplica só leitura, cluster fora), em que vale repetir.
+var transientRedisReplies = []string{"LOADING ", "READONLY ", "MASTERDOWN ", "CLUSTERDOWN ", "TRYAGAIN ", "ERR max number of clients reached"}
+
+// isTransientRedisError indica se o erro é falha de conexão ou indisponibilidade passageira do Redis.
+// Respostas de erro do servidor (WRONGTYPE, comando inválido) mostram que ele está no ar: não são
+// repetidas nem contam para abrir o circuito. O cancelamento do contexto do chamador também não.
+func isTransientRedisError(err error) bool {
+	if err == nil || err == redis.Nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var reply redis.Error
+	if errors.As(err, &reply) {
+		for _, prefix := range transientRedisReplies {
+			if strings.HasPrefix(reply.Error(), prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// redisBreaker repete operações com erro transitório e, após Threshold falhas seguidas, abre o
+// circuito por Cooldown: nesse período as operações falham de imediato e a sessão usa a memória local.
+// Passado o cooldown, uma única operação de teste (half-open) decide se o circuito fecha ou reabre;
+// as demais continuam falhando de imediato enquanto ela não termina. Ao fechar, onRecover é chamado
+// em segundo plano para reconciliar no Redis o que foi gravado na memória local, sem atrasar a operação
+// que fechou o circuito.
+type redisBreaker struct {
+	Retries   int
+	Backoff   time.Duration
+	Threshold int
+	Cooldown  time.Duration
+	now       func() time.Time
+	onRecover func()
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// newRedisBreaker cria o breaker fechado com os parâmetros da configuração.
+func newRedisBreaker(cfg Config, now func() time.Time) *redisBreaker {
+	return &redisBreaker{
+		Retries:   cfg.RedisRetries,
+		Backoff:   cfg.RedisRetryBackoff,
+		Threshold: cfg.RedisBreakerThreshold,
+		Cooldown:  cfg.RedisBreakerCooldown,
+		now:       now,
+		state:     CircuitClosed,
+	}
+}
+
+// State retorna o estado atual do circuito.
+func (b *redisBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow indica se a operação pode ir ao Redis, passando a half-open quando o cooldown termina.
+// Em half-open só a operação de teste passa.
+func (b *redisBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.Cooldown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		log.Printf("Circuito do Redis em half-open, testando conexão")
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// record atualiza o circuito com o resultado da operação. Retorna true quando o circuito acaba de fechar.
+func (b *redisBreaker) record(err error) (recovered bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err == nil {
+		recovered = b.state != CircuitClosed
+		if recovered {
+			log.Printf("Circuito do Redis fechado")
+		}
+		b.state = CircuitClosed
+		b.failures = 0
+		return recovered
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || (b.Threshold > 0 && b.failures >= b.Threshold) {
+		if b.state != CircuitOpen {
+			log.Printf("Circuito do Redis aberto por %s após %d falhas: %v", b.Cooldown, b.failures, err)
+		}
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+	return false
+}
+
+// release libera a vaga de teste do half-open sem alterar o circuito, quando a operação não diz nada
+// sobre o Redis (contexto do chamador cancelado).
+func (b *redisBreaker) release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// do executa a operação com até Retries novas tentativas em erros transitórios (isTransientRedisError).
+// redis.Nil (chave inexistente) e respostas de erro do servidor não são falha do Redis e são repassados
+// ao chamador sem repetir.
+func (b *redisBreaker) do(ctx context.Context, op func() error) error {
+	if !b.allow() {
+		return errCircuitOpen
+	}
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = op()
+		if !isTransientRedisError(err) || attempt >= b.Retries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			b.settle(err)
+			return err
+		case <-time.After(b.Backoff):
+		}
+	}
+	b.settle(err)
+	return err
+}
+
+// settle registra no circuito o resultado final da operação.
+func (b *redisBreaker) settle(err error) {
+	switch {
+	case isTransientRedisError(err):
+		b.record(err)
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		b.release()
+	default:
+		if b.record(nil) && b.onRecover != nil {
+			go b.onRecover()
+		}
+	}
+}
+
+// memoryStoreMaxEntries limita as chaves guardadas na memória local durante uma queda do Redis.
+const memoryStoreMaxEntries = 10000
+
+// memoryStore guarda chaves de sessão em memória, com expiração, enquanto o Redis está fora.
+// O conteúdo é local ao processo: quando o circuito fecha, sessionStore.reconcile leva ao Redis as
+// chaves gravadas aqui (a cópia local, mais recente, vence a do Redis). Com várias instâncias, cada
+// uma tem a própria memória; um usuário atendido por instâncias diferentes durante a queda pode ter o
+// progresso de uma delas sobrescrito pela outra na reconciliação, ou recomeçar o fluxo.
+// O tamanho é limitado a maxEntries: cheio, as chaves expiradas são varridas e, se preciso, a que expira
+// primeiro é descartada.
+type memoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	now        func() time.Time
+	maxEntries int
+}
+
+type memoryEntry struct {
+	value   string
+	expires time.Time
+}
+
+func newMemoryStore(now func() time.Time) *memoryStore {
+	return &memoryStore{entries: make(map[string]memoryEntry), now: now, maxEntries: memoryStoreMaxEntries}
+}
+
+// get retorna o valor da chave; ok é falso se ela não existir ou tiver expirado.
+func (m *memoryStore) get(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || (!e.expires.IsZero() && m.now().After(e.expires)) {
+		delete(m.entries, key)
+		return "", false
+	}
+	return e.value, true
+}
+
+// set grava a chave com o TTL informado (0 não expira), abrindo espaço se a memória estiver cheia.
+func (m *memoryStore) set(key, value string, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.entries[key]; !exists && m.maxEntries > 0 && len(m.entries) >= m.maxEntries {
+		m.sweepLocked()
+		if len(m.entries) >= m.maxEntries {
+			m.evictLocked()
+		}
+	}
+	e := memoryEntry{value: value}
+	if ttl > 0 {
+		e.expires = m.now().Add(ttl)
+	}
+	m.entries[key] = e
+}
+
+// sweep remove as chaves expiradas.
+func (m *memoryStore) sweep() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweepLocked()
+}
+
+// sweepLocked é sweep com mu travado.
+func (m *memoryStore) sweepLocked() {
+	now := m.now()
+	for k, e := range m.entries {
+		if !e.expires.IsZero() && now.After(e.expires) {
+			delete(m.entries, k)
+		}
+	}
+}
+
+// evictLocked descarta a chave que expira primeiro (as sem expiração por último). Deve ser chamado com mu travado.
+func (m *memoryStore) evictLocked() {
+	var victim string
+	var victimExpires time.Time
+	for k, e := range m.entries {
+		if victim == "" || (!e.expires.IsZero() && (victimExpires.IsZero() || e.expires.Before(victimExpires))) {
+			victim, victimExpires = k, e.expires
+		}
+	}
+	delete(m.entries, victim)
+	log.Printf("Memória local de sessões cheia (%d chaves); chave %s descartada", m.maxEntries, victim)
+}
+
+// expire renova o TTL da chave, se existir.
+func (m *memoryStore) expire(key string, ttl time.Duration) {
+	if v, ok := m.get(key); ok {
+		m.set(key, v, ttl)
+	}
+}
+
+// ttl retorna o tempo restante da chave (0 se não expira); ok é falso se ela não existir.
+func (m *memoryStore) ttl(key string) (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return 0, false
+	}
+	if e.expires.IsZero() {
+		return 0, true
+	}
+	left := e.expires.Sub(m.now())
+	return left, left > 0
+}
+
+// del remove as chaves.
+func (m *memoryStore) del(keys ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range keys {
+		delete(m.entries, k)
+	}
+}
+
+// keys retorna as chaves ainda não expiradas.
+func (m *memoryStore) keys() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweepLocked()
+	keys := make([]string, 0, len(m.entries))
+	for k := range m.entries {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// delIf remove a chave se ela ainda tiver o valor informado, preservando gravações feitas depois.
+func (m *memoryStore) delIf(key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok && e.value == value {
+		delete(m.entries, key)
+	}
+}
+
+// redisDo executa a operação pelo breaker do Redis. É o caminho das chaves fora da sessão (índice de
+// sessões ativas, fila de atendimento, marcas de onboarding, nome de perfil, status de entrega): com o
+// circuito aberto elas falham de imediato, sem ir à rede, e cada chamador trata a falha como dado
+// indisponível, sem cópia na memória local.
+func (s *ChatbotService) redisDo(ctx context.Context, op func() error) error {
+	return s.sessions.breaker.do(ctx, op)
+}
+
+// RedisCircuitState retorna o estado do circuito do Redis (closed, open ou half_open).
+func (s *ChatbotService) RedisCircuitState() string {
+	return s.sessions.breaker.State()
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// replyError imita uma resposta de erro do servidor Redis (implementa redis.Error).
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+func (replyError) RedisError()     {}
+
+// flakyOp é uma operação de Redis que falha com errs em sequência e depois tem sucesso.
+type flakyOp struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyOp) run() error {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return f.errs[f.calls-1]
+	}
+	return nil
+}
+
+func newTestBreaker(clock *fixedClock, retries, threshold int) *redisBreaker {
+	return &redisBreaker{Retries: retries, Threshold: threshold, Cooldown: time.Minute, now: clock.now, state: CircuitClosed}
+}
+
+func TestBreakerRetriesTransientErrors(t *testing.T) {
+	b := newTestBreaker(&fixedClock{t: time.Now()}, 2, 5)
+	op := &flakyOp{errs: []error{io.EOF, replyError("LOADING Redis is loading the dataset in memory")}}
+
+	if err := b.do(context.Background(), op.run); err != nil {
+		t.Fatalf("do = %v; esperado sucesso na terceira tentativa", err)
+	}
+	if op.calls != 3 {
+		t.Errorf("calls = %d; esperado 3", op.calls)
+	}
+}
+
+func TestBreakerDoesNotRetryReplyErrors(t *testing.T) {
+	b := newTestBreaker(&fixedClock{t: time.Now()}, 2, 1)
+	wrongType := replyError("WRONGTYPE Operation against a key holding the wrong kind of value")
+	op := &flakyOp{errs: []error{wrongType, wrongType}}
+
+	if err := b.do(context.Background(), op.run); !errors.Is(err, wrongType) {
+		t.Fatalf("do = %v; esperado repassar o erro do servidor", err)
+	}
+	if op.calls != 1 {
+		t.Errorf("calls = %d; respostas de erro do servidor não devem ser repetidas", op.calls)
+	}
+	if b.State() != CircuitClosed {
+		t.Errorf("estado = %q; o servidor respondeu, o circuito não deve abrir", b.State())
+	}
+}
+
+func TestBreakerIgnoresCallerCancellation(t *testing.T) {
+	b := newTestBreaker(&fixedClock{t: time.Now()}, 0, 1)
+	op := &flakyOp{errs: []error{context.Canceled}}
+
+	b.do(context.Background(), op.run)
+	if b.State() != CircuitClosed {
+		t.Errorf("estado = %q; cancelamento do chamador não é falha do Redis", b.State())
+	}
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b := newTestBreaker(&fixedClock{t: time.Now()}, 0, 2)
+	op := &flakyOp{errs: []error{io.EOF, io.EOF}}
+
+	b.do(context.Background(), op.run)
+	if b.State() != CircuitClosed {
+		t.Fatalf("estado = %q após 1 falha", b.State())
+	}
+	b.do(context.Background(), op.run)
+	if b.State() != CircuitOpen {
+		t.Fatalf("estado = %q; esperado aberto após 2 falhas", b.State())
+	}
+	calls := op.calls
+	if err := b.do(context.Background(), op.run); !errors.Is(err, errCircuitOpen) || op.calls != calls {
+		t.Errorf("do = %v com %d chamadas; esperado falhar sem chamar o Redis", err, op.calls-calls)
+	}
+}
+
+func TestBreakerHalfOpenAllowsSingleProbe(t *testing.T) {
+	clock := &fixedClock{t: time.Now()}
+	b := newTestBreaker(clock, 0, 1)
+	recovered := make(chan struct{}, 2)
+	b.onRecover = func() { recovered <- struct{}{} }
+	b.do(context.Background(), (&flakyOp{errs: []error{io.EOF}}).run)
+	clock.advance(time.Minute)
+
+	probeStarted := make(chan struct{})
+	finishProbe := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.do(context.Background(), func() error {
+			close(probeStarted)
+			<-finishProbe
+			return nil
+		})
+	}()
+	<-probeStarted
+
+	other := &flakyOp{}
+	if err := b.do(context.Background(), other.run); !errors.Is(err, errCircuitOpen) || other.calls != 0 {
+		t.Errorf("do durante o teste = %v com %d chamadas; esperado só uma operação de teste", err, other.calls)
+	}
+
+	close(finishProbe)
+	wg.Wait()
+	if b.State() != CircuitClosed {
+		t.Errorf("estado = %q; esperado fechado após o teste bem-sucedido", b.State())
+	}
+	select {
+	case <-recovered:
+	case <-time.After(time.Second):
+		t.Error("onRecover não foi chamado ao fechar o circuito")
+	}
+	if err := b.do(context.Background(), other.run); err != nil || other.calls != 1 {
+		t.Errorf("do após fechar = %v com %d chamadas", err, other.calls)
+	}
+	select {
+	case <-recovered:
+		t.Error("onRecover chamado de novo com o circuito já fechado")
+	default:
+	}
+}
+
+func TestBreakerHalfOpenFailureReopens(t *testing.T) {
+	clock := &fixedClock{t: time.Now()}
+	b := newTestBreaker(clock, 0, 1)
+	op := &flakyOp{errs: []error{io.EOF, io.EOF}}
+	b.do(context.Background(), op.run)
+	clock.advance(time.Minute)
+
+	b.do(context.Background(), op.run)
+	if b.State() != CircuitOpen {
+		t.Errorf("estado = %q; esperado reabrir com a falha do teste", b.State())
+	}
+}
+
+func TestMemoryStoreIsBounded(t *testing.T) {
+	clock := &fixedClock{t: time.Now()}
+	m := newMemoryStore(clock.now)
+	m.maxEntries = 2
+
+	m.set("a", "1", time.Minute)
+	m.set("b", "2", time.Hour)
+	m.set("c", "3", time.Hour)
+	if len(m.entries) != 2 {
+		t.Fatalf("len = %d; esperado o limite de 2", len(m.entries))
+	}
+	if _, ok := m.get("a"); ok {
+		t.Error("a chave que expira primeiro deveria ter sido descartada")
+	}
+
+	clock.advance(2 * time.Hour)
+	m.set("d", "4", time.Hour)
+	if len(m.entries) != 1 {
+		t.Errorf("len = %d; esperado varrer as expiradas antes de descartar", len(m.entries))
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	clock := &fixedClock{t: time.Now()}
+	m := newMemoryStore(clock.now)
+	m.set("curta", "1", time.Minute)
+	m.set("longa", "2", time.Hour)
+
+	clock.advance(2 * time.Minute)
+	m.sweep()
+	if _, ok := m.entries["curta"]; ok {
+		t.Error("chave expirada não foi varrida")
+	}
+	if _, ok := m.entries["longa"]; !ok {
+		t.Error("chave válida foi varrida")
+	}
+}
+
+func TestSessionPrefersLocalCopyWrittenDuringOutage(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.sessions.setState(ctx, user, StatePlansName)
+	if state := s.sessions.state(ctx, user); state != StatePlansName {
+		t.Fatalf("estado = %q; esperado a cópia local com o Redis fora", state)
+	}
+	if _, ok := s.sessions.memory.get(stateKeyPrefix + user); !ok {
+		t.Error("cópia local descartada sem ter sido gravada no Redis")
+	}
+}
+
+func TestAuxiliaryRedisCallsFailFastWithCircuitOpen(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	s.sessions.setState(context.Background(), "u1", StateMenu) // abre o circuito (limiar 1)
+	if s.RedisCircuitState() != CircuitOpen {
+		t.Fatalf("estado = %q; esperado aberto com o Redis fora", s.RedisCircuitState())
+	}
+
+	if _, err := s.enqueueHandoff("u1"); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("enqueueHandoff = %v; esperado errCircuitOpen", err)
+	}
+	if _, err := s.ResolveHandoff("u1"); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("ResolveHandoff = %v; esperado errCircuitOpen", err)
+	}
+	if err := s.RecordDeliveryStatus("wamid.1", "read", "u1"); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("RecordDeliveryStatus = %v; esperado errCircuitOpen", err)
+	}
+	if channel := s.sessionChannel("u1"); channel != "" {
+		t.Errorf("sessionChannel = %q; esperado vazio com o circuito aberto", channel)
+	}
+}
+
+func TestReconcileRunsInBackgroundAfterRecovery(t *testing.T) {
+	s, mr := newRedisTestService(t, nil, func(cfg *Config) { cfg.RedisBreakerCooldown = time.Millisecond })
+	ctx := context.Background()
+
+	mr.Close()
+	s.sessions.setState(ctx, "u1", StatePlansName)
+	s.sessions.setState(ctx, "u2", StateSupportName)
+	if s.RedisCircuitState() != CircuitOpen {
+		t.Fatalf("estado = %q; esperado aberto com o Redis fora", s.RedisCircuitState())
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if state := s.sessions.state(ctx, "u1"); state != StatePlansName {
+		t.Fatalf("estado de u1 = %q; esperado a cópia local", state)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if v, err := mr.Get(stateKeyPrefix + "u2"); err == nil && v == string(StateSupportName) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("a sessão de u2 gravada durante a queda não foi reconciliada no Redis")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
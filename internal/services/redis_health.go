@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// redisPinger é a verificação de conectividade usada pelo heartbeat (o *redis.Client em produção).
+type redisPinger func(ctx context.Context) error
+
+// redisHealth acompanha a disponibilidade do Redis, atualizada pelo heartbeat.
+type redisHealth struct {
+	ping redisPinger
+	up   atomic.Bool
+}
+
+// check executa um ping e registra em log as transições de estado. Retorna o estado atual.
+func (h *redisHealth) check(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	err := h.ping(ctx)
+	up := err == nil
+	if was := h.up.Swap(up); was != up {
+		if up {
+			log.Printf("Redis disponível novamente")
+		} else {
+			log.Printf("Redis indisponível: %v", err)
+		}
+	}
+	return up
+}
+
+// StartRedisHeartbeat verifica o Redis imediatamente e depois a cada cfg.RedisHeartbeatInterval,
+// até o contexto ser cancelado. O go-redis reconecta sozinho; o heartbeat só observa o estado e
+// varre as chaves expiradas da memória local usada com o Redis fora.
+func (s *ChatbotService) StartRedisHeartbeat(ctx context.Context) {
+	s.redisHealth.check(ctx)
+
+	ticker := time.NewTicker(s.cfg.RedisHeartbeatInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.redisHealth.check(ctx)
+				s.sessions.memory.sweep()
+			}
+		}
+	}()
+}
+
+// RedisAvailable indica se o último heartbeat alcançou o Redis.
+func (s *ChatbotService) RedisAvailable() bool {
+	return s.redisHealth.up.Load()
+}
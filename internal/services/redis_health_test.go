@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRedisHeartbeatTracksAvailability(t *testing.T) {
+	s, mr := newRedisTestService(t, nil, func(cfg *Config) { cfg.RedisHeartbeatInterval = time.Hour })
+	ctx := context.Background()
+
+	s.StartRedisHeartbeat(ctx)
+	if !s.RedisAvailable() {
+		t.Fatal("RedisAvailable = false com o Redis no ar")
+	}
+
+	mr.Close()
+	if s.redisHealth.check(ctx) || s.RedisAvailable() {
+		t.Error("RedisAvailable = true com o Redis fora")
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.redisHealth.check(ctx) || !s.RedisAvailable() {
+		t.Error("RedisAvailable = false após o Redis voltar; esperado o cliente reconectar")
+	}
+}
+
+func TestRedisHeartbeatStopsWithContext(t *testing.T) {
+	pings := make(chan struct{}, 100)
+	s := newTestService(t, nil, func(cfg *Config) { cfg.RedisHeartbeatInterval = 5 * time.Millisecond })
+	s.redisHealth.ping = func(context.Context) error { pings <- struct{}{}; return nil }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.StartRedisHeartbeat(ctx)
+	<-pings
+	<-pings
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	for len(pings) > 0 {
+		<-pings
+	}
+	time.Sleep(30 * time.Millisecond)
+	if n := len(pings); n != 0 {
+		t.Errorf("%d pings após cancelar o contexto; esperado nenhum", n)
+	}
+}
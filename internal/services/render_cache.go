@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// displaySettings são as configurações que alteram os textos fixos (menu, planos, contatos)
+// e podem ser recarregadas sem reiniciar o serviço.
+type displaySettings struct {
+	mainMenuMessage string
+	listStyles      map[string]string
+	units           []BusinessUnit
+}
+
+// displaySettingsFrom extrai as configurações de exibição de cfg.
+func displaySettingsFrom(cfg Config) displaySettings {
+	return displaySettings{mainMenuMessage: cfg.MainMenuMessage, listStyles: cfg.ListStyles, units: cfg.Units}
+}
+
+// renderCache guarda os textos fixos já renderizados, por chave (tipo + estilo de lista),
+// para que não sejam remontados a cada mensagem. É esvaziado quando a configuração é recarregada.
+type renderCache struct {
+	mu       sync.RWMutex
+	settings displaySettings
+	entries  map[string]string
+	// gen muda a cada recarga, para descartar renderizações feitas com as configurações antigas.
+	gen uint64
+}
+
+// newRenderCache cria o cache com as configurações de exibição iniciais.
+func newRenderCache(settings displaySettings) *renderCache {
+	return &renderCache{settings: settings, entries: make(map[string]string)}
+}
+
+// display retorna as configurações de exibição em vigor.
+func (c *renderCache) display() displaySettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings
+}
+
+// get retorna o texto da chave, renderizando-o com as configurações em vigor na primeira vez.
+func (c *renderCache) get(key string, render func(d displaySettings) string) string {
+	c.mu.RLock()
+	text, ok := c.entries[key]
+	settings, gen := c.settings, c.gen
+	c.mu.RUnlock()
+	if ok {
+		return text
+	}
+
+	text = render(settings)
+	c.mu.Lock()
+	// Só guarda se não houve recarga durante a renderização.
+	if c.gen == gen {
+		c.entries[key] = text
+	}
+	c.mu.Unlock()
+	return text
+}
+
+// reload troca as configurações de exibição e descarta os textos renderizados.
+func (c *renderCache) reload(settings displaySettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settings = settings
+	c.entries = make(map[string]string)
+	c.gen++
+}
+
+// ReloadConfig relê do ambiente as configurações de exibição (MAIN_MENU_MESSAGE, LIST_STYLES,
+// UNITS_JSON) e invalida os textos em cache. As demais configurações exigem reinício.
+func (s *ChatbotService) ReloadConfig() {
+	s.render.reload(displaySettingsFrom(LoadConfig()))
+	log.Printf("Configuração de exibição recarregada; cache de textos invalidado")
+}
+
+// listStyleName retorna o nome do estilo de lista do canal pelo qual o usuário conversa.
+func (s *ChatbotService) listStyleName(userID string) string {
+	if name, ok := s.render.display().listStyles[s.sessionChannel(userID)]; ok {
+		return name
+	}
+	return defaultListStyle
+}
+
+// planList retorna a lista de planos do estilo do usuário: numerada (com ou sem negrito)
+// ou, com detailed, com marcadores e velocidade/benefícios de cada plano.
+func (s *ChatbotService) planList(userID string, detailed, bold bool) string {
+	style := s.listStyleName(userID)
+	key := fmt.Sprintf("plans:%s:%t:%t", style, detailed, bold)
+	return s.render.get(key, func(displaySettings) string {
+		if detailed {
+			return strings.TrimRight(listStyles[style].bulleted(planItems(true), bold), "\n")
+		}
+		return listStyles[style].numbered(planItems(false), bold)
+	})
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// errNoDatabase indica que a operação depende do banco local, que não foi configurado.
+var errNoDatabase = errors.New("banco de dados não configurado")
+
+// maxReplyAttempts é o número de reenvios de uma resposta guardada antes de ela ser descartada.
+const maxReplyAttempts = 10
+
+// QueueReply guarda uma resposta que não pôde ser entregue para reenvio pelo StartReplyReplay.
+func (s *ChatbotService) QueueReply(channel, recipient, text string) error {
+	if s.db == nil {
+		return errNoDatabase
+	}
+	_, err := s.db.Exec(`INSERT INTO pending_replies (channel, recipient, text, created_at) VALUES (?, ?, ?, ?)`,
+		channel, recipient, text, s.now().UTC())
+	if err != nil {
+		log.Printf("Erro ao guardar resposta pendente para %s: %v", recipient, err)
+	}
+	return err
+}
+
+// StartReplyReplay reenvia periodicamente as respostas pendentes pelos pushers registrados,
+// até o contexto ser cancelado.
+func (s *ChatbotService) StartReplyReplay(ctx context.Context) {
+	if s.db == nil {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.ReplyReplayInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.replayPendingReplies()
+			}
+		}
+	}()
+}
+
+// replayPendingReplies tenta entregar cada resposta pendente, removendo as enviadas
+// e descartando as que excederam maxReplyAttempts.
+func (s *ChatbotService) replayPendingReplies() {
+	rows, err := s.db.Query(`SELECT id, channel, recipient, text, attempts FROM pending_replies ORDER BY id LIMIT 50`)
+	if err != nil {
+		log.Printf("Erro ao ler respostas pendentes: %v", err)
+		return
+	}
+	type pending struct {
+		id                      int64
+		channel, recipient, txt string
+		attempts                int
+	}
+	var items []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.channel, &p.recipient, &p.txt, &p.attempts); err == nil {
+			items = append(items, p)
+		}
+	}
+	rows.Close()
+
+	for _, p := range items {
+		push, ok := s.pushers[p.channel]
+		if !ok || (p.channel == ChannelWhatsApp && !s.flags.enabled(FlagWhatsApp)) {
+			continue
+		}
+		if err := push(p.recipient, p.txt); err != nil {
+			if p.attempts+1 >= maxReplyAttempts {
+				log.Printf("Resposta pendente %d para %s descartada após %d tentativas: %v", p.id, p.recipient, p.attempts+1, err)
+				s.db.Exec(`DELETE FROM pending_replies WHERE id = ?`, p.id)
+				s.LogOutbound(p.channel, p.recipient, "", p.txt, "failed")
+				continue
+			}
+			s.db.Exec(`UPDATE pending_replies SET attempts = attempts + 1 WHERE id = ?`, p.id)
+			continue
+		}
+		s.db.Exec(`DELETE FROM pending_replies WHERE id = ?`, p.id)
+		s.LogOutbound(p.channel, p.recipient, "", p.txt, "sent")
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resumePrompts traz, para cada estado retomável, a pergunta que o usuário deixou sem resposta.
+var resumePrompts = map[SessionState]string{
+	StateSupportName:      "Para continuar, preciso do seu *nome completo*:",
+	StateSupportProblem:   "Descreva o problema técnico que você está enfrentando:",
+	StateSupportIA:        "A última solução resolveu seu problema?\n- Digite *SIM* se resolveu\n- Digite *NÃO* se não resolveu",
+	StateSupportFeedback:  "Poderia nos dar um *feedback/opinião* sobre nosso atendimento? (Ex: Excelente, Bom, Regular...)",
+	StatePlansClientCheck: "Você já é cliente QI TELECOM? Responda *SIM* ou *NÃO*.",
+	StatePlansCurrent:     "Qual seu *plano atual*? Digite o número correspondente:\n" + numberedPlans(),
+	StatePlansSelection:   "Qual plano desperta seu interesse? Digite o número correspondente:\n" + numberedPlans(),
+	StatePlansRetention:   "Deseja aproveitar a oferta? Responda *SIM* ou *NÃO*.",
+	StatePlansName:        "Para avançar, preciso do seu *nome completo*:",
+	StatePlansContactTime: contactTimePrompt,
+	StatePlansPhone:       "Informe um *telefone/WhatsApp* para contato (somente números ou formato (XX) XXXXX-XXXX):",
+	StateBoletoIdentifier: "Informe o *CPF*, *CNPJ* ou *número do contrato* do titular (somente números):",
+	StateAIFree:           "Pode fazer sua pergunta ao assistente. 🤖",
+}
+
+// isResumable indica se o estado representa um fluxo em andamento que pode ser retomado.
+func isResumable(state SessionState) bool {
+	_, ok := resumePrompts[state]
+	return ok
+}
+
+// offerResume avisa o usuário que há um atendimento em andamento, sem descartá-lo.
+func (s *ChatbotService) offerResume(userID string, state SessionState) (string, error) {
+	return "👋 *Que bom ter você de volta!*\n\nVocê tem um atendimento em andamento" + s.recap(userID) +
+		"\n\nDigite *CONTINUAR* para retomar de onde parou ou *MENU* para recomeçar.", nil
+}
+
+// resumeFlow retoma o fluxo no estado salvo, com um resumo dos dados já informados.
+// Estados terminais (ou sessão inexistente) levam ao menu principal.
+func (s *ChatbotService) resumeFlow(userID string, state SessionState) (string, error) {
+	if !isResumable(state) {
+		return s.showMainMenu(userID)
+	}
+	prompt := resumePrompts[state]
+	if state == StateSupportFeedback && s.getUserData(userID).AguardandoFeedback {
+		prompt = "Tem alguma *sugestão* ou *comentário* para melhorarmos nosso atendimento?\n\n*(Digite sua sugestão ou 'NÃO' se não tiver)*"
+	}
+	return "▶️ *Retomando seu atendimento*" + s.recap(userID) + "\n\n" + prompt, nil
+}
+
+// recap lista os dados já coletados na sessão, ou "" se nenhum foi informado.
+func (s *ChatbotService) recap(userID string) string {
+	userData := s.getUserData(userID)
+	fields := []struct{ label, value string }{
+		{"Atendimento", userData.TipoAtendimento},
+		{"Nome", userData.Nome},
+		{"Problema", userData.Problema},
+		{"Situação", userData.Situacao},
+		{"Plano atual", userData.PlanoAtual},
+		{"Plano desejado", userData.PlanoDesejado},
+		{"Telefone", userData.Telefone},
+	}
+
+	var b strings.Builder
+	for _, f := range fields {
+		if f.value != "" {
+			fmt.Fprintf(&b, "\n*%s*: %s", f.label, f.value)
+		}
+	}
+	if b.Len() == 0 {
+		return "."
+	}
+	return ":\n" + b.String()
+}
+
+// numberedPlans lista os planos com o número a ser digitado, no estilo padrão.
+func numberedPlans() string {
+	return listStyles[defaultListStyle].numbered(planItems(false), true)
+}
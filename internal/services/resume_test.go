@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGreetingOffersResumeAndContinuarResumes(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "2")
+	s.setState(user, StatePlansPhone)
+	userData := s.getUserData(user)
+	userData.Nome = "Maria Souza"
+	userData.PlanoDesejado = "Plano 500MB"
+	s.setUserData(user, userData)
+
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	if !strings.Contains(response, "atendimento em andamento") || !strings.Contains(response, "*Nome*: Maria Souza") {
+		t.Errorf("saudação = %q; esperado oferecer a retomada com o resumo", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StatePlansPhone {
+		t.Fatalf("estado após a saudação = %q; esperado manter %q", state, StatePlansPhone)
+	}
+
+	response, _ = s.ProcessMessage(ctx, ChannelWhatsApp, user, "continuar")
+	if !strings.HasPrefix(response, "▶️ *Retomando seu atendimento*") || !strings.HasSuffix(response, resumePrompts[StatePlansPhone]) {
+		t.Errorf("CONTINUAR = %q; esperado retomar com a pergunta do telefone", response)
+	}
+	if got := s.getUserData(user); got.Nome != "Maria Souza" || got.PlanoDesejado != "Plano 500MB" {
+		t.Errorf("dados = %+v; esperado manter o que já foi informado", got)
+	}
+}
+
+func TestContinuarWithoutFlowShowsMenu(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "continuar")
+	if !strings.Contains(response, s.mainMenu(user)) {
+		t.Errorf("CONTINUAR sem fluxo = %q; esperado o menu principal", response)
+	}
+}
+
+func TestRecapWithoutData(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	if got := s.recap("5544999998888"); got != "." {
+		t.Errorf("recap = %q; esperado \".\" sem dados coletados", got)
+	}
+}
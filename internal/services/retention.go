@@ -0,0 +1,44 @@
+package services
+
+import "fmt"
+
+// Eventos de analytics da oferta de retenção.
+const (
+	eventRetentionOffer    = "retention_offer"
+	eventRetentionAccepted = "retention_accepted"
+	eventRetentionDeclined = "retention_declined"
+)
+
+// offerRetention apresenta o incentivo configurado ao cliente que optou por manter o plano atual.
+func (s *ChatbotService) offerRetention(userID string) (string, error) {
+	s.recordEvent(eventRetentionOffer, "", s.sessionChannel(userID))
+	s.setState(userID, StatePlansRetention)
+	return fmt.Sprintf("🎁 *Antes de decidir...*\n\n%s\n\nResponda *SIM* para aproveitar a oferta ou *NÃO* para manter seu plano atual.", s.cfg.RetentionMessage), nil
+}
+
+// handlePlansRetention processa a resposta à oferta de retenção.
+// Aceitando, o fluxo segue para a coleta de contato; recusando, encerra como antes.
+func (s *ChatbotService) handlePlansRetention(userID, message string) (string, error) {
+	response := normalizeCommand(message)
+	userData := s.getUserData(userID)
+
+	if isYes(response) {
+		s.recordEvent(eventRetentionAccepted, "", s.sessionChannel(userID))
+		userData.PlanoDesejado = fmt.Sprintf("%s (oferta de retenção)", userData.PlanoAtual)
+		s.setUserData(userID, userData)
+		s.setState(userID, StatePlansName)
+		return s.askName(userID, "🎉 *Oferta aceita!*\n\n", "📝 Para registrarmos, preciso do seu *nome completo*:"), nil
+	}
+
+	if isNo(response) {
+		s.recordEvent(eventRetentionDeclined, "", s.sessionChannel(userID))
+		return s.confirmKeepPlan(userID)
+	}
+
+	return s.invalidInput(userID, "Por favor, responda *SIM* ou *NÃO*.")
+}
+
+// confirmKeepPlan encerra o fluxo de planos mantendo o plano atual do cliente.
+func (s *ChatbotService) confirmKeepPlan(userID string) (string, error) {
+	return s.finishFlow(userID, "✅ *Entendido!*\n\nVocê optou por manter seu plano atual. Se mudar de ideia, estaremos aqui!\n\nDigite *MENU* para voltar ao menu principal.")
+}
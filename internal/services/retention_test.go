@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// keepCurrentPlan leva o cliente à escolha do mesmo plano que já tem.
+func keepCurrentPlan(t *testing.T, s *ChatbotService, user string) string {
+	t.Helper()
+	s.setUserData(user, UserData{Nome: "Ana Souza", PlanoAtual: planOptions[0]})
+	s.setState(user, StatePlansSelection)
+	response, err := s.route(context.Background(), ChannelWhatsApp, user, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func TestKeepingPlanWithoutRetentionFinishesFlow(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.RetentionEnabled = false })
+	const user = "5544999998888"
+
+	response := keepCurrentPlan(t, s, user)
+	if !strings.Contains(response, "manter seu plano atual") {
+		t.Errorf("resposta = %q; esperado confirmar a manutenção do plano", response)
+	}
+	if state := s.sessions.state(context.Background(), user); state != StateMenu {
+		t.Errorf("estado = %q; esperado %q", state, StateMenu)
+	}
+}
+
+func TestRetentionOfferUsesConfiguredMessage(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) {
+		cfg.RetentionEnabled = true
+		cfg.RetentionMessage = "Ganhe 3 meses de streaming grátis!"
+	})
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	response := keepCurrentPlan(t, s, user)
+	if !strings.Contains(response, "Ganhe 3 meses de streaming grátis!") {
+		t.Errorf("resposta = %q; esperado o incentivo configurado", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StatePlansRetention {
+		t.Fatalf("estado = %q; esperado %q", state, StatePlansRetention)
+	}
+
+	response, _ = s.route(ctx, ChannelWhatsApp, user, "talvez")
+	if !strings.Contains(response, "SIM") || s.sessions.state(ctx, user) != StatePlansRetention {
+		t.Errorf("resposta inválida = %q; esperado pedir SIM ou NÃO sem sair da oferta", response)
+	}
+
+	s.route(ctx, ChannelWhatsApp, user, "Sim")
+	if state := s.sessions.state(ctx, user); state != StatePlansName {
+		t.Errorf("estado após aceitar = %q; esperado seguir para a coleta de contato", state)
+	}
+	if want := planOptions[0] + " (oferta de retenção)"; s.getUserData(user).PlanoDesejado != want {
+		t.Errorf("plano desejado = %q; esperado %q", s.getUserData(user).PlanoDesejado, want)
+	}
+}
+
+func TestRetentionOfferDeclinedKeepsPlan(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.RetentionEnabled = true })
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	keepCurrentPlan(t, s, user)
+	response, _ := s.route(ctx, ChannelWhatsApp, user, "NÃO")
+	if !strings.Contains(response, "manter seu plano atual") {
+		t.Errorf("resposta = %q; esperado confirmar a manutenção do plano", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateMenu {
+		t.Errorf("estado = %q; esperado %q", state, StateMenu)
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// SetupSchema cria as tabelas do banco local, se ainda não existirem, e acrescenta as colunas que faltam
+// em bancos criados por versões anteriores. É usado na inicialização e pelos testes.
+func SetupSchema(db *sql.DB) error {
+	// Criar tabela se não existir
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS leads (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			nome TEXT NOT NULL,
+			telefone TEXT,
+			email TEXT,
+			tipo TEXT DEFAULT 'Lead',
+			plano TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Bancos criados antes da identificação de clientes não têm a coluna plano
+	if err = addColumn(db, "leads", "plano TEXT"); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS analytics_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			event_type TEXT NOT NULL,
+			option TEXT,
+			channel TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS sheets_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			attempts INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Bancos criados antes do limite de tentativas não têm a coluna attempts
+	if err = addColumn(db, "sheets_queue", "attempts INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS sheets_dead_letters (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			error TEXT,
+			attempts INTEGER,
+			created_at DATETIME,
+			failed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS notification_dead_letters (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			notifier TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			body TEXT NOT NULL,
+			error TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			replayed_at DATETIME
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS store_dead_letters (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			store TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			error TEXT,
+			attempts INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			replayed_at DATETIME
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS outbound_messages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel TEXT NOT NULL,
+			recipient TEXT NOT NULL,
+			message_id TEXT,
+			text TEXT NOT NULL,
+			status TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS nps_scores (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			nome TEXT,
+			tipo_atendimento TEXT,
+			nota INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS pending_replies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel TEXT NOT NULL,
+			recipient TEXT NOT NULL,
+			text TEXT NOT NULL,
+			attempts INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addColumn acrescenta a coluna à tabela de um banco já existente; se ela já existir, não faz nada.
+func addColumn(db *sql.DB, table, column string) error {
+	_, err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column)
+	if err != nil && strings.Contains(err.Error(), "duplicate column") {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetupSchemaUpgradesExistingDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "old.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// Banco de uma versão anterior, sem leads.plano nem sheets_queue.attempts.
+	for _, stmt := range []string{
+		`CREATE TABLE leads (id INTEGER PRIMARY KEY AUTOINCREMENT, nome TEXT NOT NULL, telefone TEXT, email TEXT, tipo TEXT DEFAULT 'Lead', created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE TABLE sheets_queue (id INTEGER PRIMARY KEY AUTOINCREMENT, kind TEXT NOT NULL, payload TEXT NOT NULL, created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Aplicar duas vezes não deve falhar: as colunas já acrescentadas são ignoradas.
+	for i := 0; i < 2; i++ {
+		if err := SetupSchema(db); err != nil {
+			t.Fatalf("SetupSchema (execução %d): %v", i+1, err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO leads (nome, plano) VALUES ('Ana', '500 MEGA')`); err != nil {
+		t.Errorf("leads sem a coluna plano após SetupSchema: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO sheets_queue (kind, payload, attempts) VALUES ('plans', '{}', 1)`); err != nil {
+		t.Errorf("sheets_queue sem a coluna attempts após SetupSchema: %v", err)
+	}
+	for _, table := range []string{"analytics_events", "outbound_messages", "nps_scores", "pending_replies", "notification_dead_letters", "store_dead_letters", "sheets_dead_letters"} {
+		count(t, db, table)
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Prefixos das chaves de sessão no Redis.
+const (
+	stateKeyPrefix      = "chat:"
+	dataKeyPrefix       = "data:"
+	stateSinceKeyPrefix = "since:"
+)
+
+// SessionTTLs define o tempo de vida de cada tipo de chave mantida no Redis.
+// TTLs curtos demais derrubam conversas em andamento; longos demais desperdiçam memória.
+// Dedupe é a janela de deduplicação de interesses (telefone+plano) e Idempotency a das mensagens já
+// recebidas pelo webhook; nos dois, 0 desativa a deduplicação.
+type SessionTTLs struct {
+	State       time.Duration
+	Data        time.Duration
+	Dedupe      time.Duration
+	Idempotency time.Duration
+	History     time.Duration
+}
+
+// sessionStore centraliza o acesso às chaves de sessão no Redis e a aplicação dos TTLs.
+// As operações passam pelo breaker; com o Redis fora, as chaves ficam em memória local.
+type sessionStore struct {
+	redis   *redis.Client
+	ttl     SessionTTLs
+	now     func() time.Time
+	breaker *redisBreaker
+	memory  *memoryStore
+}
+
+// get lê a chave. Uma cópia na memória local só existe se foi gravada com o Redis fora e é mais recente
+// que a do Redis: ela vence e é levada ao Redis se ele já tiver voltado. ok é falso se a chave não existir
+// ou se o Redis estiver fora sem cópia local.
+func (st *sessionStore) get(ctx context.Context, key string) (string, bool) {
+	if value, ok := st.memory.get(key); ok {
+		st.writeBack(ctx, key, value)
+		return value, true
+	}
+	var value string
+	err := st.breaker.do(ctx, func() (err error) {
+		value, err = st.redis.Get(ctx, key).Result()
+		return err
+	})
+	return value, err == nil
+}
+
+// set grava a chave no Redis com o TTL informado; com o Redis fora, grava na memória local. Uma gravação
+// bem-sucedida no Redis descarta a cópia local, que deixou de ser a mais recente.
+func (st *sessionStore) set(ctx context.Context, key, value string, ttl time.Duration) {
+	err := st.breaker.do(ctx, func() error {
+		return st.redis.Set(ctx, key, value, ttl).Err()
+	})
+	if err != nil {
+		st.memory.set(key, value, ttl)
+		return
+	}
+	st.memory.del(key)
+}
+
+// writeBack leva ao Redis, com o TTL restante, uma chave gravada na memória local durante a queda e a
+// remove da memória. Com o circuito aberto não faz nada.
+func (st *sessionStore) writeBack(ctx context.Context, key, value string) bool {
+	ttl, ok := st.memory.ttl(key)
+	if !ok {
+		return false
+	}
+	err := st.breaker.do(ctx, func() error {
+		return st.redis.Set(ctx, key, value, ttl).Err()
+	})
+	if err != nil {
+		return false
+	}
+	st.memory.delIf(key, value)
+	return true
+}
+
+// claim grava a chave só se ela ainda não existir, com o TTL informado, e retorna fresh=true se a
+// gravação ocorreu. Com o Redis fora, a marcação fica na memória local e o erro é repassado.
+func (st *sessionStore) claim(ctx context.Context, key string, ttl time.Duration) (fresh bool, err error) {
+	err = st.breaker.do(ctx, func() (err error) {
+		fresh, err = st.redis.SetNX(ctx, key, 1, ttl).Result()
+		return err
+	})
+	if err != nil {
+		if _, ok := st.memory.get(key); ok {
+			return false, err
+		}
+		st.memory.set(key, "1", ttl)
+		return true, err
+	}
+	return fresh, nil
+}
+
+// release remove a chave gravada por claim, no Redis e na memória local.
+func (st *sessionStore) release(ctx context.Context, key string) {
+	st.memory.del(key)
+	st.breaker.do(ctx, func() error {
+		return st.redis.Del(ctx, key).Err()
+	})
+}
+
+// claimDedupe marca a chave de deduplicação de interesses com o TTL de deduplicação.
+func (st *sessionStore) claimDedupe(ctx context.Context, key string) (bool, error) {
+	return st.claim(ctx, key, st.ttl.Dedupe)
+}
+
+// claimIdempotency marca a chave de idempotência do webhook com o TTL de idempotência.
+func (st *sessionStore) claimIdempotency(ctx context.Context, key string) (bool, error) {
+	return st.claim(ctx, key, st.ttl.Idempotency)
+}
+
+// reconcile leva ao Redis todas as chaves gravadas na memória local durante a queda. É chamado pelo
+// breaker quando o circuito fecha; o que não puder ser gravado fica na memória e é levado na próxima leitura.
+func (st *sessionStore) reconcile() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	keys := st.memory.keys()
+	written := 0
+	for _, key := range keys {
+		value, ok := st.memory.get(key)
+		if !ok {
+			continue
+		}
+		if !st.writeBack(ctx, key, value) {
+			break
+		}
+		written++
+	}
+	if len(keys) > 0 {
+		log.Printf("Redis de volta: %d de %d chaves da memória local reconciliadas", written, len(keys))
+	}
+}
+
+// state retorna o estado atual da conversa, ou "" se não houver sessão. Com o Redis fora e sem cópia
+// local o estado também é "", e o usuário recomeça pelo menu.
+func (st *sessionStore) state(ctx context.Context, userID string) SessionState {
+	state, _ := st.get(ctx, stateKeyPrefix+userID)
+	return SessionState(state)
+}
+
+// setState grava o estado da conversa com o TTL de estado. Ao mudar de estado, registra
+// também o instante de entrada, usado pelos timeouts por estado. Com o Redis fora, grava na memória local.
+func (st *sessionStore) setState(ctx context.Context, userID string, state SessionState) {
+	prev := st.state(ctx, userID)
+	now := strconv.FormatInt(st.now().Unix(), 10)
+	err := st.breaker.do(ctx, func() error {
+		pipe := st.redis.TxPipeline()
+		pipe.Set(ctx, stateKeyPrefix+userID, string(state), st.ttl.State)
+		if prev != state {
+			pipe.Set(ctx, stateSinceKeyPrefix+userID, now, st.ttl.State)
+		} else {
+			pipe.Expire(ctx, stateSinceKeyPrefix+userID, st.ttl.State)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		st.memory.set(stateKeyPrefix+userID, string(state), st.ttl.State)
+		if prev != state {
+			st.memory.set(stateSinceKeyPrefix+userID, now, st.ttl.State)
+		}
+		return
+	}
+	st.memory.del(stateKeyPrefix + userID)
+	if prev != state {
+		st.memory.del(stateSinceKeyPrefix + userID)
+	}
+}
+
+// stateSince retorna quando o usuário entrou no estado atual; ok é falso se não houver registro.
+func (st *sessionStore) stateSince(ctx context.Context, userID string) (since time.Time, ok bool) {
+	raw, ok := st.get(ctx, stateSinceKeyPrefix+userID)
+	if !ok {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0), true
+}
+
+// resetStateSince reinicia a contagem do tempo no estado atual.
+func (st *sessionStore) resetStateSince(ctx context.Context, userID string) {
+	st.set(ctx, stateSinceKeyPrefix+userID, strconv.FormatInt(st.now().Unix(), 10), st.ttl.State)
+}
+
+// data retorna os dados coletados na sessão; sessão inexistente resulta em UserData vazio.
+func (st *sessionStore) data(ctx context.Context, userID string) (UserData, error) {
+	var userData UserData
+	raw, ok := st.get(ctx, dataKeyPrefix+userID)
+	if !ok {
+		return userData, nil
+	}
+	err := json.Unmarshal([]byte(raw), &userData)
+	return userData, err
+}
+
+// setData grava os dados coletados na sessão com o TTL de dados.
+func (st *sessionStore) setData(ctx context.Context, userID string, userData UserData) error {
+	raw, err := json.Marshal(userData)
+	if err != nil {
+		return err
+	}
+	st.set(ctx, dataKeyPrefix+userID, string(raw), st.ttl.Data)
+	return nil
+}
+
+// refresh renova o TTL de estado e dados a cada mensagem (expiração deslizante), mesmo quando
+// a mensagem não altera a sessão; conversas ativas não expiram no meio do fluxo e as ociosas expiram.
+func (st *sessionStore) refresh(ctx context.Context, userID string) {
+	err := st.breaker.do(ctx, func() error {
+		pipe := st.redis.Pipeline()
+		pipe.Expire(ctx, stateKeyPrefix+userID, st.ttl.State)
+		pipe.Expire(ctx, dataKeyPrefix+userID, st.ttl.Data)
+		pipe.Expire(ctx, stateSinceKeyPrefix+userID, st.ttl.State)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		st.memory.expire(stateKeyPrefix+userID, st.ttl.State)
+		st.memory.expire(dataKeyPrefix+userID, st.ttl.Data)
+		st.memory.expire(stateSinceKeyPrefix+userID, st.ttl.State)
+	}
+}
+
+// clear remove estado, dados e histórico da sessão, no Redis e na memória local.
+func (st *sessionStore) clear(ctx context.Context, userID string) error {
+	st.memory.del(stateKeyPrefix+userID, dataKeyPrefix+userID, stateSinceKeyPrefix+userID, historyKeyPrefix+userID)
+	return st.breaker.do(ctx, func() error {
+		return st.redis.Del(ctx, stateKeyPrefix+userID, dataKeyPrefix+userID, stateSinceKeyPrefix+userID, historyKeyPrefix+userID).Err()
+	})
+}
+
+// setState grava o novo estado da conversa do usuário.
+func (s *ChatbotService) setState(userID string, state SessionState) {
+	s.sessions.setState(context.Background(), userID, state)
+}
+
+// ResetSession descarta a sessão do usuário (estado, dados, histórico e rastreamento de inatividade, também
+// na memória local usada com o Redis fora), fazendo a próxima mensagem recomeçar pelo menu.
+// Usado pela equipe de suporte em conversas travadas.
+func (s *ChatbotService) ResetSession(userID string) error {
+	if err := s.expireSession(userID); err != nil {
+		return fmt.Errorf("erro ao reiniciar sessão: %w", err)
+	}
+	log.Printf("Sessão do usuário %s reiniciada pelo suporte", userID)
+	return nil
+}
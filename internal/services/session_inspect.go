@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"leadprojectarrumado/internal/security"
+)
+
+// SessionInspection é a visão de uma sessão para depuração pelo suporte (GET /admin/session/{userID}).
+type SessionInspection struct {
+	UserID     string       `json:"user_id"`
+	State      SessionState `json:"state"`
+	StateSince *time.Time   `json:"state_since,omitempty"`
+	Data       UserData     `json:"data"`
+	// TTLSeconds é o tempo restante de cada chave da sessão (state, data, state_since, history);
+	// chaves inexistentes são omitidas e -1 indica chave sem expiração.
+	TTLSeconds map[string]int64  `json:"ttl_seconds"`
+	History    []TranscriptEntry `json:"history"`
+}
+
+// sessionKeys mapeia o nome exibido de cada chave da sessão ao seu prefixo no Redis.
+var sessionKeys = map[string]string{
+	"state":       stateKeyPrefix,
+	"data":        dataKeyPrefix,
+	"state_since": stateSinceKeyPrefix,
+	"history":     historyKeyPrefix,
+}
+
+// InspectSession retorna o estado, os dados, os TTLs e o histórico recente da sessão do usuário.
+// Os dados voltam sem máscara; a exibição deve usar Redacted salvo acesso explícito.
+func (s *ChatbotService) InspectSession(userID string) (SessionInspection, error) {
+	ctx := context.Background()
+	state := s.sessions.state(ctx, userID)
+	data, err := s.sessions.data(ctx, userID)
+	if err != nil {
+		return SessionInspection{}, err
+	}
+	in := SessionInspection{
+		UserID:     userID,
+		State:      state,
+		Data:       data,
+		TTLSeconds: s.sessions.ttls(ctx, userID),
+		History:    s.sessions.history(ctx, userID),
+	}
+	if since, ok := s.sessions.stateSince(ctx, userID); ok {
+		in.StateSince = &since
+	}
+	return in, nil
+}
+
+// ttls consulta o tempo restante de cada chave da sessão, no Redis ou, com ele fora, na memória local.
+func (st *sessionStore) ttls(ctx context.Context, userID string) map[string]int64 {
+	out := make(map[string]int64, len(sessionKeys))
+	err := st.breaker.do(ctx, func() error {
+		pipe := st.redis.Pipeline()
+		cmds := make(map[string]interface{ Val() time.Duration }, len(sessionKeys))
+		for name, prefix := range sessionKeys {
+			cmds[name] = pipe.TTL(ctx, prefix+userID)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		for name, cmd := range cmds {
+			// O Redis responde -2 para chave inexistente e -1 para chave sem expiração.
+			switch d := cmd.Val(); {
+			case d == -1:
+				out[name] = -1
+			case d >= 0:
+				out[name] = int64(d / time.Second)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for name, prefix := range sessionKeys {
+			if d, ok := st.memory.ttl(prefix + userID); ok {
+				if d == 0 {
+					out[name] = -1
+				} else {
+					out[name] = int64(d / time.Second)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Redacted retorna uma cópia com os dados pessoais mascarados: nome só com as iniciais, telefone com os
+// 4 últimos dígitos e CPFs, e-mails e telefones mascarados nos textos livres e no histórico.
+func (in SessionInspection) Redacted() SessionInspection {
+	out := in
+	d := &out.Data
+	d.Nome = security.MaskName(d.Nome)
+	d.NomeSugerido = security.MaskName(d.NomeSugerido)
+	d.Telefone = security.MaskPhone(d.Telefone)
+	d.Problema = security.RedactPII(d.Problema)
+	d.Descricao = security.RedactPII(d.Descricao)
+	if len(in.History) > 0 {
+		out.History = make([]TranscriptEntry, len(in.History))
+		for i, e := range in.History {
+			e.Text = security.RedactPII(e.Text)
+			out.History[i] = e
+		}
+	}
+	return out
+}
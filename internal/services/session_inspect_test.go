@@ -0,0 +1,51 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInspectSessionReportsStateDataAndTTLs(t *testing.T) {
+	s, _ := newRedisTestService(t, nil, func(cfg *Config) { cfg.EscalationTranscript = true })
+	const user = "5544999998888"
+	s.setState(user, StateSupportProblem)
+	s.setUserData(user, UserData{Nome: "Maria da Silva", Telefone: "5544999998888"})
+	s.recordTranscript(user, TranscriptCustomer, "meu CPF é 123.456.789-09")
+
+	in, err := s.InspectSession(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.UserID != user || in.State != StateSupportProblem || in.Data.Nome != "Maria da Silva" || len(in.History) != 1 {
+		t.Errorf("InspectSession = %+v; esperado o estado, os dados e o histórico gravados", in)
+	}
+	for _, key := range []string{"state", "data", "history"} {
+		if ttl, ok := in.TTLSeconds[key]; !ok || ttl <= 0 {
+			t.Errorf("TTL de %s = %d (%v); esperado o tempo restante da chave", key, ttl, ok)
+		}
+	}
+
+	empty, err := s.InspectSession("5544000000000")
+	if err != nil || len(empty.TTLSeconds) != 0 || empty.History != nil {
+		t.Errorf("sessão inexistente = %+v, %v; esperado sem TTLs nem histórico", empty, err)
+	}
+}
+
+func TestSessionInspectionRedacted(t *testing.T) {
+	in := SessionInspection{
+		Data:    UserData{Nome: "Maria da Silva", Telefone: "(44) 99999-8888", Problema: "sem sinal, e-mail maria@example.com"},
+		History: []TranscriptEntry{{Role: TranscriptCustomer, Text: "cpf 123.456.789-09", At: time.Now()}},
+	}
+
+	out := in.Redacted()
+	if out.Data.Nome != "M*** d*** S***" || out.Data.Telefone != "(**) *****-8888" {
+		t.Errorf("dados mascarados = %q / %q", out.Data.Nome, out.Data.Telefone)
+	}
+	if strings.Contains(out.Data.Problema, "maria@") || strings.Contains(out.History[0].Text, "123.456") {
+		t.Errorf("textos livres = %q / %q; esperado e-mail e CPF mascarados", out.Data.Problema, out.History[0].Text)
+	}
+	if in.Data.Nome != "Maria da Silva" || in.History[0].Text != "cpf 123.456.789-09" {
+		t.Error("Redacted alterou a inspeção original")
+	}
+}
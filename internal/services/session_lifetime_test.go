@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMenuTripDoesNotRenewSessionLifetime(t *testing.T) {
+	clock := &fixedClock{t: time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)}
+	s := newTestService(t, nil, func(cfg *Config) {
+		cfg.IdleTimeout = 10 * time.Minute
+		cfg.MaxSessionLifetime = time.Hour
+	})
+	s.now = clock.now
+	ctx := context.Background()
+
+	s.ProcessMessage(ctx, ChannelWeb, "u1", "oi")
+	started := s.getUserData("u1").CriadaEm
+	for _, msg := range []string{"1", "menu", "2", "menu", "4"} {
+		clock.advance(5 * time.Minute)
+		s.ProcessMessage(ctx, ChannelWeb, "u1", msg)
+	}
+	if got := s.getUserData("u1").CriadaEm; got != started {
+		t.Fatalf("CriadaEm = %d; voltar ao menu não deve renovar o início da sessão (%d)", got, started)
+	}
+}
+
+func TestSessionLifetimeResetsDespiteRecentActivity(t *testing.T) {
+	clock := &fixedClock{t: time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)}
+	s := newTestService(t, nil, func(cfg *Config) {
+		cfg.IdleTimeout = 10 * time.Minute
+		cfg.MaxSessionLifetime = time.Hour
+	})
+	s.now = clock.now
+	ctx := context.Background()
+
+	s.ProcessMessage(ctx, ChannelWeb, "u1", "oi")
+	started := s.getUserData("u1").CriadaEm
+	for i := 0; i < 13; i++ {
+		clock.advance(5 * time.Minute)
+		s.ProcessMessage(ctx, ChannelWeb, "u1", "menu")
+	}
+
+	if got := s.getUserData("u1").CriadaEm; got == started || got != clock.now().Unix() {
+		t.Errorf("CriadaEm = %d; esperado reinício em %d após 65min de atividade contínua", got, clock.now().Unix())
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"log"
+	"strings"
+)
+
+// SessionState é a etapa da conversa em que o usuário está, gravada no Redis (chave chat:<userID>).
+type SessionState string
+
+// Estados da conversa.
+const (
+	StateMenu             SessionState = "menu"
+	StateSupportName      SessionState = "support_name"
+	StateSupportProblem   SessionState = "support_problem"
+	StateSupportIA        SessionState = "support_ia"
+	StateSupportFeedback  SessionState = "support_feedback"
+	StatePlansClientCheck SessionState = "plans_client_check"
+	StatePlansCurrent     SessionState = "plans_current"
+	StatePlansName        SessionState = "plans_name"
+	StatePlansPhone       SessionState = "plans_phone"
+	StatePlansContactTime SessionState = "plans_contact_time"
+	StatePlansSelection   SessionState = "plans_selection"
+	StatePlansRetention   SessionState = "plans_retention"
+	StateBoletoIdentifier SessionState = "boleto_identifier"
+	StateAIFree           SessionState = "ai_free"
+	// StateCorrection aguarda o novo valor de um campo já preenchido.
+	StateCorrection SessionState = "correction"
+	// StateNPS aguarda a nota de 0 a 10 da pesquisa de satisfação ao fim de um fluxo.
+	StateNPS SessionState = "nps_survey"
+)
+
+// knownStates lista os estados tratados por dispatch.
+var knownStates = map[SessionState]bool{
+	StateMenu: true, StateSupportName: true, StateSupportProblem: true, StateSupportIA: true,
+	StateSupportFeedback: true, StatePlansClientCheck: true, StatePlansCurrent: true, StatePlansName: true,
+	StatePlansPhone: true, StatePlansContactTime: true, StatePlansSelection: true, StatePlansRetention: true, StateBoletoIdentifier: true,
+	StateAIFree: true, StateCorrection: true, StateNPS: true,
+}
+
+// Valid indica se o estado é um dos conhecidos. Estados gravados por versões antigas
+// ou corrompidos no Redis não são válidos.
+func (st SessionState) Valid() bool {
+	return knownStates[st]
+}
+
+// IsPlans indica se o estado pertence ao fluxo de planos.
+func (st SessionState) IsPlans() bool {
+	switch st {
+	case StatePlansClientCheck, StatePlansCurrent, StatePlansName, StatePlansPhone, StatePlansContactTime, StatePlansSelection, StatePlansRetention:
+		return true
+	}
+	return false
+}
+
+// configState converte o estado informado em uma variável de configuração, avisando em log se não for conhecido.
+func configState(key, raw string) SessionState {
+	st := SessionState(strings.TrimSpace(raw))
+	if !st.Valid() {
+		log.Printf("%s: estado desconhecido %q", key, st)
+	}
+	return st
+}
+
+// eventUnknownState é o evento de analytics registrado quando a sessão tem um estado desconhecido.
+const eventUnknownState = "unknown_state"
+
+// unknownStateNotice antecede o menu quando a sessão não pôde ser retomada.
+const unknownStateNotice = "⚠️ Não consegui retomar seu atendimento anterior. Vamos recomeçar:\n\n"
+
+// recoverUnknownState trata uma sessão com estado desconhecido (gravado por outra versão ou corrompido):
+// registra o ocorrido em log e analytics e leva o usuário ao menu avisando que o fluxo foi reiniciado.
+func (s *ChatbotService) recoverUnknownState(channel, userID string, state SessionState) (string, error) {
+	log.Printf("Estado de sessão desconhecido %q para o usuário %s, reiniciando no menu", state, userID)
+	s.recordEvent(eventUnknownState, string(state), channel)
+	menu, err := s.showMainMenu(userID)
+	if err != nil {
+		return "", err
+	}
+	return unknownStateNotice + menu, nil
+}
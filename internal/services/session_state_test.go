@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSessionStateValidAndIsPlans(t *testing.T) {
+	for st := range knownStates {
+		if !st.Valid() {
+			t.Errorf("%q.Valid() = false", st)
+		}
+	}
+	for _, st := range []SessionState{"", "suport_name", "MENU"} {
+		if st.Valid() {
+			t.Errorf("%q.Valid() = true; esperado estado desconhecido", st)
+		}
+	}
+	if !StatePlansPhone.IsPlans() || StateSupportName.IsPlans() || StateMenu.IsPlans() {
+		t.Error("IsPlans deveria valer só para os estados do fluxo de planos")
+	}
+}
+
+func TestUnknownStateRestartsAtMenu(t *testing.T) {
+	db := newTestDB(t)
+	s := newTestServiceWith(t, db, nil, nil, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+	s.setState(user, SessionState("suport_name"))
+
+	response, err := s.ProcessMessage(ctx, ChannelWhatsApp, user, "Ana Souza")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(response, unknownStateNotice) {
+		t.Errorf("resposta = %q; esperado o aviso de reinício seguido do menu", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateMenu {
+		t.Errorf("estado = %q; esperado %q", state, StateMenu)
+	}
+	s.writer.drain(ctx)
+	var option string
+	if err := db.QueryRow(`SELECT option FROM analytics_events WHERE event_type = ?`, eventUnknownState).Scan(&option); err != nil || option != "suport_name" {
+		t.Errorf("evento unknown_state = %q, %v; esperado o estado desconhecido registrado", option, err)
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResetSessionClearsMemoryFallbackAndHistory(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) { cfg.EscalationTranscript = true })
+	ctx := context.Background()
+
+	s.ProcessMessage(ctx, ChannelWeb, "u1", "oi")
+	s.ProcessMessage(ctx, ChannelWeb, "u1", "1")
+	s.recordTranscript("u1", TranscriptCustomer, "minha internet caiu")
+	if state := s.sessions.state(ctx, "u1"); state != StateSupportName {
+		t.Fatalf("estado = %q; esperado support_name na memória local", state)
+	}
+
+	// Com o Redis fora a limpeza remota falha, mas a cópia local precisa sumir.
+	s.ResetSession("u1")
+
+	if state := s.sessions.state(ctx, "u1"); state != "" {
+		t.Errorf("estado = %q após o reset", state)
+	}
+	if data, _ := s.sessions.data(ctx, "u1"); data.TipoAtendimento != "" {
+		t.Errorf("dados = %+v após o reset", data)
+	}
+	if history := s.sessions.history(ctx, "u1"); len(history) != 0 {
+		t.Errorf("histórico = %v após o reset", history)
+	}
+}
+
+func TestSessionKeysUseConfiguredTTLs(t *testing.T) {
+	ttls := SessionTTLs{State: 10 * time.Minute, Data: 20 * time.Minute, Dedupe: 3 * time.Hour, Idempotency: 4 * time.Hour, History: 50 * time.Minute}
+	s, mr := newRedisTestService(t, nil, func(cfg *Config) {
+		cfg.SessionTTLs = ttls
+		cfg.EscalationTranscript = true
+	})
+	ctx := context.Background()
+
+	s.setState("u1", StateSupportName)
+	s.setUserData("u1", UserData{Nome: "Ana"})
+	s.recordTranscript("u1", TranscriptCustomer, "minha internet caiu")
+	leadKey, fresh := s.claimLead("44999998888", "500MB")
+	if !fresh {
+		t.Fatal("claimLead = duplicado na primeira chamada")
+	}
+	if !s.ClaimMessage("whatsapp", "wamid.1") {
+		t.Fatal("ClaimMessage = duplicada na primeira chamada")
+	}
+
+	for key, want := range map[string]time.Duration{
+		stateKeyPrefix + "u1":                     ttls.State,
+		stateSinceKeyPrefix + "u1":                ttls.State,
+		dataKeyPrefix + "u1":                      ttls.Data,
+		historyKeyPrefix + "u1":                   ttls.History,
+		leadKey:                                   ttls.Dedupe,
+		messageSeenKeyPrefix + "whatsapp:wamid.1": ttls.Idempotency,
+	} {
+		if got := mr.TTL(key); got != want {
+			t.Errorf("TTL(%s) = %s; esperado %s", key, got, want)
+		}
+	}
+
+	// A expiração deslizante renova estado e dados com os TTLs de cada tipo.
+	mr.FastForward(5 * time.Minute)
+	s.sessions.refresh(ctx, "u1")
+	if got := mr.TTL(stateKeyPrefix + "u1"); got != ttls.State {
+		t.Errorf("TTL do estado após refresh = %s; esperado %s", got, ttls.State)
+	}
+	if got := mr.TTL(dataKeyPrefix + "u1"); got != ttls.Data {
+		t.Errorf("TTL dos dados após refresh = %s; esperado %s", got, ttls.Data)
+	}
+}
+
+func TestClaimsRejectRepeatsWithinTTL(t *testing.T) {
+	s, mr := newRedisTestService(t, nil, func(cfg *Config) {
+		cfg.SessionTTLs.Idempotency = time.Hour
+	})
+
+	if !s.ClaimMessage("whatsapp", "wamid.1") {
+		t.Fatal("primeira mensagem rejeitada")
+	}
+	if s.ClaimMessage("whatsapp", "wamid.1") {
+		t.Error("reenvio da mesma mensagem aceito dentro do TTL de idempotência")
+	}
+	if !s.ClaimMessage("messenger", "wamid.1") {
+		t.Error("o mesmo ID em outro canal não é repetição")
+	}
+	mr.FastForward(time.Hour)
+	if !s.ClaimMessage("whatsapp", "wamid.1") {
+		t.Error("mensagem rejeitada após o TTL de idempotência")
+	}
+}
+
+func TestActiveSessionOutlivesStateTTLOnLocalMemory(t *testing.T) {
+	clock := &fixedClock{t: time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)}
+	s := newTestService(t, nil, func(cfg *Config) {
+		cfg.SessionTTLs.State = 10 * time.Minute
+		cfg.IdleTimeout = time.Hour
+		cfg.MaxInvalidInputs = 0
+	})
+	s.now = clock.now
+	ctx := context.Background()
+
+	s.ProcessMessage(ctx, ChannelWeb, "u1", "oi")
+	s.setState("u1", StatePlansPhone)
+	// Telefones inválidos não regravam o estado: só a expiração deslizante o mantém vivo.
+	for i := 0; i < 3; i++ {
+		clock.advance(6 * time.Minute)
+		s.ProcessMessage(ctx, ChannelWeb, "u1", "abc")
+	}
+	if state := s.sessions.state(ctx, "u1"); state != StatePlansPhone {
+		t.Fatalf("estado após 18min de conversa = %q; esperado %q", state, StatePlansPhone)
+	}
+
+	clock.advance(11 * time.Minute)
+	if state := s.sessions.state(ctx, "u1"); state != "" {
+		t.Errorf("estado = %q após 11min ocioso; esperado expirar com o TTL de 10min", state)
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// sheetsJob é uma gravação pendente no Sheets; record é o payload usado se for preciso guardá-la no banco.
+type sheetsJob struct {
+	ctx    context.Context
+	kind   string
+	record interface{}
+	send   func() error
+}
+
+// sheetsPool executa as gravações no Sheets com um número fixo de workers e fila limitada,
+// para que picos de leads não atrasem as respostas ao usuário.
+type sheetsPool struct {
+	queue   chan sheetsJob
+	wg      sync.WaitGroup
+	persist func(sheetsJob) error
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// newSheetsPool inicia workers consumidores da fila. Falhas de envio vão para a fila local (sheets_queue).
+func newSheetsPool(s *ChatbotService, workers, size int) *sheetsPool {
+	p := &sheetsPool{queue: make(chan sheetsJob, size), persist: s.persistSheetsJob}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range p.queue {
+				if err := s.sendSheets(job.ctx, job.kind, job.record, job.send); err != nil {
+					log.Printf("Erro ao gravar %s no Sheets, guardando para reenvio: %v", job.kind, err)
+					if err := s.queueSheets(job.kind, job.record); err != nil {
+						log.Printf("Registro %s perdido: %v", job.kind, err)
+					}
+				}
+			}
+		}()
+	}
+	return p
+}
+
+// submit enfileira a gravação sem bloquear; retorna false com a fila cheia ou o pool encerrado.
+func (p *sheetsPool) submit(job sheetsJob) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+	select {
+	case p.queue <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// drain para de aceitar gravações e aguarda as enfileiradas terminarem. Se ctx expirar antes, as que
+// ainda não foram retiradas da fila são gravadas no banco (sheets_queue) na hora, sem passar pela fila de
+// gravação, para o reenvio após o reinício; só as que já estavam sendo enviadas ficam de fora.
+func (p *sheetsPool) drain(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+	err := waitGroup(ctx, &p.wg)
+	if err == nil {
+		return nil
+	}
+	persisted, lost := 0, 0
+	for job := range p.queue {
+		if perr := p.persist(job); perr != nil {
+			log.Printf("Registro %s perdido no desligamento: %v", job.kind, perr)
+			lost++
+			continue
+		}
+		persisted++
+	}
+	if persisted+lost > 0 {
+		log.Printf("Fila do Sheets não esvaziada a tempo: %d registros guardados no banco, %d perdidos", persisted, lost)
+	}
+	return err
+}
+
+// persistSheetsJob grava o registro em sheets_queue de forma síncrona, para o desligamento, em que a fila
+// de gravação do banco pode não ter mais tempo de ser esvaziada.
+func (s *ChatbotService) persistSheetsJob(job sheetsJob) error {
+	if s.db == nil {
+		return errSheetsNotQueued
+	}
+	payload, _ := json.Marshal(job.record)
+	return s.execDB(`INSERT INTO sheets_queue (kind, payload, created_at) VALUES (?, ?, ?)`, job.kind, string(payload), s.now().UTC())
+}
+
+// dispatchSheets envia o registro ao Sheets: pelo pool, quando configurado, ou de forma síncrona.
+// Com a fila cheia (ou o pool já encerrado) o registro é guardado no banco em vez de bloquear a requisição;
+// o erro retornado indica que ele não pôde ser enviado nem guardado.
+func (s *ChatbotService) dispatchSheets(ctx context.Context, kind string, record interface{}, send func() error) error {
+	if s.sheetsPool == nil {
+		return s.sendSheets(ctx, kind, record, send)
+	}
+	if s.sheetsPool.submit(sheetsJob{ctx: ctx, kind: kind, record: record, send: send}) {
+		return nil
+	}
+	log.Printf("Fila do Sheets cheia, registro %s guardado no banco", kind)
+	return s.queueSheets(kind, record)
+}
+
+// sheetsError é implementado pelos erros tipados do cliente do Sheets (sheets.Error).
+type sheetsError interface {
+	error
+	Quota() bool
+	Auth() bool
+	Temporary() bool
+}
+
+// sendSheets grava o registro conforme a classe da falha: cota excedida pausa os envios por SheetsQuotaBackoff,
+// falha transitória ganha uma nova tentativa imediata e erro de credencial é logado; nos três casos o registro
+// vai para a fila local, se houver banco. Demais erros são retornados a quem chamou.
+func (s *ChatbotService) sendSheets(ctx context.Context, kind string, record interface{}, send func() error) error {
+	if s.sheetsPaused() && s.writer != nil {
+		return s.queueSheets(kind, record)
+	}
+
+	err := traceSheets(ctx, kind, send)
+	var se sheetsError
+	if err == nil || !errors.As(err, &se) {
+		return err
+	}
+	switch {
+	case se.Quota():
+		s.pauseSheets()
+		log.Printf("Cota do Sheets excedida, envios pausados por %s: %v", s.cfg.SheetsQuotaBackoff, err)
+	case se.Temporary():
+		if err = traceSheets(ctx, kind, send); err == nil {
+			return nil
+		}
+	case se.Auth():
+		log.Printf("Credenciais do Sheets rejeitadas, verifique credentials.json e o compartilhamento da planilha: %v", err)
+	default:
+		return err
+	}
+	if s.writer == nil {
+		return err
+	}
+	return s.queueSheets(kind, record)
+}
+
+// pauseSheets suspende os envios ao Sheets por SheetsQuotaBackoff.
+func (s *ChatbotService) pauseSheets() {
+	s.sheetsPausedUntil.Store(s.now().Add(s.cfg.SheetsQuotaBackoff).UnixNano())
+}
+
+// sheetsPaused indica se os envios ao Sheets estão suspensos após uma cota excedida.
+func (s *ChatbotService) sheetsPaused() bool {
+	return s.now().UnixNano() < s.sheetsPausedUntil.Load()
+}
+
+// SheetsQueueDepth retorna quantas gravações aguardam na fila em memória do Sheets.
+func (s *ChatbotService) SheetsQueueDepth() int {
+	if s.sheetsPool == nil {
+		return 0
+	}
+	return len(s.sheetsPool.queue)
+}
+
+// StartSheetsReplay reenvia a cada cfg.SheetsReplayInterval os registros guardados no banco enquanto o
+// Sheets estiver ligado, até o contexto ser cancelado. Sem banco ou com intervalo zero não faz nada.
+func (s *ChatbotService) StartSheetsReplay(ctx context.Context) {
+	if s.db == nil || s.cfg.SheetsReplayInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.SheetsReplayInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if s.flags.enabled(FlagSheets) {
+					s.flushSheetsQueue()
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"leadprojectarrumado/internal/sheets"
+)
+
+func TestFlushSheetsQueueDeadLettersPermanentFailures(t *testing.T) {
+	db := newTestDB(t)
+	fake := &fakeSheets{fail: func(nome string) error {
+		if nome == "Quebrado" {
+			return &sheets.Error{Op: "append", Class: sheets.ClassPermanent, Err: errors.New("400")}
+		}
+		return nil
+	}}
+	s := newTestServiceWith(t, db, fake, nil, func(cfg *Config) { cfg.SheetsMaxAttempts = 2 })
+
+	for _, nome := range []string{"Quebrado", "Ana"} {
+		if err := s.queueSheets(sheetsKindSupport, sheetsSupportRecord{Nome: nome}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.writer.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s.flushSheetsQueue()
+	if got := fake.names(); len(got) != 1 || got[0] != "Ana" {
+		t.Fatalf("gravados = %v; o registro com falha não deve bloquear os seguintes", got)
+	}
+	if count(t, db, "sheets_queue") != 1 || count(t, db, "sheets_dead_letters") != 0 {
+		t.Fatal("o registro com falha deve continuar na fila antes de esgotar as tentativas")
+	}
+
+	s.flushSheetsQueue()
+	if count(t, db, "sheets_queue") != 0 || count(t, db, "sheets_dead_letters") != 1 {
+		t.Errorf("fila = %d, dead-letter = %d; esperado o registro movido para a dead-letter",
+			count(t, db, "sheets_queue"), count(t, db, "sheets_dead_letters"))
+	}
+}
+
+func TestFlushSheetsQueueStopsOnTransientFailureWithoutCounting(t *testing.T) {
+	db := newTestDB(t)
+	fake := &fakeSheets{fail: func(string) error {
+		return &sheets.Error{Op: "append", Class: sheets.ClassTransient, Err: errors.New("503")}
+	}}
+	s := newTestServiceWith(t, db, fake, nil, func(cfg *Config) { cfg.SheetsMaxAttempts = 1 })
+
+	s.queueSheets(sheetsKindSupport, sheetsSupportRecord{Nome: "Ana"})
+	s.writer.drain(context.Background())
+
+	s.flushSheetsQueue()
+	s.flushSheetsQueue()
+	if count(t, db, "sheets_queue") != 1 || count(t, db, "sheets_dead_letters") != 0 {
+		t.Error("falha passageira não deve mandar o registro para a dead-letter")
+	}
+}
+
+func TestFlushSheetsQueueConcurrentCallsSendOnce(t *testing.T) {
+	db := newTestDB(t)
+	fake := &fakeSheets{fail: func(string) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}}
+	s := newTestServiceWith(t, db, fake, nil, nil)
+
+	s.queueSheets(sheetsKindSupport, sheetsSupportRecord{Nome: "Ana"})
+	s.writer.drain(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.flushSheetsQueue()
+		}()
+	}
+	wg.Wait()
+	if got := fake.names(); len(got) != 1 {
+		t.Errorf("registro enviado %d vezes; esperado 1", len(got))
+	}
+}
+
+func TestShutdownDrainsSheetsPool(t *testing.T) {
+	fake := &fakeSheets{fail: func(string) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+	s := newTestServiceWith(t, nil, fake, nil, func(cfg *Config) {
+		cfg.SheetsWorkers = 1
+		cfg.SheetsQueueSize = 10
+	})
+
+	for _, nome := range []string{"Ana", "Bruno", "Carla"} {
+		if err := s.saveSupport(context.Background(), nome, "internet", "", "Aberto", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.names(); len(got) != 3 {
+		t.Errorf("gravados no desligamento = %v; esperado os 3 registros da fila", got)
+	}
+	if err := s.saveSupport(context.Background(), "Davi", "internet", "", "Aberto", ""); err == nil {
+		t.Error("gravação após o desligamento, sem banco, deve retornar erro")
+	}
+}
+
+func TestDispatchSheetsReportsFullQueueWithoutDB(t *testing.T) {
+	block := make(chan struct{})
+	fake := &fakeSheets{fail: func(string) error {
+		<-block
+		return nil
+	}}
+	s := newTestServiceWith(t, nil, fake, nil, func(cfg *Config) {
+		cfg.SheetsWorkers = 1
+		cfg.SheetsQueueSize = 1
+	})
+	defer close(block)
+
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = s.saveSupport(context.Background(), "Ana", "internet", "", "Aberto", "")
+	}
+	if !errors.Is(err, errSheetsNotQueued) {
+		t.Errorf("err = %v; esperado errSheetsNotQueued com a fila cheia e sem banco", err)
+	}
+}
+
+func TestShutdownPersistsSheetsQueueOnTimeout(t *testing.T) {
+	db := newTestDB(t)
+	block := make(chan struct{})
+	fake := &fakeSheets{fail: func(string) error {
+		<-block
+		return nil
+	}}
+	s := newTestServiceWith(t, db, fake, nil, func(cfg *Config) {
+		cfg.SheetsWorkers = 1
+		cfg.SheetsQueueSize = 10
+	})
+	defer close(block)
+
+	for _, nome := range []string{"Ana", "Bruno", "Carla"} {
+		if err := s.saveSupport(context.Background(), nome, "internet", "", "Aberto", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Aguarda o worker retirar o primeiro registro, que fica preso no envio.
+	for deadline := time.Now().Add(time.Second); s.SheetsQueueDepth() != 2; {
+		if time.Now().After(deadline) {
+			t.Fatalf("fila = %d; esperado o worker ocupado com o primeiro registro", s.SheetsQueueDepth())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.sheetsPool.drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("drain = %v; esperado o prazo esgotado", err)
+	}
+	if n := count(t, db, "sheets_queue"); n != 2 {
+		t.Errorf("sheets_queue = %d; esperado os 2 registros ainda na fila guardados no banco", n)
+	}
+}
+
+func TestSheetsQuotaErrorPausesAndQueues(t *testing.T) {
+	db := newTestDB(t)
+	quota := true
+	fake := &fakeSheets{fail: func(string) error {
+		if quota {
+			return &sheets.Error{Op: "SaveSupport", Class: sheets.ClassQuota, Err: errors.New("429")}
+		}
+		return nil
+	}}
+	s := newTestServiceWith(t, db, fake, nil, func(cfg *Config) { cfg.SheetsQuotaBackoff = time.Minute })
+	clock := &fixedClock{t: time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)}
+	s.now = clock.now
+	ctx := context.Background()
+
+	if err := s.saveSupport(ctx, "Ana", "internet", "", "Aberto", ""); err != nil {
+		t.Fatalf("saveSupport = %v; esperado guardar o registro sem erro", err)
+	}
+	if !s.sheetsPaused() {
+		t.Fatal("envios ao Sheets não pausados após erro de cota")
+	}
+	quota = false
+	s.saveSupport(ctx, "Bruno", "internet", "", "Aberto", "")
+	s.writer.drain(ctx)
+	s.flushSheetsQueue()
+	if got := fake.names(); len(got) != 0 || count(t, db, "sheets_queue") != 2 {
+		t.Fatalf("gravados = %v, fila = %d; esperado nenhum envio durante a pausa", got, count(t, db, "sheets_queue"))
+	}
+
+	clock.advance(time.Minute)
+	s.flushSheetsQueue()
+	if got := fake.names(); len(got) != 2 || count(t, db, "sheets_queue") != 0 {
+		t.Errorf("gravados = %v, fila = %d; esperado reenviar tudo após a pausa", got, count(t, db, "sheets_queue"))
+	}
+}
+
+func TestSheetsTransientErrorRetriesOnce(t *testing.T) {
+	calls := 0
+	fake := &fakeSheets{fail: func(string) error {
+		calls++
+		if calls == 1 {
+			return &sheets.Error{Op: "SaveSupport", Class: sheets.ClassTransient, Err: errors.New("503")}
+		}
+		return nil
+	}}
+	s := newTestServiceWith(t, nil, fake, nil, nil)
+
+	if err := s.saveSupport(context.Background(), "Ana", "internet", "", "Aberto", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.names(); len(got) != 1 || calls != 2 {
+		t.Errorf("gravados = %v em %d tentativas; esperado gravar na segunda", got, calls)
+	}
+}
+
+func TestSheetsPermanentErrorIsReturned(t *testing.T) {
+	fake := &fakeSheets{fail: func(string) error {
+		return &sheets.Error{Op: "SaveSupport", Class: sheets.ClassPermanent, Err: errors.New("400")}
+	}}
+	s := newTestServiceWith(t, newTestDB(t), fake, nil, nil)
+
+	var se *sheets.Error
+	if err := s.saveSupport(context.Background(), "Ana", "internet", "", "Aberto", ""); !errors.As(err, &se) {
+		t.Errorf("saveSupport = %v; esperado o erro permanente a quem chamou", err)
+	}
+	if s.sheetsPaused() {
+		t.Error("erro permanente não deveria pausar os envios")
+	}
+}
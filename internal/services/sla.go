@@ -0,0 +1,89 @@
+package services
+
+import (
+	"log"
+	"strings"
+)
+
+// Categorias de problema usadas para escolher o prazo do encaminhamento ao técnico.
+const (
+	CategoryOutage  = "outage"
+	CategoryInstall = "install"
+	CategoryBilling = "billing"
+)
+
+// defaultEscalationSLA é o prazo informado quando o problema não se encaixa em nenhuma categoria.
+const defaultEscalationSLA = "24-48 horas"
+
+// defaultProblemKeywords são os termos que classificam o problema relatado em cada categoria.
+var defaultProblemKeywords = map[string][]string{
+	CategoryOutage:  {"sem internet", "caiu", "queda", "fora do ar", "sem sinal", "sem conexao", "rompido", "los"},
+	CategoryInstall: {"instalacao", "instalar", "mudanca de endereco", "mudar de endereco", "ponto adicional", "tecnico em casa"},
+	CategoryBilling: {"boleto", "fatura", "cobranca", "pagamento", "cobrado", "valor"},
+}
+
+// defaultEscalationSLAs são os prazos por categoria.
+var defaultEscalationSLAs = map[string]string{
+	CategoryOutage:  "4-8 horas",
+	CategoryInstall: "3-5 dias úteis",
+	CategoryBilling: "1 dia útil",
+}
+
+// problemCategoryOrder define a prioridade quando o problema cita termos de mais de uma categoria.
+var problemCategoryOrder = []string{CategoryOutage, CategoryBilling, CategoryInstall}
+
+// parseCategoryMap interpreta valores no formato "categoria=valor;categoria=valor" sobre os padrões,
+// ignorando categorias desconhecidas. Usado por ESCALATION_SLAS e PROBLEM_CATEGORY_KEYWORDS.
+func parseCategoryMap(env, v string, defaults map[string]string) map[string]string {
+	out := make(map[string]string, len(defaults))
+	for k, val := range defaults {
+		out[k] = val
+	}
+	for _, item := range strings.Split(v, ";") {
+		category, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		category = strings.ToLower(strings.TrimSpace(category))
+		if _, known := defaults[category]; !known {
+			log.Printf("%s: categoria desconhecida %q ignorada", env, category)
+			continue
+		}
+		out[category] = strings.TrimSpace(value)
+	}
+	return out
+}
+
+// parseProblemKeywords interpreta PROBLEM_CATEGORY_KEYWORDS no formato "categoria=termo|termo;...".
+func parseProblemKeywords(v string) map[string][]string {
+	joined := make(map[string]string, len(defaultProblemKeywords))
+	for category, kws := range defaultProblemKeywords {
+		joined[category] = strings.Join(kws, "|")
+	}
+	keywords := make(map[string][]string)
+	for category, list := range parseCategoryMap("PROBLEM_CATEGORY_KEYWORDS", v, joined) {
+		keywords[category] = strings.Split(list, "|")
+	}
+	return keywords
+}
+
+// classifyProblem retorna a categoria do problema relatado, ou "" se nenhum termo for encontrado.
+func (s *ChatbotService) classifyProblem(problema string) string {
+	words := " " + wordsOnly(problema) + " "
+	for _, category := range problemCategoryOrder {
+		for _, k := range s.cfg.ProblemKeywords[category] {
+			if k = wordsOnly(k); k != "" && strings.Contains(words, " "+k+" ") {
+				return category
+			}
+		}
+	}
+	return ""
+}
+
+// escalationSLA retorna o prazo de atendimento técnico para o problema relatado.
+func (s *ChatbotService) escalationSLA(problema string) string {
+	if sla := s.cfg.EscalationSLAs[s.classifyProblem(problema)]; sla != "" {
+		return sla
+	}
+	return defaultEscalationSLA
+}
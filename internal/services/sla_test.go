@@ -0,0 +1,46 @@
+package services
+
+import "testing"
+
+func TestEscalationSLAByProblemCategory(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	cases := []struct {
+		problema, category, sla string
+	}{
+		{"Estou SEM INTERNET desde ontem", CategoryOutage, "4-8 horas"},
+		{"Quero agendar a instalação", CategoryInstall, "3-5 dias úteis"},
+		{"Fui cobrado duas vezes na fatura", CategoryBilling, "1 dia útil"},
+		{"A fatura veio alta e a internet caiu", CategoryOutage, "4-8 horas"},
+		{"O roteador faz um barulho estranho", "", defaultEscalationSLA},
+		{"Os cabos estão soltos", "", defaultEscalationSLA},
+	}
+	for _, c := range cases {
+		if got := s.classifyProblem(c.problema); got != c.category {
+			t.Errorf("classifyProblem(%q) = %q; esperado %q", c.problema, got, c.category)
+		}
+		if got := s.escalationSLA(c.problema); got != c.sla {
+			t.Errorf("escalationSLA(%q) = %q; esperado %q", c.problema, got, c.sla)
+		}
+	}
+}
+
+func TestEscalationSLAFromEnv(t *testing.T) {
+	t.Setenv("ESCALATION_SLAS", "outage=2 horas; vip=1 hora")
+	t.Setenv("PROBLEM_CATEGORY_KEYWORDS", "install=ponto extra")
+	cfg := LoadConfig()
+
+	if cfg.EscalationSLAs[CategoryOutage] != "2 horas" || cfg.EscalationSLAs[CategoryBilling] != defaultEscalationSLAs[CategoryBilling] {
+		t.Errorf("EscalationSLAs = %v; esperado sobrescrever só outage", cfg.EscalationSLAs)
+	}
+	if _, ok := cfg.EscalationSLAs["vip"]; ok {
+		t.Error("categoria desconhecida aceita em ESCALATION_SLAS")
+	}
+
+	s := newTestService(t, nil, func(c *Config) { c.ProblemKeywords = cfg.ProblemKeywords })
+	if got := s.classifyProblem("preciso de um ponto extra"); got != CategoryInstall {
+		t.Errorf("classifyProblem com termo configurado = %q; esperado %q", got, CategoryInstall)
+	}
+	if got := s.classifyProblem("quero instalar"); got != "" {
+		t.Errorf("classifyProblem com termo padrão substituído = %q; esperado sem categoria", got)
+	}
+}
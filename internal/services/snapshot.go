@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// snapshotScanCount é o tamanho sugerido de cada página do SCAN.
+const snapshotScanCount = 200
+
+// ServiceStats é a fotografia das sessões em andamento, usada pelo painel administrativo.
+type ServiceStats struct {
+	ActiveSessions  int            `json:"active_sessions"`
+	SessionsByState map[string]int `json:"sessions_by_state"`
+	// OldestSessionAgeSeconds é há quanto tempo a sessão ativa mais antiga não recebe mensagens.
+	OldestSessionAgeSeconds int64 `json:"oldest_session_age_seconds"`
+}
+
+// Snapshot conta as sessões por estado percorrendo as chaves chat:* com SCAN, que não bloqueia o
+// Redis como KEYS, e busca os estados de cada página com MGET. As chamadas passam pelo breaker:
+// com o circuito aberto o erro é retornado sem ir à rede.
+func (s *ChatbotService) Snapshot(ctx context.Context) (ServiceStats, error) {
+	stats := ServiceStats{SessionsByState: make(map[string]int)}
+
+	var cursor uint64
+	for {
+		var keys []string
+		var next uint64
+		err := s.redisDo(ctx, func() (err error) {
+			keys, next, err = s.redis.Scan(ctx, cursor, stateKeyPrefix+"*", snapshotScanCount).Result()
+			return err
+		})
+		if err != nil {
+			return stats, err
+		}
+		if len(keys) > 0 {
+			var states []interface{}
+			err := s.redisDo(ctx, func() (err error) {
+				states, err = s.redis.MGet(ctx, keys...).Result()
+				return err
+			})
+			if err != nil {
+				return stats, err
+			}
+			for _, v := range states {
+				// Chaves expiradas entre o SCAN e o MGET voltam nil.
+				if state, ok := v.(string); ok {
+					stats.SessionsByState[state]++
+					stats.ActiveSessions++
+				}
+			}
+		}
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	var oldest []redis.Z
+	err := s.redisDo(ctx, func() (err error) {
+		oldest, err = s.redis.ZRangeWithScores(ctx, activeSessionsKey, 0, 0).Result()
+		return err
+	})
+	if err != nil && err != redis.Nil {
+		return stats, err
+	}
+	if len(oldest) > 0 {
+		age := s.now().Sub(time.Unix(int64(oldest[0].Score), 0))
+		stats.OldestSessionAgeSeconds = int64(age.Seconds())
+	}
+	return stats, nil
+}
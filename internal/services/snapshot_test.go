@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestSnapshotCountsSessionsAcrossScanPages(t *testing.T) {
+	s, mr := newRedisTestService(t, nil, nil)
+	clock := &fixedClock{t: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	s.now = clock.now
+
+	// Mais chaves que uma página do SCAN, para exercitar o cursor.
+	total := snapshotScanCount*2 + 50
+	for i := 0; i < total; i++ {
+		state := StateMenu
+		if i%3 == 0 {
+			state = StatePlansName
+		}
+		mr.Set(fmt.Sprintf("%s5544%08d", stateKeyPrefix, i), string(state))
+	}
+	// Chaves de outros prefixos não entram na contagem.
+	mr.Set(dataKeyPrefix+"5544000000000", "{}")
+	ctx := context.Background()
+	s.redis.ZAdd(ctx, activeSessionsKey,
+		&redis.Z{Score: float64(clock.t.Add(-90 * time.Second).Unix()), Member: "5544000000000"},
+		&redis.Z{Score: float64(clock.t.Add(-10 * time.Second).Unix()), Member: "5544000000001"},
+	)
+
+	stats, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if stats.ActiveSessions != total {
+		t.Errorf("ActiveSessions = %d; esperado %d", stats.ActiveSessions, total)
+	}
+	plans := (total + 2) / 3
+	if got := stats.SessionsByState[string(StatePlansName)]; got != plans {
+		t.Errorf("sessões em %s = %d; esperado %d", StatePlansName, got, plans)
+	}
+	if got := stats.SessionsByState[string(StateMenu)]; got != total-plans {
+		t.Errorf("sessões em %s = %d; esperado %d", StateMenu, got, total-plans)
+	}
+	if stats.OldestSessionAgeSeconds != 90 {
+		t.Errorf("OldestSessionAgeSeconds = %d; esperado 90", stats.OldestSessionAgeSeconds)
+	}
+}
+
+func TestSnapshotFailsFastWithCircuitOpen(t *testing.T) {
+	s := newTestService(t, nil, nil)
+
+	if _, err := s.Snapshot(context.Background()); err == nil {
+		t.Fatal("Snapshot sem Redis retornou nil; esperado erro")
+	}
+	if _, err := s.Snapshot(context.Background()); err != errCircuitOpen {
+		t.Errorf("Snapshot com circuito aberto = %v; esperado errCircuitOpen", err)
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// Ações possíveis quando o usuário passa tempo demais em um mesmo estado.
+const (
+	StateTimeoutNudge = "nudge"
+	StateTimeoutExit  = "exit"
+)
+
+// eventStateTimeout registra nos analytics a saída de um estado por timeout.
+const eventStateTimeout = "state_timeout"
+
+// StateTimeout define por quanto tempo o usuário pode ficar em um estado sem avançar e o que fazer depois:
+// "nudge" acrescenta um lembrete à resposta; "exit" encerra o fluxo e volta ao menu. Nos dois casos a ação só
+// vale se a mensagem que chegou após o prazo não fizer o fluxo avançar.
+type StateTimeout struct {
+	After  time.Duration
+	Action string
+}
+
+// parseStateTimeouts interpreta STATE_TIMEOUTS no formato "estado=duração:ação,...",
+// ex.: "support_problem=5m:nudge,ai_free=15m:exit". A ação padrão é nudge.
+func parseStateTimeouts(v string) map[SessionState]StateTimeout {
+	timeouts := map[SessionState]StateTimeout{}
+	for _, item := range splitList(v) {
+		state, spec, ok := strings.Cut(item, "=")
+		if !ok {
+			log.Printf("STATE_TIMEOUTS: entrada inválida %q ignorada", item)
+			continue
+		}
+		rawAfter, action, _ := strings.Cut(spec, ":")
+		after, err := time.ParseDuration(strings.TrimSpace(rawAfter))
+		if err != nil || after <= 0 {
+			log.Printf("STATE_TIMEOUTS: duração inválida em %q ignorada", item)
+			continue
+		}
+		action = strings.ToLower(strings.TrimSpace(action))
+		if action != StateTimeoutExit {
+			action = StateTimeoutNudge
+		}
+		timeouts[configState("STATE_TIMEOUTS", state)] = StateTimeout{After: after, Action: action}
+	}
+	return timeouts
+}
+
+// stateExpired indica se o usuário está no estado atual há mais tempo que o permitido.
+func (s *ChatbotService) stateExpired(userID string, after time.Duration) bool {
+	since, ok := s.sessions.stateSince(context.Background(), userID)
+	return ok && s.now().Sub(since) >= after
+}
+
+// dispatchWithTimeout aplica o timeout do estado. A mensagem é sempre processada primeiro: se ela fez o fluxo
+// avançar, a resposta segue normalmente. Caso o usuário continue no mesmo estado, o "exit" encerra o fluxo e
+// volta ao menu, e o "nudge" acrescenta um lembrete à resposta.
+func (s *ChatbotService) dispatchWithTimeout(ctx context.Context, channel, userID string, state SessionState, message string, t StateTimeout) (string, error) {
+	if !s.stateExpired(userID, t.After) {
+		return s.dispatch(ctx, channel, userID, state, message)
+	}
+
+	response, err := s.dispatch(ctx, channel, userID, state, message)
+	if err != nil {
+		return "", err
+	}
+	sessionCtx := context.Background()
+	if s.sessions.state(sessionCtx, userID) != state {
+		return response, nil
+	}
+
+	if t.Action == StateTimeoutExit {
+		s.recordEvent(eventStateTimeout, string(state), channel)
+		menu, err := s.showMainMenu(userID)
+		if err != nil {
+			return "", err
+		}
+		return "⏰ *Essa etapa expirou* e o atendimento foi reiniciado.\n\n" + menu, nil
+	}
+
+	s.sessions.resetStateSince(sessionCtx, userID)
+	return response + "\n\n⏰ Parece que esta etapa está demorando. Se preferir, digite *MENU* para recomeçar ou *ATENDENTE* para falar com uma pessoa.", nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTimeoutTestService(t *testing.T, action string) (*ChatbotService, *fixedClock) {
+	t.Helper()
+	s := newTestServiceWith(t, nil, &fakeSheets{}, nil, func(cfg *Config) {
+		cfg.StateTimeouts = map[SessionState]StateTimeout{StatePlansPhone: {After: 5 * time.Minute, Action: action}}
+	})
+	clock := &fixedClock{t: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+	s.now = clock.now
+	return s, clock
+}
+
+func TestStateTimeoutExitKeepsValidMessage(t *testing.T) {
+	s, clock := newTimeoutTestService(t, StateTimeoutExit)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.setState(user, StatePlansPhone)
+	clock.advance(10 * time.Minute)
+	response, err := s.ProcessMessage(ctx, ChannelWeb, user, "(44) 99999-8888")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(response, "expirou") || !strings.Contains(response, leadContactPromise) {
+		t.Errorf("resposta = %q; o telefone válido deve concluir o interesse, não ser descartado pelo timeout", response)
+	}
+}
+
+func TestStateTimeoutExitWhenMessageDoesNotAdvance(t *testing.T) {
+	s, clock := newTimeoutTestService(t, StateTimeoutExit)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.setState(user, StatePlansPhone)
+	clock.advance(10 * time.Minute)
+	response, _ := s.ProcessMessage(ctx, ChannelWeb, user, "não sei")
+	if !strings.Contains(response, "expirou") {
+		t.Errorf("resposta = %q; esperado o aviso de etapa expirada", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StateMenu {
+		t.Errorf("estado = %q; esperado voltar ao menu", state)
+	}
+}
+
+func TestStateTimeoutNudgeAndWithinLimit(t *testing.T) {
+	s, clock := newTimeoutTestService(t, StateTimeoutNudge)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.setState(user, StatePlansPhone)
+	if response, _ := s.ProcessMessage(ctx, ChannelWeb, user, "não sei"); strings.Contains(response, "⏰") {
+		t.Errorf("resposta = %q; dentro do prazo não há lembrete", response)
+	}
+	clock.advance(10 * time.Minute)
+	response, _ := s.ProcessMessage(ctx, ChannelWeb, user, "não sei")
+	if !strings.Contains(response, "demorando") {
+		t.Errorf("resposta = %q; esperado o lembrete", response)
+	}
+	if state := s.sessions.state(ctx, user); state != StatePlansPhone {
+		t.Errorf("estado = %q; o nudge não encerra o fluxo", state)
+	}
+}
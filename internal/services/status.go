@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// aiProbeTimeout limita a verificação de disponibilidade da IA.
+const aiProbeTimeout = 5 * time.Second
+
+// defaultAIUnavailableNotice é o aviso exibido pelo widget enquanto a IA está indisponível.
+const defaultAIUnavailableNotice = "O assistente de IA está temporariamente indisponível. As demais opções do menu funcionam normalmente."
+
+// AIPinger é implementado por clientes de IA que sabem verificar a própria disponibilidade.
+type AIPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// SubsystemStatus é a disponibilidade de um subsistema, com o motivo quando indisponível.
+type SubsystemStatus struct {
+	Available bool   `json:"available"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// ServiceStatus é a disponibilidade de cada subsistema (ai, sheets, redis), consultada pelo widget em /status.
+// Status é "ok" com todos disponíveis e "degraded" caso contrário; Notice traz o aviso para o usuário
+// quando a IA está indisponível.
+type ServiceStatus struct {
+	Status     string                     `json:"status"`
+	Subsystems map[string]SubsystemStatus `json:"subsystems"`
+	Notice     string                     `json:"notice,omitempty"`
+}
+
+// aiProbe guarda o resultado da última verificação da IA, reaproveitado por AIProbeInterval.
+type aiProbe struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// probeAI verifica a IA com AIPinger, reaproveitando o resultado recente para não consultar o provedor
+// a cada requisição. Clientes sem AIPinger são considerados disponíveis.
+func (s *ChatbotService) probeAI(ctx context.Context) error {
+	pinger, ok := s.ai.(AIPinger)
+	if !ok {
+		return nil
+	}
+	s.aiProbe.mu.Lock()
+	defer s.aiProbe.mu.Unlock()
+	if !s.aiProbe.checkedAt.IsZero() && s.now().Sub(s.aiProbe.checkedAt) < s.cfg.AIProbeInterval {
+		return s.aiProbe.err
+	}
+	ctx, cancel := context.WithTimeout(ctx, aiProbeTimeout)
+	defer cancel()
+	s.aiProbe.err = pinger.Ping(ctx)
+	s.aiProbe.checkedAt = s.now()
+	return s.aiProbe.err
+}
+
+// Status informa a disponibilidade da IA (verificada no provedor), do Sheets e do Redis.
+func (s *ChatbotService) Status(ctx context.Context) ServiceStatus {
+	st := ServiceStatus{Status: "ok", Subsystems: map[string]SubsystemStatus{
+		"ai":     s.aiStatus(ctx),
+		"sheets": s.sheetsStatus(),
+		"redis":  s.redisStatus(),
+	}}
+	for _, sub := range st.Subsystems {
+		if !sub.Available {
+			st.Status = "degraded"
+		}
+	}
+	if !st.Subsystems["ai"].Available {
+		st.Notice = s.cfg.AIUnavailableNotice
+	}
+	return st
+}
+
+// aiStatus avalia a IA: configurada, ligada pela feature flag, respondendo à verificação e com a taxa de
+// fallback da janela abaixo de AIFallbackAlertRatio. O Ping só confirma que o provedor responde; a taxa
+// cobre os casos em que ele responde mas as gerações falham (cota, bloqueio, timeout).
+func (s *ChatbotService) aiStatus(ctx context.Context) SubsystemStatus {
+	switch {
+	case s.ai == nil:
+		return SubsystemStatus{Detail: "não configurada"}
+	case !s.flags.enabled(FlagAI):
+		return SubsystemStatus{Detail: "desligada"}
+	}
+	if err := s.probeAI(ctx); err != nil {
+		return SubsystemStatus{Detail: "sem resposta do provedor"}
+	}
+	if ratio, bad := s.aiStats.degraded(s.cfg.AIFallbackAlertRatio); bad {
+		return SubsystemStatus{Detail: fmt.Sprintf("%.0f%% das respostas recentes caíram no fallback", ratio*100)}
+	}
+	return SubsystemStatus{Available: true}
+}
+
+// redisStatus reflete o último heartbeat do Redis.
+func (s *ChatbotService) redisStatus() SubsystemStatus {
+	if !s.RedisAvailable() {
+		return SubsystemStatus{Detail: "sem conexão; sessões em memória local"}
+	}
+	return SubsystemStatus{Available: true}
+}
+
+// sheetsStatus avalia o Sheets: configurado, ligado pela feature flag e fora da pausa por cota.
+func (s *ChatbotService) sheetsStatus() SubsystemStatus {
+	switch {
+	case s.sheets == nil:
+		return SubsystemStatus{Detail: "não configurado"}
+	case !s.flags.enabled(FlagSheets):
+		return SubsystemStatus{Detail: "desligado; registros na fila local"}
+	case s.sheetsPaused():
+		return SubsystemStatus{Detail: "cota excedida; registros na fila local"}
+	}
+	return SubsystemStatus{Available: true}
+}
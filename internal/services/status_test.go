@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// pingAI é um fakeAI que também implementa AIPinger, devolvendo pingErr na verificação.
+type pingAI struct {
+	fakeAI
+	pingErr error
+	pings   int
+}
+
+func (p *pingAI) Ping(context.Context) error {
+	p.pings++
+	return p.pingErr
+}
+
+func TestStatusReflectsUnavailableAIClient(t *testing.T) {
+	s := newTestService(t, &pingAI{pingErr: errors.New("503")}, nil)
+
+	st := s.Status(context.Background())
+	if ai := st.Subsystems["ai"]; ai.Available || ai.Detail != "sem resposta do provedor" {
+		t.Errorf("ai = %+v; esperado indisponível pelo Ping", ai)
+	}
+	if st.Status != "degraded" || st.Notice != s.cfg.AIUnavailableNotice {
+		t.Errorf("status = %q, notice = %q; esperado degraded com o aviso", st.Status, st.Notice)
+	}
+}
+
+func TestStatusReportsAIDownOnHighFallbackRatio(t *testing.T) {
+	s := newTestService(t, &pingAI{}, func(cfg *Config) { cfg.AIFallbackAlertRatio = 0.5 })
+	for i := 0; i < aiFallbackAlertMinCalls; i++ {
+		s.aiStats.record("free", i%4 == 0)
+	}
+
+	st := s.Status(context.Background())
+	if ai := st.Subsystems["ai"]; ai.Available {
+		t.Errorf("ai = %+v; esperado indisponível com 75%% de fallback mesmo respondendo ao Ping", ai)
+	}
+	if st.Notice == "" {
+		t.Error("aviso ausente com a IA indisponível")
+	}
+}
+
+func TestStatusAIAvailableBelowRatioOrWithFewCalls(t *testing.T) {
+	s := newTestService(t, &pingAI{}, func(cfg *Config) { cfg.AIFallbackAlertRatio = 0.5 })
+	for i := 0; i < aiFallbackAlertMinCalls-1; i++ {
+		s.aiStats.record("free", false)
+	}
+	if ai := s.Status(context.Background()).Subsystems["ai"]; !ai.Available {
+		t.Errorf("ai = %+v; poucas chamadas não devem derrubar a IA", ai)
+	}
+
+	for i := 0; i < 3*aiFallbackAlertMinCalls; i++ {
+		s.aiStats.record("free", true)
+	}
+	st := s.Status(context.Background())
+	if ai := st.Subsystems["ai"]; !ai.Available || st.Notice != "" {
+		t.Errorf("ai = %+v, notice = %q; esperado disponível abaixo do limite", ai, st.Notice)
+	}
+}
+
+func TestStatusReusesRecentAIProbe(t *testing.T) {
+	ai := &pingAI{}
+	s := newTestService(t, ai, nil)
+	clock := &fixedClock{t: s.now()}
+	s.now = clock.now
+
+	s.Status(context.Background())
+	s.Status(context.Background())
+	clock.advance(s.cfg.AIProbeInterval)
+	s.Status(context.Background())
+	if ai.pings != 2 {
+		t.Errorf("pings = %d; esperado reaproveitar a verificação dentro de AIProbeInterval", ai.pings)
+	}
+}
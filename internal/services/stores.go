@@ -0,0 +1,382 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// SupportStore, LeadStore, FeedbackStore e NPSStore são os backends de persistência dos registros do atendimento.
+// Um backend pode implementar só as interfaces dos registros que lhe interessam.
+
+// SupportStore recebe os atendimentos de suporte técnico, resolvidos pela IA ou encaminhados.
+type SupportStore interface {
+	SaveSupport(nome, problema, descricao, status string) error
+}
+
+// SupportTranscriptStore é implementado pelos backends de SupportStore com campo próprio para o histórico
+// do suporte técnico. Os que implementam só SupportStore recebem o histórico no fim da descrição.
+type SupportTranscriptStore interface {
+	SaveSupportWithTranscript(nome, problema, descricao, status, historico string) error
+}
+
+// LeadStore recebe os interesses em planos (leads) concluídos no fluxo comercial.
+type LeadStore interface {
+	SavePlans(nome, situacao, planoAtual, planoDesejado, telefone, observacoes string) error
+}
+
+// LeadContactTimeStore é implementado pelos backends de LeadStore com campo próprio para o horário de
+// contato preferido. Os que implementam só LeadStore recebem o horário junto às observações.
+type LeadContactTimeStore interface {
+	SavePlansWithContactTime(nome, situacao, planoAtual, planoDesejado, telefone, horario, observacoes string) error
+}
+
+// FeedbackStore recebe a avaliação e as sugestões deixadas ao fim do atendimento.
+type FeedbackStore interface {
+	SaveFeedback(nome, tipoAtendimento, feedback, sugestoes string) error
+}
+
+// NPSStore recebe as notas de 0 a 10 da pesquisa de satisfação (NPS), com o tipo de atendimento avaliado.
+type NPSStore interface {
+	SaveNPS(nome, tipoAtendimento string, nota int) error
+}
+
+// namedStore é um backend adicional registrado com AddStore.
+type namedStore struct {
+	name  string
+	store interface{}
+}
+
+// AddStore registra um backend adicional (CRM, webhook, outro banco) que recebe os registros em paralelo
+// ao Sheets. O backend deve implementar ao menos uma de SupportStore, LeadStore, FeedbackStore ou NPSStore.
+func (s *ChatbotService) AddStore(name string, store interface{}) {
+	s.stores = append(s.stores, namedStore{name: name, store: store})
+}
+
+// fanOut grava o registro em todos os backends adicionais que o suportam, cada um em sua goroutine e com
+// StoreAttempts tentativas (backoff dobrado a cada falha). Esgotadas, o registro vai para store_dead_letters,
+// de onde ReplayStores o reenvia ao mesmo backend. A falha de um backend não afeta os demais nem a resposta
+// ao usuário. As gravações em andamento são aguardadas por Shutdown.
+func (s *ChatbotService) fanOut(ctx context.Context, kind string, record interface{}) {
+	for _, ns := range s.stores {
+		s.storesWG.Add(1)
+		go func(ns namedStore) {
+			defer s.storesWG.Done()
+			span, _ := tracer.StartSpanFromContext(ctx, "store.write", tracer.ResourceName(ns.name), tracer.Tag("operation", kind))
+			handled, tries, err := s.deliverToStore(ns, record)
+			span.SetTag("attempts", tries)
+			span.Finish(tracer.WithError(err))
+			if handled && err != nil {
+				s.deadLetterStore(ns.name, kind, record, tries, err)
+			}
+		}(ns)
+	}
+}
+
+// deliverToStore tenta gravar o registro em ns até StoreAttempts vezes. handled é falso se o backend não
+// implementa a interface do registro.
+func (s *ChatbotService) deliverToStore(ns namedStore, record interface{}) (handled bool, tries int, err error) {
+	attempts := s.cfg.StoreAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := s.cfg.StoreBackoff
+	for tries = 1; ; tries++ {
+		handled, err = saveToStore(ns.store, record)
+		if !handled || err == nil || tries >= attempts {
+			return handled, tries, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// deadLetterStore guarda em store_dead_letters o registro que o backend recusou em todas as tentativas.
+func (s *ChatbotService) deadLetterStore(store, kind string, record interface{}, tries int, cause error) {
+	log.Printf("Erro ao gravar %s no backend %s após %d tentativa(s), guardado na dead-letter: %v", kind, store, tries, cause)
+	if s.writer == nil {
+		return
+	}
+	payload, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("Erro ao serializar %s para a dead-letter do backend %s: %v", kind, store, err)
+		return
+	}
+	s.writer.enqueue("registro na dead-letter dos backends",
+		`INSERT INTO store_dead_letters (store, kind, payload, error, attempts, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		store, kind, string(payload), cause.Error(), tries, s.now().UTC(),
+	)
+}
+
+// maxStoreReplay limita quantos registros da dead-letter dos backends são reenviados por chamada.
+const maxStoreReplay = 100
+
+// ErrStoreReplayInProgress indica que já há um reenvio da dead-letter dos backends em andamento.
+var ErrStoreReplayInProgress = errors.New("reenvio aos backends já em andamento")
+
+// StartStoreReplay dispara ReplayStores em segundo plano e retorna em seguida; o resultado vai para o log.
+// Com outro reenvio em andamento retorna ErrStoreReplayInProgress.
+func (s *ChatbotService) StartStoreReplay() error {
+	if s.db == nil {
+		return fmt.Errorf("banco local não configurado")
+	}
+	if !s.storeReplaying.CompareAndSwap(false, true) {
+		return ErrStoreReplayInProgress
+	}
+	s.storesWG.Add(1)
+	go func() {
+		defer s.storesWG.Done()
+		defer s.storeReplaying.Store(false)
+		replayed, failed, err := s.ReplayStores()
+		if err != nil {
+			log.Printf("Erro ao reenviar registros da dead-letter dos backends: %v", err)
+			return
+		}
+		log.Printf("Registros da dead-letter dos backends reenviados: %d gravados, %d pendentes", replayed, failed)
+	}()
+	return nil
+}
+
+// ReplayStores reenvia os registros pendentes de store_dead_letters ao backend de origem (uma tentativa cada),
+// marcando os gravados. Retorna quantos foram reenviados e quantos continuam pendentes.
+func (s *ChatbotService) ReplayStores() (replayed, failed int, err error) {
+	if s.db == nil {
+		return 0, 0, fmt.Errorf("banco local não configurado")
+	}
+	rows, err := s.queryDB(
+		`SELECT id, store, kind, payload FROM store_dead_letters
+		WHERE replayed_at IS NULL ORDER BY id LIMIT ?`, maxStoreReplay,
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("erro ao ler dead-letter dos backends: %w", err)
+	}
+	type deadLetter struct {
+		id                   int64
+		store, kind, payload string
+	}
+	var pending []deadLetter
+	for rows.Next() {
+		var d deadLetter
+		if err := rows.Scan(&d.id, &d.store, &d.kind, &d.payload); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		pending = append(pending, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, d := range pending {
+		ns, ok := s.storeByName(d.store)
+		if !ok {
+			failed++
+			continue
+		}
+		record, saveErr := decodeRecord(d.kind, d.payload)
+		if saveErr == nil {
+			_, saveErr = saveToStore(ns.store, record)
+		}
+		if saveErr != nil {
+			failed++
+			if err := s.execDB(`UPDATE store_dead_letters SET error = ? WHERE id = ?`, saveErr.Error(), d.id); err != nil {
+				log.Printf("Erro ao registrar falha do registro %d do backend %s: %v", d.id, d.store, err)
+			}
+			continue
+		}
+		if err := s.execDB(`UPDATE store_dead_letters SET replayed_at = ? WHERE id = ?`, s.now().UTC(), d.id); err != nil {
+			log.Printf("Erro ao marcar registro %d do backend %s como reenviado: %v", d.id, d.store, err)
+		}
+		replayed++
+	}
+	return replayed, failed, nil
+}
+
+// storeByName retorna o backend adicional registrado com o nome informado.
+func (s *ChatbotService) storeByName(name string) (namedStore, bool) {
+	for _, ns := range s.stores {
+		if ns.name == name {
+			return ns, true
+		}
+	}
+	return namedStore{}, false
+}
+
+// saveToStore grava o registro (sheetsSupportRecord, sheetsPlansRecord, sheetsFeedbackRecord ou
+// sheetsNPSRecord) em store. handled é falso se store não implementa a interface correspondente.
+func saveToStore(store, record interface{}) (handled bool, err error) {
+	switch r := record.(type) {
+	case sheetsSupportRecord:
+		st, ok := store.(SupportStore)
+		if !ok {
+			return false, nil
+		}
+		return true, saveSupportRecord(st, r)
+	case sheetsPlansRecord:
+		st, ok := store.(LeadStore)
+		if !ok {
+			return false, nil
+		}
+		return true, savePlansRecord(st, r)
+	case sheetsFeedbackRecord:
+		st, ok := store.(FeedbackStore)
+		if !ok {
+			return false, nil
+		}
+		return true, st.SaveFeedback(r.Nome, r.TipoAtendimento, r.Feedback, r.Sugestoes)
+	case sheetsNPSRecord:
+		st, ok := store.(NPSStore)
+		if !ok {
+			return false, nil
+		}
+		return true, st.SaveNPS(r.Nome, r.TipoAtendimento, r.Nota)
+	}
+	return false, nil
+}
+
+// decodeRecord desserializa o payload de um registro enfileirado (fila local do Sheets ou dead-letter dos
+// backends). Tipos desconhecidos retornam nil, que saveToStore ignora.
+func decodeRecord(kind, payload string) (interface{}, error) {
+	var record interface{}
+	switch kind {
+	case sheetsKindSupport:
+		var r sheetsSupportRecord
+		if err := json.Unmarshal([]byte(payload), &r); err != nil {
+			return nil, err
+		}
+		record = r
+	case sheetsKindPlans:
+		var r sheetsPlansRecord
+		if err := json.Unmarshal([]byte(payload), &r); err != nil {
+			return nil, err
+		}
+		record = r
+	case sheetsKindFeedback:
+		var r sheetsFeedbackRecord
+		if err := json.Unmarshal([]byte(payload), &r); err != nil {
+			return nil, err
+		}
+		record = r
+	case sheetsKindNPS:
+		var r sheetsNPSRecord
+		if err := json.Unmarshal([]byte(payload), &r); err != nil {
+			return nil, err
+		}
+		record = r
+	}
+	return record, nil
+}
+
+// fanOutSupport repassa o atendimento de suporte aos backends adicionais que implementam SupportStore.
+func (s *ChatbotService) fanOutSupport(ctx context.Context, r sheetsSupportRecord) {
+	s.fanOut(ctx, sheetsKindSupport, r)
+}
+
+// saveSupportRecord grava r em st, com o histórico em campo próprio se st implementar
+// SupportTranscriptStore ou anexado à descrição caso contrário.
+func saveSupportRecord(st SupportStore, r sheetsSupportRecord) error {
+	if ts, ok := st.(SupportTranscriptStore); ok {
+		return ts.SaveSupportWithTranscript(r.Nome, r.Problema, r.Descricao, r.Status, r.Historico)
+	}
+	descricao := r.Descricao
+	if r.Historico != "" {
+		descricao = strings.TrimSpace(descricao + "\n\n" + r.Historico)
+	}
+	return st.SaveSupport(r.Nome, r.Problema, descricao, r.Status)
+}
+
+// fanOutPlans repassa o lead aos backends adicionais que implementam LeadStore.
+func (s *ChatbotService) fanOutPlans(ctx context.Context, r sheetsPlansRecord) {
+	s.fanOut(ctx, sheetsKindPlans, r)
+}
+
+// savePlansRecord grava r em st, com o horário de contato em campo próprio se st implementar
+// LeadContactTimeStore ou anexado às observações caso contrário.
+func savePlansRecord(st LeadStore, r sheetsPlansRecord) error {
+	if ct, ok := st.(LeadContactTimeStore); ok {
+		return ct.SavePlansWithContactTime(r.Nome, r.Situacao, r.PlanoAtual, r.PlanoDesejado, r.Telefone, r.Horario, r.Observacoes)
+	}
+	observacoes := r.Observacoes
+	if r.Horario != "" {
+		observacoes = strings.TrimSpace(observacoes + "\nHorário para contato: " + r.Horario)
+	}
+	return st.SavePlans(r.Nome, r.Situacao, r.PlanoAtual, r.PlanoDesejado, r.Telefone, observacoes)
+}
+
+// fanOutFeedback repassa a avaliação aos backends adicionais que implementam FeedbackStore.
+func (s *ChatbotService) fanOutFeedback(ctx context.Context, r sheetsFeedbackRecord) {
+	s.fanOut(ctx, sheetsKindFeedback, r)
+}
+
+// fanOutNPS repassa a nota da pesquisa aos backends adicionais que implementam NPSStore. Backends sem
+// NPSStore, como os registrados antes da pesquisa existir, são ignorados.
+func (s *ChatbotService) fanOutNPS(ctx context.Context, r sheetsNPSRecord) {
+	s.fanOut(ctx, sheetsKindNPS, r)
+}
+
+// WebhookStore envia cada registro como JSON (POST) para uma URL externa, como a de um CRM.
+type WebhookStore struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookStore cria um WebhookStore com timeout de 10 segundos.
+func NewWebhookStore(url string) *WebhookStore {
+	return &WebhookStore{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// SaveSupport envia o atendimento de suporte ao webhook.
+func (w *WebhookStore) SaveSupport(nome, problema, descricao, status string) error {
+	return w.SaveSupportWithTranscript(nome, problema, descricao, status, "")
+}
+
+// SaveSupportWithTranscript envia o atendimento de suporte ao webhook, com o histórico em historico.
+func (w *WebhookStore) SaveSupportWithTranscript(nome, problema, descricao, status, historico string) error {
+	return w.post(sheetsKindSupport, sheetsSupportRecord{nome, problema, descricao, status, historico})
+}
+
+// SavePlans envia o interesse em planos ao webhook, sem horário de contato.
+func (w *WebhookStore) SavePlans(nome, situacao, planoAtual, planoDesejado, telefone, observacoes string) error {
+	return w.SavePlansWithContactTime(nome, situacao, planoAtual, planoDesejado, telefone, "", observacoes)
+}
+
+// SavePlansWithContactTime envia o interesse em planos ao webhook, com o horário em horario_contato.
+func (w *WebhookStore) SavePlansWithContactTime(nome, situacao, planoAtual, planoDesejado, telefone, horario, observacoes string) error {
+	return w.post(sheetsKindPlans, sheetsPlansRecord{nome, situacao, planoAtual, planoDesejado, telefone, horario, observacoes})
+}
+
+// SaveFeedback envia o feedback ao webhook.
+func (w *WebhookStore) SaveFeedback(nome, tipoAtendimento, feedback, sugestoes string) error {
+	return w.post(sheetsKindFeedback, sheetsFeedbackRecord{nome, tipoAtendimento, feedback, sugestoes})
+}
+
+// SaveNPS envia a nota da pesquisa de satisfação ao webhook.
+func (w *WebhookStore) SaveNPS(nome, tipoAtendimento string, nota int) error {
+	return w.post(sheetsKindNPS, sheetsNPSRecord{nome, tipoAtendimento, nota})
+}
+
+// post serializa o registro no formato {"kind": ..., "record": ...} e o envia ao webhook.
+func (w *WebhookStore) post(kind string, record interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"kind": kind, "record": record})
+	if err != nil {
+		return err
+	}
+	resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook retornou status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// legacyLeadStore implementa só LeadStore, com a assinatura anterior ao horário de contato.
+type legacyLeadStore struct{ observacoes string }
+
+func (l *legacyLeadStore) SavePlans(_, _, _, _, _, observacoes string) error {
+	l.observacoes = observacoes
+	return nil
+}
+
+// contactTimeLeadStore também implementa LeadContactTimeStore.
+type contactTimeLeadStore struct {
+	legacyLeadStore
+	horario string
+}
+
+func (c *contactTimeLeadStore) SavePlansWithContactTime(_, _, _, _, _, horario, observacoes string) error {
+	c.horario, c.observacoes = horario, observacoes
+	return nil
+}
+
+func TestFanOutPlansContactTimeByStoreCapability(t *testing.T) {
+	s := newTestService(t, nil, nil)
+	legacy := &legacyLeadStore{}
+	modern := &contactTimeLeadStore{}
+	s.AddStore("legado", legacy)
+	s.AddStore("crm", modern)
+
+	s.fanOutPlans(context.Background(), sheetsPlansRecord{Nome: "Ana", Horario: "Tarde (12h às 18h)", Observacoes: "Via: Chat"})
+	s.storesWG.Wait()
+
+	if legacy.observacoes != "Via: Chat\nHorário para contato: Tarde (12h às 18h)" {
+		t.Errorf("observações do backend legado = %q; esperado o horário anexado", legacy.observacoes)
+	}
+	if modern.horario != "Tarde (12h às 18h)" || modern.observacoes != "Via: Chat" {
+		t.Errorf("backend com horário = %q / %q; esperado o horário em campo próprio", modern.horario, modern.observacoes)
+	}
+}
+
+func TestSavePlansRecordWithoutContactTimeKeepsObservations(t *testing.T) {
+	legacy := &legacyLeadStore{}
+	if err := savePlansRecord(legacy, sheetsPlansRecord{Nome: "Ana", Observacoes: "Via: Chat"}); err != nil {
+		t.Fatal(err)
+	}
+	if legacy.observacoes != "Via: Chat" {
+		t.Errorf("observações = %q; nada deve ser anexado sem horário", legacy.observacoes)
+	}
+}
+
+// flakyNPSStore falha nas primeiras failures gravações.
+type flakyNPSStore struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	saved    []string
+}
+
+func (f *flakyNPSStore) SaveNPS(nome, _ string, _ int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("CRM indisponível")
+	}
+	f.saved = append(f.saved, nome)
+	return nil
+}
+
+func newStoreTestService(t *testing.T, store *flakyNPSStore) *ChatbotService {
+	t.Helper()
+	s := newTestServiceWith(t, newTestDB(t), &fakeSheets{}, nil, func(cfg *Config) {
+		cfg.StoreAttempts = 3
+		cfg.StoreBackoff = time.Millisecond
+	})
+	s.AddStore("crm", store)
+	return s
+}
+
+// flushWriter aguarda as gravações no banco enfileiradas até aqui, recriando o writer para o restante do teste.
+func flushWriter(t *testing.T, s *ChatbotService) {
+	t.Helper()
+	s.storesWG.Wait()
+	if err := s.writer.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.writer = newAsyncWriter(s.db, 1, time.Millisecond)
+}
+
+func TestFanOutRetriesFailingStore(t *testing.T) {
+	store := &flakyNPSStore{failures: 2}
+	s := newStoreTestService(t, store)
+
+	s.fanOutNPS(context.Background(), sheetsNPSRecord{Nome: "Ana", Nota: 9})
+	flushWriter(t, s)
+
+	if store.calls != 3 || len(store.saved) != 1 {
+		t.Errorf("calls = %d, gravados = %v; esperado gravar na terceira tentativa", store.calls, store.saved)
+	}
+	if got := count(t, s.db, "store_dead_letters"); got != 0 {
+		t.Errorf("dead-letter = %d; esperado 0", got)
+	}
+}
+
+func TestFanOutDeadLettersAndReplays(t *testing.T) {
+	store := &flakyNPSStore{failures: 3}
+	s := newStoreTestService(t, store)
+
+	s.fanOutNPS(context.Background(), sheetsNPSRecord{Nome: "Ana", TipoAtendimento: "Suporte", Nota: 9})
+	flushWriter(t, s)
+	if got := count(t, s.db, "store_dead_letters"); got != 1 {
+		t.Fatalf("dead-letter = %d; esperado o registro após 3 falhas", got)
+	}
+
+	replayed, failed, err := s.ReplayStores()
+	if err != nil || replayed != 1 || failed != 0 {
+		t.Fatalf("ReplayStores = %d, %d, %v", replayed, failed, err)
+	}
+	if len(store.saved) != 1 || store.saved[0] != "Ana" {
+		t.Errorf("gravados = %v; esperado o registro reenviado ao backend de origem", store.saved)
+	}
+	if replayed, _, _ := s.ReplayStores(); replayed != 0 {
+		t.Errorf("registro reenviado de novo: %d", replayed)
+	}
+}
+
+func TestFanOutSkipsStoresWithoutInterface(t *testing.T) {
+	s := newStoreTestService(t, &flakyNPSStore{})
+	legacy := &legacyLeadStore{}
+	s.AddStore("legado", legacy)
+
+	s.fanOutFeedback(context.Background(), sheetsFeedbackRecord{Nome: "Ana"})
+	flushWriter(t, s)
+	if got := count(t, s.db, "store_dead_letters"); got != 0 {
+		t.Errorf("dead-letter = %d; backends sem FeedbackStore não devem falhar", got)
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"leadprojectarrumado/internal/ai"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// generateAI executa a chamada à IA dentro de um span filho do atendimento, marcado com o modo (technical/free),
+// e anexa o aviso de IA somente ao texto gerado pelo modelo; respostas estáticas (ai.ErrFallback, inclusive
+// resposta vazia do Gemini) seguem sem ele. Acima de AIMaxConcurrent chamadas simultâneas retorna errAIBusy,
+// conforme AIOverflowPolicy, para que o fluxo use a resposta estática. A espera por vaga e a chamada dividem
+// o prazo AIResponseBudget, derivado do contexto da requisição.
+func (s *ChatbotService) generateAI(ctx context.Context, mode string, call func(context.Context) (string, error)) (string, error) {
+	if s.cfg.AIResponseBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.AIResponseBudget)
+		defer cancel()
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, "ai.generate", tracer.ResourceName(mode), tracer.Tag("operation", mode))
+	if err := s.aiLimiter.acquire(ctx); err != nil {
+		span.SetTag("ai_busy", true)
+		span.Finish(tracer.WithError(err))
+		s.recordAIResult(mode, err)
+		return "", err
+	}
+	span.SetTag("ai_in_flight", s.AIInFlight())
+	response, err := call(ctx)
+	s.aiLimiter.release()
+	span.Finish(tracer.WithError(err))
+	s.recordAIResult(mode, err)
+	// ai.ErrFallback traz o texto estático do cliente, ainda utilizável pelo fluxo; só conta como fallback.
+	if errors.Is(err, ai.ErrFallback) && response != "" {
+		return response, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.withDisclaimer(response), nil
+}
+
+// traceSheets executa a gravação no Sheets dentro de um span filho do atendimento, marcado com o tipo de registro.
+func traceSheets(ctx context.Context, kind string, send func() error) error {
+	span, _ := tracer.StartSpanFromContext(ctx, "sheets.write", tracer.ResourceName(kind), tracer.Tag("operation", kind))
+	err := send()
+	span.Finish(tracer.WithError(err))
+	return err
+}
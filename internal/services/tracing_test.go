@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+)
+
+// finishedSpan retorna o span finalizado com o nome de operação informado.
+func finishedSpan(t *testing.T, mt mocktracer.Tracer, operation string) mocktracer.Span {
+	t.Helper()
+	for _, span := range mt.FinishedSpans() {
+		if span.OperationName() == operation {
+			return span
+		}
+	}
+	t.Fatalf("span %s não encontrado", operation)
+	return nil
+}
+
+func TestAICallIsChildSpanOfMessage(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	s := newTestService(t, &fakeAI{text: "A fibra chega até 1 Gbps."}, nil)
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWeb, user, "oi")
+	s.ProcessMessage(ctx, ChannelWeb, user, "4")
+	mt.Reset()
+	s.ProcessMessage(ctx, ChannelWeb, user, "qual a velocidade da fibra?")
+
+	root := finishedSpan(t, mt, "chatbot.process_message")
+	ai := finishedSpan(t, mt, "ai.generate")
+	if ai.ParentID() != root.SpanID() {
+		t.Errorf("ai.generate com pai %d; esperado o span do atendimento (%d)", ai.ParentID(), root.SpanID())
+	}
+	if ai.Tag("operation") != "free" || root.Tag("channel") != ChannelWeb {
+		t.Errorf("tags = %v / %v; esperado operation=free e channel=%s", ai.Tags(), root.Tags(), ChannelWeb)
+	}
+}
+
+func TestTraceSheetsRecordsErrors(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	failure := errors.New("quota")
+	if err := traceSheets(context.Background(), "lead", func() error { return failure }); err != failure {
+		t.Fatalf("traceSheets err = %v; esperado o erro do envio", err)
+	}
+	span := finishedSpan(t, mt, "sheets.write")
+	if span.Tag("operation") != "lead" || span.Tag("error") == nil {
+		t.Errorf("tags = %v; esperado operation=lead e o erro registrado", span.Tags())
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// historyKeyPrefix guarda o histórico do atendimento técnico (problema, sugestões da IA e respostas).
+const historyKeyPrefix = "history:"
+
+// maxTranscriptEntries limita o histórico guardado por sessão (5 tentativas com resposta cabem com folga).
+const maxTranscriptEntries = 20
+
+// Autores das entradas da transcrição.
+const (
+	TranscriptCustomer = "cliente"
+	TranscriptBot      = "bot"
+)
+
+// TranscriptEntry é uma mensagem do atendimento técnico guardada para o encaminhamento ao técnico humano.
+type TranscriptEntry struct {
+	Role string    `json:"role"`
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
+
+// history retorna o histórico da sessão, em ordem cronológica.
+func (st *sessionStore) history(ctx context.Context, userID string) []TranscriptEntry {
+	raw, ok := st.get(ctx, historyKeyPrefix+userID)
+	if !ok {
+		return nil
+	}
+	var entries []TranscriptEntry
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		log.Printf("Histórico de %s ilegível, descartado: %v", userID, err)
+		return nil
+	}
+	return entries
+}
+
+// setHistory grava o histórico com o TTL de histórico; uma lista vazia remove a chave.
+func (st *sessionStore) setHistory(ctx context.Context, userID string, entries []TranscriptEntry) {
+	if len(entries) == 0 {
+		st.memory.del(historyKeyPrefix + userID)
+		st.breaker.do(ctx, func() error { return st.redis.Del(ctx, historyKeyPrefix+userID).Err() })
+		return
+	}
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+	st.set(ctx, historyKeyPrefix+userID, string(raw), st.ttl.History)
+}
+
+// recordTranscript acrescenta uma mensagem ao histórico do atendimento técnico, se habilitado
+// (ESCALATION_TRANSCRIPT). Mantém apenas as maxTranscriptEntries mais recentes.
+func (s *ChatbotService) recordTranscript(userID, role, text string) {
+	if !s.cfg.EscalationTranscript || strings.TrimSpace(text) == "" {
+		return
+	}
+	ctx := context.Background()
+	entries := append(s.sessions.history(ctx, userID), TranscriptEntry{Role: role, Text: text, At: s.now()})
+	if len(entries) > maxTranscriptEntries {
+		entries = entries[len(entries)-maxTranscriptEntries:]
+	}
+	s.sessions.setHistory(ctx, userID, entries)
+}
+
+// resetTranscript descarta o histórico ao iniciar um novo atendimento técnico.
+func (s *ChatbotService) resetTranscript(userID string) {
+	s.sessions.setHistory(context.Background(), userID, nil)
+}
+
+// transcript retorna o histórico do atendimento técnico do usuário.
+func (s *ChatbotService) transcript(userID string) []TranscriptEntry {
+	if !s.cfg.EscalationTranscript {
+		return nil
+	}
+	return s.sessions.history(context.Background(), userID)
+}
+
+// formatTranscript monta o texto do histórico para o chamado (Sheets e notificações), uma entrada por bloco,
+// com os horários no fuso loc.
+func formatTranscript(entries []TranscriptEntry, loc *time.Location) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Histórico do atendimento:")
+	for _, e := range entries {
+		author := "Cliente"
+		if e.Role == TranscriptBot {
+			author = "Bot"
+		}
+		fmt.Fprintf(&b, "\n\n[%s] %s: %s", e.At.In(loc).Format("15:04"), author, strings.TrimSpace(e.Text))
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// transcriptSupportStore implementa SupportTranscriptStore e guarda o último registro recebido.
+type transcriptSupportStore struct {
+	mu     sync.Mutex
+	record sheetsSupportRecord
+}
+
+func (t *transcriptSupportStore) SaveSupport(nome, problema, descricao, status string) error {
+	return t.SaveSupportWithTranscript(nome, problema, descricao, status, "")
+}
+
+func (t *transcriptSupportStore) SaveSupportWithTranscript(nome, problema, descricao, status, historico string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record = sheetsSupportRecord{nome, problema, descricao, status, historico}
+	return nil
+}
+
+// legacySupportStore implementa só SupportStore.
+type legacySupportStore struct{ descricao string }
+
+func (l *legacySupportStore) SaveSupport(_, _, descricao, _ string) error {
+	l.descricao = descricao
+	return nil
+}
+
+// escalate leva o usuário do menu ao encaminhamento automático, respondendo NÃO às sugestões da IA.
+func escalate(t *testing.T, s *ChatbotService, user string) {
+	t.Helper()
+	ctx := context.Background()
+	for _, msg := range []string{"oi", "1", "Ana Souza", "minha internet caiu"} {
+		s.ProcessMessage(ctx, ChannelWeb, user, msg)
+	}
+	for i := 0; i < 5; i++ {
+		if response, _ := s.ProcessMessage(ctx, ChannelWeb, user, "não"); strings.Contains(response, "Encaminhamento") {
+			return
+		}
+	}
+	t.Fatal("sem encaminhamento após 5 respostas NÃO")
+}
+
+func TestEscalationSendsTranscriptSeparateFromDescription(t *testing.T) {
+	s := newTestServiceWith(t, nil, &fakeSheets{}, &fakeAI{text: "Reinicie o roteador."}, func(cfg *Config) { cfg.EscalationTranscript = true })
+	store := &transcriptSupportStore{}
+	legacy := &legacySupportStore{}
+	s.AddStore("crm", store)
+	s.AddStore("legado", legacy)
+	escalated := make(chan TicketEscalated, 1)
+	s.events.Subscribe(EventTicketEscalated, func(e Event) { escalated <- e.(TicketEscalated) })
+
+	escalate(t, s, "5544999998888")
+	s.storesWG.Wait()
+
+	store.mu.Lock()
+	record := store.record
+	store.mu.Unlock()
+	if record.Status != "Encaminhado para Técnico Humano" {
+		t.Fatalf("status = %q", record.Status)
+	}
+	if strings.Contains(record.Descricao, "Histórico do atendimento") {
+		t.Errorf("descrição contém o histórico: %q", record.Descricao)
+	}
+	if !strings.HasPrefix(record.Historico, "Histórico do atendimento:") ||
+		!strings.Contains(record.Historico, "Cliente: minha internet caiu") ||
+		!strings.Contains(record.Historico, "Reinicie o roteador.") ||
+		strings.Count(record.Historico, "Cliente: não") != 4 {
+		t.Errorf("histórico = %q; esperado o problema, as sugestões e as respostas", record.Historico)
+	}
+	if !strings.HasSuffix(legacy.descricao, record.Historico) {
+		t.Errorf("backend legado sem o histórico na descrição: %q", legacy.descricao)
+	}
+
+	e := <-escalated
+	if e.Nome != "Ana Souza" || e.Tentativas != 5 || e.Problema != "minha internet caiu" {
+		t.Errorf("evento = %+v", e)
+	}
+	if len(e.Transcript) == 0 || e.Transcript[0].Role != TranscriptCustomer || e.Transcript[0].Text != "minha internet caiu" {
+		t.Errorf("Transcript = %+v; esperado começar pelo problema relatado", e.Transcript)
+	}
+}
+
+func TestEscalationWithoutTranscript(t *testing.T) {
+	s := newTestServiceWith(t, nil, &fakeSheets{}, &fakeAI{text: "Reinicie o roteador."}, func(cfg *Config) { cfg.EscalationTranscript = false })
+	store := &transcriptSupportStore{}
+	s.AddStore("crm", store)
+	escalated := make(chan TicketEscalated, 1)
+	s.events.Subscribe(EventTicketEscalated, func(e Event) { escalated <- e.(TicketEscalated) })
+
+	escalate(t, s, "5544999998888")
+	s.storesWG.Wait()
+
+	if store.record.Historico != "" {
+		t.Errorf("histórico = %q com ESCALATION_TRANSCRIPT=false", store.record.Historico)
+	}
+	if e := <-escalated; len(e.Transcript) != 0 {
+		t.Errorf("Transcript = %+v com ESCALATION_TRANSCRIPT=false", e.Transcript)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// BusinessUnit representa uma unidade de atendimento presencial.
+type BusinessUnit struct {
+	Name    string   `json:"name"`
+	Address string   `json:"address"`
+	Phones  []string `json:"phones"`
+}
+
+// defaultUnits é o diretório de unidades usado quando UNITS_JSON não está definido.
+var defaultUnits = []BusinessUnit{
+	{Name: "Francisco Alves", Address: "Av. Brigadeiro Faria Lima 703 - Centro", Phones: []string{"(44) 3643-1736"}},
+	{Name: "Iporã", Address: "Rua Katsuo Nakata 1115 - Centro", Phones: []string{"(44) 98402-7130", "(44) 3199-9115"}},
+	{Name: "Palotina", Address: "Aldir Pedron 1319 - Centro", Phones: []string{"(44) 3649-1486"}},
+	{Name: "Terra Roxa", Address: "Av. da Saudade 369 - Centro", Phones: []string{"(44) 3645-3257"}},
+}
+
+// parseUnits lê o diretório de unidades em JSON, mantendo o padrão se o valor for inválido ou vazio.
+func parseUnits(raw string) []BusinessUnit {
+	var units []BusinessUnit
+	if err := json.Unmarshal([]byte(raw), &units); err != nil {
+		log.Printf("UNITS_JSON inválido, usando unidades padrão: %v", err)
+		return defaultUnits
+	}
+	valid := units[:0]
+	for _, u := range units {
+		if strings.TrimSpace(u.Name) != "" {
+			valid = append(valid, u)
+		}
+	}
+	if len(valid) == 0 {
+		return defaultUnits
+	}
+	return valid
+}
+
+// renderUnits formata o diretório de unidades como bloco de contatos.
+func renderUnits(units []BusinessUnit) string {
+	var b strings.Builder
+	b.WriteString("*Unidade / Responsável*")
+	for _, u := range units {
+		fmt.Fprintf(&b, "\n%s: %s | %s\n", u.Name, u.Address, strings.Join(u.Phones, " / "))
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	units := parseUnits(`[{"name":"Umuarama","address":"Rua A 10","phones":["(44) 3000-0000","(44) 99999-0000"]},{"name":"  ","address":"sem nome"}]`)
+	if len(units) != 1 || units[0].Name != "Umuarama" || len(units[0].Phones) != 2 {
+		t.Errorf("parseUnits = %+v; esperado só a unidade com nome", units)
+	}
+
+	for _, raw := range []string{"não é json", "[]", `[{"address":"sem nome"}]`} {
+		if got := parseUnits(raw); len(got) != len(defaultUnits) || got[0].Name != defaultUnits[0].Name {
+			t.Errorf("parseUnits(%q) = %+v; esperado as unidades padrão", raw, got)
+		}
+	}
+}
+
+func TestRenderUnits(t *testing.T) {
+	got := renderUnits([]BusinessUnit{{Name: "Umuarama", Address: "Rua A 10", Phones: []string{"(44) 3000-0000", "(44) 99999-0000"}}})
+	want := "*Unidade / Responsável*\nUmuarama: Rua A 10 | (44) 3000-0000 / (44) 99999-0000\n"
+	if got != want {
+		t.Errorf("renderUnits = %q; esperado %q", got, want)
+	}
+}
+
+func TestBoletoInfoListsConfiguredUnits(t *testing.T) {
+	s := newTestService(t, nil, func(cfg *Config) {
+		cfg.Units = []BusinessUnit{{Name: "Umuarama", Address: "Rua A 10", Phones: []string{"(44) 3000-0000"}}}
+	})
+	ctx := context.Background()
+	const user = "5544999998888"
+
+	s.ProcessMessage(ctx, ChannelWhatsApp, user, "oi")
+	response, _ := s.ProcessMessage(ctx, ChannelWhatsApp, user, "3")
+	if !strings.Contains(response, "Umuarama: Rua A 10 | (44) 3000-0000") {
+		t.Errorf("resposta = %q; esperado a unidade configurada", response)
+	}
+	if strings.Contains(response, defaultUnits[0].Name) {
+		t.Errorf("resposta = %q; unidades padrão não deveriam aparecer", response)
+	}
+}
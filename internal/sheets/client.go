@@ -22,6 +22,7 @@ const (
 type Client struct {
 	service *sheets.Service
 	ctx     context.Context
+	loc     *time.Location
 }
 
 // NewClient inicializa e autentica um novo cliente Google Sheets.
@@ -58,25 +59,26 @@ func NewClient() (*Client, error) {
 	return client, nil
 }
 
-// formatSheets formata as três abas principais da planilha.
+// formatSheets formata as abas principais da planilha.
 func (c *Client) formatSheets() {
 	c.formatFeedbackSheet()
 	c.formatSupportSheet()
 	c.formatPlansSheet()
+	c.formatNPSSheet()
 }
 
 // formatFeedbackSheet formata a aba de feedbacks (Página1).
 func (c *Client) formatFeedbackSheet() {
 
 	headers := [][]interface{}{
-		{"DATA/HORA", "NOME COMPLETO", "TIPO DE ATENDIMENTO", "AVALIAÇÃO", "SUGESTÕES/OBSERVAÇÕES"},
+		{"DATA/HORA", "NOME COMPLETO", "TIPO DE ATENDIMENTO", "AVALIAÇÃO", "SUGESTÕES/OBSERVAÇÕES", isoHeader},
 	}
 
 	valueRange := &sheets.ValueRange{
 		Values: headers,
 	}
 
-	c.service.Spreadsheets.Values.Update(SpreadsheetID, "Página1!A1:E1", valueRange).
+	c.service.Spreadsheets.Values.Update(SpreadsheetID, "Página1!A1:F1", valueRange).
 		ValueInputOption("RAW").
 		Do()
 
@@ -87,14 +89,14 @@ func (c *Client) formatFeedbackSheet() {
 func (c *Client) formatSupportSheet() {
 
 	headers := [][]interface{}{
-		{"DATA/HORA", "NOME COMPLETO", "PROBLEMA RELATADO", "DESCRIÇÃO DETALHADA", "STATUS RESOLUÇÃO"},
+		{"DATA/HORA", "NOME COMPLETO", "PROBLEMA RELATADO", "DESCRIÇÃO DETALHADA", "STATUS RESOLUÇÃO", isoHeader, "HISTÓRICO DO ATENDIMENTO"},
 	}
 
 	valueRange := &sheets.ValueRange{
 		Values: headers,
 	}
 
-	c.service.Spreadsheets.Values.Update(SpreadsheetID, "Página2!A1:E1", valueRange).
+	c.service.Spreadsheets.Values.Update(SpreadsheetID, "Página2!A1:G1", valueRange).
 		ValueInputOption("RAW").
 		Do()
 
@@ -105,22 +107,46 @@ func (c *Client) formatSupportSheet() {
 func (c *Client) formatPlansSheet() {
 
 	headers := [][]interface{}{
-		{"DATA/HORA", "NOME COMPLETO", "SITUAÇÃO CLIENTE", "PLANO ATUAL", "PLANO DESEJADO", "TELEFONE", "OBSERVAÇÕES"},
+		{"DATA/HORA", "NOME COMPLETO", "SITUAÇÃO CLIENTE", "PLANO ATUAL", "PLANO DESEJADO", "TELEFONE", "OBSERVAÇÕES", isoHeader, "HORÁRIO PARA CONTATO"},
 	}
 
 	valueRange := &sheets.ValueRange{
 		Values: headers,
 	}
 
-	c.service.Spreadsheets.Values.Update(SpreadsheetID, "Página3!A1:G1", valueRange).
+	c.service.Spreadsheets.Values.Update(SpreadsheetID, "Página3!A1:I1", valueRange).
 		ValueInputOption("RAW").
 		Do()
 
 	log.Println("Página3 (Planos) formatada com cabeçalhos")
 }
 
-// SaveSupport salva dados de suporte técnico na Página2 do Google Sheets.
+// formatNPSSheet formata a aba da pesquisa de satisfação (Página4).
+func (c *Client) formatNPSSheet() {
+
+	headers := [][]interface{}{
+		{"DATA/HORA", "NOME COMPLETO", "TIPO DE ATENDIMENTO", "NOTA (0-10)", isoHeader},
+	}
+
+	valueRange := &sheets.ValueRange{
+		Values: headers,
+	}
+
+	c.service.Spreadsheets.Values.Update(SpreadsheetID, "Página4!A1:E1", valueRange).
+		ValueInputOption("RAW").
+		Do()
+
+	log.Println("Página4 (NPS) formatada com cabeçalhos")
+}
+
+// SaveSupport salva dados de suporte técnico na Página2 do Google Sheets, com a coluna de histórico vazia.
 func (c *Client) SaveSupport(nome, problema, descricao, status string) error {
+	return c.SaveSupportWithTranscript(nome, problema, descricao, status, "")
+}
+
+// SaveSupportWithTranscript salva dados de suporte técnico na Página2 do Google Sheets, com a transcrição
+// do atendimento na coluna HISTÓRICO DO ATENDIMENTO.
+func (c *Client) SaveSupportWithTranscript(nome, problema, descricao, status, historico string) error {
 	logger := logrus.WithFields(logrus.Fields{
 		"operation": "SaveSupport",
 		"user":      nome,
@@ -128,50 +154,56 @@ func (c *Client) SaveSupport(nome, problema, descricao, status string) error {
 	})
 	logger.Info("Salvando dados de suporte")
 
-	timestamp := time.Now().Format("02/01/2006 15:04:05")
+	timestamp, iso := c.timestamps()
 
 	values := [][]interface{}{
-		{timestamp, nome, problema, descricao, status},
+		{timestamp, nome, problema, descricao, status, iso, historico},
 	}
 
 	valueRange := &sheets.ValueRange{
 		Values: values,
 	}
 
-	_, err := c.service.Spreadsheets.Values.Append(SpreadsheetID, "Página2!A:E", valueRange).
+	_, err := c.service.Spreadsheets.Values.Append(SpreadsheetID, "Página2!A:G", valueRange).
 		ValueInputOption("RAW").
 		Do()
 
 	if err != nil {
 		log.Printf("Erro ao salvar suporte: %v", err)
-		return err
+		return classifyError("SaveSupport", err)
 	}
 
 	log.Println("Suporte salvo com sucesso!")
 	return nil
 }
 
-// SavePlans salva dados de planos na Página3 do Google Sheets.
+// SavePlans salva dados de planos na Página3 do Google Sheets, com a coluna de horário vazia.
 func (c *Client) SavePlans(nome, situacao, planoAtual, planoDesejado, telefone, observacoes string) error {
-	log.Printf("Salvando planos: %s, %s, %s, %s, %s, %s", nome, situacao, planoAtual, planoDesejado, telefone, observacoes)
+	return c.SavePlansWithContactTime(nome, situacao, planoAtual, planoDesejado, telefone, "", observacoes)
+}
 
-	timestamp := time.Now().Format("02/01/2006 15:04:05")
+// SavePlansWithContactTime salva dados de planos na Página3 do Google Sheets, com o horário preferido
+// na coluna HORÁRIO PARA CONTATO.
+func (c *Client) SavePlansWithContactTime(nome, situacao, planoAtual, planoDesejado, telefone, horario, observacoes string) error {
+	log.Printf("Salvando planos: %s, %s, %s, %s, %s, %s, %s", nome, situacao, planoAtual, planoDesejado, telefone, horario, observacoes)
+
+	timestamp, iso := c.timestamps()
 
 	values := [][]interface{}{
-		{timestamp, nome, situacao, planoAtual, planoDesejado, telefone, observacoes},
+		{timestamp, nome, situacao, planoAtual, planoDesejado, telefone, observacoes, iso, horario},
 	}
 
 	valueRange := &sheets.ValueRange{
 		Values: values,
 	}
 
-	_, err := c.service.Spreadsheets.Values.Append(SpreadsheetID, "Página3!A:G", valueRange).
+	_, err := c.service.Spreadsheets.Values.Append(SpreadsheetID, "Página3!A:I", valueRange).
 		ValueInputOption("RAW").
 		Do()
 
 	if err != nil {
 		log.Printf("Erro ao salvar planos: %v", err)
-		return err
+		return classifyError("SavePlans", err)
 	}
 
 	log.Println("Planos salvos com sucesso!")
@@ -182,25 +214,52 @@ func (c *Client) SavePlans(nome, situacao, planoAtual, planoDesejado, telefone,
 func (c *Client) SaveFeedback(nome, tipoAtendimento, feedback, sugestoes string) error {
 	log.Printf("Salvando feedback: %s, %s, %s, %s", nome, tipoAtendimento, feedback, sugestoes)
 
-	timestamp := time.Now().Format("02/01/2006 15:04:05")
+	timestamp, iso := c.timestamps()
 
 	values := [][]interface{}{
-		{timestamp, nome, tipoAtendimento, feedback, sugestoes},
+		{timestamp, nome, tipoAtendimento, feedback, sugestoes, iso},
 	}
 
 	valueRange := &sheets.ValueRange{
 		Values: values,
 	}
 
-	_, err := c.service.Spreadsheets.Values.Append(SpreadsheetID, "Página1!A:E", valueRange).
+	_, err := c.service.Spreadsheets.Values.Append(SpreadsheetID, "Página1!A:F", valueRange).
 		ValueInputOption("RAW").
 		Do()
 
 	if err != nil {
 		log.Printf("Erro ao salvar feedback: %v", err)
-		return err
+		return classifyError("SaveFeedback", err)
 	}
 
 	log.Println("Feedback salvo com sucesso!")
 	return nil
 }
+
+// SaveNPS salva a nota da pesquisa de satisfação na Página4 do Google Sheets.
+func (c *Client) SaveNPS(nome, tipoAtendimento string, nota int) error {
+	log.Printf("Salvando NPS: %s, %s, %d", nome, tipoAtendimento, nota)
+
+	timestamp, iso := c.timestamps()
+
+	values := [][]interface{}{
+		{timestamp, nome, tipoAtendimento, nota, iso},
+	}
+
+	valueRange := &sheets.ValueRange{
+		Values: values,
+	}
+
+	_, err := c.service.Spreadsheets.Values.Append(SpreadsheetID, "Página4!A:E", valueRange).
+		ValueInputOption("RAW").
+		Do()
+
+	if err != nil {
+		log.Printf("Erro ao salvar NPS: %v", err)
+		return classifyError("SaveNPS", err)
+	}
+
+	log.Println("NPS salvo com sucesso!")
+	return nil
+}
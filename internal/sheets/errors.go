@@ -0,0 +1,85 @@
+package sheets
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+)
+
+// ErrorClass classifica as falhas da API do Google Sheets conforme a reação esperada de quem chama.
+type ErrorClass string
+
+const (
+	// ClassQuota indica cota ou rate limit excedido (429): guardar localmente e aguardar antes de reenviar.
+	ClassQuota ErrorClass = "quota"
+	// ClassAuth indica credenciais inválidas ou sem permissão na planilha (401/403): reenviar não resolve.
+	ClassAuth ErrorClass = "auth"
+	// ClassTransient indica falha temporária (5xx, timeout, rede): uma nova tentativa pode funcionar.
+	ClassTransient ErrorClass = "transient"
+	// ClassPermanent indica requisição rejeitada (demais 4xx).
+	ClassPermanent ErrorClass = "permanent"
+)
+
+// Error é o erro tipado retornado pelas gravações do Client.
+type Error struct {
+	Op    string
+	Class ErrorClass
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("sheets %s (%s): %v", e.Op, e.Class, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Quota indica se o erro é de cota excedida.
+func (e *Error) Quota() bool { return e.Class == ClassQuota }
+
+// Auth indica se o erro é de autenticação ou permissão.
+func (e *Error) Auth() bool { return e.Class == ClassAuth }
+
+// Temporary indica se o erro é transitório.
+func (e *Error) Temporary() bool { return e.Class == ClassTransient }
+
+// quotaReasons são os motivos com que a API sinaliza cota excedida, às vezes com status 403.
+var quotaReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+	"quotaExceeded":         true,
+	"RESOURCE_EXHAUSTED":    true,
+}
+
+// classifyError envolve o erro da operação op em *Error com a classe correspondente; nil continua nil.
+func classifyError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, Class: classOf(err), Err: err}
+}
+
+// classOf determina a classe de um erro da API do Google ou da camada de rede.
+func classOf(err error) ErrorClass {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		for _, item := range gerr.Errors {
+			if quotaReasons[item.Reason] {
+				return ClassQuota
+			}
+		}
+		switch {
+		case gerr.Code == http.StatusTooManyRequests:
+			return ClassQuota
+		case gerr.Code == http.StatusUnauthorized || gerr.Code == http.StatusForbidden:
+			return ClassAuth
+		case gerr.Code == http.StatusRequestTimeout || gerr.Code >= 500:
+			return ClassTransient
+		}
+		return ClassPermanent
+	}
+
+	// Falhas fora da API (rede, timeout, conexão recusada) são transitórias.
+	return ClassTransient
+}
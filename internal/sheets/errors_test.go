@@ -0,0 +1,50 @@
+package sheets
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestClassOf(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"429", &googleapi.Error{Code: http.StatusTooManyRequests}, ClassQuota},
+		{"403 por cota", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}, ClassQuota},
+		{"403", &googleapi.Error{Code: http.StatusForbidden}, ClassAuth},
+		{"401", &googleapi.Error{Code: http.StatusUnauthorized}, ClassAuth},
+		{"503", &googleapi.Error{Code: http.StatusServiceUnavailable}, ClassTransient},
+		{"408", &googleapi.Error{Code: http.StatusRequestTimeout}, ClassTransient},
+		{"400", &googleapi.Error{Code: http.StatusBadRequest}, ClassPermanent},
+		{"envolvido", fmt.Errorf("append: %w", &googleapi.Error{Code: http.StatusTooManyRequests}), ClassQuota},
+		{"rede", errors.New("connection refused"), ClassTransient},
+	}
+	for _, c := range cases {
+		if got := classOf(c.err); got != c.want {
+			t.Errorf("%s: classOf = %s; esperado %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	if classifyError("SavePlans", nil) != nil {
+		t.Error("classifyError(nil) != nil")
+	}
+
+	cause := &googleapi.Error{Code: http.StatusTooManyRequests}
+	err := classifyError("SavePlans", cause)
+	var se *Error
+	if !errors.As(err, &se) || se.Op != "SavePlans" || !se.Quota() || se.Auth() || se.Temporary() {
+		t.Fatalf("classifyError = %#v; esperado *Error de cota da operação", err)
+	}
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) || gerr != cause {
+		t.Error("o erro original da API deveria continuar acessível com errors.As")
+	}
+}
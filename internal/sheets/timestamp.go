@@ -0,0 +1,26 @@
+package sheets
+
+import "time"
+
+const (
+	// timestampLayout é o formato legível da coluna DATA/HORA, no padrão brasileiro.
+	timestampLayout = "02/01/2006 15:04:05"
+	// isoHeader é o cabeçalho da última coluna de cada aba, com a data/hora em ISO 8601.
+	isoHeader = "DATA/HORA (ISO 8601)"
+)
+
+// SetLocation define o fuso das datas gravadas na planilha (APP_TIMEZONE); sem ele vale o fuso local do servidor.
+func (c *Client) SetLocation(loc *time.Location) {
+	c.loc = loc
+}
+
+// timestamps retorna o instante atual no fuso configurado, no formato legível e em ISO 8601 (com o offset),
+// este último sem ambiguidade entre implantações em fusos diferentes.
+func (c *Client) timestamps() (human, iso string) {
+	loc := c.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	now := time.Now().In(loc)
+	return now.Format(timestampLayout), now.Format(time.RFC3339)
+}
@@ -0,0 +1,40 @@
+package sheets
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampsUseConfiguredLocation(t *testing.T) {
+	c := &Client{}
+	c.SetLocation(time.FixedZone("BRT", -3*60*60))
+
+	human, iso := c.timestamps()
+	if !strings.HasSuffix(iso, "-03:00") {
+		t.Errorf("iso = %q; esperado o offset do fuso configurado", iso)
+	}
+	parsedISO, err := time.Parse(time.RFC3339, iso)
+	if err != nil {
+		t.Fatalf("iso = %q não é RFC 3339: %v", iso, err)
+	}
+	parsedHuman, err := time.ParseInLocation(timestampLayout, human, c.loc)
+	if err != nil {
+		t.Fatalf("data/hora = %q fora do formato %q: %v", human, timestampLayout, err)
+	}
+	if !parsedHuman.Equal(parsedISO) {
+		t.Errorf("data/hora %q e iso %q representam instantes diferentes", human, iso)
+	}
+}
+
+func TestTimestampsDefaultToLocal(t *testing.T) {
+	_, iso := (&Client{}).timestamps()
+	parsed, err := time.Parse(time.RFC3339, iso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, want := time.Now().Zone()
+	if _, offset := parsed.Zone(); offset != want {
+		t.Errorf("iso = %q; esperado o fuso local do servidor sem SetLocation", iso)
+	}
+}
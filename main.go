@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -14,6 +19,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/rs/zerolog"
 	zerologlog "github.com/rs/zerolog/log"
+	"github.com/sirupsen/logrus"
 
 	"leadprojectarrumado/internal/ai"
 	"leadprojectarrumado/internal/handlers"
@@ -24,32 +30,45 @@ import (
 	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
+
+// Metadados de build, injetados com:
+// go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   string
+	commit    string
+	buildTime string
+)
+
+// defaultEnvFile é o arquivo .env lido sem ENV_FILE, relativo ao diretório de trabalho.
+const defaultEnvFile = ".env"
+
 func main() {
 	// 📋 Configurar logging
+	// Telefones, e-mails e CPFs são mascarados em todos os loggers (LOG_REDACT_PII=false desliga para depuração)
+	logOut := security.NewRedactingWriter(os.Stderr)
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
-	zerologlog.Logger = zerologlog.Output(zerolog.ConsoleWriter{Out: os.Stderr})
-
-	// 🔑 Carregar variáveis de ambiente
-	if err := godotenv.Load("C:/Users/Uaurio/Documents/LEADPROJECT/.env"); err != nil {
+	zerologlog.Logger = zerologlog.Output(zerolog.ConsoleWriter{Out: logOut})
+	log.SetOutput(logOut)
+	logrus.SetOutput(logOut)
+
+	// 🔑 Carregar variáveis de ambiente (ENV_FILE vem do ambiente do processo, não do próprio .env)
+	envFile := os.Getenv("ENV_FILE")
+	if envFile == "" {
+		envFile = defaultEnvFile
+	}
+	if err := godotenv.Load(envFile); err != nil {
 		zerologlog.Warn().Err(err).Msg("Arquivo .env não encontrado, usando variáveis de ambiente do sistema")
 	}
+	logOut.SetEnabled(os.Getenv("LOG_REDACT_PII") != "false")
 
-	// ▶️ Iniciar Datadog tracer (APM)
-	agentHost := os.Getenv("DD_AGENT_HOST")
-	if agentHost == "" {
-		agentHost = "localhost"
-	}
-	agentPort := os.Getenv("DD_TRACE_AGENT_PORT")
-	if agentPort == "" {
-		agentPort = "8126"
+	// ▶️ Iniciar Datadog tracer (APM), se habilitado
+	tracingEnabled = ddTraceEnabled(os.Getenv("DD_TRACE_ENABLED"), os.Getenv("DD_ENV"))
+	if tracingEnabled {
+		startTracer()
+		defer tracer.Stop()
+	} else {
+		zerologlog.Info().Msg("Datadog tracing desabilitado (DD_TRACE_ENABLED)")
 	}
-	tracer.Start(
-		tracer.WithAgentAddr(fmt.Sprintf("%s:%s", agentHost, agentPort)),
-		tracer.WithServiceName("qibot-chatbot"),
-		tracer.WithEnv(os.Getenv("DD_ENV")),
-		tracer.WithRuntimeMetrics(),
-	)
-	defer tracer.Stop()
 
 	// 🗄️ Configurar banco de dados SQLite
 	db, err := setupDatabase()
@@ -64,11 +83,16 @@ func main() {
 		zerologlog.Fatal().Err(err).Msg("Erro ao configurar Google Sheets")
 	}
 	
+	// ⚙️ Configuração dos serviços, lida antes da IA para repassar os limites de resposta ao cliente
+	serviceCfg := services.LoadConfig()
+
 	// 🤖 Configurar cliente IA Gemini
-	aiClient, err := ai.NewClient()
-	if err != nil {
+	// A interface só recebe o cliente quando ele existe, para que s.ai != nil reflita a disponibilidade.
+	var aiClient services.AIClient
+	if client, err := ai.NewClient(serviceCfg.AILimits); err != nil {
 		zerologlog.Warn().Err(err).Msg("IA Gemini não disponível")
 	} else {
+		aiClient = client
 		zerologlog.Info().Msg("Gemini habilitado.")
 	}
 
@@ -77,16 +101,68 @@ func main() {
 	defer redisClient.Close()
 
 	// ⚙️ Configurar serviços
-	chatbotService := services.NewChatbotService(redisClient, db, sheetsClient, aiClient)
+	sheetsClient.SetLocation(serviceCfg.Location)
+	chatbotService := services.NewChatbotService(redisClient, db, sheetsClient, aiClient, serviceCfg)
+	// O handler do webhook é criado aqui para que o envio proativo use o mesmo sender das respostas
+	whatsappHandler := handlers.NewWhatsAppWebhookHandler(chatbotService, handlers.CloudAPISender{})
+	chatbotService.RegisterPusher(services.ChannelWhatsApp, whatsappHandler.Push)
+	chatbotService.RegisterPusher(services.ChannelMessenger, handlers.SendMessengerMessage)
+	if url := os.Getenv("BOLETO_PORTAL_URL"); url != "" {
+		chatbotService.SetBoletoProvider(services.StubBoletoProvider{BaseURL: url})
+	}
+	// Cadastro de clientes consultado no fluxo de planos (ativado com CUSTOMER_LOOKUP_ENABLED)
+	chatbotService.SetCustomerLookup(services.DBCustomerLookup{DB: db})
+	if url := os.Getenv("STORE_WEBHOOK_URL"); url != "" {
+		chatbotService.AddStore("webhook", services.NewWebhookStore(url))
+	}
+	for _, name := range []string{services.EventLeadCreated, services.EventTicketEscalated, services.EventFeedbackReceived} {
+		chatbotService.Events().Subscribe(name, func(e services.Event) {
+			zerologlog.Info().Str("event", e.EventName()).Msg("Evento de domínio publicado")
+		})
+	}
+
+	// ⏳ Lembretes e expiração de sessões inativas
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	chatbotService.StartInactivitySweeper(sweeperCtx)
+	chatbotService.StartSheetsReplay(sweeperCtx)
+	chatbotService.StartRedisHeartbeat(sweeperCtx)
+	chatbotService.StartReplyReplay(sweeperCtx)
+	security.StartTarpitSweeper(sweeperCtx)
+	chatbotService.StartDailyReport(sweeperCtx)
+
+	// 🔄 SIGHUP relê o .env, os textos do menu/planos/contatos e a denylist sem reiniciar
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := godotenv.Overload(envFile); err != nil {
+				zerologlog.Warn().Err(err).Msg("Arquivo .env não encontrado na recarga, usando variáveis de ambiente do sistema")
+			}
+			logOut.SetEnabled(os.Getenv("LOG_REDACT_PII") != "false")
+			chatbotService.ReloadConfig()
+			if _, err := chatbotService.ReloadDenylist(); err != nil {
+				zerologlog.Error().Err(err).Msg("Erro ao recarregar denylist")
+			}
+		}
+	}()
 
 	// 🚪 Configurar handlers
 	chatbotHandler := handlers.NewChatbotHandler(chatbotService)
+	adminHandler := handlers.NewAdminHandler(chatbotService)
 
 	// 🌐 Configurar rotas
-	setupRoutes(chatbotHandler)
+	setupRoutes(chatbotHandler, adminHandler, whatsappHandler, redisClient)
 
 	// 🚀 Iniciar servidor
-	startServer()
+	startServer(whatsappHandler)
+
+	// 💾 Gravações em segundo plano (Sheets e banco) terminam antes de fechar o banco
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelShutdown()
+	if err := chatbotService.Shutdown(shutdownCtx); err != nil {
+		zerologlog.Error().Err(err).Msg("Gravações pendentes não concluídas no desligamento")
+	}
 }
 
 func setupDatabase() (*sql.DB, error) {
@@ -94,22 +170,9 @@ func setupDatabase() (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	// Criar tabela se não existir
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS leads (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			nome TEXT NOT NULL,
-			telefone TEXT,
-			email TEXT,
-			tipo TEXT DEFAULT 'Lead',
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
+	if err := services.SetupSchema(db); err != nil {
 		return nil, err
 	}
-
 	return db, nil
 }
 
@@ -141,44 +204,111 @@ func setupRedis() *redis.Client {
 	return client
 }
 
-func setupRoutes(chatbotHandler *handlers.ChatbotHandler) {
+func setupRoutes(chatbotHandler *handlers.ChatbotHandler, adminHandler *handlers.AdminHandler, whatsappHandler *handlers.WhatsAppWebhookHandler, redisClient *redis.Client) {
 	cfg := security.LoadConfig()
-	rl := security.NewGlobalRateLimiter(cfg.RatePerMinute)
+	var rl security.Limiter = security.NewGlobalRateLimiter(cfg.RatePerMinute)
+	if cfg.RateLimitBackend == "redis" {
+		rl = security.NewRedisRateLimiter(redisClient, cfg.RatePerMinute)
+	}
+	cl := security.NewConcurrencyLimiter(cfg.MaxConcurrentPerIP)
 
 	// Wrappear handlers com Datadog tracing
-	tracedChatbot := httptrace.WrapHandler(http.HandlerFunc(chatbotHandler.HandleChatbot), "qibot-chatbot", "/chatbot")
-	tracedHealth := httptrace.WrapHandler(http.HandlerFunc(chatbotHandler.HandleHealth), "qibot-chatbot", "/health")
-
-	http.Handle("/chatbot", security.WrapHandler(tracedChatbot, cfg, rl))
-	http.Handle("/health", security.WrapHandler(tracedHealth, cfg, rl))
+	tracedChatbot := traced(http.HandlerFunc(chatbotHandler.HandleChatbot), "/chatbot")
+	tracedHealth := traced(http.HandlerFunc(chatbotHandler.HandleHealth), "/health")
+	tracedReady := traced(http.HandlerFunc(chatbotHandler.HandleReady), "/readyz")
+	tracedStatus := traced(http.HandlerFunc(chatbotHandler.HandleStatus), "/status")
+
+	http.Handle("/chatbot", security.WrapHandler(security.MethodGuard(tracedChatbot, http.MethodPost, http.MethodOptions), cfg, rl, cl))
+	http.Handle("/health", security.WrapHandler(security.MethodGuard(tracedHealth, http.MethodGet, http.MethodHead), cfg, rl, cl))
+	http.Handle("/readyz", security.WrapHandler(security.MethodGuard(tracedReady, http.MethodGet, http.MethodHead), cfg, rl, cl))
+	http.Handle("/status", security.WrapHandler(security.MethodGuard(tracedStatus, http.MethodGet, http.MethodOptions), cfg, rl, cl))
+	versionHandler := handlers.HandleVersion(handlers.BuildInfo{Version: version, Commit: commit, BuildTime: buildTime})
+	http.Handle("/version", security.WrapHandler(security.MethodGuard(versionHandler, http.MethodGet, http.MethodHead), cfg, rl, cl))
+	if catalog, ok := chatbotHandler.Service().(handlers.PlanCatalog); ok {
+		http.Handle("/plans", security.WrapHandler(security.MethodGuard(handlers.HandlePlans(catalog), http.MethodGet, http.MethodHead), cfg, rl, cl))
+	}
 	http.HandleFunc("/", chatbotHandler.HandleStatic) // página estática sem wrappers
 
 	// WhatsApp webhook handler
-	whatsappHandler := handlers.NewWhatsAppWebhookHandler(chatbotHandler.Service())
-	http.HandleFunc("/webhook/whatsapp", whatsappHandler.HandleWhatsAppWebhook)
+	http.Handle("/webhook/whatsapp", security.MethodGuard(http.HandlerFunc(whatsappHandler.HandleWhatsAppWebhook), http.MethodGet, http.MethodPost))
+	messengerHandler := handlers.NewMessengerWebhookHandler(chatbotHandler.Service())
+	http.Handle("/webhook/messenger", security.MethodGuard(http.HandlerFunc(messengerHandler.HandleMessengerWebhook), http.MethodGet, http.MethodPost))
+
+	// Endpoints administrativos (exigem ADMIN_TOKEN)
+	resolveHandoff := security.MethodGuard(http.HandlerFunc(adminHandler.HandleHandoffResolve), http.MethodPost)
+	http.Handle("/admin/handoff/resolve", security.WrapHandler(security.RequireAdmin(resolveHandoff, cfg.AdminToken), cfg, rl, cl))
+	menuAnalytics := security.MethodGuard(http.HandlerFunc(adminHandler.HandleMenuAnalytics), http.MethodGet)
+	http.Handle("/admin/analytics/menu", security.WrapHandler(security.RequireAdmin(menuAnalytics, cfg.AdminToken), cfg, rl, cl))
+	maintenance := security.MethodGuard(http.HandlerFunc(adminHandler.HandleMaintenance), http.MethodGet, http.MethodPost)
+	http.Handle("/admin/maintenance", security.WrapHandler(security.RequireAdmin(maintenance, cfg.AdminToken), cfg, rl, cl))
+	flags := security.MethodGuard(http.HandlerFunc(adminHandler.HandleFeatureFlags), http.MethodGet, http.MethodPost)
+	http.Handle("/admin/flags", security.WrapHandler(security.RequireAdmin(flags, cfg.AdminToken), cfg, rl, cl))
+	sessionReset := security.MethodGuard(http.HandlerFunc(adminHandler.HandleSessionReset), http.MethodPost)
+	http.Handle("/admin/session/reset", security.WrapHandler(security.RequireAdmin(sessionReset, cfg.AdminToken), cfg, rl, cl))
+	sessionInspect := security.MethodGuard(http.HandlerFunc(adminHandler.HandleSessionInspect), http.MethodGet)
+	http.Handle("/admin/session/", security.WrapHandler(security.RequireAdmin(sessionInspect, cfg.AdminToken), cfg, rl, cl))
+	denylistReload := security.MethodGuard(http.HandlerFunc(adminHandler.HandleDenylistReload), http.MethodPost)
+	http.Handle("/admin/denylist/reload", security.WrapHandler(security.RequireAdmin(denylistReload, cfg.AdminToken), cfg, rl, cl))
+	sessionStats := security.MethodGuard(http.HandlerFunc(adminHandler.HandleSessionStats), http.MethodGet)
+	http.Handle("/admin/sessions/stats", security.WrapHandler(security.RequireAdmin(sessionStats, cfg.AdminToken), cfg, rl, cl))
+	notificationsReplay := security.MethodGuard(http.HandlerFunc(adminHandler.HandleNotificationsReplay), http.MethodPost)
+	http.Handle("/admin/notifications/replay", security.WrapHandler(security.RequireAdmin(notificationsReplay, cfg.AdminToken), cfg, rl, cl))
+	storesReplay := security.MethodGuard(http.HandlerFunc(adminHandler.HandleStoresReplay), http.MethodPost)
+	http.Handle("/admin/stores/replay", security.WrapHandler(security.RequireAdmin(storesReplay, cfg.AdminToken), cfg, rl, cl))
+
+	// Rotas de depuração (QA e demonstrações); desligadas por padrão, sem rota registrada respondem 404.
+	if os.Getenv("DEBUG_ROUTES") == "true" {
+		zerologlog.Warn().Msg("DEBUG_ROUTES habilitado: /debug/simulate exposto")
+		simulate := security.MethodGuard(http.HandlerFunc(chatbotHandler.HandleSimulate), http.MethodPost)
+		http.Handle("/debug/simulate", security.WrapHandler(security.RequireAdmin(simulate, cfg.AdminToken), cfg, rl, cl))
+	}
 }
 
-func startServer() {
+func startServer(whatsappHandler *handlers.WhatsAppWebhookHandler) {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8081"
 	}
+	addr := listenAddr(os.Getenv("BIND_ADDR"), port)
 
-	server := &http.Server{
-		Addr:         "0.0.0.0:" + port,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	cfg := security.LoadConfig()
+	server := newServer(addr, cfg)
+	// Cancelado ao receber o sinal de parada, para interromper esperas longas das requisições em andamento.
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+	server.BaseContext = func(net.Listener) context.Context { return baseCtx }
+	if !cfg.TLSEnabled() {
+		zerologlog.Warn().Msg("⚠️ TLS_CERT_FILE/TLS_KEY_FILE não configurados: servindo HTTP sem criptografia (apenas desenvolvimento)")
+	}
+	var redirect *http.Server
+	if cfg.TLSEnabled() && cfg.HTTPRedirectAddr != "" {
+		redirect = newServer(cfg.HTTPRedirectAddr, cfg)
+		redirect.Handler = redirectToHTTPS(port)
+		go func() {
+			if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				zerologlog.Error().Err(err).Msg("Erro no listener de redirecionamento HTTPS")
+			}
+		}()
 	}
 
-	// Canal para capturar sinais do sistema
+	// Canal para capturar sinais do sistema; docker stop e o Kubernetes enviam SIGTERM
 	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	// Iniciar servidor em goroutine
 	go func() {
-		zerologlog.Info().Msgf("🚀 QIBOT rodando em http://localhost:8081")
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		scheme := "http"
+		if cfg.TLSEnabled() {
+			scheme = "https"
+		}
+		zerologlog.Info().Msgf("🚀 QIBOT rodando em %s://%s", scheme, addr)
+		var err error
+		if cfg.TLSEnabled() {
+			err = server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			zerologlog.Fatal().Err(err).Msg("Erro ao iniciar servidor")
 		}
 	}()
@@ -186,16 +316,105 @@ func startServer() {
 	// Aguardar sinal de parada
 	<-stop
 	zerologlog.Info().Msg("🛑 Parando servidor...")
+	cancelBase()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if redirect != nil {
+		redirect.Shutdown(ctx)
+	}
 	if err := server.Shutdown(ctx); err != nil {
 		zerologlog.Error().Err(err).Msg("Erro ao parar servidor")
 	} else {
 		zerologlog.Info().Msg("✅ Servidor parado com sucesso")
 	}
+	// Mensagens do WhatsApp já confirmadas ao Meta terminam de ser processadas antes de sair.
+	if err := whatsappHandler.Drain(ctx); err != nil {
+		zerologlog.Error().Err(err).Msg("Mensagens do WhatsApp pendentes não processadas no desligamento")
+	}
+}
+
+// tracingEnabled indica se o tracer do Datadog foi iniciado; sem ele os handlers não são instrumentados.
+var tracingEnabled bool
+
+// ddTraceEnabled decide se o tracing deve ser ligado: DD_TRACE_ENABLED explícito prevalece; sem ele,
+// o tracing fica desligado em desenvolvimento (DD_ENV vazio, dev, development ou local).
+func ddTraceEnabled(flag, env string) bool {
+	if enabled, err := strconv.ParseBool(strings.TrimSpace(flag)); err == nil {
+		return enabled
+	}
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case "", "dev", "development", "local":
+		return false
+	}
+	return true
+}
+
+// startTracer inicia o tracer com o agente em DD_AGENT_HOST:DD_TRACE_AGENT_PORT e a taxa de
+// amostragem de DD_TRACE_SAMPLE_RATE (0 a 1, padrão 1).
+func startTracer() {
+	agentHost := os.Getenv("DD_AGENT_HOST")
+	if agentHost == "" {
+		agentHost = "localhost"
+	}
+	agentPort := os.Getenv("DD_TRACE_AGENT_PORT")
+	if agentPort == "" {
+		agentPort = "8126"
+	}
+	opts := []tracer.StartOption{
+		tracer.WithAgentAddr(fmt.Sprintf("%s:%s", agentHost, agentPort)),
+		tracer.WithServiceName("qibot-chatbot"),
+		tracer.WithEnv(os.Getenv("DD_ENV")),
+		tracer.WithRuntimeMetrics(),
+	}
+	if rate, err := strconv.ParseFloat(os.Getenv("DD_TRACE_SAMPLE_RATE"), 64); err == nil && rate >= 0 && rate <= 1 {
+		opts = append(opts, tracer.WithSamplingRules([]tracer.SamplingRule{tracer.RateRule(rate)}))
+	}
+	tracer.Start(opts...)
+}
+
+// traced instrumenta o handler com o Datadog quando o tracing está habilitado.
+func traced(h http.Handler, resource string) http.Handler {
+	if !tracingEnabled {
+		return h
+	}
+	return httptrace.WrapHandler(h, "qibot-chatbot", resource)
+}
+
+// listenAddr monta o endereço de escuta a partir de BIND_ADDR (padrão 0.0.0.0) e PORT,
+// colocando endereços IPv6 entre colchetes.
+func listenAddr(bindAddr, port string) string {
+	bindAddr = strings.TrimSpace(bindAddr)
+	if bindAddr == "" {
+		bindAddr = "0.0.0.0"
+	}
+	return net.JoinHostPort(strings.Trim(bindAddr, "[]"), port)
+}
+
+// newServer monta o servidor HTTP com os timeouts da configuração de segurança.
+func newServer(addr string, cfg security.SecurityConfig) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
+
+// redirectToHTTPS redireciona requisições HTTP para o mesmo caminho na porta HTTPS.
+func redirectToHTTPS(tlsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if tlsPort != "443" {
+			host = net.JoinHostPort(host, tlsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
 }
 
 //Copyright 2025 Kauan Botura
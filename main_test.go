@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"leadprojectarrumado/internal/handlers"
+	"leadprojectarrumado/internal/security"
+	"leadprojectarrumado/internal/services"
+)
+
+func TestNewServerUsesConfiguredTimeouts(t *testing.T) {
+	cfg := security.SecurityConfig{ReadTimeout: 5 * time.Second, WriteTimeout: 7 * time.Second, IdleTimeout: 9 * time.Second}
+
+	server := newServer(":8443", cfg)
+	if server.Addr != ":8443" || server.ReadTimeout != cfg.ReadTimeout || server.WriteTimeout != cfg.WriteTimeout || server.IdleTimeout != cfg.IdleTimeout {
+		t.Errorf("servidor = %s %s/%s/%s; esperado o endereço e os timeouts da configuração",
+			server.Addr, server.ReadTimeout, server.WriteTimeout, server.IdleTimeout)
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	cases := []struct {
+		tlsPort, host, want string
+	}{
+		{"443", "bot.exemplo.com:80", "https://bot.exemplo.com/chatbot?x=1"},
+		{"8443", "bot.exemplo.com", "https://bot.exemplo.com:8443/chatbot?x=1"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/chatbot?x=1", nil)
+		req.Host = c.host
+		rec := httptest.NewRecorder()
+		redirectToHTTPS(c.tlsPort).ServeHTTP(rec, req)
+		if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != c.want {
+			t.Errorf("porta %s: %d %q; esperado 301 para %q", c.tlsPort, rec.Code, rec.Header().Get("Location"), c.want)
+		}
+	}
+}
+
+func TestTLSEnabledRequiresCertAndKey(t *testing.T) {
+	t.Setenv("TLS_CERT_FILE", "cert.pem")
+	if security.LoadConfig().TLSEnabled() {
+		t.Error("TLS habilitado só com o certificado; esperado exigir também a chave")
+	}
+	t.Setenv("TLS_KEY_FILE", "key.pem")
+	if !security.LoadConfig().TLSEnabled() {
+		t.Error("TLS desabilitado com certificado e chave configurados")
+	}
+}
+
+func TestListenAddr(t *testing.T) {
+	cases := []struct{ bind, port, want string }{
+		{"", "8080", "0.0.0.0:8080"},
+		{" 127.0.0.1 ", "8080", "127.0.0.1:8080"},
+		{"::1", "8080", "[::1]:8080"},
+		{"[::]", "9000", "[::]:9000"},
+	}
+	for _, c := range cases {
+		if got := listenAddr(c.bind, c.port); got != c.want {
+			t.Errorf("listenAddr(%q, %q) = %q; esperado %q", c.bind, c.port, got, c.want)
+		}
+	}
+}
+
+func TestDDTraceEnabled(t *testing.T) {
+	cases := []struct {
+		flag, env string
+		want      bool
+	}{
+		{"", "", false},
+		{"", "dev", false},
+		{"", " Local ", false},
+		{"", "production", true},
+		{"true", "dev", true},
+		{"false", "production", false},
+		{"talvez", "staging", true},
+	}
+	for _, c := range cases {
+		if got := ddTraceEnabled(c.flag, c.env); got != c.want {
+			t.Errorf("ddTraceEnabled(%q, %q) = %v; esperado %v", c.flag, c.env, got, c.want)
+		}
+	}
+}
+
+func TestDebugSimulateNotRegisteredByDefault(t *testing.T) {
+	t.Setenv("DEBUG_ROUTES", "")
+	t.Setenv("ADMIN_TOKEN", "admin")
+	// setupRoutes registra no DefaultServeMux; um mux novo permite rodar o teste mais de uma vez.
+	defaultMux := http.DefaultServeMux
+	http.DefaultServeMux = http.NewServeMux()
+	defer func() { http.DefaultServeMux = defaultMux }()
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond, MaxRetries: -1})
+	defer rdb.Close()
+	svc := services.NewChatbotService(rdb, nil, nil, nil, services.LoadConfig())
+	defer svc.Shutdown(context.Background())
+	setupRoutes(handlers.NewChatbotHandler(svc), handlers.NewAdminHandler(svc), handlers.NewWhatsAppWebhookHandler(svc, nil), rdb)
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/simulate", strings.NewReader(`{"user_id":"qa-1","messages":["oi"]}`))
+	req.Header.Set("Authorization", "Bearer admin")
+	rec := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("/debug/simulate sem DEBUG_ROUTES = %d; esperado 404", rec.Code)
+	}
+}